@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"x2-sharding-module/sharding"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// Order 订单模型（按地区分表）
+type Order struct {
+	ID        uint      `gorm:"primarykey"`
+	Region    string    `gorm:"column:region;size:8;not null"`
+	Amount    float64   `gorm:"column:amount"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+}
+
+func main() {
+	// 连接数据库
+	dsn := "root:password@tcp(localhost:3306)/testdb?charset=utf8mb4&parseTime=True&loc=Local"
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+
+	// 示例 1: 列表分表（按状态值映射到分表）
+	fmt.Println("=== 列表分表 ===")
+
+	listStrategy := sharding.NewListShardingStrategy("tickets", "Status", map[interface{}]string{
+		"open":    "active",
+		"pending": "active",
+		"closed":  "archive",
+	})
+	listStrategy.SetDefaultTable("other")
+	fmt.Printf("Status 'pending' will be in table: %s\n", listStrategy.GetTableName("tickets", "pending"))
+	fmt.Printf("All list sharding tables: %v\n", listStrategy.GetAllTableNames("tickets"))
+
+	// 示例 2: 地区分表（按地区组分表）
+	fmt.Println("\n=== 地区分表 ===")
+
+	geoStrategy := sharding.NewGeoShardingStrategy("orders", "Region", sharding.GeoShardingOptions{
+		Groups: map[string][]string{
+			"eu":   {"DE", "FR", "IT"},
+			"us":   {"US", "CA"},
+			"apac": {"CN", "JP", "SG"},
+		},
+	})
+	if err := sharding.RegisterSharding(db, geoStrategy); err != nil {
+		log.Fatal("Failed to register sharding:", err)
+	}
+
+	fmt.Printf("Region 'cn' will be in table: %s\n", geoStrategy.GetTableName("orders", "cn"))
+	fmt.Printf("Tables for regions DE, US: %v\n", geoStrategy.TablesForRegions("DE", "US"))
+	fmt.Printf("All geo sharding tables: %v\n", geoStrategy.GetAllTableNames("orders"))
+
+	// 创建订单（自动路由到地区组的分表）
+	order := &Order{Region: "FR", Amount: 99.5, CreatedAt: time.Now()}
+	if err := db.Create(order).Error; err != nil {
+		log.Printf("Failed to create order: %v", err)
+	}
+
+	// 示例 3: 地区分表，再按月分表（按地区+日期）
+	fmt.Println("\n=== 地区分表 + 按月分表 ===")
+
+	regionDateStrategy := sharding.NewCompositeShardingStrategy(
+		"orders",
+		geoStrategy,
+		sharding.NewTimeShardingStrategy("orders", "CreatedAt", sharding.TimeShardingByMonth),
+	)
+
+	shardingValue := sharding.CompositeShardingValue{
+		Primary:   "CN",
+		Secondary: time.Date(2024, 1, 15, 0, 0, 0, 0, time.Local),
+	}
+	tableName := regionDateStrategy.GetTableName("orders", shardingValue)
+	fmt.Printf("Order with region CN on 2024-01-15 will be in table: %s\n", tableName)
+}
@@ -3,12 +3,14 @@ module x2-sharding-module
 go 1.25.0
 
 require (
+	github.com/go-sql-driver/mysql v1.9.3
 	gorm.io/driver/mysql v1.6.0
 	gorm.io/gorm v1.31.1
 )
 
 require (
-	github.com/go-sql-driver/mysql v1.9.3 // indirect
+	filippo.io/edwards25519 v1.1.0 // indirect
 	github.com/jinzhu/inflection v1.0.0 // indirect
 	github.com/jinzhu/now v1.1.5 // indirect
+	golang.org/x/text v0.40.0 // indirect
 )
@@ -0,0 +1,347 @@
+package sharding
+
+import (
+	"database/sql"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// AggregateFunc 聚合函数
+type AggregateFunc string
+
+const (
+	AggregateSum   AggregateFunc = "SUM"
+	AggregateAvg   AggregateFunc = "AVG"
+	AggregateMin   AggregateFunc = "MIN"
+	AggregateMax   AggregateFunc = "MAX"
+	AggregateCount AggregateFunc = "COUNT"
+)
+
+// Aggregation 聚合表达式
+type Aggregation struct {
+	Func   AggregateFunc // 聚合函数
+	Column string        // 聚合列（COUNT 时为空或 "*" 表示 COUNT(*)）
+}
+
+// CrossTableAggregate 跨表聚合，在每个分表中执行聚合后按正确的语义合并结果
+// SUM/COUNT 直接相加；AVG 在每个分表中计算 SUM 和 COUNT，合并后再相除；MIN/MAX 逐个比较
+// 返回值：COUNT 为 int64；SUM 与列的类型一致：整数为 int64（溢出时返回 ErrAggregateOverflow），
+// DECIMAL 为精确计算的十进制字符串，浮点数为 float64；AVG 对 DECIMAL 为十进制字符串（比 SUM 多 4 位小数），其余为 float64；
+// MIN、MAX 为列的值（数字会转换为 int64/float64）。
+// 没有任何满足条件的记录时，SUM 返回 int64(0)，AVG、MIN、MAX 返回 nil；
+// 部分分表失败时（ContinueOnError，或数据源被健康检查排除、分表熔断）返回成功分表的聚合结果和 ShardErrors
+func CrossTableAggregate(db *gorm.DB, strategy ShardingStrategy, aggregation Aggregation, queryBuilder QueryBuilder, options ...ShardQueryOptions) (interface{}, error) {
+	selects, err := aggregationSelects(db, aggregation)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := getShardQueryOptions(options)
+	tableNames, err := allShardTables(db, strategy)
+	if err != nil {
+		return nil, err
+	}
+	partials := make([][]interface{}, len(tableNames))
+	queryErr := runOnShards(db, tableNames, opts, func(tx *gorm.DB, index int, tableName string) error {
+		query := tx.Table(tableName)
+		if queryBuilder != nil {
+			query = queryBuilder(query)
+		}
+
+		rows, err := query.Select(strings.Join(selects, ", ")).Rows()
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		columnTypes, err := rows.ColumnTypes()
+		if err != nil {
+			return err
+		}
+		if !rows.Next() {
+			if err := rows.Err(); err != nil {
+				return err
+			}
+			return sql.ErrNoRows
+		}
+
+		partial := make([]interface{}, len(selects))
+		scanArgs := make([]interface{}, len(selects))
+		for i := range partial {
+			scanArgs[i] = &partial[i]
+		}
+		if err := rows.Scan(scanArgs...); err != nil {
+			return err
+		}
+		// 驱动以文本返回的浮点数按列的类型转换，避免被当作 DECIMAL 合并
+		for i := range partial {
+			partial[i] = normalizeFloatPartial(partial[i], columnTypes[i])
+		}
+		partials[index] = partial
+		return rows.Err()
+	})
+	if queryErr != nil && !isPartialShardError(queryErr) {
+		return nil, queryErr
+	}
+
+	// 按分表顺序合并部分聚合结果（部分分表失败时只包含成功的分表）
+	state := &aggregateState{}
+	for _, partial := range partials {
+		if partial == nil {
+			continue
+		}
+		if err := state.merge(aggregation.Func, partial); err != nil {
+			return nil, err
+		}
+	}
+
+	return state.result(aggregation.Func), queryErr
+}
+
+// aggregationSelects 生成在每个分表中执行的部分聚合表达式
+func aggregationSelects(db *gorm.DB, aggregation Aggregation) ([]string, error) {
+	column := aggregation.Column
+	if column != "" && column != "*" {
+		column = db.Statement.Quote(column)
+	}
+
+	switch aggregation.Func {
+	case AggregateCount:
+		if column == "" {
+			column = "*"
+		}
+		return []string{"COUNT(" + column + ")"}, nil
+	case AggregateSum, AggregateMin, AggregateMax:
+		if column == "" || column == "*" {
+			return nil, fmt.Errorf("aggregate %s requires a column", aggregation.Func)
+		}
+		return []string{string(aggregation.Func) + "(" + column + ")"}, nil
+	case AggregateAvg:
+		if column == "" || column == "*" {
+			return nil, fmt.Errorf("aggregate %s requires a column", aggregation.Func)
+		}
+		// AVG 不能直接合并，拆分为 SUM 和 COUNT
+		return []string{"SUM(" + column + ")", "COUNT(" + column + ")"}, nil
+	default:
+		return nil, fmt.Errorf("unsupported aggregate function: %s", aggregation.Func)
+	}
+}
+
+// numberKind 部分聚合结果的数值类型，合并时按 整数 < DECIMAL < 浮点数 提升
+type numberKind int
+
+const (
+	numberInt numberKind = iota
+	numberDecimal
+	numberFloat
+)
+
+// aggregateNumber 部分聚合结果（SUM）的数值，按 kind 使用 i、r 或 f
+type aggregateNumber struct {
+	kind  numberKind
+	i     int64
+	r     *big.Rat
+	f     float64
+	scale int // DECIMAL 的小数位数
+}
+
+// aggregateState 聚合的合并状态
+type aggregateState struct {
+	sum      aggregateNumber
+	count    int64
+	value    interface{} // MIN/MAX 的当前值
+	hasValue bool
+}
+
+// merge 合并一个分表的部分聚合结果
+func (s *aggregateState) merge(fn AggregateFunc, partial []interface{}) error {
+	switch fn {
+	case AggregateCount:
+		count, err := toInt64(partial[0])
+		if err != nil {
+			return err
+		}
+		s.count += count
+	case AggregateSum:
+		return s.addSum(partial[0])
+	case AggregateAvg:
+		count, err := toInt64(partial[1])
+		if err != nil {
+			return err
+		}
+		if err := s.addSum(partial[0]); err != nil {
+			return err
+		}
+		s.count += count
+	case AggregateMin, AggregateMax:
+		value := normalizeScannedValue(partial[0])
+		if value == nil {
+			return nil
+		}
+		c := compareValues(value, s.value)
+		if !s.hasValue || (fn == AggregateMin && c < 0) || (fn == AggregateMax && c > 0) {
+			s.value = value
+			s.hasValue = true
+		}
+	}
+	return nil
+}
+
+// result 获取最终的聚合结果
+func (s *aggregateState) result(fn AggregateFunc) interface{} {
+	switch fn {
+	case AggregateCount:
+		return s.count
+	case AggregateSum:
+		switch s.sum.kind {
+		case numberDecimal:
+			return s.sum.r.FloatString(s.sum.scale)
+		case numberFloat:
+			return s.sum.f
+		default:
+			return s.sum.i
+		}
+	case AggregateAvg:
+		if s.count == 0 {
+			return nil
+		}
+		switch s.sum.kind {
+		case numberDecimal:
+			avg := new(big.Rat).Quo(s.sum.r, new(big.Rat).SetInt64(s.count))
+			return avg.FloatString(s.sum.scale + 4)
+		case numberFloat:
+			return s.sum.f / float64(s.count)
+		default:
+			return float64(s.sum.i) / float64(s.count)
+		}
+	default:
+		if !s.hasValue {
+			return nil
+		}
+		return s.value
+	}
+}
+
+// addSum 累加一个分表的 SUM（NULL 忽略），整数溢出时返回 ErrAggregateOverflow
+func (s *aggregateState) addSum(value interface{}) error {
+	n, ok, err := parseAggregateNumber(value)
+	if err != nil || !ok {
+		return err
+	}
+
+	if n.kind > s.sum.kind {
+		s.sum = s.sum.promote(n.kind)
+	} else {
+		n = n.promote(s.sum.kind)
+	}
+	switch s.sum.kind {
+	case numberInt:
+		sum := s.sum.i + n.i
+		if (sum > s.sum.i) != (n.i > 0) {
+			return fmt.Errorf("%w: SUM exceeds int64 (%d + %d)", ErrAggregateOverflow, s.sum.i, n.i)
+		}
+		s.sum.i = sum
+	case numberDecimal:
+		s.sum.r.Add(s.sum.r, n.r)
+		if n.scale > s.sum.scale {
+			s.sum.scale = n.scale
+		}
+	case numberFloat:
+		s.sum.f += n.f
+	}
+	return nil
+}
+
+// promote 将数值转换为更宽的类型
+func (n aggregateNumber) promote(kind numberKind) aggregateNumber {
+	if kind == n.kind {
+		return n
+	}
+	switch kind {
+	case numberDecimal:
+		return aggregateNumber{kind: numberDecimal, r: new(big.Rat).SetInt64(n.i)}
+	case numberFloat:
+		if n.kind == numberDecimal {
+			f, _ := n.r.Float64()
+			return aggregateNumber{kind: numberFloat, f: f}
+		}
+		return aggregateNumber{kind: numberFloat, f: float64(n.i)}
+	}
+	return n
+}
+
+// parseAggregateNumber 解析部分聚合结果：整数（包括没有小数部分的 DECIMAL 文本）、DECIMAL 文本和浮点数，NULL 时返回 false
+func parseAggregateNumber(value interface{}) (aggregateNumber, bool, error) {
+	switch v := value.(type) {
+	case nil:
+		return aggregateNumber{}, false, nil
+	case float64:
+		return aggregateNumber{kind: numberFloat, f: v}, true, nil
+	case float32:
+		return aggregateNumber{kind: numberFloat, f: float64(v)}, true, nil
+	case []byte:
+		return parseAggregateNumber(string(v))
+	case string:
+		if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return aggregateNumber{kind: numberInt, i: i}, true, nil
+		}
+		r, ok := new(big.Rat).SetString(v)
+		if !ok {
+			return aggregateNumber{}, false, fmt.Errorf("failed to convert aggregate value %q to a number", v)
+		}
+		scale := 0
+		if dot := strings.IndexByte(v, '.'); dot >= 0 {
+			scale = len(v) - dot - 1
+		}
+		return aggregateNumber{kind: numberDecimal, r: r, scale: scale}, true, nil
+	default:
+		i, err := toInt64(value)
+		if err != nil {
+			return aggregateNumber{}, false, err
+		}
+		return aggregateNumber{kind: numberInt, i: i}, true, nil
+	}
+}
+
+// normalizeFloatPartial 将驱动以文本返回的浮点数列（FLOAT、DOUBLE、REAL 等）转换为 float64
+func normalizeFloatPartial(value interface{}, columnType *sql.ColumnType) interface{} {
+	b, ok := value.([]byte)
+	if !ok || columnType == nil {
+		return value
+	}
+	switch strings.ToUpper(columnType.DatabaseTypeName()) {
+	case "FLOAT", "DOUBLE", "REAL", "FLOAT4", "FLOAT8", "DOUBLE PRECISION":
+		if f, err := strconv.ParseFloat(string(b), 64); err == nil {
+			return f
+		}
+	}
+	return value
+}
+
+// normalizeScannedValue 规范化扫描到的值：驱动以文本返回的数字转换为 int64/float64，其余文本转换为字符串
+func normalizeScannedValue(value interface{}) interface{} {
+	b, ok := value.([]byte)
+	if !ok {
+		return value
+	}
+	s := string(b)
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+	return s
+}
+
+// toInt64 将扫描到的值转换为 int64（NULL 视为 0）
+func toInt64(value interface{}) (int64, error) {
+	var n sql.NullInt64
+	if err := n.Scan(value); err != nil {
+		return 0, fmt.Errorf("failed to convert aggregate value %v to int64: %w", value, err)
+	}
+	return n.Int64, nil
+}
@@ -0,0 +1,61 @@
+package sharding
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestAggregateMerge(t *testing.T) {
+	tests := []struct {
+		name     string
+		fn       AggregateFunc
+		partials [][]interface{}
+		want     interface{}
+	}{
+		{"count", AggregateCount, [][]interface{}{{int64(2)}, {[]byte("3")}}, int64(5)},
+		{"sum int", AggregateSum, [][]interface{}{{int64(2)}, {nil}, {[]byte("40")}}, int64(42)},
+		{"sum empty", AggregateSum, [][]interface{}{{nil}, {nil}}, int64(0)},
+		{"sum decimal", AggregateSum, [][]interface{}{{[]byte("0.10")}, {[]byte("0.20")}, {int64(1)}}, "1.30"},
+		{"sum decimal exact", AggregateSum, [][]interface{}{{[]byte("9007199254740993.5")}, {[]byte("1")}}, "9007199254740994.5"},
+		{"sum float", AggregateSum, [][]interface{}{{1.5}, {[]byte("2.25")}, {int64(1)}}, 4.75},
+		{"avg", AggregateAvg, [][]interface{}{{int64(10), int64(2)}, {int64(5), int64(3)}}, 3.0},
+		{"avg decimal", AggregateAvg, [][]interface{}{{[]byte("1.00"), int64(2)}, {[]byte("1.00"), int64(1)}}, "0.666667"},
+		{"avg empty", AggregateAvg, [][]interface{}{{nil, int64(0)}}, nil},
+		{"min", AggregateMin, [][]interface{}{{nil}, {[]byte("7")}, {int64(3)}}, int64(3)},
+		{"max", AggregateMax, [][]interface{}{{[]byte("b")}, {[]byte("a")}, {nil}}, "b"},
+		{"max empty", AggregateMax, [][]interface{}{{nil}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var state aggregateState
+			for _, partial := range tt.partials {
+				if err := state.merge(tt.fn, partial); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := state.result(tt.fn); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAggregateSumOverflow(t *testing.T) {
+	var state aggregateState
+	if err := state.merge(AggregateSum, []interface{}{int64(math.MaxInt64)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := state.merge(AggregateSum, []interface{}{int64(1)}); !errors.Is(err, ErrAggregateOverflow) {
+		t.Errorf("got error %v, want ErrAggregateOverflow", err)
+	}
+
+	state = aggregateState{}
+	if err := state.merge(AggregateSum, []interface{}{int64(math.MinInt64)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := state.merge(AggregateSum, []interface{}{int64(-1)}); !errors.Is(err, ErrAggregateOverflow) {
+		t.Errorf("negative: got error %v, want ErrAggregateOverflow", err)
+	}
+}
@@ -0,0 +1,282 @@
+package sharding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// defaultArchiveProgressTable 默认的归档进度表名
+	defaultArchiveProgressTable = "sharding_archive_progress"
+
+	// defaultArchiveBatchSize 默认每批复制的行数
+	defaultArchiveBatchSize = 1000
+
+	// defaultArchiveKeyColumn 默认的分批复制列
+	defaultArchiveKeyColumn = "id"
+)
+
+// 归档进度状态
+const (
+	archiveStatusCopying  = "copying"  // 正在复制（可从 LastKey 继续）
+	archiveStatusVerified = "verified" // 行数已校验，等待删除源分表
+	archiveStatusDone     = "done"     // 已完成
+)
+
+// ArchiveProgressRecord 归档进度（保存在归档库中），中断后从 LastKey 之后继续复制
+type ArchiveProgressRecord struct {
+	TableName  string `gorm:"column:table_name;primaryKey;size:128"` // 源分表
+	LastKey    string `gorm:"size:255"`                              // 已复制的最大 KeyColumn 值
+	CopiedRows int64  // 已复制的行数
+	Status     string `gorm:"size:16"`
+	UpdatedAt  time.Time
+}
+
+// ArchiverOptions 归档配置
+type ArchiverOptions struct {
+	ProgressTable string      // 归档进度表名（默认 sharding_archive_progress，位于归档库）
+	BatchSize     int         // 每批复制的行数（默认 1000）
+	KeyColumn     string      // 按顺序分批复制使用的唯一列（默认 "id"），用于中断后继续
+	Model         interface{} // 在归档库中创建分表使用的模型（为空时使用源分表的 SHOW CREATE TABLE，仅 MySQL）
+	KeepSource    bool        // 校验后保留源分表（默认删除）
+	Schema        string      // 归档库与源分表在同一个 MySQL 实例时的 schema 名，设置后直接 RENAME TABLE 到该 schema，不复制数据
+}
+
+// ArchiveResult 单个分表的归档结果
+type ArchiveResult struct {
+	Table   string `json:"table"`
+	Rows    int64  `json:"rows"`              // 复制的行数（RENAME 时为 0）
+	Renamed bool   `json:"renamed,omitempty"` // 通过 RENAME TABLE 移动到归档 schema
+	Err     error  `json:"-"`
+}
+
+// Archiver 将过期的时间分表移动到独立的归档库
+// 按 KeyColumn 顺序分批复制（每批的写入与进度在归档库的同一个事务中提交），复制完成后校验行数，一致时才删除源分表；
+// 进度保存在归档库中，中断后再次调用从上次的位置继续
+type Archiver struct {
+	source  *gorm.DB // 源分表所在的数据库
+	archive *gorm.DB // 归档库
+	options ArchiverOptions
+}
+
+// NewArchiver 创建归档器
+func NewArchiver(source, archive *gorm.DB, options ...ArchiverOptions) *Archiver {
+	opts := ArchiverOptions{}
+	if len(options) > 0 {
+		opts = options[0]
+	}
+	if opts.ProgressTable == "" {
+		opts.ProgressTable = defaultArchiveProgressTable
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultArchiveBatchSize
+	}
+	if opts.KeyColumn == "" {
+		opts.KeyColumn = defaultArchiveKeyColumn
+	}
+	return &Archiver{source: source, archive: archive, options: opts}
+}
+
+// Migrate 在归档库中创建归档进度表
+func (a *Archiver) Migrate() error {
+	return a.archive.Table(a.options.ProgressTable).AutoMigrate(&ArchiveProgressRecord{})
+}
+
+// ArchiveExpired 归档时间分表中早于保留窗口（包括当前时间单位在内的 keepPeriods 个时间单位）的分表
+// 受保护的分表跳过；某个分表失败后继续处理其余分表，失败的分表汇总为 ShardErrors
+func (a *Archiver) ArchiveExpired(ctx context.Context, strategy *TimeShardingStrategy, keepPeriods int) ([]ArchiveResult, error) {
+	if keepPeriods < 1 {
+		return nil, fmt.Errorf("archive must keep at least 1 period")
+	}
+	tableNames, err := strategy.expiredTables(a.source.WithContext(ctx), keepPeriods)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		results   []ArchiveResult
+		shardErrs ShardErrors
+	)
+	for _, tableName := range tableNames {
+		if IsShardProtected(tableName) {
+			continue
+		}
+		result, err := a.ArchiveTable(ctx, tableName)
+		if err != nil {
+			if ctx.Err() != nil {
+				return results, err
+			}
+			shardErrs = append(shardErrs, &ShardError{Table: tableName, Err: err})
+		}
+		results = append(results, result)
+	}
+
+	if len(shardErrs) > 0 {
+		return results, shardErrs
+	}
+	return results, nil
+}
+
+// ArchiveTable 归档单个分表：复制到归档库、校验行数后删除源分表（设置 Schema 时直接 RENAME TABLE）
+func (a *Archiver) ArchiveTable(ctx context.Context, tableName string) (ArchiveResult, error) {
+	result := ArchiveResult{Table: tableName}
+	fail := func(err error) (ArchiveResult, error) {
+		result.Err = err
+		return result, err
+	}
+	if err := checkShardNotProtected("archive", tableName); err != nil {
+		return fail(err)
+	}
+	source, archive := a.source.WithContext(ctx), a.archive.WithContext(ctx)
+
+	if a.options.Schema != "" {
+		err := runDDL(source, "ArchiveShardTable", tableName, func(tx *gorm.DB) error {
+			return tx.Exec(fmt.Sprintf("RENAME TABLE %s TO %s.%s",
+				tx.Statement.Quote(tableName), tx.Statement.Quote(a.options.Schema), tx.Statement.Quote(tableName))).Error
+		})
+		if err != nil {
+			return fail(err)
+		}
+		forgetAutoCreatedTable(source, tableName)
+		result.Renamed = true
+		return result, nil
+	}
+
+	var progress ArchiveProgressRecord
+	err := archive.Table(a.options.ProgressTable).Where("table_name = ?", tableName).Take(&progress).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		progress = ArchiveProgressRecord{TableName: tableName, Status: archiveStatusCopying}
+	} else if err != nil {
+		return fail(fmt.Errorf("failed to load archive progress: %w", err))
+	}
+	result.Rows = progress.CopiedRows
+	if progress.Status == archiveStatusDone {
+		return result, nil
+	}
+
+	if progress.Status == archiveStatusCopying {
+		if err := a.ensureArchiveTable(source, archive, tableName); err != nil {
+			return fail(err)
+		}
+		if err := a.copyRows(ctx, source, archive, &progress); err != nil {
+			result.Rows = progress.CopiedRows
+			return fail(err)
+		}
+		result.Rows = progress.CopiedRows
+
+		// 校验行数：归档库中的行数必须与源分表一致
+		var sourceCount, archiveCount int64
+		if err := source.Table(tableName).Count(&sourceCount).Error; err != nil {
+			return fail(err)
+		}
+		if err := archive.Table(tableName).Count(&archiveCount).Error; err != nil {
+			return fail(err)
+		}
+		if sourceCount != archiveCount {
+			return fail(fmt.Errorf("%w: table %s has %d rows, archive has %d", ErrArchiveMismatch, tableName, sourceCount, archiveCount))
+		}
+		if err := a.saveProgress(archive, &progress, archiveStatusVerified); err != nil {
+			return fail(err)
+		}
+	}
+
+	if !a.options.KeepSource {
+		if err := DropShardTable(source, tableName); err != nil {
+			return fail(err)
+		}
+	}
+	if err := a.saveProgress(archive, &progress, archiveStatusDone); err != nil {
+		return fail(err)
+	}
+	return result, nil
+}
+
+// ensureArchiveTable 在归档库中创建分表（已存在时跳过）
+func (a *Archiver) ensureArchiveTable(source, archive *gorm.DB, tableName string) error {
+	if archive.Migrator().HasTable(tableName) {
+		return nil
+	}
+	if a.options.Model != nil {
+		return runDDL(archive, "ArchiveShardTable", tableName, func(tx *gorm.DB) error {
+			return tx.Table(tableName).AutoMigrate(a.options.Model)
+		})
+	}
+
+	var definition struct {
+		Table       string `gorm:"column:Table"`
+		CreateTable string `gorm:"column:Create Table"`
+	}
+	if err := source.Raw("SHOW CREATE TABLE " + source.Statement.Quote(tableName)).Scan(&definition).Error; err != nil {
+		return fmt.Errorf("failed to read definition of table %s: %w", tableName, err)
+	}
+	if definition.CreateTable == "" {
+		return fmt.Errorf("failed to read definition of table %s, set ArchiverOptions.Model", tableName)
+	}
+	return runDDL(archive, "ArchiveShardTable", tableName, func(tx *gorm.DB) error {
+		return tx.Exec(definition.CreateTable).Error
+	})
+}
+
+// copyRows 从 progress.LastKey 之后按 KeyColumn 顺序分批复制，每批的写入与进度在同一个事务中提交
+func (a *Archiver) copyRows(ctx context.Context, source, archive *gorm.DB, progress *ArchiveProgressRecord) error {
+	keyColumn := a.options.KeyColumn
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		query := source.Table(progress.TableName).Order(keyColumn).Limit(a.options.BatchSize)
+		if progress.LastKey != "" {
+			query = query.Where(source.Statement.Quote(keyColumn)+" > ?", progress.LastKey)
+		}
+		var rows []map[string]interface{}
+		if err := query.Find(&rows).Error; err != nil {
+			return fmt.Errorf("failed to read table %s: %w", progress.TableName, err)
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+
+		lastKey, ok := rows[len(rows)-1][keyColumn]
+		if !ok {
+			return fmt.Errorf("table %s has no column %s, set ArchiverOptions.KeyColumn", progress.TableName, keyColumn)
+		}
+		next := *progress
+		if b, ok := lastKey.([]byte); ok {
+			next.LastKey = string(b)
+		} else {
+			next.LastKey = fmt.Sprintf("%v", lastKey)
+		}
+		next.CopiedRows += int64(len(rows))
+		next.UpdatedAt = time.Now()
+
+		err := archive.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Table(progress.TableName).Create(&rows).Error; err != nil {
+				return err
+			}
+			return tx.Table(a.options.ProgressTable).Save(&next).Error
+		})
+		if err != nil {
+			return fmt.Errorf("failed to copy rows of table %s: %w", progress.TableName, err)
+		}
+		*progress = next
+
+		if len(rows) < a.options.BatchSize {
+			return nil
+		}
+	}
+}
+
+// saveProgress 更新归档进度的状态
+func (a *Archiver) saveProgress(archive *gorm.DB, progress *ArchiveProgressRecord, status string) error {
+	progress.Status = status
+	progress.UpdatedAt = time.Now()
+	if err := archive.Table(a.options.ProgressTable).Save(progress).Error; err != nil {
+		return fmt.Errorf("failed to save archive progress of table %s: %w", progress.TableName, err)
+	}
+	return nil
+}
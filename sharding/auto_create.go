@@ -0,0 +1,108 @@
+package sharding
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// tableCreationLockPrefix 建表使用的 MySQL 命名锁前缀
+	tableCreationLockPrefix = "sharding_create_"
+
+	// maxLockNameLength MySQL GET_LOCK 锁名的最大长度
+	maxLockNameLength = 64
+
+	// defaultTableCreationLockTimeout 没有截止时间时等待建表锁的时间
+	defaultTableCreationLockTimeout = 10 * time.Second
+)
+
+// autoCreateKey 已创建分表的缓存键（同一个数据库的会话共用 gorm.Config）
+type autoCreateKey struct {
+	config *gorm.Config
+	table  string
+}
+
+var (
+	autoCreatedTables sync.Map // autoCreateKey -> struct{}，已确认存在的分表
+	autoCreateLocks   sync.Map // autoCreateKey -> *sync.Mutex，同一进程内同一分表的建表串行执行
+)
+
+// ensureShardTable 插入时同步创建分表：已确认存在的分表直接返回，
+// 同一进程内同一分表的并发插入只有一个执行建表，其余等待结果
+func ensureShardTable(db *gorm.DB, strategy ShardingStrategy, tableName string, model interface{}) error {
+	key := autoCreateKey{config: db.Config, table: tableName}
+	if _, ok := autoCreatedTables.Load(key); ok {
+		return nil
+	}
+
+	value, _ := autoCreateLocks.LoadOrStore(key, &sync.Mutex{})
+	mu := value.(*sync.Mutex)
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := autoCreatedTables.Load(key); ok {
+		return nil
+	}
+
+	if err := AutoCreateTable(db, strategy, tableName, model); err != nil {
+		return err
+	}
+	autoCreatedTables.Store(key, struct{}{})
+	return nil
+}
+
+// forgetAutoCreatedTable 分表被删除或重命名后清除缓存，之后的插入重新检查并创建
+func forgetAutoCreatedTable(db *gorm.DB, tableName string) {
+	autoCreatedTables.Delete(autoCreateKey{config: db.Config, table: tableName})
+}
+
+// withTableCreationLock 持有分表的 MySQL 命名锁（GET_LOCK）执行 fn，避免多个应用实例同时创建同一个分表
+// 命名锁属于会话，fn 在同一个连接上执行；其他数据库直接执行 fn
+func withTableCreationLock(db *gorm.DB, tableName string, fn func(tx *gorm.DB) error) error {
+	if db.Dialector.Name() != "mysql" {
+		return fn(db)
+	}
+
+	return db.Connection(func(tx *gorm.DB) error {
+		lockName := tableCreationLockName(tableName)
+		var acquired *int
+		if err := tx.Raw("SELECT GET_LOCK(?, ?)", lockName, tableCreationLockTimeout(tx.Statement.Context)).Scan(&acquired).Error; err != nil {
+			return fmt.Errorf("failed to acquire creation lock of table %s: %w", tableName, err)
+		}
+		if acquired == nil || *acquired != 1 {
+			return fmt.Errorf("timed out waiting for creation lock of table %s", tableName)
+		}
+		defer tx.Exec("SELECT RELEASE_LOCK(?)", lockName)
+
+		return fn(tx)
+	})
+}
+
+// tableCreationLockName 获取分表的命名锁名称（超过 64 个字符时使用分表名的哈希）
+func tableCreationLockName(tableName string) string {
+	name := tableCreationLockPrefix + tableName
+	if len(name) <= maxLockNameLength {
+		return name
+	}
+	sum := sha1.Sum([]byte(tableName))
+	return tableCreationLockPrefix + hex.EncodeToString(sum[:])
+}
+
+// tableCreationLockTimeout 等待命名锁的秒数：有截止时间时使用剩余时间（至少 1 秒）
+func tableCreationLockTimeout(ctx context.Context) int {
+	timeout := defaultTableCreationLockTimeout
+	if ctx != nil {
+		if deadline, ok := ctx.Deadline(); ok {
+			timeout = time.Until(deadline)
+		}
+	}
+	if seconds := int(timeout / time.Second); seconds > 0 {
+		return seconds
+	}
+	return 1
+}
@@ -1,8 +1,10 @@
 package sharding
 
 import (
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"gorm.io/gorm"
@@ -10,8 +12,11 @@ import (
 
 // AutoMigrateOptions 自动迁移选项
 type AutoMigrateOptions struct {
-	SkipIfExists bool             // 如果表已存在则跳过
-	TimeRange    *AutoMigrateTimeRange // 时间分表的时间范围（可选）
+	SkipIfExists    bool                  // 如果表已存在则跳过
+	TimeRange       *AutoMigrateTimeRange // 时间分表的时间范围（可选）
+	CheckPrivileges bool                  // 迁移前检查 CREATE/ALTER 权限，缺少权限时直接返回错误
+	Concurrency     int                   // 并发迁移的分表数量（0 表示顺序迁移并在第一个失败处停止；大于 0 时迁移所有分表，失败的分表汇总为 ShardErrors）
+	DryRun          bool                  // 只生成每个分表的 CREATE/ALTER 语句并通过 db.Logger 输出，不修改数据库（获取语句使用 AutoMigrateDryRun）
 }
 
 // AutoMigrateTimeRange 自动迁移的时间范围
@@ -40,30 +45,43 @@ func AutoMigrate(db *gorm.DB, strategy ShardingStrategy, model interface{}, opti
 		skipIfExists = true
 	}
 
-	// 创建所有分表
-	for _, tableName := range tableNames {
-		if err := migrateTable(db, tableName, model, skipIfExists); err != nil {
-			return fmt.Errorf("failed to migrate table %s: %w", tableName, err)
+	// 预先检查权限，避免迁移到一半才失败
+	if len(options) > 0 && options[0].CheckPrivileges {
+		if err := CheckPrivileges(db, PrivilegeCreate, PrivilegeAlter); err != nil {
+			return err
 		}
 	}
 
-	return nil
+	// 创建所有分表
+	return migrateTables(db, strategy, tableNames, model, skipIfExists, options...)
 }
 
 // AutoMigrateTimeSharding 自动创建时间分表
 func AutoMigrateTimeSharding(db *gorm.DB, strategy *TimeShardingStrategy, model interface{}, options ...AutoMigrateOptions) error {
-	baseTableName := strategy.GetBaseTableName()
-	
-	var timeRange *AutoMigrateTimeRange
 	skipIfExists := false
-
 	if len(options) > 0 {
 		skipIfExists = options[0].SkipIfExists
-		if options[0].TimeRange != nil {
-			timeRange = options[0].TimeRange
+	}
+
+	tableNames := timeMigrationTableNames(strategy, options...)
+
+	// 预先检查权限，避免迁移到一半才失败
+	if len(options) > 0 && options[0].CheckPrivileges {
+		if err := CheckPrivileges(db, PrivilegeCreate, PrivilegeAlter); err != nil {
+			return err
 		}
 	}
 
+	return migrateTables(db, strategy, tableNames, model, skipIfExists, options...)
+}
+
+// timeMigrationTableNames 获取时间分表需要迁移的分表（未指定时间范围时为最近一年）
+func timeMigrationTableNames(strategy *TimeShardingStrategy, options ...AutoMigrateOptions) []string {
+	var timeRange *AutoMigrateTimeRange
+	if len(options) > 0 && options[0].TimeRange != nil {
+		timeRange = options[0].TimeRange
+	}
+
 	// 如果没有指定时间范围，使用默认范围（最近一年）
 	if timeRange == nil {
 		endTime := time.Now()
@@ -74,19 +92,62 @@ func AutoMigrateTimeSharding(db *gorm.DB, strategy *TimeShardingStrategy, model
 		}
 	}
 
-	tableNames := strategy.GetAllTableNamesInRange(baseTableName, timeRange.StartTime, timeRange.EndTime)
+	return strategy.GetAllTableNamesInRange(strategy.GetBaseTableName(), timeRange.StartTime, timeRange.EndTime)
+}
 
-	for _, tableName := range tableNames {
-		if err := migrateTable(db, tableName, model, skipIfExists); err != nil {
-			return fmt.Errorf("failed to migrate table %s: %w", tableName, err)
+// migrateTables 迁移所有分表：未设置 Concurrency 时顺序迁移并在第一个失败处停止，
+// 否则使用 Concurrency 个 worker 并发迁移所有分表，返回按分表顺序排列的 ShardErrors
+func migrateTables(db *gorm.DB, strategy ShardingStrategy, tableNames []string, model interface{}, skipIfExists bool, options ...AutoMigrateOptions) error {
+	concurrency := 0
+	if len(options) > 0 {
+		concurrency = options[0].Concurrency
+		if options[0].DryRun {
+			return logMigrationPlan(db, strategy, tableNames, model, skipIfExists)
 		}
 	}
+	if concurrency <= 0 {
+		for _, tableName := range tableNames {
+			if err := migrateTable(db, strategy, tableName, model, skipIfExists); err != nil {
+				return fmt.Errorf("failed to migrate table %s: %w", tableName, err)
+			}
+		}
+		return nil
+	}
 
+	// 分发前创建新的会话，worker 之间不共用调用方的 Statement
+	db = db.Session(&gorm.Session{})
+	errs := make([]error, len(tableNames))
+	tasks := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(concurrency, len(tableNames)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range tasks {
+				errs[i] = migrateTable(db, strategy, tableNames[i], model, skipIfExists)
+			}
+		}()
+	}
+	for i := range tableNames {
+		tasks <- i
+	}
+	close(tasks)
+	wg.Wait()
+
+	var shardErrs ShardErrors
+	for i, err := range errs {
+		if err != nil {
+			shardErrs = append(shardErrs, &ShardError{Table: tableNames[i], Err: err})
+		}
+	}
+	if len(shardErrs) > 0 {
+		return shardErrs
+	}
 	return nil
 }
 
-// migrateTable 迁移单个表
-func migrateTable(db *gorm.DB, tableName string, model interface{}, skipIfExists bool) error {
+// migrateTable 迁移单个表（使用策略为该分表配置的建表选项）
+func migrateTable(db *gorm.DB, strategy ShardingStrategy, tableName string, model interface{}, skipIfExists bool) error {
 	// 检查表是否存在
 	if skipIfExists {
 		if tableExists(db, tableName) {
@@ -95,7 +156,13 @@ func migrateTable(db *gorm.DB, tableName string, model interface{}, skipIfExists
 	}
 
 	// 使用 GORM 的 Table 方法指定表名进行迁移
-	return db.Table(tableName).AutoMigrate(model)
+	return runDDL(db, "AutoMigrate", tableName, func(tx *gorm.DB) error {
+		tx, err := withShardTableOptions(tx, strategy, tableName)
+		if err != nil {
+			return err
+		}
+		return tx.Table(tableName).AutoMigrate(model)
+	})
 }
 
 // tableExists 检查表是否存在
@@ -107,18 +174,40 @@ func tableExists(db *gorm.DB, tableName string) bool {
 }
 
 // AutoCreateTable 自动创建分表（如果不存在）
-// 在插入数据时调用，如果表不存在则自动创建
+// 在插入数据时调用，如果表不存在则自动创建；MySQL 持有分表的命名锁建表并在加锁后再次检查，
+// 其他应用实例同时创建同一个分表时不会失败（表已存在的错误视为成功）
 func AutoCreateTable(db *gorm.DB, strategy ShardingStrategy, tableName string, model interface{}) error {
 	if tableExists(db, tableName) {
 		return nil // 表已存在
 	}
 
-	// 创建表
-	return db.Table(tableName).AutoMigrate(model)
+	return withTableCreationLock(db, tableName, func(tx *gorm.DB) error {
+		// 等待锁期间其他实例可能已经创建了分表
+		if tableExists(tx, tableName) {
+			return nil
+		}
+
+		// 创建表
+		err := runDDL(tx, "AutoCreateTable", tableName, func(tx *gorm.DB) error {
+			tx, err := withShardTableOptions(tx, strategy, tableName)
+			if err != nil {
+				return err
+			}
+			return tx.Table(tableName).AutoMigrate(model)
+		})
+		if isDuplicateObjectError(err) {
+			return nil
+		}
+		return err
+	})
 }
 
 // AutoMigrateAll 批量自动迁移多个策略
+// 设置 Concurrency 时每个策略的分表并发迁移，某个策略失败后继续迁移其余策略，返回所有策略的错误
 func AutoMigrateAll(db *gorm.DB, strategies []ShardingStrategy, models map[string]interface{}, options ...AutoMigrateOptions) error {
+	continueOnError := len(options) > 0 && options[0].Concurrency > 0
+
+	var errs []error
 	for _, strategy := range strategies {
 		baseTableName := strategy.GetBaseTableName()
 		model, ok := models[baseTableName]
@@ -127,16 +216,40 @@ func AutoMigrateAll(db *gorm.DB, strategies []ShardingStrategy, models map[strin
 		}
 
 		if err := AutoMigrate(db, strategy, model, options...); err != nil {
-			return fmt.Errorf("failed to auto migrate strategy %s: %w", baseTableName, err)
+			err = fmt.Errorf("failed to auto migrate strategy %s: %w", baseTableName, err)
+			if !continueOnError {
+				return err
+			}
+			errs = append(errs, err)
 		}
 	}
 
-	return nil
+	return errors.Join(errs...)
+}
+
+// CreateTablesOptions 批量创建分表选项
+type CreateTablesOptions struct {
+	SkipIfExists    bool                      // 如果表已存在则跳过
+	Charset         string                    // 所有分表的默认字符集（可选，为空时使用 SQL 中的定义）
+	Collation       string                    // 所有分表的默认排序规则（可选）
+	TableCharsets   map[string]CharsetOptions // 按分表名覆盖字符集和排序规则（可选）
+	CheckPrivileges bool                      // 创建前检查 CREATE 权限，缺少权限时直接返回错误
+}
+
+// CharsetOptions 字符集和排序规则
+type CharsetOptions struct {
+	Charset   string
+	Collation string
 }
 
 // CreateAllShardingTables 创建所有分表（使用 SQL）
 // 这个方法适用于需要自定义表结构的情况
 func CreateAllShardingTables(db *gorm.DB, strategy ShardingStrategy, createTableSQL string, skipIfExists bool) error {
+	return CreateAllShardingTablesWithOptions(db, strategy, createTableSQL, CreateTablesOptions{SkipIfExists: skipIfExists})
+}
+
+// CreateAllShardingTablesWithOptions 创建所有分表（使用 SQL，支持按分表覆盖字符集和排序规则）
+func CreateAllShardingTablesWithOptions(db *gorm.DB, strategy ShardingStrategy, createTableSQL string, options CreateTablesOptions) error {
 	baseTableName := strategy.GetBaseTableName()
 	tableNames := strategy.GetAllTableNames(baseTableName)
 
@@ -150,18 +263,45 @@ func CreateAllShardingTables(db *gorm.DB, strategy ShardingStrategy, createTable
 		}
 	}
 
+	// 预先检查权限，避免创建到一半才失败
+	if options.CheckPrivileges {
+		if err := CheckPrivileges(db, PrivilegeCreate); err != nil {
+			return err
+		}
+	}
+
 	for _, tableName := range tableNames {
 		// 替换表名
 		sql := strings.ReplaceAll(createTableSQL, baseTableName, tableName)
 
 		// 如果需要跳过已存在的表
-		if skipIfExists {
-			sql = fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s", extractTableDefinition(sql))
+		if options.SkipIfExists {
+			sql = extractTableDefinition(sql)
+		}
+
+		// 追加字符集、排序规则和表选项
+		// 优先级：options.TableCharsets > 策略中的分表建表选项 > options 中的默认字符集
+		shardOptions, hasShardOptions := getShardTableOptions(strategy, tableName)
+		if !hasShardOptions || (shardOptions.Charset == "" && shardOptions.Collation == "") {
+			shardOptions.Charset, shardOptions.Collation = options.Charset, options.Collation
+		}
+		if tableCharset, ok := options.TableCharsets[tableName]; ok {
+			shardOptions.Charset, shardOptions.Collation = tableCharset.Charset, tableCharset.Collation
+		}
+		tableOptions, err := buildShardTableOptions(shardOptions)
+		if err != nil {
+			return fmt.Errorf("invalid table options for table %s: %w", tableName, err)
+		}
+		if tableOptions != "" {
+			sql = strings.TrimRight(strings.TrimSpace(sql), ";") + " " + tableOptions
 		}
 
-		if err := db.Exec(sql).Error; err != nil {
+		err = runDDL(db, "CreateAllShardingTables", tableName, func(tx *gorm.DB) error {
+			return tx.Exec(sql).Error
+		})
+		if err != nil {
 			// 如果表已存在且设置了跳过，忽略错误
-			if skipIfExists && strings.Contains(strings.ToLower(err.Error()), "already exists") {
+			if options.SkipIfExists && strings.Contains(strings.ToLower(err.Error()), "already exists") {
 				continue
 			}
 			return fmt.Errorf("failed to create table %s: %w", tableName, err)
@@ -171,6 +311,22 @@ func CreateAllShardingTables(db *gorm.DB, strategy ShardingStrategy, createTable
 	return nil
 }
 
+// buildCharsetTableOptions 构建建表语句的字符集选项（如 "DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci"）
+func buildCharsetTableOptions(options CharsetOptions) (string, error) {
+	if err := validateCharsetAndCollation(options.Charset, options.Collation); err != nil {
+		return "", err
+	}
+
+	parts := make([]string, 0, 2)
+	if options.Charset != "" {
+		parts = append(parts, "DEFAULT CHARSET="+options.Charset)
+	}
+	if options.Collation != "" {
+		parts = append(parts, "COLLATE="+options.Collation)
+	}
+	return strings.Join(parts, " "), nil
+}
+
 // extractTableDefinition 从 CREATE TABLE SQL 中提取表定义部分
 func extractTableDefinition(sql string) string {
 	// 简化处理：如果 SQL 中已经包含 CREATE TABLE IF NOT EXISTS，直接返回
@@ -200,6 +356,12 @@ func EnsureTableExists(db *gorm.DB, strategy ShardingStrategy, shardingValue int
 	}
 
 	// 创建表
-	return db.Table(tableName).AutoMigrate(model)
+	return runDDL(db, "EnsureTableExists", tableName, func(tx *gorm.DB) error {
+		tx, err := withShardTableOptions(tx, strategy, tableName)
+		if err != nil {
+			return err
+		}
+		return tx.Table(tableName).AutoMigrate(model)
+	})
 }
 
@@ -0,0 +1,276 @@
+package sharding
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	defaultBackfillBatchSize      = 1000
+	defaultBackfillMinBatchSize   = 100
+	defaultBackfillMaxBatchSize   = 10000
+	defaultBackfillBatchDuration  = 500 * time.Millisecond
+	defaultBackfillLagCheckPeriod = time.Second
+)
+
+// BackfillOptions 批量回填写入选项
+type BackfillOptions struct {
+	BatchSize           int           // 初始批次大小（默认 1000）
+	MinBatchSize        int           // 自动调整时的最小批次大小（默认 100）
+	MaxBatchSize        int           // 自动调整时的最大批次大小（默认 10000）
+	TargetBatchDuration time.Duration // 单个批次写入耗时的目标值（默认 500ms），超过时缩小批次，远低于时扩大批次，避免产生过大的 binlog 事务
+	SleepInterval       time.Duration // 每个批次写入后的最小休眠时间（可选）
+	TargetRowsPerSecond int           // 目标写入速率（行/秒，0 表示不限制），写入过快时自动休眠
+	LowPriority         bool          // 使用低优先级写入（MySQL 的 INSERT LOW_PRIORITY）
+	IgnoreDuplicates    bool          // 忽略主键或唯一键已存在的行（ON CONFLICT DO NOTHING），重复写入同一批数据时不会失败
+
+	Replicas      *ReplicaRouter // 副本路由器（可选），用于在复制延迟过高时暂停写入
+	MaxReplicaLag time.Duration  // 允许的最大复制延迟，超过时暂停写入并缩小批次（需要设置 Replicas）
+}
+
+// BackfillStats 回填写入统计
+type BackfillStats struct {
+	Rows       int64         // 已写入行数
+	Batches    int64         // 已写入批次数
+	Elapsed    time.Duration // 写入耗时（不含休眠）
+	Throttled  time.Duration // 限速和等待复制追上的总休眠时间
+	BatchSize  int           // 当前批次大小
+	RowsPerSec float64       // 平均写入速率（按总耗时计算）
+}
+
+// BackfillWriter 限速的批量回填写入器，用于重新分表、归档等需要大量写入的维护任务
+// 按批次写入，根据每批耗时动态调整批次大小，并按目标速率和复制延迟进行限速，避免主从延迟突增
+type BackfillWriter struct {
+	db      *gorm.DB
+	options BackfillOptions
+
+	mu        sync.Mutex
+	batchSize int
+	stats     BackfillStats
+	startedAt time.Time
+}
+
+// NewBackfillWriter 创建批量回填写入器
+func NewBackfillWriter(db *gorm.DB, options ...BackfillOptions) *BackfillWriter {
+	opts := BackfillOptions{}
+	if len(options) > 0 {
+		opts = options[0]
+	}
+	if opts.MinBatchSize <= 0 {
+		opts.MinBatchSize = defaultBackfillMinBatchSize
+	}
+	if opts.MaxBatchSize <= 0 {
+		opts.MaxBatchSize = defaultBackfillMaxBatchSize
+	}
+	if opts.MaxBatchSize < opts.MinBatchSize {
+		opts.MaxBatchSize = opts.MinBatchSize
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultBackfillBatchSize
+	}
+	if opts.TargetBatchDuration <= 0 {
+		opts.TargetBatchDuration = defaultBackfillBatchDuration
+	}
+
+	w := &BackfillWriter{
+		db:      db,
+		options: opts,
+	}
+	w.batchSize = w.clampBatchSize(opts.BatchSize)
+	return w
+}
+
+// Write 将 rows（结构体切片或切片指针）分批写入 tableName
+// 每个批次是一个独立的 INSERT 语句；ctx 取消时停止写入并返回 ctx 的错误，已写入的批次不会回滚
+func (w *BackfillWriter) Write(ctx context.Context, tableName string, rows interface{}) error {
+	rowsValue := reflect.ValueOf(rows)
+	for rowsValue.Kind() == reflect.Ptr {
+		rowsValue = rowsValue.Elem()
+	}
+	if rowsValue.Kind() != reflect.Slice {
+		return fmt.Errorf("rows must be a slice, got %T", rows)
+	}
+
+	w.mu.Lock()
+	if w.startedAt.IsZero() {
+		w.startedAt = time.Now()
+	}
+	w.mu.Unlock()
+
+	total := rowsValue.Len()
+	for start := 0; start < total; {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := w.waitForReplicas(ctx); err != nil {
+			return err
+		}
+
+		end := start + w.BatchSize()
+		if end > total {
+			end = total
+		}
+		batch := rowsValue.Slice(start, end)
+
+		begin := time.Now()
+		if err := w.insertBatch(ctx, tableName, batch); err != nil {
+			return fmt.Errorf("failed to write rows %d-%d to table %s: %w", start, end, tableName, err)
+		}
+		elapsed := time.Since(begin)
+
+		w.recordBatch(batch.Len(), elapsed)
+		start = end
+
+		if start < total {
+			if err := w.throttle(ctx, batch.Len(), elapsed); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// insertBatch 写入一个批次
+func (w *BackfillWriter) insertBatch(ctx context.Context, tableName string, batch reflect.Value) error {
+	if batch.Len() == 0 {
+		return nil
+	}
+
+	tx := w.db.WithContext(ctx).Table(tableName)
+	if w.options.LowPriority && w.db.Dialector.Name() == "mysql" {
+		tx = tx.Clauses(clause.Insert{Modifier: "LOW_PRIORITY"})
+	}
+	if w.options.IgnoreDuplicates {
+		tx = tx.Clauses(clause.OnConflict{DoNothing: true})
+	}
+	return tx.Create(batch.Interface()).Error
+}
+
+// recordBatch 记录批次统计，并根据耗时调整批次大小
+func (w *BackfillWriter) recordBatch(rows int, elapsed time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.stats.Rows += int64(rows)
+	w.stats.Batches++
+	w.stats.Elapsed += elapsed
+
+	// 只有满批次的耗时才能反映批次大小是否合适
+	if rows < w.batchSize {
+		return
+	}
+	target := w.options.TargetBatchDuration
+	switch {
+	case elapsed > target:
+		w.batchSize = w.clampBatchSize(w.batchSize / 2)
+	case elapsed < target/2:
+		w.batchSize = w.clampBatchSize(w.batchSize + w.batchSize/4 + 1)
+	}
+}
+
+// throttle 按 SleepInterval 和 TargetRowsPerSecond 休眠
+func (w *BackfillWriter) throttle(ctx context.Context, rows int, elapsed time.Duration) error {
+	sleep := w.options.SleepInterval
+	if w.options.TargetRowsPerSecond > 0 {
+		budget := time.Duration(float64(rows) / float64(w.options.TargetRowsPerSecond) * float64(time.Second))
+		if remaining := budget - elapsed; remaining > sleep {
+			sleep = remaining
+		}
+	}
+	return w.sleep(ctx, sleep)
+}
+
+// waitForReplicas 复制延迟超过 MaxReplicaLag 时等待副本追上，并缩小批次
+func (w *BackfillWriter) waitForReplicas(ctx context.Context) error {
+	if w.options.Replicas == nil || w.options.MaxReplicaLag <= 0 {
+		return nil
+	}
+
+	shrunk := false
+	for {
+		w.options.Replicas.Refresh(ctx)
+		if maxLag(w.options.Replicas.ReplicaLags()) <= w.options.MaxReplicaLag {
+			return nil
+		}
+
+		if !shrunk {
+			w.mu.Lock()
+			w.batchSize = w.clampBatchSize(w.batchSize / 2)
+			w.mu.Unlock()
+			shrunk = true
+		}
+		if err := w.sleep(ctx, defaultBackfillLagCheckPeriod); err != nil {
+			return err
+		}
+	}
+}
+
+// maxLag 获取最大的复制延迟（检查失败的副本不计入）
+func maxLag(lags []time.Duration) time.Duration {
+	var result time.Duration
+	for _, lag := range lags {
+		if lag > result {
+			result = lag
+		}
+	}
+	return result
+}
+
+// sleep 休眠指定时间并记录，ctx 取消时提前返回
+func (w *BackfillWriter) sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	w.mu.Lock()
+	w.stats.Throttled += d
+	w.mu.Unlock()
+	return nil
+}
+
+// clampBatchSize 将批次大小限制在 MinBatchSize 和 MaxBatchSize 之间
+func (w *BackfillWriter) clampBatchSize(size int) int {
+	if size < w.options.MinBatchSize {
+		return w.options.MinBatchSize
+	}
+	if size > w.options.MaxBatchSize {
+		return w.options.MaxBatchSize
+	}
+	return size
+}
+
+// BatchSize 获取当前批次大小
+func (w *BackfillWriter) BatchSize() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.batchSize
+}
+
+// Stats 获取写入统计
+func (w *BackfillWriter) Stats() BackfillStats {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	stats := w.stats
+	stats.BatchSize = w.batchSize
+	if !w.startedAt.IsZero() {
+		if total := time.Since(w.startedAt).Seconds(); total > 0 {
+			stats.RowsPerSec = float64(stats.Rows) / total
+		}
+	}
+	return stats
+}
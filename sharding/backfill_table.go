@@ -0,0 +1,285 @@
+package sharding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	// defaultBackfillProgressTable 默认的回填进度表名
+	defaultBackfillProgressTable = "sharding_backfill_progress"
+
+	// defaultBackfillKeyColumn 默认的分批读取列
+	defaultBackfillKeyColumn = "id"
+)
+
+// 回填进度状态
+const (
+	backfillStatusCopying = "copying" // 正在复制（可从 LastKey 继续）
+	backfillStatusDone    = "done"    // 已复制并校验
+)
+
+// BackfillProgressRecord 回填进度，中断后从 LastKey 之后继续
+type BackfillProgressRecord struct {
+	SourceTable string `gorm:"column:source_table;primaryKey;size:128"` // 源表
+	TargetTable string `gorm:"column:target_table;primaryKey;size:128"` // 分表的基础表名
+	LastKey     string `gorm:"size:255"`                                // 已复制的最大 KeyColumn 值
+	CopiedRows  int64  // 已复制的行数
+	Status      string `gorm:"size:16"`
+	UpdatedAt   time.Time
+}
+
+// BackfillTableOptions 从未分表的源表回填到分表的配置
+type BackfillTableOptions struct {
+	ProgressTable string                 // 回填进度表名（默认 sharding_backfill_progress，位于 db）
+	BatchSize     int                    // 每批从源表读取的行数（默认 1000）
+	Concurrency   int                    // 每批并发写入的分表数量（默认 1）
+	KeyColumn     string                 // 按顺序分批读取使用的唯一列（默认 "id"），用于中断后继续
+	SkipVerify    bool                   // 跳过复制完成后的行数校验
+	Writer        BackfillOptions        // 写入分表使用的 BackfillWriter 选项（写入批次大小、限速、复制延迟等），总是忽略已存在的行
+	OnProgress    func(copiedRows int64) // 每批写入后调用（可选）
+}
+
+// BackfillResult 回填结果
+type BackfillResult struct {
+	SourceTable string           `json:"source_table"`
+	Rows        int64            `json:"rows"`       // 复制的行数（包括之前中断前复制的行）
+	ShardRows   map[string]int64 `json:"shard_rows"` // 本次调用写入每个分表的行数
+	Verified    bool             `json:"verified"`   // 本次调用校验了行数（之前已完成的回填直接返回，为 false）
+}
+
+// BackfillFromTable 将未分表的源表中的数据迁移到分表：按 KeyColumn 顺序分批读取，按策略把每行路由到对应的分表后并发写入，
+// 写入使用 BackfillWriter（按 Writer 选项限速），每批写入完成后记录进度（保存在 db 的进度表中，自动创建），中断后再次调用从上次的位置继续；
+// 写入时忽略已存在的行（重复执行中断前的一批不会失败），复制完成后校验源表的行数与分表中 KeyColumn 不大于最后一行的行数之和，
+// 不一致时返回 ErrBackfillMismatch。分表不存在时自动创建，源表不会被修改
+func BackfillFromTable(ctx context.Context, db *gorm.DB, sourceTable string, strategy ShardingStrategy, model interface{}, options ...BackfillTableOptions) (*BackfillResult, error) {
+	opts := BackfillTableOptions{}
+	if len(options) > 0 {
+		opts = options[0]
+	}
+	if opts.ProgressTable == "" {
+		opts.ProgressTable = defaultBackfillProgressTable
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultBackfillBatchSize
+	}
+	opts.Writer.IgnoreDuplicates = true
+	if opts.Concurrency < 1 {
+		opts.Concurrency = 1
+	}
+	if opts.KeyColumn == "" {
+		opts.KeyColumn = defaultBackfillKeyColumn
+	}
+
+	db = db.WithContext(ctx)
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, fmt.Errorf("failed to parse model: %w", err)
+	}
+	keyField := stmt.Schema.LookUpField(opts.KeyColumn)
+	if keyField == nil {
+		return nil, fmt.Errorf("model has no column %s, set BackfillTableOptions.KeyColumn", opts.KeyColumn)
+	}
+
+	if err := db.Table(opts.ProgressTable).AutoMigrate(&BackfillProgressRecord{}); err != nil {
+		return nil, fmt.Errorf("failed to create backfill progress table: %w", err)
+	}
+	baseTableName := strategy.GetBaseTableName()
+	var progress BackfillProgressRecord
+	err := db.Table(opts.ProgressTable).Where("source_table = ? AND target_table = ?", sourceTable, baseTableName).Take(&progress).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		progress = BackfillProgressRecord{SourceTable: sourceTable, TargetTable: baseTableName, Status: backfillStatusCopying}
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load backfill progress: %w", err)
+	}
+
+	result := &BackfillResult{SourceTable: sourceTable, Rows: progress.CopiedRows, ShardRows: make(map[string]int64)}
+	if progress.Status == backfillStatusDone {
+		return result, nil
+	}
+
+	// writeGroups 并发写入各分表，使用新的会话，worker 之间不共用调用方的 Statement
+	db = db.Session(&gorm.Session{})
+	filler := &backfiller{db: db, strategy: strategy, model: model, options: opts, keyField: keyField, writer: NewBackfillWriter(db, opts.Writer)}
+	if err := filler.copyRows(ctx, &progress, result); err != nil {
+		result.Rows = progress.CopiedRows
+		return result, err
+	}
+	result.Rows = progress.CopiedRows
+
+	if !opts.SkipVerify && progress.LastKey != "" {
+		if err := filler.verify(&progress); err != nil {
+			return result, err
+		}
+		result.Verified = true
+	}
+
+	progress.Status = backfillStatusDone
+	progress.UpdatedAt = time.Now()
+	if err := db.Table(opts.ProgressTable).Save(&progress).Error; err != nil {
+		return result, fmt.Errorf("failed to save backfill progress: %w", err)
+	}
+	return result, nil
+}
+
+// backfiller 单次回填的状态
+type backfiller struct {
+	db       *gorm.DB
+	strategy ShardingStrategy
+	model    interface{}
+	options  BackfillTableOptions
+	keyField *schema.Field // KeyColumn 对应的字段
+	writer   *BackfillWriter
+}
+
+// copyRows 从 progress.LastKey 之后按 KeyColumn 顺序分批读取并写入分表，每批写入完成后保存进度
+func (b *backfiller) copyRows(ctx context.Context, progress *BackfillProgressRecord, result *BackfillResult) error {
+	modelType := reflect.Indirect(reflect.ValueOf(b.model)).Type()
+	sliceType := reflect.SliceOf(modelType)
+	quotedKey := b.db.Statement.Quote(b.keyField.DBName)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		rows := reflect.New(sliceType)
+		query := b.db.Table(progress.SourceTable).Order(quotedKey).Limit(b.options.BatchSize)
+		if progress.LastKey != "" {
+			query = query.Where(quotedKey+" > ?", progress.LastKey)
+		}
+		if err := query.Find(rows.Interface()).Error; err != nil {
+			return fmt.Errorf("failed to read table %s: %w", progress.SourceTable, err)
+		}
+		batch := rows.Elem()
+		if batch.Len() == 0 {
+			return nil
+		}
+
+		groups, order, err := b.routeRows(batch, sliceType)
+		if err != nil {
+			return err
+		}
+		if err := b.writeGroups(ctx, groups, order); err != nil {
+			return err
+		}
+		for _, tableName := range order {
+			result.ShardRows[tableName] += int64(groups[tableName].Elem().Len())
+		}
+
+		lastKey, _ := b.keyField.ValueOf(ctx, batch.Index(batch.Len()-1))
+		next := *progress
+		next.LastKey = fmt.Sprintf("%v", lastKey)
+		next.CopiedRows += int64(batch.Len())
+		next.UpdatedAt = time.Now()
+		if err := b.db.Table(b.options.ProgressTable).Save(&next).Error; err != nil {
+			return fmt.Errorf("failed to save backfill progress: %w", err)
+		}
+		*progress = next
+		if b.options.OnProgress != nil {
+			b.options.OnProgress(progress.CopiedRows)
+		}
+
+		if batch.Len() < b.options.BatchSize {
+			return nil
+		}
+	}
+}
+
+// routeRows 按策略把一批数据分组到分表，返回每个分表的数据（切片指针）和分表的出现顺序
+func (b *backfiller) routeRows(batch reflect.Value, sliceType reflect.Type) (map[string]reflect.Value, []string, error) {
+	groups := make(map[string]reflect.Value)
+	var order []string
+	for i := 0; i < batch.Len(); i++ {
+		row := batch.Index(i)
+		shardingValue, err := b.strategy.GetShardingValue(row.Addr().Interface())
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get sharding value of row %d: %w", i, err)
+		}
+		tableName, err := GetTableNameE(b.strategy, b.strategy.GetBaseTableName(), shardingValue)
+		if err != nil {
+			return nil, nil, err
+		}
+		group, ok := groups[tableName]
+		if !ok {
+			group = reflect.New(sliceType)
+			groups[tableName] = group
+			order = append(order, tableName)
+		}
+		group.Elem().Set(reflect.Append(group.Elem(), row))
+	}
+	return groups, order, nil
+}
+
+// writeGroups 并发写入每个分表的数据（分表不存在时创建，已存在的行忽略），失败的分表汇总为 ShardErrors
+func (b *backfiller) writeGroups(ctx context.Context, groups map[string]reflect.Value, order []string) error {
+	errs := make([]error, len(order))
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, b.options.Concurrency)
+	for i, tableName := range order {
+		semaphore <- struct{}{}
+		wg.Add(1)
+		go func(i int, tableName string) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+
+			if err := ensureShardTable(b.db, b.strategy, tableName, b.model); err != nil {
+				errs[i] = err
+				return
+			}
+			errs[i] = b.writer.Write(ctx, tableName, groups[tableName].Interface())
+		}(i, tableName)
+	}
+	wg.Wait()
+
+	var shardErrs ShardErrors
+	for i, err := range errs {
+		if err != nil {
+			shardErrs = append(shardErrs, &ShardError{Table: order[i], Err: err})
+		}
+	}
+	if len(shardErrs) > 0 {
+		return shardErrs
+	}
+	return nil
+}
+
+// verify 校验源表的行数与分表中 KeyColumn 不大于 LastKey 的行数之和（不包括回填开始后写入分表的新数据）
+func (b *backfiller) verify(progress *BackfillProgressRecord) error {
+	var sourceCount int64
+	if err := b.db.Table(progress.SourceTable).Count(&sourceCount).Error; err != nil {
+		return fmt.Errorf("failed to count table %s: %w", progress.SourceTable, err)
+	}
+
+	tableNames, err := knownShardTables(b.db, b.strategy)
+	if err != nil {
+		return err
+	}
+	quotedKey := b.db.Statement.Quote(b.keyField.DBName)
+	var shardCount int64
+	for _, tableName := range tableNames {
+		var count int64
+		err := b.db.Table(tableName).Where(quotedKey+" <= ?", progress.LastKey).Count(&count).Error
+		if isTableNotFoundError(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to count table %s: %w", tableName, err)
+		}
+		shardCount += count
+	}
+
+	if sourceCount != shardCount {
+		return fmt.Errorf("%w: table %s has %d rows, shards have %d", ErrBackfillMismatch, progress.SourceTable, sourceCount, shardCount)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package sharding
+
+import (
+	"gorm.io/gorm"
+)
+
+// Capabilities 数据库方言和分表策略支持的能力，调用方可以据此选择执行方式，而不是在运行时遇到错误后再回退
+type Capabilities struct {
+	Dialect string // 数据库方言（如 "mysql"、"postgres"、"sqlite"）
+
+	UnionPagination bool // 支持 UNION ALL 子查询分页（CrossTablePaginateUnion、CrossTableQueryUnion）
+	NativeUpsert    bool // 支持原生 upsert（MySQL ON DUPLICATE KEY UPDATE、PostgreSQL/SQLite ON CONFLICT、SQL Server MERGE）
+	FullOuterJoin   bool // 支持 FULL OUTER JOIN
+	NullsLast       bool // 升序排序时 NULL 默认排在非 NULL 值之后（PostgreSQL），与跨分表归并的 NULL 最小不一致
+
+	KeyPruning       bool // 策略可以根据 WHERE 条件中的分表键只访问对应的分表
+	TimeRangePruning bool // 策略可以根据时间范围只访问对应的分表
+	Pruning          bool // 支持任意一种分表裁剪
+	Colocation       bool // 策略可以与相同配置的策略按分表一一对应连接（共置连接）
+}
+
+// GetCapabilities 根据数据库方言和分表策略计算支持的能力（strategy 为 nil 时只计算方言的能力）
+func GetCapabilities(db *gorm.DB, strategy ShardingStrategy) Capabilities {
+	capabilities := Capabilities{}
+	if db != nil && db.Dialector != nil {
+		capabilities.Dialect = db.Dialector.Name()
+	}
+
+	switch capabilities.Dialect {
+	case "mysql":
+		capabilities.UnionPagination = true
+		capabilities.NativeUpsert = true
+	case "postgres":
+		capabilities.UnionPagination = true
+		capabilities.NativeUpsert = true
+		capabilities.FullOuterJoin = true
+		capabilities.NullsLast = true
+	case "sqlite":
+		capabilities.UnionPagination = true
+		capabilities.NativeUpsert = true
+	case "sqlserver":
+		capabilities.UnionPagination = true
+		capabilities.NativeUpsert = true
+		capabilities.FullOuterJoin = true
+	}
+
+	if strategy != nil {
+		_, capabilities.KeyPruning = strategy.(ShardingKeyProvider)
+		_, capabilities.TimeRangePruning = strategy.(timeRangeSharding)
+		capabilities.Pruning = capabilities.KeyPruning || capabilities.TimeRangePruning
+		_, capabilities.Colocation = colocationSignature(strategy)
+	}
+	return capabilities
+}
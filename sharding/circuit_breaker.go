@@ -0,0 +1,223 @@
+package sharding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultBreakerFailureThreshold 默认的连续失败次数阈值
+	defaultBreakerFailureThreshold = 5
+
+	// defaultBreakerCoolDown 默认的熔断冷却时间
+	defaultBreakerCoolDown = 30 * time.Second
+)
+
+// CircuitState 熔断器状态
+type CircuitState int
+
+const (
+	CircuitClosed   CircuitState = iota // 正常执行
+	CircuitOpen                         // 熔断中，直接返回 ErrCircuitOpen
+	CircuitHalfOpen                     // 冷却结束，允许一次试探执行
+)
+
+// String 状态名称
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// CircuitBreakerOptions 熔断器配置
+type CircuitBreakerOptions struct {
+	FailureThreshold int                                     // 连续失败多少次后熔断（默认 5）
+	CoolDown         time.Duration                           // 熔断后的冷却时间，之后允许一次试探执行（默认 30 秒）
+	IsFailure        func(err error) bool                    // 哪些错误计入失败（默认不包括表或列不存在、取消、结果超过限制）
+	OnStateChange    func(key string, from, to CircuitState) // 状态变化时调用（可选）
+}
+
+// circuit 单个分表（或表组合）的熔断状态
+type circuit struct {
+	state    CircuitState
+	failures int       // 连续失败次数
+	openedAt time.Time // 熔断开始时间
+	probing  bool      // 半开状态下是否已有试探执行
+}
+
+// CircuitBreaker 分表级别的熔断器
+// 在 ShardQueryOptions.CircuitBreaker 或 MultiJoinConfig.CircuitBreaker 中指定后，连续失败的分表（或表组合）
+// 在冷却时间内不再执行：跨表查询跳过该分表并返回部分结果（ShardErrors 中的错误包装 ErrCircuitOpen），
+// 多表连接查询立即返回 ErrCircuitOpen，避免每次查询都等待故障分表超时。同一个熔断器可以在多个查询间共享
+type CircuitBreaker struct {
+	options CircuitBreakerOptions
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+// NewCircuitBreaker 创建熔断器
+func NewCircuitBreaker(options ...CircuitBreakerOptions) *CircuitBreaker {
+	opts := CircuitBreakerOptions{}
+	if len(options) > 0 {
+		opts = options[0]
+	}
+	if opts.FailureThreshold < 1 {
+		opts.FailureThreshold = defaultBreakerFailureThreshold
+	}
+	if opts.CoolDown <= 0 {
+		opts.CoolDown = defaultBreakerCoolDown
+	}
+	if opts.IsFailure == nil {
+		opts.IsFailure = isBreakerFailure
+	}
+	return &CircuitBreaker{
+		options:  opts,
+		circuits: make(map[string]*circuit),
+	}
+}
+
+// Do 在熔断器的保护下执行 fn（熔断器为 nil 时直接执行），key 为分表名称或表组合
+func (b *CircuitBreaker) Do(key string, fn func() error) error {
+	if b == nil {
+		return fn()
+	}
+	if err := b.allow(key); err != nil {
+		return err
+	}
+	err := fn()
+	b.record(key, err)
+	return err
+}
+
+// State 获取分表（或表组合）的熔断状态
+func (b *CircuitBreaker) State(key string) CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if c, ok := b.circuits[key]; ok {
+		return c.state
+	}
+	return CircuitClosed
+}
+
+// States 获取所有非正常状态的分表（或表组合）
+func (b *CircuitBreaker) States() map[string]CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	states := make(map[string]CircuitState)
+	for key, c := range b.circuits {
+		if c.state != CircuitClosed {
+			states[key] = c.state
+		}
+	}
+	return states
+}
+
+// Reset 恢复分表（或表组合）的正常状态（如确认故障已修复后）
+func (b *CircuitBreaker) Reset(key string) {
+	b.mu.Lock()
+	c, ok := b.circuits[key]
+	var from CircuitState
+	if ok {
+		from = c.state
+		delete(b.circuits, key)
+	}
+	b.mu.Unlock()
+	if ok && from != CircuitClosed {
+		b.notify(key, from, CircuitClosed)
+	}
+}
+
+// allow 判断是否可以执行，熔断中返回 ErrCircuitOpen
+func (b *CircuitBreaker) allow(key string) error {
+	b.mu.Lock()
+	c, ok := b.circuits[key]
+	if !ok || c.state == CircuitClosed {
+		b.mu.Unlock()
+		return nil
+	}
+
+	var from CircuitState
+	changed := false
+	if c.state == CircuitOpen && time.Since(c.openedAt) >= b.options.CoolDown {
+		from, changed = c.state, true
+		c.state = CircuitHalfOpen
+		c.probing = false
+	}
+	if c.state == CircuitHalfOpen && !c.probing {
+		c.probing = true
+		b.mu.Unlock()
+		if changed {
+			b.notify(key, from, CircuitHalfOpen)
+		}
+		return nil
+	}
+	retryAt := c.openedAt.Add(b.options.CoolDown)
+	b.mu.Unlock()
+	return fmt.Errorf("%w: %s (retry after %s)", ErrCircuitOpen, key, retryAt.Format(time.RFC3339))
+}
+
+// record 记录执行结果
+func (b *CircuitBreaker) record(key string, err error) {
+	failed := err != nil && b.options.IsFailure(err)
+
+	b.mu.Lock()
+	c, ok := b.circuits[key]
+	if !ok {
+		if !failed {
+			b.mu.Unlock()
+			return
+		}
+		c = &circuit{}
+		b.circuits[key] = c
+	}
+
+	from := c.state
+	if failed {
+		c.failures++
+		if c.state == CircuitHalfOpen || c.failures >= b.options.FailureThreshold {
+			c.state = CircuitOpen
+			c.openedAt = time.Now()
+		}
+	} else if err == nil || c.state != CircuitHalfOpen {
+		// 半开状态下不计入失败的错误（如取消）不能说明分表已经恢复，等待下一次试探
+		c.state = CircuitClosed
+		c.failures = 0
+	}
+	c.probing = false
+	to := c.state
+	if to == CircuitClosed && c.failures == 0 {
+		delete(b.circuits, key)
+	}
+	b.mu.Unlock()
+
+	if from != to {
+		b.notify(key, from, to)
+	}
+}
+
+// notify 调用状态变化回调
+func (b *CircuitBreaker) notify(key string, from, to CircuitState) {
+	if b.options.OnStateChange != nil {
+		b.options.OnStateChange(key, from, to)
+	}
+}
+
+// isBreakerFailure 默认的失败判断：表或列不存在、取消、结果超过限制、数据源已被排除等不是分表故障
+func isBreakerFailure(err error) bool {
+	return !isTableNotFoundError(err) &&
+		!isColumnNotFoundError(err) &&
+		!errors.Is(err, context.Canceled) &&
+		!errors.Is(err, errStopShards) &&
+		!errors.Is(err, ErrResultTooLarge) &&
+		!errors.Is(err, ErrCircuitOpen) &&
+		!errors.Is(err, ErrShardUnavailable)
+}
@@ -0,0 +1,75 @@
+package sharding
+
+import (
+	"fmt"
+	"strings"
+)
+
+// colocationSignature 获取策略的共置签名
+// 策略类型、分表键、分表数量（以及范围大小）都相同的两个策略签名相同，
+// 同一个分表键值在两边一定落在相同索引的分表中（如 users_1 与 orders_1）
+func colocationSignature(strategy ShardingStrategy) (string, bool) {
+	signature, ok := partitionSignature(strategy)
+	if !ok {
+		return "", false
+	}
+	return signature + ":" + normalizeKey(strategy.(ShardingKeyProvider).GetShardingKey()), true
+}
+
+// partitionSignature 获取策略的分区签名（不包含分表键）
+// 签名相同的两个策略把相同的值映射到相同索引的分表，分表键可以是不同的列（如 users.id 与 orders.user_id）
+func partitionSignature(strategy ShardingStrategy) (string, bool) {
+	switch s := strategy.(type) {
+	case *HashShardingStrategy:
+		if s.hash != nil && s.hashName == "" {
+			// 自定义哈希方案未命名，无法判断两边是否一致
+			return "", false
+		}
+		hashName := s.hashName
+		if hashName == "" {
+			hashName = "fnv1a"
+		}
+		return fmt.Sprintf("hash:%s:%d", hashName, s.tableCount), true
+	case *JumpHashShardingStrategy:
+		return fmt.Sprintf("jump:%d", s.tableCount), true
+	case *ModuloShardingStrategy:
+		return fmt.Sprintf("modulo:%d", s.modulo), true
+	case *RangeShardingStrategy:
+		return fmt.Sprintf("range:%d:%d", s.rangeSize, s.tableCount), true
+	default:
+		return "", false
+	}
+}
+
+// isColocated 判断两个策略是否共置，并且 ON 条件中有两边分表键的等值连接（如 users.id = orders.user_id）
+// 只有连接条件是分表键相等时，按索引一一对应连接才不会丢失数据；条件中的表使用基础表名
+func isColocated(strategy1, strategy2 ShardingStrategy, onCondition string) bool {
+	if strategy1 == nil || strategy2 == nil {
+		return false
+	}
+	signature1, ok1 := partitionSignature(strategy1)
+	signature2, ok2 := partitionSignature(strategy2)
+	if !ok1 || !ok2 || signature1 != signature2 {
+		return false
+	}
+
+	table1 := JoinInfo{Strategy: strategy1, OnCondition: onCondition}
+	table2 := JoinInfo{Strategy: strategy2}
+	base1, base2 := strategy1.GetBaseTableName(), strategy2.GetBaseTableName()
+	for _, on := range table1.joinEqualities() {
+		if strings.EqualFold(on.LeftTable, base1) && strings.EqualFold(on.RightTable, base2) &&
+			table1.isShardKeyColumn(on.LeftCol) && table2.isShardKeyColumn(on.RightCol) {
+			return true
+		}
+		if strings.EqualFold(on.LeftTable, base2) && strings.EqualFold(on.RightTable, base1) &&
+			table2.isShardKeyColumn(on.LeftCol) && table1.isShardKeyColumn(on.RightCol) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeKey 规范化分表键名称，使 UserID、user_id、userid 视为同一个键
+func normalizeKey(key string) string {
+	return strings.ToLower(strings.ReplaceAll(key, "_", ""))
+}
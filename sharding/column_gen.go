@@ -0,0 +1,142 @@
+package sharding
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm/schema"
+)
+
+// ColumnGenOptions 列常量生成选项
+type ColumnGenOptions struct {
+	PackageName    string       // 生成代码的包名（默认为第一个模型所在包的包名）
+	PackagePath    string       // 生成代码的包导入路径，该包中的类型不加包名前缀（默认为第一个模型所在的包）
+	NamingStrategy schema.Namer // 表名和列名的命名策略（默认与 GORM 默认值相同）
+	Suffix         string       // 变量名后缀（默认 "Cols"，如 UserCols）
+}
+
+// GenerateColumns 为模型生成类型安全的列引用代码，写入 w
+// 每个模型生成一个变量（如 UserCols），字段与模型的字段一一对应，类型为 sharding.Column[字段类型]：
+//
+//	db.Where(UserCols.UserID.Eq(1001)).Find(&users)
+//	JoinInfo{OnCondition: sharding.On(UserCols.ID, OrderCols.UserID)}
+//
+// 模型字段改名或改类型后重新生成，使用旧字段的代码会编译失败，而不是在运行时生成错误的 SQL。
+// 通常在模型所在的包中通过 go:generate 调用一个执行 GenerateColumns 的小程序
+func GenerateColumns(w io.Writer, options ColumnGenOptions, models ...interface{}) error {
+	if len(models) == 0 {
+		return fmt.Errorf("no models to generate columns for")
+	}
+	if options.NamingStrategy == nil {
+		options.NamingStrategy = schema.NamingStrategy{}
+	}
+	if options.Suffix == "" {
+		options.Suffix = "Cols"
+	}
+	firstType := reflect.Indirect(reflect.ValueOf(models[0])).Type()
+	if options.PackagePath == "" {
+		options.PackagePath = firstType.PkgPath()
+	}
+	if options.PackageName == "" {
+		options.PackageName = path.Base(options.PackagePath)
+	}
+
+	shardingPkgPath := reflect.TypeOf(Column[int]{}).PkgPath()
+	imports := map[string]bool{}
+	if shardingPkgPath != options.PackagePath {
+		imports[shardingPkgPath] = true
+	}
+	columnType := "Column"
+	newColumn := "NewColumn"
+	if shardingPkgPath != options.PackagePath {
+		columnType = path.Base(shardingPkgPath) + ".Column"
+		newColumn = path.Base(shardingPkgPath) + ".NewColumn"
+	}
+
+	var body bytes.Buffer
+	cache := &sync.Map{}
+	for _, model := range models {
+		s, err := schema.Parse(model, cache, options.NamingStrategy)
+		if err != nil {
+			return fmt.Errorf("failed to parse model %T: %w", model, err)
+		}
+
+		varName := s.Name + options.Suffix
+		fmt.Fprintf(&body, "\n// %s %s 表的列\n", varName, s.Table)
+		fmt.Fprintf(&body, "var %s = struct {\n", varName)
+		fields := make([]*schema.Field, 0, len(s.Fields))
+		for _, field := range s.Fields {
+			if field.DBName != "" {
+				fields = append(fields, field)
+			}
+		}
+		typeExprs := make([]string, len(fields))
+		for i, field := range fields {
+			typeExprs[i] = goTypeExpr(field.FieldType, options.PackagePath, imports)
+			fmt.Fprintf(&body, "\t%s %s[%s]\n", field.Name, columnType, typeExprs[i])
+		}
+		fmt.Fprintf(&body, "}{\n")
+		for i, field := range fields {
+			fmt.Fprintf(&body, "\t%s: %s[%s](%q, %q),\n", field.Name, newColumn, typeExprs[i], s.Table, field.DBName)
+		}
+		fmt.Fprintf(&body, "}\n")
+	}
+
+	var source bytes.Buffer
+	fmt.Fprintf(&source, "// Code generated by sharding.GenerateColumns. DO NOT EDIT.\n\npackage %s\n", options.PackageName)
+	if len(imports) > 0 {
+		paths := make([]string, 0, len(imports))
+		for importPath := range imports {
+			paths = append(paths, importPath)
+		}
+		sort.Strings(paths)
+		source.WriteString("\nimport (\n")
+		for _, importPath := range paths {
+			fmt.Fprintf(&source, "\t%q\n", importPath)
+		}
+		source.WriteString(")\n")
+	}
+	source.Write(body.Bytes())
+
+	formatted, err := format.Source(source.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to format generated code: %w", err)
+	}
+	_, err = w.Write(formatted)
+	return err
+}
+
+// goTypeExpr 获取类型在生成代码中的表达式，记录需要导入的包
+func goTypeExpr(t reflect.Type, pkgPath string, imports map[string]bool) string {
+	if t.Name() != "" {
+		if t.PkgPath() == "" || t.PkgPath() == pkgPath {
+			return t.Name()
+		}
+		imports[t.PkgPath()] = true
+		// t.String() 为 "包名.类型名"，包名可能与导入路径的最后一段不同
+		return t.String()[:strings.Index(t.String(), ".")] + "." + t.Name()
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return "*" + goTypeExpr(t.Elem(), pkgPath, imports)
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 && t.Elem().Name() == "uint8" {
+			return "[]byte"
+		}
+		return "[]" + goTypeExpr(t.Elem(), pkgPath, imports)
+	case reflect.Array:
+		return fmt.Sprintf("[%d]%s", t.Len(), goTypeExpr(t.Elem(), pkgPath, imports))
+	case reflect.Map:
+		return "map[" + goTypeExpr(t.Key(), pkgPath, imports) + "]" + goTypeExpr(t.Elem(), pkgPath, imports)
+	default:
+		return t.String()
+	}
+}
@@ -0,0 +1,117 @@
+package sharding
+
+import (
+	"fmt"
+
+	"gorm.io/gorm/clause"
+)
+
+// ColumnRef 列引用（用于连接条件）
+type ColumnRef interface {
+	// QualifiedName 带基础表名的列名（如 "users.user_id"）
+	QualifiedName() string
+}
+
+// Column 类型安全的列引用，通常由 GenerateColumns 为每个模型生成（如 UserCols.UserID.Eq(1)）
+// 生成的谓词可以直接传给 Where（不带表名，分表路由后仍然有效），也能被分表路由识别
+type Column[T any] struct {
+	Table string // 基础表名
+	Name  string // 列名
+}
+
+// NewColumn 创建列引用
+func NewColumn[T any](table, name string) Column[T] {
+	return Column[T]{Table: table, Name: name}
+}
+
+// String 列名（不带表名）
+func (c Column[T]) String() string {
+	return c.Name
+}
+
+// QualifiedName 带基础表名的列名（如 "users.user_id"）
+func (c Column[T]) QualifiedName() string {
+	if c.Table == "" {
+		return c.Name
+	}
+	return c.Table + "." + c.Name
+}
+
+// column 转换为不带表名的 clause.Column
+func (c Column[T]) column() clause.Column {
+	return clause.Column{Name: c.Name}
+}
+
+// Eq 等于
+func (c Column[T]) Eq(value T) clause.Expression {
+	return clause.Eq{Column: c.column(), Value: value}
+}
+
+// Neq 不等于
+func (c Column[T]) Neq(value T) clause.Expression {
+	return clause.Neq{Column: c.column(), Value: value}
+}
+
+// Gt 大于
+func (c Column[T]) Gt(value T) clause.Expression {
+	return clause.Gt{Column: c.column(), Value: value}
+}
+
+// Gte 大于等于
+func (c Column[T]) Gte(value T) clause.Expression {
+	return clause.Gte{Column: c.column(), Value: value}
+}
+
+// Lt 小于
+func (c Column[T]) Lt(value T) clause.Expression {
+	return clause.Lt{Column: c.column(), Value: value}
+}
+
+// Lte 小于等于
+func (c Column[T]) Lte(value T) clause.Expression {
+	return clause.Lte{Column: c.column(), Value: value}
+}
+
+// In 在列表中
+func (c Column[T]) In(values ...T) clause.Expression {
+	list := make([]interface{}, len(values))
+	for i, value := range values {
+		list[i] = value
+	}
+	return clause.IN{Column: c.column(), Values: list}
+}
+
+// Between 在闭区间 [start, end] 中
+func (c Column[T]) Between(start, end T) clause.Expression {
+	return clause.And(c.Gte(start), c.Lte(end))
+}
+
+// Like 模糊匹配
+func (c Column[T]) Like(pattern string) clause.Expression {
+	return clause.Like{Column: c.column(), Value: pattern}
+}
+
+// IsNull 为 NULL
+func (c Column[T]) IsNull() clause.Expression {
+	return clause.Eq{Column: c.column(), Value: nil}
+}
+
+// IsNotNull 不为 NULL
+func (c Column[T]) IsNotNull() clause.Expression {
+	return clause.Neq{Column: c.column(), Value: nil}
+}
+
+// Asc 升序排序列（用于 CrossTableOrderedPaginate 等）
+func (c Column[T]) Asc() OrderByColumn {
+	return OrderByColumn{Column: c.Name}
+}
+
+// Desc 倒序排序列
+func (c Column[T]) Desc() OrderByColumn {
+	return OrderByColumn{Column: c.Name, Desc: true}
+}
+
+// On 生成等值连接条件（用于 JoinInfo.OnCondition 等），如 On(UserCols.ID, OrderCols.UserID) 得到 "users.id = orders.user_id"
+func On(left, right ColumnRef) string {
+	return fmt.Sprintf("%s = %s", left.QualifiedName(), right.QualifiedName())
+}
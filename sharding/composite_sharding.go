@@ -0,0 +1,158 @@
+package sharding
+
+import (
+	"time"
+)
+
+// CompositeShardingValue 组合分表策略的分表键值
+type CompositeShardingValue struct {
+	Primary   interface{} // 第一级策略的分表键值
+	Secondary interface{} // 第二级策略的分表键值
+}
+
+// CompositeShardingStrategy 组合分表策略，先按第一级策略分表，再在结果上按第二级策略分表
+// 例如：先按 user_id Hash 分表，再按月分表，得到 orders_3_202401
+// 组合策略需要两个分表键才能确定分表，因此不会根据 WHERE 条件中的单个分表键自动路由查询
+type CompositeShardingStrategy struct {
+	baseTableName string
+	primary       ShardingStrategy
+	secondary     ShardingStrategy
+
+	ShardOptionsMap // 分表级别的建表选项（通过 SetShardOptions 配置）
+}
+
+// NewCompositeShardingStrategy 创建组合分表策略
+// primary: 第一级策略（如 NewHashShardingStrategy("orders", "user_id", 4)）
+// secondary: 第二级策略（如 NewTimeShardingStrategy("orders", "created_at", TimeShardingByMonth)），
+// 以第一级策略得到的表名作为基础表名生成最终表名
+func NewCompositeShardingStrategy(baseTableName string, primary, secondary ShardingStrategy) *CompositeShardingStrategy {
+	return &CompositeShardingStrategy{
+		baseTableName: baseTableName,
+		primary:       primary,
+		secondary:     secondary,
+	}
+}
+
+// GetTableName 根据分表键值获取实际表名
+// shardingValue 应为 CompositeShardingValue；其他值作为第一级策略的分表键值，第二级策略的分表键值为 nil（时间分表使用当前时间）
+func (s *CompositeShardingStrategy) GetTableName(baseTableName string, shardingValue interface{}) string {
+	value := compositeValue(shardingValue)
+	return s.secondary.GetTableName(s.primary.GetTableName(baseTableName, value.Primary), value.Secondary)
+}
+
+// GetTableNameE 根据分表键值获取实际表名，任意一级策略返回错误（如严格模式的时间分表）时返回该错误
+func (s *CompositeShardingStrategy) GetTableNameE(baseTableName string, shardingValue interface{}) (string, error) {
+	value := compositeValue(shardingValue)
+	primaryTable, err := GetTableNameE(s.primary, baseTableName, value.Primary)
+	if err != nil {
+		return "", err
+	}
+	return GetTableNameE(s.secondary, primaryTable, value.Secondary)
+}
+
+// GetAllTableNames 获取所有分表名称（两级分表的笛卡尔积；时间分表的维度默认展开最近一年）
+func (s *CompositeShardingStrategy) GetAllTableNames(baseTableName string) []string {
+	endTime := time.Now()
+	startTime := endTime.AddDate(-1, 0, 0)
+	return s.GetAllTableNamesInRange(baseTableName, startTime, endTime)
+}
+
+// GetAllTableNamesInRange 获取指定时间范围内的所有表名（只缩小时间分表的维度，其他维度展开全部分表）
+func (s *CompositeShardingStrategy) GetAllTableNamesInRange(baseTableName string, startTime, endTime time.Time) []string {
+	tableNames := make([]string, 0)
+	for _, primaryTable := range expandTableNamesInRange(s.primary, baseTableName, startTime, endTime) {
+		tableNames = append(tableNames, expandTableNamesInRange(s.secondary, primaryTable, startTime, endTime)...)
+	}
+	return tableNames
+}
+
+// GetAllTableNamesInRangeWithValues 使用时间值（支持多种类型）获取表名范围
+func (s *CompositeShardingStrategy) GetAllTableNamesInRangeWithValues(baseTableName string, startValue, endValue interface{}) []string {
+	timeStrategy := s.timeStrategy()
+	if timeStrategy == nil {
+		return s.GetAllTableNames(baseTableName)
+	}
+	startTime, endTime, _ := timeStrategy.ParseTimeRange(startValue, endValue)
+	return s.GetAllTableNamesInRange(baseTableName, startTime, endTime)
+}
+
+// GetShardingValue 从模型对象中提取两级策略的分表键值
+func (s *CompositeShardingStrategy) GetShardingValue(value interface{}) (interface{}, error) {
+	primaryValue, err := s.primary.GetShardingValue(value)
+	if err != nil {
+		return nil, err
+	}
+	secondaryValue, err := s.secondary.GetShardingValue(value)
+	if err != nil {
+		return nil, err
+	}
+	return CompositeShardingValue{Primary: primaryValue, Secondary: secondaryValue}, nil
+}
+
+// GetBaseTableName 获取基础表名
+func (s *CompositeShardingStrategy) GetBaseTableName() string {
+	return s.baseTableName
+}
+
+// GetShardingKeys 获取两级策略的分表键字段名（未提供分表键的策略返回空字符串）
+func (s *CompositeShardingStrategy) GetShardingKeys() []string {
+	keys := make([]string, 0, 2)
+	for _, strategy := range []ShardingStrategy{s.primary, s.secondary} {
+		key := ""
+		if provider, ok := strategy.(ShardingKeyProvider); ok {
+			key = provider.GetShardingKey()
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// Primary 获取第一级策略
+func (s *CompositeShardingStrategy) Primary() ShardingStrategy {
+	return s.primary
+}
+
+// Secondary 获取第二级策略
+func (s *CompositeShardingStrategy) Secondary() ShardingStrategy {
+	return s.secondary
+}
+
+// compositeValue 将分表键值转换为 CompositeShardingValue（其他值作为第一级策略的分表键值）
+func compositeValue(shardingValue interface{}) CompositeShardingValue {
+	switch v := shardingValue.(type) {
+	case CompositeShardingValue:
+		return v
+	case *CompositeShardingValue:
+		if v != nil {
+			return *v
+		}
+		return CompositeShardingValue{}
+	default:
+		return CompositeShardingValue{Primary: shardingValue}
+	}
+}
+
+// timeStrategy 获取组合中的时间分表策略（没有时返回 nil）
+func (s *CompositeShardingStrategy) timeStrategy() *TimeShardingStrategy {
+	if timeStrategy, ok := s.primary.(*TimeShardingStrategy); ok {
+		return timeStrategy
+	}
+	if timeStrategy, ok := s.secondary.(*TimeShardingStrategy); ok {
+		return timeStrategy
+	}
+	return nil
+}
+
+// timeRangeSharding 支持按时间范围缩小分表范围的策略（时间分表、包含时间分表的组合分表）
+type timeRangeSharding interface {
+	GetAllTableNamesInRange(baseTableName string, startTime, endTime time.Time) []string
+	GetAllTableNamesInRangeWithValues(baseTableName string, startValue, endValue interface{}) []string
+}
+
+// expandTableNamesInRange 展开策略在 baseTableName 下的分表，支持时间范围的策略只展开该时间范围内的分表
+func expandTableNamesInRange(strategy ShardingStrategy, baseTableName string, startTime, endTime time.Time) []string {
+	if rangeStrategy, ok := strategy.(timeRangeSharding); ok {
+		return rangeStrategy.GetAllTableNamesInRange(baseTableName, startTime, endTime)
+	}
+	return strategy.GetAllTableNames(baseTableName)
+}
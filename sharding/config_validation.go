@@ -0,0 +1,166 @@
+package sharding
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidConfig 分表配置与数据库不一致
+var ErrInvalidConfig = errors.New("invalid sharding config")
+
+// ConfigIssue 配置校验发现的一个问题
+type ConfigIssue struct {
+	Table   string `json:"table"`   // 基础表名
+	Check   string `json:"check"`   // 检查项（config、missing_shard、extra_shard、routing）
+	Message string `json:"message"` // 问题描述
+}
+
+// ConfigValidationError 配置校验失败的详细信息，可通过 errors.Is(err, ErrInvalidConfig) 判断
+// 结构体可以直接序列化为 JSON，便于在管理接口中返回
+type ConfigValidationError struct {
+	Issues []ConfigIssue `json:"issues"`
+}
+
+func (e *ConfigValidationError) Error() string {
+	messages := make([]string, len(e.Issues))
+	for i, issue := range e.Issues {
+		messages[i] = fmt.Sprintf("%s [%s]: %s", issue.Table, issue.Check, issue.Message)
+	}
+	return fmt.Sprintf("invalid sharding config: %s", strings.Join(messages, "; "))
+}
+
+func (e *ConfigValidationError) Unwrap() error {
+	return ErrInvalidConfig
+}
+
+// ValidateConfigOptions 配置校验选项
+type ValidateConfigOptions struct {
+	SampleKeys map[string][]interface{} // 基础表名 -> 分表键样本，检查路由结果是否稳定且落在已知分表中
+}
+
+// ValidateConfig 将分表配置与数据库中的实际分表进行交叉检查，可在启动时调用（或设置 Config.ValidateOnStartup），
+// 也可以在管理接口中调用并返回 *ConfigValidationError：
+//   - 配置本身：策略不能为空、基础表名不能重复、自动建表时必须提供模型
+//   - 分表是否存在：未开启自动建表时，每个分表（时间分表为当前周期的分表）都必须存在
+//   - 分表数量是否一致：数据库中存在配置之外的同名编号分表（如配置 4 张表，数据库有 users_0 ~ users_7）
+//   - 路由是否一致：同一个分表键多次路由的结果相同，且落在配置的分表中
+func ValidateConfig(db *gorm.DB, config Config, options ...ValidateConfigOptions) error {
+	opts := ValidateConfigOptions{}
+	if len(options) > 0 {
+		opts = options[0]
+	}
+
+	existingTables, err := db.Migrator().GetTables()
+	if err != nil {
+		return fmt.Errorf("failed to list tables: %w", err)
+	}
+	existing := make(map[string]bool, len(existingTables))
+	for _, tableName := range existingTables {
+		existing[tableName] = true
+	}
+
+	var issues []ConfigIssue
+	addIssue := func(table, check, format string, args ...interface{}) {
+		issues = append(issues, ConfigIssue{Table: table, Check: check, Message: fmt.Sprintf(format, args...)})
+	}
+
+	seen := make(map[string]bool)
+	for i, table := range config.Tables {
+		if table.Strategy == nil {
+			addIssue(fmt.Sprintf("#%d", i), "config", "sharding strategy is required")
+			continue
+		}
+
+		baseTableName := table.Strategy.GetBaseTableName()
+		if seen[baseTableName] {
+			addIssue(baseTableName, "config", "duplicate sharding strategy")
+			continue
+		}
+		seen[baseTableName] = true
+		if table.AutoCreateTable && table.Model == nil {
+			addIssue(baseTableName, "config", "model is required for auto create table")
+		}
+
+		// 分表是否存在
+		expected := expectedShardTables(table.Strategy)
+		if !table.AutoCreateTable {
+			missing := make([]string, 0)
+			for _, tableName := range expected {
+				if !existing[tableName] {
+					missing = append(missing, tableName)
+				}
+			}
+			if len(missing) > 0 {
+				addIssue(baseTableName, "missing_shard", "%d of %d shard table(s) do not exist: %s",
+					len(missing), len(expected), strings.Join(missing, ", "))
+			}
+		}
+
+		// 分表数量是否一致（时间分表的表名随时间增加，不检查）
+		if _, isTime := table.Strategy.(*TimeShardingStrategy); !isTime {
+			expectedSet := make(map[string]bool, len(expected))
+			for _, tableName := range expected {
+				expectedSet[tableName] = true
+			}
+			pattern := shardTablePattern(table.Strategy)
+			extra := make([]string, 0)
+			for _, tableName := range existingTables {
+				if pattern.MatchString(tableName) && !expectedSet[tableName] {
+					extra = append(extra, tableName)
+				}
+			}
+			if len(extra) > 0 {
+				sort.Strings(extra)
+				addIssue(baseTableName, "extra_shard", "config expects %d shard table(s) but the database also has: %s",
+					len(expected), strings.Join(extra, ", "))
+			}
+		}
+
+		// 样本键的路由是否一致
+		for _, key := range opts.SampleKeys[baseTableName] {
+			first := table.Strategy.GetTableName(baseTableName, key)
+			if second := table.Strategy.GetTableName(baseTableName, key); second != first {
+				addIssue(baseTableName, "routing", "key %v routes to %s and %s", key, first, second)
+				continue
+			}
+			if !isKnownShardTable(table.Strategy, first) {
+				addIssue(baseTableName, "routing", "key %v routes to %s, which is not a configured shard table", key, first)
+			}
+		}
+	}
+
+	if len(issues) > 0 {
+		return &ConfigValidationError{Issues: issues}
+	}
+	return nil
+}
+
+// expectedShardTables 获取配置中必须存在的分表（时间分表只要求当前周期的分表）
+func expectedShardTables(strategy ShardingStrategy) []string {
+	if _, ok := strategy.(*TimeShardingStrategy); ok {
+		return []string{strategy.GetTableName(strategy.GetBaseTableName(), time.Now())}
+	}
+	return strategy.GetAllTableNames(strategy.GetBaseTableName())
+}
+
+// isKnownShardTable 判断表名是否为策略的分表（时间分表按表名前缀或名称模板判断）
+func isKnownShardTable(strategy ShardingStrategy, tableName string) bool {
+	baseTableName := strategy.GetBaseTableName()
+	if timeStrategy, ok := strategy.(*TimeShardingStrategy); ok {
+		if timeStrategy.nameTemplate != nil {
+			return timeStrategy.nameTemplate.pattern(baseTableName).MatchString(tableName)
+		}
+		return strings.HasPrefix(tableName, baseTableName+"_")
+	}
+	for _, name := range strategy.GetAllTableNames(baseTableName) {
+		if name == tableName {
+			return true
+		}
+	}
+	return false
+}
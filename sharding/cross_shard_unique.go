@@ -0,0 +1,190 @@
+package sharding
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrDuplicate 唯一值已被其他分表（或同一分表）中的行占用
+var ErrDuplicate = errors.New("duplicate value across shards")
+
+// defaultUniqueIndexTable 全局唯一索引表的默认表名
+const defaultUniqueIndexTable = "sharding_unique_index"
+
+// UniqueIndexRecord 全局唯一索引表中的一条占用记录
+// (Scope, Value) 上有唯一索引，由数据库保证同一个值只能被占用一次
+type UniqueIndexRecord struct {
+	ID         uint64 `gorm:"primaryKey;autoIncrement"`
+	Scope      string `gorm:"size:191;not null;uniqueIndex:idx_sharding_unique_scope_value"` // 唯一约束范围（如 "users.email"）
+	Value      string `gorm:"size:191;not null;uniqueIndex:idx_sharding_unique_scope_value"` // 唯一值
+	ShardTable string `gorm:"size:191;not null"`                                             // 占用该值的行所在的分表
+	CreatedAt  time.Time
+}
+
+// CrossShardUniqueOptions 跨分表唯一约束选项
+type CrossShardUniqueOptions struct {
+	TableName       string // 全局唯一索引表名（默认 sharding_unique_index）
+	CaseInsensitive bool   // 是否忽略大小写（如邮箱）
+}
+
+// CrossShardUnique 跨分表唯一约束
+// 按哈希等方式分表后，各分表上的 UNIQUE(email) 只能保证单个分表内唯一。CrossShardUnique 在写入分表之前
+// 先向全局唯一索引表插入一条占用记录（insert-claim），插入失败说明该值已被占用，返回 ErrDuplicate。
+//
+// 一致性保证：
+//   - 唯一索引表与分表在同一个数据库时，占用和写入在同一个事务中完成，二者要么都成功、要么都失败；
+//   - 唯一索引表与分表不在同一个数据库时（如分库），先占用再写入，写入失败时释放占用；
+//     进程在两步之间崩溃会留下孤立的占用记录（之后写入相同的值会误报 ErrDuplicate，需要人工或定期清理），
+//     但不会产生重复值；
+//   - 绕过 CrossShardUnique 直接写入分表或修改唯一列的数据不受保护。
+type CrossShardUnique struct {
+	strategy ShardingStrategy
+	column   string
+	scope    string
+	options  CrossShardUniqueOptions
+}
+
+// NewCrossShardUnique 创建跨分表唯一约束
+// column: 唯一列对应的字段名或列名（如 "Email" 或 "email"，同一个约束需使用相同的写法，用于区分索引表中的记录）
+func NewCrossShardUnique(strategy ShardingStrategy, column string, options ...CrossShardUniqueOptions) *CrossShardUnique {
+	opts := CrossShardUniqueOptions{}
+	if len(options) > 0 {
+		opts = options[0]
+	}
+	if opts.TableName == "" {
+		opts.TableName = defaultUniqueIndexTable
+	}
+
+	return &CrossShardUnique{
+		strategy: strategy,
+		column:   column,
+		scope:    strategy.GetBaseTableName() + "." + column,
+		options:  opts,
+	}
+}
+
+// Migrate 创建全局唯一索引表
+func (u *CrossShardUnique) Migrate(db *gorm.DB) error {
+	return db.Table(u.options.TableName).AutoMigrate(&UniqueIndexRecord{})
+}
+
+// Create 占用唯一值并将 value 写入对应的分表（在同一个事务中）
+// 唯一值已被占用时返回包装了 ErrDuplicate 的错误
+func (u *CrossShardUnique) Create(db *gorm.DB, value interface{}) error {
+	uniqueValue, tableName, err := u.resolve(value)
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := u.Claim(tx, uniqueValue, tableName); err != nil {
+			return err
+		}
+		if err := tx.Table(tableName).Create(value).Error; err != nil {
+			return fmt.Errorf("failed to write table %s: %w", tableName, err)
+		}
+		return nil
+	})
+}
+
+// CreateIn 唯一索引表与分表不在同一个数据库时使用：先在 indexDB 中占用唯一值，再写入 shardDB，写入失败时释放占用
+func (u *CrossShardUnique) CreateIn(indexDB, shardDB *gorm.DB, value interface{}) error {
+	uniqueValue, tableName, err := u.resolve(value)
+	if err != nil {
+		return err
+	}
+
+	if err := u.Claim(indexDB, uniqueValue, tableName); err != nil {
+		return err
+	}
+	if err := shardDB.Table(tableName).Create(value).Error; err != nil {
+		if releaseErr := u.Release(indexDB, uniqueValue); releaseErr != nil {
+			return fmt.Errorf("failed to write table %s: %w (and failed to release unique claim: %v)", tableName, err, releaseErr)
+		}
+		return fmt.Errorf("failed to write table %s: %w", tableName, err)
+	}
+	return nil
+}
+
+// Delete 从分表中删除 value 并释放其唯一值（在同一个事务中）
+func (u *CrossShardUnique) Delete(db *gorm.DB, value interface{}) error {
+	uniqueValue, tableName, err := u.resolve(value)
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Table(tableName).Delete(value).Error; err != nil {
+			return fmt.Errorf("failed to delete from table %s: %w", tableName, err)
+		}
+		return u.Release(tx, uniqueValue)
+	})
+}
+
+// Claim 占用唯一值，已被占用时返回包装了 ErrDuplicate 的错误
+func (u *CrossShardUnique) Claim(db *gorm.DB, uniqueValue interface{}, tableName string) error {
+	record := UniqueIndexRecord{
+		Scope:      u.scope,
+		Value:      u.normalize(uniqueValue),
+		ShardTable: tableName,
+	}
+	if err := db.Table(u.options.TableName).Create(&record).Error; err != nil {
+		if isDuplicateKeyError(err) {
+			return fmt.Errorf("%w: %s = %q", ErrDuplicate, u.scope, record.Value)
+		}
+		return fmt.Errorf("failed to claim unique value: %w", err)
+	}
+	return nil
+}
+
+// Release 释放唯一值
+func (u *CrossShardUnique) Release(db *gorm.DB, uniqueValue interface{}) error {
+	err := db.Table(u.options.TableName).
+		Where("scope = ? AND value = ?", u.scope, u.normalize(uniqueValue)).
+		Delete(&UniqueIndexRecord{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to release unique value: %w", err)
+	}
+	return nil
+}
+
+// Lookup 查找占用唯一值的行所在的分表（可用于按唯一列路由查询，如按邮箱查找用户）
+func (u *CrossShardUnique) Lookup(db *gorm.DB, uniqueValue interface{}) (string, bool, error) {
+	var record UniqueIndexRecord
+	err := db.Table(u.options.TableName).
+		Where("scope = ? AND value = ?", u.scope, u.normalize(uniqueValue)).
+		Take(&record).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to look up unique value: %w", err)
+	}
+	return record.ShardTable, true, nil
+}
+
+// resolve 获取 value 的唯一值和所在分表
+func (u *CrossShardUnique) resolve(value interface{}) (interface{}, string, error) {
+	uniqueValue, err := ExtractValue(value, u.column)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get unique column %s: %w", u.column, err)
+	}
+	shardingValue, err := u.strategy.GetShardingValue(value)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get sharding value: %w", err)
+	}
+	return uniqueValue, u.strategy.GetTableName(u.strategy.GetBaseTableName(), shardingValue), nil
+}
+
+// normalize 将唯一值转换为索引表中保存的字符串
+func (u *CrossShardUnique) normalize(uniqueValue interface{}) string {
+	value := fmt.Sprint(uniqueValue)
+	if u.options.CaseInsensitive {
+		value = strings.ToLower(value)
+	}
+	return value
+}
@@ -0,0 +1,118 @@
+package sharding
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CrossTableFirst 跨表获取第一条记录（与 gorm 的 First 一致：在 queryBuilder 的排序之后按主键升序）
+// 每个分表只查询 ORDER BY ... LIMIT 1，再比较各分表的结果；所有分表都没有数据时返回 gorm.ErrRecordNotFound
+func CrossTableFirst(db *gorm.DB, strategy ShardingStrategy, dest interface{}, queryBuilder QueryBuilder, options ...ShardQueryOptions) error {
+	return crossTableFirstRow(db, strategy, dest, false, queryBuilder, getShardQueryOptions(options))
+}
+
+// CrossTableLast 跨表获取最后一条记录（与 gorm 的 Last 一致：在 queryBuilder 的排序之后按主键倒序）
+// 所有分表都没有数据时返回 gorm.ErrRecordNotFound
+func CrossTableLast(db *gorm.DB, strategy ShardingStrategy, dest interface{}, queryBuilder QueryBuilder, options ...ShardQueryOptions) error {
+	return crossTableFirstRow(db, strategy, dest, true, queryBuilder, getShardQueryOptions(options))
+}
+
+// CrossTableTake 跨表获取任意一条记录（不排序），找到第一条即返回，不会继续查询剩余分表
+// 所有分表都没有数据时返回 gorm.ErrRecordNotFound
+func CrossTableTake(db *gorm.DB, strategy ShardingStrategy, dest interface{}, queryBuilder QueryBuilder, options ...ShardQueryOptions) error {
+	destValue, err := structDest(dest)
+	if err != nil {
+		return err
+	}
+
+	tableNames, err := allShardTables(db, strategy)
+	if err != nil {
+		return err
+	}
+	var (
+		mu    sync.Mutex
+		found bool
+	)
+	err = runOnShards(db, tableNames, getShardQueryOptions(options), func(tx *gorm.DB, index int, tableName string) error {
+		query := tx.Table(tableName)
+		if queryBuilder != nil {
+			query = queryBuilder(query)
+		}
+
+		rows := reflect.New(reflect.SliceOf(destValue.Type()))
+		if err := query.Limit(1).Find(rows.Interface()).Error; err != nil {
+			return err
+		}
+		if rows.Elem().Len() == 0 {
+			return nil
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if !found {
+			found = true
+			destValue.Set(rows.Elem().Index(0))
+		}
+		return errStopShards
+	})
+	if found {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return gorm.ErrRecordNotFound
+}
+
+// crossTableFirstRow 按主键（升序或倒序）获取全局第一条记录
+func crossTableFirstRow(db *gorm.DB, strategy ShardingStrategy, dest interface{}, desc bool, queryBuilder QueryBuilder, options ShardQueryOptions) error {
+	destValue, err := structDest(dest)
+	if err != nil {
+		return err
+	}
+
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(dest); err != nil {
+		return fmt.Errorf("failed to parse model schema: %w", err)
+	}
+	primaryField := stmt.Schema.PrioritizedPrimaryField
+	if primaryField == nil {
+		return fmt.Errorf("model %s has no primary key", stmt.Schema.Name)
+	}
+
+	// 主键排序追加在查询构建器的排序之后，归并时从查询中解析完整的排序列
+	orderedBuilder := func(tx *gorm.DB) *gorm.DB {
+		if queryBuilder != nil {
+			tx = queryBuilder(tx)
+		}
+		return tx.Order(clause.OrderByColumn{Column: clause.Column{Name: primaryField.DBName}, Desc: desc})
+	}
+
+	rows := reflect.New(reflect.SliceOf(destValue.Type()))
+	// ContinueOnError 时部分分表失败仍返回成功分表中的第一条记录和 ShardErrors
+	err = crossTableOrderedQuery(db, strategy, rows.Interface(), 0, 1, nil, orderedBuilder, options)
+	if err != nil && !isPartialShardError(err) {
+		return err
+	}
+	if rows.Elem().Len() == 0 {
+		if err != nil {
+			return err
+		}
+		return gorm.ErrRecordNotFound
+	}
+	destValue.Set(rows.Elem().Index(0))
+	return err
+}
+
+// structDest 检查 dest 是否为结构体指针，返回指向的结构体
+func structDest(dest interface{}) (reflect.Value, error) {
+	destValue := reflect.ValueOf(dest)
+	if destValue.Kind() != reflect.Ptr || destValue.IsNil() || destValue.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("dest must be a pointer to struct")
+	}
+	return destValue.Elem(), nil
+}
@@ -4,7 +4,7 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
-	"time"
+	"sync/atomic"
 
 	"gorm.io/gorm"
 )
@@ -12,9 +12,10 @@ import (
 // QueryBuilder 查询构建器函数类型
 type QueryBuilder func(*gorm.DB) *gorm.DB
 
-// CrossTableQuery 跨表查询，在所有分表中执行查询并合并结果
-func CrossTableQuery(db *gorm.DB, strategy ShardingStrategy, dest interface{}, queryBuilder QueryBuilder) error {
-	return CrossTableQueryWithTimeRange(db, strategy, dest, queryBuilder, nil, nil)
+// CrossTableQuery 跨表查询，在所有分表中执行查询并合并结果（按分表顺序）
+// options: 分表执行选项（并发、超时、重试等，可选）
+func CrossTableQuery(db *gorm.DB, strategy ShardingStrategy, dest interface{}, queryBuilder QueryBuilder, options ...ShardQueryOptions) error {
+	return CrossTableQueryWithTimeRange(db, strategy, dest, queryBuilder, nil, nil, options...)
 }
 
 // CrossTableQueryWithTimeRange 跨表查询（支持指定时间范围）
@@ -25,23 +26,34 @@ func CrossTableQueryWithTimeRange(
 	dest interface{},
 	queryBuilder QueryBuilder,
 	startValue, endValue interface{},
+	options ...ShardQueryOptions,
 ) error {
-	tableNames := strategy.GetAllTableNames(strategy.GetBaseTableName())
+	return crossTableQueryWithTimeRange(db, strategy, dest, queryBuilder, startValue, endValue, nil, getShardQueryOptions(options))
+}
 
-	// 如果是时间分表，需要获取时间范围
-	if timeStrategy, ok := strategy.(*TimeShardingStrategy); ok {
-		if startValue != nil && endValue != nil {
-			// 使用指定的时间范围
-			tableNames = timeStrategy.GetAllTableNamesInRangeWithValues(
-				strategy.GetBaseTableName(),
-				startValue,
-				endValue,
-			)
-		} else {
-			// 对于时间分表，默认查询最近一年的数据
-			endTime := time.Now()
-			startTime := endTime.AddDate(-1, 0, 0)
-			tableNames = timeStrategy.GetAllTableNamesInRange(strategy.GetBaseTableName(), startTime, endTime)
+// crossTableQueryWithTimeRange 跨表查询的实现，guard 不为 nil 时在加载的数据超过限制后中止
+func crossTableQueryWithTimeRange(
+	db *gorm.DB,
+	strategy ShardingStrategy,
+	dest interface{},
+	queryBuilder QueryBuilder,
+	startValue, endValue interface{},
+	guard *resultGuard,
+	options ShardQueryOptions,
+) error {
+	// 时间分表（包括含时间分表的组合分表）使用指定的时间范围，
+	// 否则与 CrossTableCount 使用相同的分表（时间分表为数据库中已存在的所有分表）
+	var tableNames []string
+	if timeStrategy, ok := strategy.(timeRangeSharding); ok && startValue != nil && endValue != nil {
+		tableNames = timeStrategy.GetAllTableNamesInRangeWithValues(
+			strategy.GetBaseTableName(),
+			startValue,
+			endValue,
+		)
+	} else {
+		var err error
+		if tableNames, err = allShardTables(db, strategy); err != nil {
+			return err
 		}
 	}
 
@@ -62,103 +74,232 @@ func CrossTableQueryWithTimeRange(
 
 	elemType := destElem.Type().Elem()
 
-	// 对每个分表执行查询并合并结果
-	for _, tableName := range tableNames {
-		query := db.Table(tableName)
+	// 对每个分表执行查询，按分表顺序合并结果
+	tableResults := make([]reflect.Value, len(tableNames))
+	err := runOnShards(db, tableNames, options, func(tx *gorm.DB, index int, tableName string) error {
+		query := tx.Table(tableName)
 		if queryBuilder != nil {
 			query = queryBuilder(query)
 		}
 
 		// 创建临时切片来存储当前表的查询结果
-		tableResults := reflect.New(reflect.SliceOf(elemType)).Interface()
-
-		if err := query.Find(tableResults).Error; err != nil {
-			// 如果表不存在，跳过（某些分表可能尚未创建）
-			errMsg := strings.ToLower(err.Error())
-			if strings.Contains(errMsg, "doesn't exist") ||
-				strings.Contains(errMsg, "unknown table") ||
-				strings.Contains(errMsg, "table") && strings.Contains(errMsg, "not found") {
-				continue
-			}
+		results := reflect.New(reflect.SliceOf(elemType))
+		if err := query.Find(results.Interface()).Error; err != nil {
+			return err
+		}
+		if err := guard.add(results.Elem()); err != nil {
 			return err
 		}
+		tableResults[index] = results.Elem()
+		return nil
+	})
 
-		// 将当前表的结果追加到总结果中
-		tableResultsValue := reflect.ValueOf(tableResults).Elem()
-		destElem.Set(reflect.AppendSlice(destElem, tableResultsValue))
+	// 将各分表的结果追加到总结果中（ContinueOnError 时包含成功的分表）
+	for _, results := range tableResults {
+		if results.IsValid() {
+			destElem.Set(reflect.AppendSlice(destElem, results))
+		}
 	}
-
-	return nil
+	return err
 }
 
 // CrossTableQueryUnion 使用 UNION ALL 进行跨表查询（更高效）
-func CrossTableQueryUnion(db *gorm.DB, strategy ShardingStrategy, dest interface{}, queryBuilder QueryBuilder) error {
-	tableNames := strategy.GetAllTableNames(strategy.GetBaseTableName())
+// 查询条件中的 ORDER BY 会应用到合并后的结果
+// 指定 options 时一条 UNION ALL 语句无法按分表控制超时、重试和数据源，改为通过 runOnShards 在每个分表上查询，
+// 有 ORDER BY 时在内存中按排序列归并（与 CrossTableOrderedPaginate 相同，dest 需要是模型切片）
+func CrossTableQueryUnion(db *gorm.DB, strategy ShardingStrategy, dest interface{}, queryBuilder QueryBuilder, options ...ShardQueryOptions) error {
+	if len(options) > 0 {
+		if len(parseOrderByFromQuery(db, queryBuilder)) > 0 {
+			return crossTableOrderedQuery(db, strategy, dest, 0, -1, nil, queryBuilder, options[0])
+		}
+		return crossTableQueryWithTimeRange(db, strategy, dest, queryBuilder, nil, nil, nil, options[0])
+	}
+
+	unionSQL, vars, err := buildUnionQuery(db, strategy, dest, queryBuilder, 0, -1)
+	if err != nil {
+		return err
+	}
+	return db.Raw(unionSQL, vars...).Find(dest).Error
+}
 
-	// 如果是时间分表，需要获取时间范围
-	if timeStrategy, ok := strategy.(*TimeShardingStrategy); ok {
-		endTime := time.Now()
-		startTime := endTime.AddDate(-1, 0, 0)
-		tableNames = timeStrategy.GetAllTableNamesInRange(strategy.GetBaseTableName(), startTime, endTime)
+// buildUnionQuery 构建跨分表的 UNION ALL 查询
+// 每个分表的查询作为参数（*gorm.DB）返回，由 db.Raw 展开为子查询，占位符（如 PostgreSQL 的 $1）按顺序统一编号；
+// 合并后包装为子查询，在外层执行 ORDER BY/LIMIT/OFFSET；
+// limit < 0 表示不分页，分页且有排序时每个分表只需要返回前 offset+limit 条
+func buildUnionQuery(db *gorm.DB, strategy ShardingStrategy, dest interface{}, queryBuilder QueryBuilder, offset, limit int) (string, []interface{}, error) {
+	tableNames, err := allShardTables(db, strategy)
+	if err != nil {
+		return "", nil, err
 	}
 
+	// UNION ALL 中任意一张表不存在都会导致整个查询失败，先过滤掉不存在的分表
+	tableNames = filterExistingTables(db, hintedTables(db.Statement.Context, tableNames))
 	if len(tableNames) == 0 {
-		return fmt.Errorf("no tables found")
+		return "", nil, fmt.Errorf("no tables found")
 	}
 
-	// 构建 UNION ALL 查询
-	queries := make([]string, 0, len(tableNames))
-	args := make([]interface{}, 0)
+	orderBy := parseOrderByFromQuery(db, queryBuilder)
 
+	queries := make([]string, 0, len(tableNames))
+	vars := make([]interface{}, 0, len(tableNames))
 	for _, tableName := range tableNames {
-		query := db.Table(tableName)
+		// Model 用于解析模型的查询条件（如软删除）
+		query := db.Table(tableName).Model(dest)
 		if queryBuilder != nil {
 			query = queryBuilder(query)
 		}
+		if limit >= 0 && len(orderBy) > 0 {
+			// 下推 LIMIT：最终结果中来自单个分表的行一定在该分表排序后的前 offset+limit 条中
+			query = query.Limit(offset + limit).Offset(0)
+		}
+		if query.Error != nil {
+			return "", nil, fmt.Errorf("failed to build query for table %s: %w", tableName, query.Error)
+		}
+		queries = append(queries, "(?)")
+		vars = append(vars, query)
+	}
+
+	// 组合 UNION ALL 查询，并在外层排序和分页
+	var unionSQL strings.Builder
+	unionSQL.WriteString("SELECT * FROM (")
+	unionSQL.WriteString(strings.Join(queries, " UNION ALL "))
+	unionSQL.WriteString(") AS ")
+	unionSQL.WriteString(db.Statement.Quote("sharding_union"))
+
+	if len(orderBy) > 0 {
+		columns := make([]string, len(orderBy))
+		for i, column := range orderBy {
+			// 外层查询只能引用子查询的列名，去掉表名前缀
+			name := column.Column
+			if idx := strings.LastIndex(name, "."); idx >= 0 {
+				name = name[idx+1:]
+			}
+			columns[i] = db.Statement.Quote(strings.Trim(name, "`\""))
+			if column.Desc {
+				columns[i] += " DESC"
+			}
+		}
+		unionSQL.WriteString(" ORDER BY ")
+		unionSQL.WriteString(strings.Join(columns, ", "))
+	}
+	if limit >= 0 {
+		fmt.Fprintf(&unionSQL, " LIMIT %d", limit)
+		if offset > 0 {
+			fmt.Fprintf(&unionSQL, " OFFSET %d", offset)
+		}
+	}
+
+	return unionSQL.String(), vars, nil
+}
 
-		// 获取 SQL 和参数
-		sql := query.ToSQL(func(query *gorm.DB) *gorm.DB {
-			return query
-		})
-		queries = append(queries, fmt.Sprintf("(%s)", sql))
+// filterExistingTables 过滤掉数据库中不存在的表（获取表列表失败时原样返回）
+func filterExistingTables(db *gorm.DB, tableNames []string) []string {
+	existingTables, err := db.Migrator().GetTables()
+	if err != nil {
+		return tableNames
 	}
 
-	// 组合 UNION ALL 查询
-	unionSQL := strings.Join(queries, " UNION ALL ")
+	existing := make(map[string]bool, len(existingTables))
+	for _, tableName := range existingTables {
+		existing[tableName] = true
+	}
 
-	return db.Raw(unionSQL, args...).Find(dest).Error
+	result := make([]string, 0, len(tableNames))
+	for _, tableName := range tableNames {
+		if existing[tableName] {
+			result = append(result, tableName)
+		}
+	}
+	return result
 }
 
 // CrossTableCount 跨表计数
-func CrossTableCount(db *gorm.DB, strategy ShardingStrategy, queryBuilder QueryBuilder) (int64, error) {
+func CrossTableCount(db *gorm.DB, strategy ShardingStrategy, queryBuilder QueryBuilder, options ...ShardQueryOptions) (int64, error) {
+	tableNames, err := allShardTables(db, strategy)
+	if err != nil {
+		return 0, err
+	}
+
+	counts := make([]int64, len(tableNames))
+	err = runOnShards(db, tableNames, getShardQueryOptions(options), func(tx *gorm.DB, index int, tableName string) error {
+		query := tx.Table(tableName)
+		if queryBuilder != nil {
+			query = queryBuilder(query)
+		}
+		return query.Count(&counts[index]).Error
+	})
+
 	var totalCount int64
-	tableNames := strategy.GetAllTableNames(strategy.GetBaseTableName())
+	for _, count := range counts {
+		totalCount += count
+	}
+	return totalCount, err
+}
 
-	// 如果是时间分表
-	if timeStrategy, ok := strategy.(*TimeShardingStrategy); ok {
-		endTime := time.Now()
-		startTime := endTime.AddDate(-1, 0, 0)
-		tableNames = timeStrategy.GetAllTableNamesInRange(strategy.GetBaseTableName(), startTime, endTime)
+// CrossTablePluck 跨表查询单列的值，合并到 dest（切片指针，如 *[]string）
+func CrossTablePluck(db *gorm.DB, strategy ShardingStrategy, column string, dest interface{}, queryBuilder QueryBuilder, options ...ShardQueryOptions) error {
+	destValue := reflect.ValueOf(dest)
+	if destValue.Kind() != reflect.Ptr || destValue.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("dest must be a pointer to slice")
 	}
+	destElem := destValue.Elem()
 
-	for _, tableName := range tableNames {
-		query := db.Table(tableName)
+	tableNames, err := allShardTables(db, strategy)
+	if err != nil {
+		return err
+	}
+	tableValues := make([]reflect.Value, len(tableNames))
+	err = runOnShards(db, tableNames, getShardQueryOptions(options), func(tx *gorm.DB, index int, tableName string) error {
+		query := tx.Table(tableName)
 		if queryBuilder != nil {
 			query = queryBuilder(query)
 		}
 
-		var count int64
-		if err := query.Count(&count).Error; err != nil {
-			errMsg := strings.ToLower(err.Error())
-			if strings.Contains(errMsg, "doesn't exist") ||
-				strings.Contains(errMsg, "unknown table") ||
-				strings.Contains(errMsg, "table") && strings.Contains(errMsg, "not found") {
-				continue
-			}
-			return 0, err
+		values := reflect.New(destElem.Type())
+		if err := query.Pluck(column, values.Interface()).Error; err != nil {
+			return err
+		}
+		tableValues[index] = values.Elem()
+		return nil
+	})
+
+	destElem.Set(reflect.MakeSlice(destElem.Type(), 0, 0))
+	for _, values := range tableValues {
+		if values.IsValid() {
+			destElem.Set(reflect.AppendSlice(destElem, values))
 		}
-		totalCount += count
 	}
+	return err
+}
 
-	return totalCount, nil
+// CrossTableExists 判断是否存在满足条件的记录
+// 每个分表只查询 LIMIT 1，找到第一条记录即返回，不会统计所有分表
+func CrossTableExists(db *gorm.DB, strategy ShardingStrategy, queryBuilder QueryBuilder, options ...ShardQueryOptions) (bool, error) {
+	tableNames, err := allShardTables(db, strategy)
+	if err != nil {
+		return false, err
+	}
+
+	var exists atomic.Bool
+	err = runOnShards(db, tableNames, getShardQueryOptions(options), func(tx *gorm.DB, index int, tableName string) error {
+		query := tx.Table(tableName)
+		if queryBuilder != nil {
+			query = queryBuilder(query)
+		}
+
+		var found int
+		result := query.Select("1").Limit(1).Scan(&found)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			exists.Store(true)
+			return errStopShards
+		}
+		return nil
+	})
+	if exists.Load() {
+		return true, nil
+	}
+	return false, err
 }
@@ -0,0 +1,174 @@
+package sharding
+
+import (
+	"fmt"
+	"reflect"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+)
+
+// CrossTableUpdate 跨表更新，在所有分表中执行 UPDATE 并返回总影响行数
+// 时间分表在数据库中已存在的所有分表中执行（见 allShardTables），不会跳过一年以前的分表
+// values: 更新内容（map[string]interface{} 或结构体，语义与 GORM Updates 一致）
+// queryBuilder: 查询构建器，用于指定 WHERE 条件（GORM 默认禁止无条件的全表更新）
+func CrossTableUpdate(db *gorm.DB, strategy ShardingStrategy, values interface{}, queryBuilder QueryBuilder, options ...ShardQueryOptions) (int64, error) {
+	if err := checkWritable(strategy.GetBaseTableName()); err != nil {
+		return 0, err
+	}
+	tableNames, err := allShardTables(db, strategy)
+	if err != nil {
+		return 0, err
+	}
+	return updateTables(db, tableNames, values, queryBuilder, getShardQueryOptions(options))
+}
+
+// CrossTableUpdateByShardingValue 只在分表键值对应的分表中执行 UPDATE
+func CrossTableUpdateByShardingValue(
+	db *gorm.DB,
+	strategy ShardingStrategy,
+	shardingValue interface{},
+	values interface{},
+	queryBuilder QueryBuilder,
+	options ...ShardQueryOptions,
+) (int64, error) {
+	if err := checkWritable(strategy.GetBaseTableName()); err != nil {
+		return 0, err
+	}
+	tableName, err := GetTableNameE(strategy, strategy.GetBaseTableName(), shardingValue)
+	if err != nil {
+		return 0, err
+	}
+	return updateTables(db, []string{tableName}, values, queryBuilder, getShardQueryOptions(options))
+}
+
+// updateTables 在指定的表中执行 UPDATE，跳过不存在的表，返回成功的分表的总影响行数
+func updateTables(db *gorm.DB, tableNames []string, values interface{}, queryBuilder QueryBuilder, options ShardQueryOptions) (int64, error) {
+	var totalAffected atomic.Int64
+	err := runOnShards(db, tableNames, options, func(tx *gorm.DB, index int, tableName string) error {
+		query := tx.Table(tableName)
+		if queryBuilder != nil {
+			query = queryBuilder(query)
+		}
+
+		result := query.Updates(values)
+		if result.Error != nil {
+			return result.Error
+		}
+		totalAffected.Add(result.RowsAffected)
+		return nil
+	})
+	return totalAffected.Load(), err
+}
+
+// CrossTableDelete 跨表删除，在所有分表中删除满足条件的记录并返回总影响行数
+// 时间分表在数据库中已存在的所有分表中执行（见 allShardTables），不会跳过一年以前的分表
+// queryBuilder: 查询构建器，用于指定 WHERE 条件（GORM 默认禁止无条件的全表删除）
+// 注意：此方法执行物理删除，不会触发模型的软删除逻辑
+func CrossTableDelete(db *gorm.DB, strategy ShardingStrategy, queryBuilder QueryBuilder, options ...ShardQueryOptions) (int64, error) {
+	if err := checkWritable(strategy.GetBaseTableName()); err != nil {
+		return 0, err
+	}
+	tableNames, err := allShardTables(db, strategy)
+	if err != nil {
+		return 0, err
+	}
+	return deleteTables(db, tableNames, queryBuilder, getShardQueryOptions(options))
+}
+
+// CrossTableDeleteByShardingValue 只在分表键值对应的分表中删除记录
+func CrossTableDeleteByShardingValue(
+	db *gorm.DB,
+	strategy ShardingStrategy,
+	shardingValue interface{},
+	queryBuilder QueryBuilder,
+	options ...ShardQueryOptions,
+) (int64, error) {
+	if err := checkWritable(strategy.GetBaseTableName()); err != nil {
+		return 0, err
+	}
+	tableName, err := GetTableNameE(strategy, strategy.GetBaseTableName(), shardingValue)
+	if err != nil {
+		return 0, err
+	}
+	return deleteTables(db, []string{tableName}, queryBuilder, getShardQueryOptions(options))
+}
+
+// deleteTables 在指定的表中执行 DELETE，跳过不存在的表，返回成功的分表的总影响行数
+func deleteTables(db *gorm.DB, tableNames []string, queryBuilder QueryBuilder, options ShardQueryOptions) (int64, error) {
+	var totalAffected atomic.Int64
+	err := runOnShards(db, tableNames, options, func(tx *gorm.DB, index int, tableName string) error {
+		query := tx.Table(tableName)
+		if queryBuilder != nil {
+			query = queryBuilder(query)
+		}
+
+		// 使用 map 作为删除目标，配合 Table() 执行不依赖模型的物理删除
+		result := query.Delete(map[string]interface{}{})
+		if result.Error != nil {
+			return result.Error
+		}
+		totalAffected.Add(result.RowsAffected)
+		return nil
+	})
+	return totalAffected.Load(), err
+}
+
+// CrossTableBatchCreate 批量插入，按分表自动分组后对每个分表执行一次批量 INSERT
+// values: 模型切片或切片指针（如 []User、[]*User、&[]User）
+// 插入成功后，数据库生成的字段（如自增主键）会回写到原切片中
+func CrossTableBatchCreate(db *gorm.DB, strategy ShardingStrategy, values interface{}, options ...ShardQueryOptions) error {
+	if err := checkWritable(strategy.GetBaseTableName()); err != nil {
+		return err
+	}
+	sliceValue := reflect.ValueOf(values)
+	if sliceValue.Kind() == reflect.Ptr {
+		sliceValue = sliceValue.Elem()
+	}
+	if sliceValue.Kind() != reflect.Slice {
+		return fmt.Errorf("values must be a slice or a pointer to slice")
+	}
+
+	// 按分表名称分组（保持首次出现的顺序）
+	tableOrder := make([]string, 0)
+	groups := make(map[string][]int)
+	for i := 0; i < sliceValue.Len(); i++ {
+		elem := sliceValue.Index(i)
+		shardingValue, err := strategy.GetShardingValue(elem.Interface())
+		if err != nil {
+			return fmt.Errorf("failed to get sharding value of element %d: %w", i, err)
+		}
+
+		tableName, err := GetTableNameE(strategy, strategy.GetBaseTableName(), shardingValue)
+		if err != nil {
+			return fmt.Errorf("failed to get table name of element %d: %w", i, err)
+		}
+		if _, ok := groups[tableName]; !ok {
+			tableOrder = append(tableOrder, tableName)
+		}
+		groups[tableName] = append(groups[tableName], i)
+	}
+
+	// 对每个分表执行一次批量插入（分表不存在时返回错误）
+	opts := getShardQueryOptions(options)
+	opts.requireTables = true
+	return runOnShards(db, tableOrder, opts, func(tx *gorm.DB, _ int, tableName string) error {
+		indexes := groups[tableName]
+		group := reflect.MakeSlice(sliceValue.Type(), len(indexes), len(indexes))
+		for i, index := range indexes {
+			group.Index(i).Set(sliceValue.Index(index))
+		}
+
+		groupPtr := reflect.New(group.Type())
+		groupPtr.Elem().Set(group)
+		if err := tx.Table(tableName).Create(groupPtr.Interface()).Error; err != nil {
+			return fmt.Errorf("failed to batch create in table %s: %w", tableName, err)
+		}
+
+		// 回写数据库生成的字段（各分表的元素互不重叠）
+		for i, index := range indexes {
+			sliceValue.Index(index).Set(groupPtr.Elem().Index(i))
+		}
+		return nil
+	})
+}
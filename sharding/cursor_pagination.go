@@ -0,0 +1,415 @@
+package sharding
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CursorPaginator 游标分页结果
+type CursorPaginator struct {
+	PageSize   int         `json:"page_size"`   // 每页数量
+	HasMore    bool        `json:"has_more"`    // 是否还有下一页
+	NextCursor string      `json:"next_cursor"` // 下一页的游标（没有下一页时为空）
+	Data       interface{} `json:"data"`        // 数据列表
+	Partial    bool        `json:"partial"`     // 部分分表失败或被排除，结果只包含成功的分表的数据（同时返回 ShardErrors）
+}
+
+// cursorValue 游标中带类型标记的排序键值，保证解码后类型不变
+type cursorValue struct {
+	Type  string `json:"t"`
+	Value string `json:"v,omitempty"`
+}
+
+// cursorVersion 游标格式版本
+const cursorVersion = 1
+
+// ErrInvalidCursor 游标格式错误、被篡改，或与当前的排序列、分表策略不匹配
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// CursorOptions 游标编码选项
+type CursorOptions struct {
+	// Secret 签名密钥（可选）。设置后游标使用 HMAC-SHA256 签名，客户端无法伪造或修改游标；
+	// 未设置时只使用校验和，只能发现游标被意外破坏
+	Secret []byte
+
+	ShardQueryOptions // 各分表的执行选项（并发、超时、重试、多数据源等）
+}
+
+// CursorToken 游标内容
+type CursorToken struct {
+	Positions   map[string][]interface{} // 每个分表最后读取到的排序键
+	OrderBy     []OrderByColumn          // 排序列
+	Fingerprint string                   // 分表策略指纹（见 StrategyFingerprint）
+}
+
+// shardCursor 游标的序列化格式
+type shardCursor struct {
+	Version     int                      `json:"v"`
+	Shards      map[string][]cursorValue `json:"s"`
+	OrderBy     []OrderByColumn          `json:"o"`
+	Fingerprint string                   `json:"f,omitempty"`
+}
+
+// CrossTableCursorPaginate 跨表游标（keyset）分页
+// 游标中记录每个分表最后读取到的排序键，每个分表只查询排序键之后的 pageSize+1 条数据，然后 k 路归并，
+// 翻页开销与页码无关，适合百万级分表数据的深度翻页
+// cursor: 上一页返回的 NextCursor（第一页传空字符串），排序列或分表策略与生成游标时不同时返回 ErrInvalidCursor
+// orderBy: 排序列，最后一列应唯一（如主键），否则相同排序键的记录可能被跳过；排序列可以为 NULL，NULL 按最小值排序
+// 设置 ContinueOnError 时返回成功分表的归并结果和 ShardErrors，失败的分表在游标中的位置不变，下一页会从原位置重新读取
+func CrossTableCursorPaginate(
+	db *gorm.DB,
+	strategy ShardingStrategy,
+	dest interface{},
+	cursor string,
+	pageSize int,
+	orderBy []OrderByColumn,
+	queryBuilder QueryBuilder,
+	options ...CursorOptions,
+) (*CursorPaginator, error) {
+	if pageSize < 1 {
+		pageSize = 10
+	}
+	if len(orderBy) == 0 {
+		return nil, fmt.Errorf("order by columns are required for cursor pagination")
+	}
+
+	destValue := reflect.ValueOf(dest)
+	if destValue.Kind() != reflect.Ptr || destValue.Elem().Kind() != reflect.Slice {
+		return nil, fmt.Errorf("dest must be a pointer to slice")
+	}
+	destElem := destValue.Elem()
+	elemType := destElem.Type().Elem()
+
+	cursorOptions := getCursorOptions(options)
+	fingerprint := StrategyFingerprint(strategy)
+	positions := make(map[string][]interface{})
+	if cursor != "" {
+		token, err := DecodeCursor(cursor, cursorOptions)
+		if err != nil {
+			return nil, err
+		}
+		if !sameOrderBy(token.OrderBy, orderBy) {
+			return nil, fmt.Errorf("%w: cursor was issued for a different sort order", ErrInvalidCursor)
+		}
+		if token.Fingerprint != fingerprint {
+			return nil, fmt.Errorf("%w: cursor was issued for a different sharding strategy", ErrInvalidCursor)
+		}
+		positions = token.Positions
+	}
+
+	sortFields, err := resolveSortFields(db, elemType, orderBy)
+	if err != nil {
+		return nil, err
+	}
+	// 查询中使用解析后的列名（orderBy 中可以是模型字段名，如 CreatedAt），字符串列按字节排序和比较
+	columns := make([]OrderByColumn, len(orderBy))
+	for i, column := range orderBy {
+		columns[i] = OrderByColumn{Column: sortColumnExpr(db, sortFields[i]), Desc: column.Desc}
+	}
+
+	// 每个分表从上次的位置开始查询 pageSize+1 条，多出的一条用于判断是否还有下一页
+	tableNames, err := allShardTables(db, strategy)
+	if err != nil {
+		return nil, err
+	}
+	tableResults := make([]reflect.Value, len(tableNames))
+	queryErr := runOnShards(db, tableNames, cursorOptions.ShardQueryOptions, func(tx *gorm.DB, index int, tableName string) error {
+		query := tx.Table(tableName)
+		if queryBuilder != nil {
+			query = queryBuilder(query)
+		}
+		if keys, ok := positions[tableName]; ok {
+			sql, vars := buildKeysetCondition(query.Statement, columns, keys)
+			query = query.Where(sql, vars...)
+		}
+		for _, column := range columns {
+			query = query.Order(sortOrderBy(query, column.Column, column.Desc))
+		}
+
+		results := reflect.New(reflect.SliceOf(elemType))
+		if err := query.Limit(pageSize + 1).Find(results.Interface()).Error; err != nil {
+			return err
+		}
+		tableResults[index] = results.Elem()
+		return nil
+	})
+	if queryErr != nil && !isPartialShardError(queryErr) {
+		return nil, queryErr
+	}
+
+	shardTables := make([]string, 0, len(tableNames))
+	shardResults := make([]reflect.Value, 0, len(tableNames))
+	for i, results := range tableResults {
+		if results.IsValid() {
+			shardTables = append(shardTables, tableNames[i])
+			shardResults = append(shardResults, results)
+		}
+	}
+
+	merged, err := kWayMerge(db, shardResults, orderBy, sortFields, pageSize+1)
+	if err != nil {
+		return nil, err
+	}
+	hasMore := len(merged) > pageSize
+	if hasMore {
+		merged = merged[:pageSize]
+	}
+
+	result := reflect.MakeSlice(destElem.Type(), 0, len(merged))
+	for _, row := range merged {
+		result = reflect.Append(result, row.value)
+		positions[shardTables[row.shard]] = row.keys
+	}
+	destElem.Set(result)
+
+	paginator := &CursorPaginator{
+		PageSize: pageSize,
+		HasMore:  hasMore,
+		Data:     dest,
+		Partial:  queryErr != nil,
+	}
+	if hasMore {
+		token := CursorToken{Positions: positions, OrderBy: orderBy, Fingerprint: fingerprint}
+		if paginator.NextCursor, err = EncodeCursor(token, cursorOptions); err != nil {
+			return nil, err
+		}
+	}
+	return paginator, queryErr
+}
+
+// buildKeysetCondition 构建 "排序键在 keys 之后" 的条件，支持混合升降序：
+// (a > ?) OR (a = ? AND b < ?) OR ...
+// NULL 按最小值处理（与 k 路归并一致）：升序时 NULL 之后是所有非 NULL 值，降序时非 NULL 值之后还有 NULL，
+// 相等条件使用 IS NULL；orderBy 中的列为 sortColumnExpr 构建的表达式，原样写入条件
+func buildKeysetCondition(stmt *gorm.Statement, orderBy []OrderByColumn, keys []interface{}) (string, []interface{}) {
+	var (
+		disjuncts []string
+		vars      []interface{}
+	)
+	for i, column := range orderBy {
+		after, afterVars, ok := keysetAfter(column.Column, column.Desc, keys[i])
+		if !ok {
+			continue // 降序时 NULL 之后没有更小的值
+		}
+
+		conjuncts := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			if isNullKey(keys[j]) {
+				conjuncts = append(conjuncts, orderBy[j].Column+" IS NULL")
+				continue
+			}
+			conjuncts = append(conjuncts, orderBy[j].Column+" = ?")
+			vars = append(vars, keys[j])
+		}
+		conjuncts = append(conjuncts, after)
+		vars = append(vars, afterVars...)
+		disjuncts = append(disjuncts, "("+strings.Join(conjuncts, " AND ")+")")
+	}
+	if len(disjuncts) == 0 {
+		return "1 = 0", nil
+	}
+	return "(" + strings.Join(disjuncts, " OR ") + ")", vars
+}
+
+// keysetAfter 构建单个排序列 "在 key 之后" 的条件，没有满足条件的值时返回 false
+func keysetAfter(column string, desc bool, key interface{}) (string, []interface{}, bool) {
+	switch {
+	case !desc && isNullKey(key):
+		return column + " IS NOT NULL", nil, true
+	case !desc:
+		return column + " > ?", []interface{}{key}, true
+	case isNullKey(key):
+		return "", nil, false
+	default:
+		return "(" + column + " < ? OR " + column + " IS NULL)", []interface{}{key}, true
+	}
+}
+
+// isNullKey 判断排序键是否为 NULL（nil 或 nil 指针）
+func isNullKey(key interface{}) bool {
+	return !derefValue(key).IsValid()
+}
+
+// getCursorOptions 获取游标选项（未指定时使用默认值）
+func getCursorOptions(options []CursorOptions) CursorOptions {
+	if len(options) > 0 {
+		return options[0]
+	}
+	return CursorOptions{}
+}
+
+// EncodeCursor 将游标内容编码为不透明的字符串（base64 编码的内容 + "." + 签名），可以安全地返回给客户端
+func EncodeCursor(token CursorToken, options ...CursorOptions) (string, error) {
+	c := shardCursor{
+		Version:     cursorVersion,
+		Shards:      make(map[string][]cursorValue, len(token.Positions)),
+		OrderBy:     token.OrderBy,
+		Fingerprint: token.Fingerprint,
+	}
+	for tableName, keys := range token.Positions {
+		values := make([]cursorValue, len(keys))
+		for i, key := range keys {
+			value, err := toCursorValue(key)
+			if err != nil {
+				return "", err
+			}
+			values[i] = value
+		}
+		c.Shards[tableName] = values
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	signature := signCursor(payload, getCursorOptions(options).Secret)
+	return payload + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// DecodeCursor 解码并校验游标字符串，签名不匹配（被篡改）或格式错误时返回 ErrInvalidCursor
+func DecodeCursor(cursor string, options ...CursorOptions) (*CursorToken, error) {
+	payload, encodedSignature, ok := strings.Cut(cursor, ".")
+	if !ok {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidCursor)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	if !hmac.Equal(signature, signCursor(payload, getCursorOptions(options).Secret)) {
+		return nil, fmt.Errorf("%w: signature mismatch", ErrInvalidCursor)
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	var c shardCursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	if c.Version != cursorVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidCursor, c.Version)
+	}
+
+	token := &CursorToken{
+		Positions:   make(map[string][]interface{}, len(c.Shards)),
+		OrderBy:     c.OrderBy,
+		Fingerprint: c.Fingerprint,
+	}
+	for tableName, values := range c.Shards {
+		if len(values) != len(c.OrderBy) {
+			return nil, fmt.Errorf("%w: expected %d sort keys, got %d", ErrInvalidCursor, len(c.OrderBy), len(values))
+		}
+		keys := make([]interface{}, len(values))
+		for i, value := range values {
+			key, err := fromCursorValue(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+			}
+			keys[i] = key
+		}
+		token.Positions[tableName] = keys
+	}
+	return token, nil
+}
+
+// signCursor 计算游标内容的签名：设置了密钥时使用 HMAC-SHA256，否则使用 SHA-256 校验和
+func signCursor(payload string, secret []byte) []byte {
+	if len(secret) == 0 {
+		sum := sha256.Sum256([]byte(payload))
+		return sum[:16]
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+// StrategyFingerprint 计算分表策略的指纹（策略类型、基础表名、分表键和分表列表），
+// 分表数量等配置变化后，旧的游标会被识别为无效
+func StrategyFingerprint(strategy ShardingStrategy) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%T|%s|", strategy, strategy.GetBaseTableName())
+	if provider, ok := strategy.(ShardingKeyProvider); ok {
+		fmt.Fprintf(h, "%s|", provider.GetShardingKey())
+	}
+	h.Write([]byte(strings.Join(strategy.GetAllTableNames(strategy.GetBaseTableName()), ",")))
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// sameOrderBy 判断两组排序列是否相同
+func sameOrderBy(a, b []OrderByColumn) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// toCursorValue 将排序键转换为带类型标记的游标值
+func toCursorValue(key interface{}) (cursorValue, error) {
+	rv := derefValue(key)
+	if !rv.IsValid() {
+		return cursorValue{Type: "null"}, nil
+	}
+	if t, ok := rv.Interface().(time.Time); ok {
+		return cursorValue{Type: "time", Value: t.Format(time.RFC3339Nano)}, nil
+	}
+
+	switch {
+	case rv.CanInt():
+		return cursorValue{Type: "int", Value: strconv.FormatInt(rv.Int(), 10)}, nil
+	case rv.CanUint():
+		return cursorValue{Type: "uint", Value: strconv.FormatUint(rv.Uint(), 10)}, nil
+	case rv.CanFloat():
+		return cursorValue{Type: "float", Value: strconv.FormatFloat(rv.Float(), 'g', -1, 64)}, nil
+	case rv.Kind() == reflect.String:
+		return cursorValue{Type: "string", Value: rv.String()}, nil
+	case rv.Kind() == reflect.Bool:
+		return cursorValue{Type: "bool", Value: strconv.FormatBool(rv.Bool())}, nil
+	case rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8:
+		return cursorValue{Type: "bytes", Value: base64.StdEncoding.EncodeToString(rv.Bytes())}, nil
+	default:
+		return cursorValue{}, fmt.Errorf("unsupported cursor key type %s", rv.Type())
+	}
+}
+
+// fromCursorValue 将游标值还原为排序键
+func fromCursorValue(value cursorValue) (interface{}, error) {
+	switch value.Type {
+	case "null":
+		return nil, nil
+	case "time":
+		return time.Parse(time.RFC3339Nano, value.Value)
+	case "int":
+		return strconv.ParseInt(value.Value, 10, 64)
+	case "uint":
+		return strconv.ParseUint(value.Value, 10, 64)
+	case "float":
+		return strconv.ParseFloat(value.Value, 64)
+	case "string":
+		return value.Value, nil
+	case "bool":
+		return strconv.ParseBool(value.Value)
+	case "bytes":
+		return base64.StdEncoding.DecodeString(value.Value)
+	default:
+		return nil, fmt.Errorf("unknown cursor key type %q", value.Type)
+	}
+}
@@ -0,0 +1,110 @@
+package sharding
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	token := CursorToken{
+		Positions: map[string][]interface{}{
+			"users_0": {int64(3), time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC), "a", nil},
+			"users_1": {uint64(4), time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), "b", []byte{0, 1}},
+		},
+		OrderBy:     []OrderByColumn{{Column: "id"}, {Column: "created_at", Desc: true}, {Column: "name"}, {Column: "data"}},
+		Fingerprint: StrategyFingerprint(NewHashShardingStrategy("users", "UserID", 2)),
+	}
+	options := CursorOptions{Secret: []byte("secret")}
+
+	cursor, err := EncodeCursor(token, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := DecodeCursor(cursor, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*decoded, token) {
+		t.Errorf("decoded %+v, want %+v", *decoded, token)
+	}
+}
+
+func TestDecodeCursorRejectsTampering(t *testing.T) {
+	token := CursorToken{
+		Positions: map[string][]interface{}{"users_0": {int64(3)}},
+		OrderBy:   []OrderByColumn{{Column: "id"}},
+	}
+	options := CursorOptions{Secret: []byte("secret")}
+	cursor, err := EncodeCursor(token, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload, signature, _ := strings.Cut(cursor, ".")
+	forged, err := EncodeCursor(CursorToken{
+		Positions: map[string][]interface{}{"users_0": {int64(100)}},
+		OrderBy:   token.OrderBy,
+	}, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forgedPayload, _, _ := strings.Cut(forged, ".")
+
+	for name, cursor := range map[string]string{
+		"malformed":        payload,
+		"swapped payload":  forgedPayload + "." + signature,
+		"truncated":        cursor[:len(cursor)-2],
+		"different secret": cursor,
+	} {
+		opts := options
+		if name == "different secret" {
+			opts.Secret = []byte("other")
+		}
+		if _, err := DecodeCursor(cursor, opts); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("%s: got error %v, want ErrInvalidCursor", name, err)
+		}
+	}
+}
+
+func TestBuildKeysetCondition(t *testing.T) {
+	stmt := newDryRunDB(t).Statement
+	orderBy := []OrderByColumn{{Column: "created_at", Desc: true}, {Column: "id"}}
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		keys     []interface{}
+		wantSQL  string
+		wantVars []interface{}
+	}{
+		{
+			name:     "values",
+			keys:     []interface{}{createdAt, int64(7)},
+			wantSQL:  "(((created_at < ? OR created_at IS NULL)) OR (created_at = ? AND id > ?))",
+			wantVars: []interface{}{createdAt, createdAt, int64(7)},
+		},
+		{
+			name:     "null descending key",
+			keys:     []interface{}{nil, int64(7)},
+			wantSQL:  "((created_at IS NULL AND id > ?))",
+			wantVars: []interface{}{int64(7)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, vars := buildKeysetCondition(stmt, orderBy, tt.keys)
+			if sql != tt.wantSQL || !reflect.DeepEqual(vars, tt.wantVars) {
+				t.Errorf("got %q %v, want %q %v", sql, vars, tt.wantSQL, tt.wantVars)
+			}
+		})
+	}
+
+	// 升序 NULL 之后是所有非 NULL 值
+	sql, vars := buildKeysetCondition(stmt, []OrderByColumn{{Column: "name"}}, []interface{}{nil})
+	if sql != "((name IS NOT NULL))" || len(vars) != 0 {
+		t.Errorf("ascending null: got %q %v", sql, vars)
+	}
+}
@@ -1,9 +1,5 @@
 package sharding
 
-import (
-	"fmt"
-)
-
 // CustomShardingFunc 自定义分表函数类型
 // 参数：baseTableName - 基础表名，shardingValue - 分表键值
 // 返回：实际表名
@@ -27,6 +23,8 @@ type CustomShardingStrategy struct {
 	getTableNameFunc   CustomShardingFunc
 	getValueFunc       CustomShardingValueFunc
 	getAllTablesFunc   CustomGetAllTablesFunc
+
+	ShardOptionsMap // 分表级别的建表选项（通过 SetShardOptions 配置）
 }
 
 // NewCustomShardingStrategy 创建自定义分表策略
@@ -85,13 +83,21 @@ func (s *CustomShardingStrategy) GetBaseTableName() string {
 	return s.baseTableName
 }
 
+// GetShardingKey 获取分表键字段名
+func (s *CustomShardingStrategy) GetShardingKey() string {
+	return s.shardingKey
+}
+
 // RangeShardingStrategy 范围分表策略（示例：按 ID 范围分表）
 // 例如：0-9999 在 table_0，10000-19999 在 table_1
 type RangeShardingStrategy struct {
 	baseTableName string
 	shardingKey   string
-	rangeSize     int64 // 每个分表的数据范围大小
-	tableCount    int   // 分表数量
+	rangeSize     int64              // 每个分表的数据范围大小
+	tableCount    int                // 分表数量
+	nameTemplate  *tableNameTemplate // 分表名称模板（为空时为 基础表名_索引）
+
+	ShardOptionsMap // 分表级别的建表选项（通过 SetShardOptions 配置）
 }
 
 // NewRangeShardingStrategy 创建范围分表策略
@@ -129,8 +135,8 @@ func (s *RangeShardingStrategy) GetTableName(baseTableName string, shardingValue
 		intValue = int64(v)
 	default:
 		// 如果不是数字类型，使用 Hash 分表作为后备方案
-		hashStrategy := NewHashShardingStrategy(baseTableName, s.shardingKey, s.tableCount)
-		return hashStrategy.GetTableName(baseTableName, shardingValue)
+		tableIndex := hashShardingValue(shardingValue) % uint64(s.tableCount)
+		return formatIndexTableName(s.nameTemplate, baseTableName, int(tableIndex))
 	}
 
 	// 计算分表索引
@@ -144,18 +150,33 @@ func (s *RangeShardingStrategy) GetTableName(baseTableName string, shardingValue
 		tableIndex = 0
 	}
 
-	return fmt.Sprintf("%s_%d", baseTableName, tableIndex)
+	return formatIndexTableName(s.nameTemplate, baseTableName, tableIndex)
 }
 
 // GetAllTableNames 获取所有分表名称
 func (s *RangeShardingStrategy) GetAllTableNames(baseTableName string) []string {
 	tableNames := make([]string, s.tableCount)
 	for i := 0; i < s.tableCount; i++ {
-		tableNames[i] = fmt.Sprintf("%s_%d", baseTableName, i)
+		tableNames[i] = formatIndexTableName(s.nameTemplate, baseTableName, i)
 	}
 	return tableNames
 }
 
+// SetNameTemplate 设置分表名称模板（如 "{base}_shard{index:02d}"），必须包含 {index}，需要在注册策略前调用
+func (s *RangeShardingStrategy) SetNameTemplate(template string) error {
+	parsed, err := parseIndexTemplate(template)
+	if err != nil {
+		return err
+	}
+	s.nameTemplate = parsed
+	return nil
+}
+
+// tableNameTemplate 获取分表名称模板
+func (s *RangeShardingStrategy) tableNameTemplate() *tableNameTemplate {
+	return s.nameTemplate
+}
+
 // GetShardingValue 从模型对象中提取分表键值
 func (s *RangeShardingStrategy) GetShardingValue(value interface{}) (interface{}, error) {
 	return ExtractValue(value, s.shardingKey)
@@ -166,12 +187,20 @@ func (s *RangeShardingStrategy) GetBaseTableName() string {
 	return s.baseTableName
 }
 
+// GetShardingKey 获取分表键字段名
+func (s *RangeShardingStrategy) GetShardingKey() string {
+	return s.shardingKey
+}
+
 // ModuloShardingStrategy 取模分表策略（另一种常见的分表方式）
 // 例如：ID % 4 = 0 的在 table_0，ID % 4 = 1 的在 table_1
 type ModuloShardingStrategy struct {
 	baseTableName string
 	shardingKey   string
-	modulo        int // 取模数
+	modulo        int                // 取模数
+	nameTemplate  *tableNameTemplate // 分表名称模板（为空时为 基础表名_索引）
+
+	ShardOptionsMap // 分表级别的建表选项（通过 SetShardOptions 配置）
 }
 
 // NewModuloShardingStrategy 创建取模分表策略
@@ -205,8 +234,8 @@ func (s *ModuloShardingStrategy) GetTableName(baseTableName string, shardingValu
 		intValue = int64(v)
 	default:
 		// 如果不是数字类型，使用 Hash 分表作为后备方案
-		hashStrategy := NewHashShardingStrategy(baseTableName, s.shardingKey, s.modulo)
-		return hashStrategy.GetTableName(baseTableName, shardingValue)
+		tableIndex := hashShardingValue(shardingValue) % uint64(s.modulo)
+		return formatIndexTableName(s.nameTemplate, baseTableName, int(tableIndex))
 	}
 
 	// 取模计算分表索引
@@ -215,18 +244,33 @@ func (s *ModuloShardingStrategy) GetTableName(baseTableName string, shardingValu
 		tableIndex = -tableIndex % s.modulo
 	}
 
-	return fmt.Sprintf("%s_%d", baseTableName, tableIndex)
+	return formatIndexTableName(s.nameTemplate, baseTableName, tableIndex)
 }
 
 // GetAllTableNames 获取所有分表名称
 func (s *ModuloShardingStrategy) GetAllTableNames(baseTableName string) []string {
 	tableNames := make([]string, s.modulo)
 	for i := 0; i < s.modulo; i++ {
-		tableNames[i] = fmt.Sprintf("%s_%d", baseTableName, i)
+		tableNames[i] = formatIndexTableName(s.nameTemplate, baseTableName, i)
 	}
 	return tableNames
 }
 
+// SetNameTemplate 设置分表名称模板（如 "{base}_shard{index:02d}"），必须包含 {index}，需要在注册策略前调用
+func (s *ModuloShardingStrategy) SetNameTemplate(template string) error {
+	parsed, err := parseIndexTemplate(template)
+	if err != nil {
+		return err
+	}
+	s.nameTemplate = parsed
+	return nil
+}
+
+// tableNameTemplate 获取分表名称模板
+func (s *ModuloShardingStrategy) tableNameTemplate() *tableNameTemplate {
+	return s.nameTemplate
+}
+
 // GetShardingValue 从模型对象中提取分表键值
 func (s *ModuloShardingStrategy) GetShardingValue(value interface{}) (interface{}, error) {
 	return ExtractValue(value, s.shardingKey)
@@ -237,3 +281,8 @@ func (s *ModuloShardingStrategy) GetBaseTableName() string {
 	return s.baseTableName
 }
 
+// GetShardingKey 获取分表键字段名
+func (s *ModuloShardingStrategy) GetShardingKey() string {
+	return s.shardingKey
+}
+
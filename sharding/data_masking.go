@@ -0,0 +1,108 @@
+package sharding
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Masker 列值脱敏函数，返回脱敏后的值（nil 值不会传入）
+type Masker func(value interface{}) interface{}
+
+// MaskRedact 完全隐藏列值（替换为 "***"）
+func MaskRedact() Masker {
+	return func(value interface{}) interface{} {
+		return "***"
+	}
+}
+
+// MaskHash 将列值替换为加盐 SHA-256 的前 16 位十六进制（相同的值脱敏结果相同，仍可用于关联和去重）
+func MaskHash(salt string) Masker {
+	return func(value interface{}) interface{} {
+		sum := sha256.Sum256([]byte(salt + maskString(value)))
+		return hex.EncodeToString(sum[:8])
+	}
+}
+
+// MaskPartial 保留前 keepPrefix 个和后 keepSuffix 个字符，其余替换为 '*'（如手机号 138****5678）
+// 值太短时全部替换
+func MaskPartial(keepPrefix, keepSuffix int) Masker {
+	return func(value interface{}) interface{} {
+		runes := []rune(maskString(value))
+		if keepPrefix < 0 {
+			keepPrefix = 0
+		}
+		if keepSuffix < 0 {
+			keepSuffix = 0
+		}
+		if len(runes) <= keepPrefix+keepSuffix {
+			return strings.Repeat("*", len(runes))
+		}
+		masked := make([]rune, len(runes))
+		for i, r := range runes {
+			if i < keepPrefix || i >= len(runes)-keepSuffix {
+				masked[i] = r
+			} else {
+				masked[i] = '*'
+			}
+		}
+		return string(masked)
+	}
+}
+
+// maskString 将列值转换为字符串
+func maskString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []byte:
+		if utf8.Valid(v) {
+			return string(v)
+		}
+		return hex.EncodeToString(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// maskingRules 按基础表配置的脱敏规则
+type maskingRules struct {
+	mu    sync.RWMutex
+	rules map[string]map[string]Masker // 基础表名 -> 列名 -> 脱敏函数
+}
+
+// defaultMaskingRules 全局脱敏规则，导出（CrossTableExport）时自动应用
+var defaultMaskingRules = &maskingRules{
+	rules: make(map[string]map[string]Masker),
+}
+
+// SetMasking 为基础表的列设置脱敏规则（如 SetMasking("users", "phone", MaskPartial(3, 4))），
+// 导出该表所有分表的数据时应用；masker 为 nil 时删除该列的规则
+func SetMasking(baseTableName, column string, masker Masker) {
+	defaultMaskingRules.mu.Lock()
+	defer defaultMaskingRules.mu.Unlock()
+
+	if masker == nil {
+		delete(defaultMaskingRules.rules[baseTableName], column)
+		return
+	}
+	if defaultMaskingRules.rules[baseTableName] == nil {
+		defaultMaskingRules.rules[baseTableName] = make(map[string]Masker)
+	}
+	defaultMaskingRules.rules[baseTableName][column] = masker
+}
+
+// MaskRow 按基础表的脱敏规则对一行数据（列名 -> 值）脱敏，原地修改
+func MaskRow(baseTableName string, row map[string]interface{}) {
+	defaultMaskingRules.mu.RLock()
+	defer defaultMaskingRules.mu.RUnlock()
+
+	for column, masker := range defaultMaskingRules.rules[baseTableName] {
+		if value, ok := row[column]; ok && value != nil {
+			row[column] = masker(value)
+		}
+	}
+}
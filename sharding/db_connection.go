@@ -132,6 +132,14 @@ func (d *DSNInfo) BuildDSNWithoutDatabase() string {
 // charset: 数据库字符集（默认为 utf8mb4）
 // collation: 数据库排序规则（默认为 utf8mb4_unicode_ci）
 func OpenWithAutoCreateDB(dsn string, config *gorm.Config, charset, collation string) (*gorm.DB, error) {
+	return openWithAutoCreateDB(dsn, charset, collation, func(dsn string) (*gorm.DB, error) {
+		return gorm.Open(mysql.Open(dsn), config)
+	})
+}
+
+// openWithAutoCreateDB 打开数据库连接，如果数据库不存在则自动创建
+// open: 根据 DSN 打开连接的函数（用于支持不同的认证方式）
+func openWithAutoCreateDB(dsn string, charset, collation string, open func(dsn string) (*gorm.DB, error)) (*gorm.DB, error) {
 	// 解析 DSN
 	dsnInfo, err := ParseDSN(dsn)
 	if err != nil {
@@ -140,12 +148,12 @@ func OpenWithAutoCreateDB(dsn string, config *gorm.Config, charset, collation st
 
 	if dsnInfo.Database == "" {
 		// 如果没有指定数据库，直接连接
-		return gorm.Open(mysql.Open(dsn), config)
+		return open(dsn)
 	}
 
 	// 先连接到 MySQL 服务器（不指定数据库）
 	serverDSN := dsnInfo.BuildDSNWithoutDatabase()
-	serverDB, err := gorm.Open(mysql.Open(serverDSN), config)
+	serverDB, err := open(serverDSN)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to MySQL server: %w", err)
 	}
@@ -166,6 +174,9 @@ func OpenWithAutoCreateDB(dsn string, config *gorm.Config, charset, collation st
 		if collation == "" {
 			collation = "utf8mb4_unicode_ci"
 		}
+		if err := validateCharsetAndCollation(charset, collation); err != nil {
+			return nil, err
+		}
 
 		// 使用反引号保护数据库名（防止 SQL 注入）
 		dbName := quoteIdentifier(dsnInfo.Database)
@@ -185,7 +196,7 @@ func OpenWithAutoCreateDB(dsn string, config *gorm.Config, charset, collation st
 	}
 
 	// 连接到指定的数据库
-	db, err := gorm.Open(mysql.Open(dsn), config)
+	db, err := open(dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
@@ -215,12 +226,17 @@ func CreateDatabase(db *gorm.DB, databaseName string, charset, collation string)
 	if collation == "" {
 		collation = "utf8mb4_unicode_ci"
 	}
+	if err := validateCharsetAndCollation(charset, collation); err != nil {
+		return err
+	}
 
 	dbName := quoteIdentifier(databaseName)
 	query := fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s CHARACTER SET %s COLLATE %s",
 		dbName, charset, collation)
 
-	return db.Exec(query).Error
+	return runDDL(db, "CreateDatabase", databaseName, func(tx *gorm.DB) error {
+		return tx.Exec(query).Error
+	})
 }
 
 // EnsureDatabaseExists 确保数据库存在，如果不存在则创建
@@ -244,6 +260,50 @@ func quoteIdentifier(ident string) string {
 	return "`" + ident + "`"
 }
 
+// allowedCharsets 允许使用的字符集白名单（字符集会被直接拼接到 SQL 中）
+var allowedCharsets = map[string]bool{
+	"utf8mb4": true,
+	"utf8mb3": true,
+	"utf8":    true,
+	"latin1":  true,
+	"ascii":   true,
+	"binary":  true,
+	"gbk":     true,
+	"gb2312":  true,
+	"gb18030": true,
+	"big5":    true,
+	"ucs2":    true,
+	"utf16":   true,
+	"utf32":   true,
+}
+
+// collationPattern 排序规则格式（只允许小写字母、数字和下划线）
+var collationPattern = regexp.MustCompile(`^[a-z0-9]+(_[a-z0-9]+)+$`)
+
+// validateCharsetAndCollation 校验字符集和排序规则（为空表示不指定）
+// 字符集必须在白名单中，排序规则必须以对应字符集为前缀（如 utf8mb4_unicode_ci）或为 binary
+func validateCharsetAndCollation(charset, collation string) error {
+	if charset != "" && !allowedCharsets[strings.ToLower(charset)] {
+		return fmt.Errorf("unsupported charset: %s", charset)
+	}
+
+	if collation != "" && !strings.EqualFold(collation, "binary") {
+		lowerCollation := strings.ToLower(collation)
+		if !collationPattern.MatchString(lowerCollation) {
+			return fmt.Errorf("invalid collation: %s", collation)
+		}
+		prefix := strings.SplitN(lowerCollation, "_", 2)[0]
+		if !allowedCharsets[prefix] {
+			return fmt.Errorf("unsupported collation: %s", collation)
+		}
+		if charset != "" && !strings.HasPrefix(lowerCollation, strings.ToLower(charset)+"_") {
+			return fmt.Errorf("collation %s does not match charset %s", collation, charset)
+		}
+	}
+
+	return nil
+}
+
 // ExtractDatabaseFromDSN 从 DSN 中提取数据库名
 func ExtractDatabaseFromDSN(dsn string) (string, error) {
 	dsnInfo, err := ParseDSN(dsn)
@@ -0,0 +1,213 @@
+package sharding
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// ddlAuditPluginName DDL 审计插件名称
+const ddlAuditPluginName = "x2-sharding:ddl-audit"
+
+// defaultDDLAuditTable 默认的 DDL 审计表名
+const defaultDDLAuditTable = "sharding_ddl_audit"
+
+// DDLAuditRecord DDL 审计记录
+type DDLAuditRecord struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`          // 执行时间
+	Initiator string    `gorm:"size:255" json:"initiator"`        // 发起者（默认为 主机名:进程号）
+	Operation string    `gorm:"size:64" json:"operation"`         // 操作（如 AutoMigrate、AutoCreateTable）
+	Shard     string    `gorm:"size:255;index" json:"shard"`      // 受影响的分表（或数据库）
+	Statement string    `gorm:"type:text" json:"statement"`       // 执行的 DDL 语句
+	Duration  int64     `json:"duration"`                         // 执行耗时（毫秒）
+	Error     string    `gorm:"type:text" json:"error,omitempty"` // 执行失败时的错误信息
+}
+
+// DDLAuditOptions DDL 审计配置
+type DDLAuditOptions struct {
+	TableName string               // 审计表名（默认 sharding_ddl_audit）
+	Initiator string               // 默认发起者（默认为 主机名:进程号），可通过 WithDDLInitiator 按操作覆盖
+	OnRecord  func(DDLAuditRecord) // 每条审计记录写入后的回调（可选，用于转发到日志或事件系统）
+	Disabled  bool                 // 只调用 OnRecord，不写入审计表
+}
+
+// DDLAuditor DDL 审计插件，实现 gorm.Plugin 接口
+// 启用后，本包执行的所有 DDL（AutoMigrate、AutoCreateTable、CreateAllShardingTables 等）都会记录到审计表
+// 用法：db.Use(sharding.NewDDLAuditor(sharding.DDLAuditOptions{}))
+type DDLAuditor struct {
+	options DDLAuditOptions
+	db      *gorm.DB
+}
+
+// NewDDLAuditor 创建 DDL 审计插件
+func NewDDLAuditor(options DDLAuditOptions) *DDLAuditor {
+	if options.TableName == "" {
+		options.TableName = defaultDDLAuditTable
+	}
+	if options.Initiator == "" {
+		hostname, _ := os.Hostname()
+		options.Initiator = fmt.Sprintf("%s:%d", hostname, os.Getpid())
+	}
+	return &DDLAuditor{options: options}
+}
+
+// Name 插件名称（gorm.Plugin 接口）
+func (a *DDLAuditor) Name() string {
+	return ddlAuditPluginName
+}
+
+// Initialize 创建审计表（gorm.Plugin 接口）
+func (a *DDLAuditor) Initialize(db *gorm.DB) error {
+	a.db = db.Session(&gorm.Session{NewDB: true})
+	if a.options.Disabled {
+		return nil
+	}
+	if err := a.db.Table(a.options.TableName).AutoMigrate(&DDLAuditRecord{}); err != nil {
+		return fmt.Errorf("failed to create ddl audit table %s: %w", a.options.TableName, err)
+	}
+	return nil
+}
+
+// ddlInitiatorKey context 中保存发起者的键
+type ddlInitiatorKey struct{}
+
+// WithDDLInitiator 在 context 中设置 DDL 发起者，配合 db.WithContext(ctx) 使用
+func WithDDLInitiator(ctx context.Context, initiator string) context.Context {
+	return context.WithValue(ctx, ddlInitiatorKey{}, initiator)
+}
+
+// getDDLAuditor 获取已注册的 DDL 审计插件
+func getDDLAuditor(db *gorm.DB) *DDLAuditor {
+	if plugin, ok := db.Config.Plugins[ddlAuditPluginName]; ok {
+		if auditor, ok := plugin.(*DDLAuditor); ok {
+			return auditor
+		}
+	}
+	return nil
+}
+
+// runDDL 执行 DDL 操作，启用了审计插件时记录执行的每条 DDL 语句
+// operation: 操作名称；shard: 受影响的分表；fn: 实际执行 DDL 的函数（必须使用传入的 tx）
+func runDDL(db *gorm.DB, operation, shard string, fn func(tx *gorm.DB) error) error {
+	auditor := getDDLAuditor(db)
+	if auditor == nil {
+		return fn(db)
+	}
+
+	recorder := newDDLRecorder(db.Logger)
+	err := fn(db.Session(&gorm.Session{Logger: recorder}))
+
+	statements := recorder.statements()
+	if len(statements) == 0 && err != nil {
+		// 执行 DDL 之前就失败了，也记录下来
+		statements = []recordedStatement{{sql: "", err: err}}
+	}
+
+	initiator := auditor.options.Initiator
+	if ctx := db.Statement.Context; ctx != nil {
+		if value, ok := ctx.Value(ddlInitiatorKey{}).(string); ok && value != "" {
+			initiator = value
+		}
+	}
+
+	for _, statement := range statements {
+		record := DDLAuditRecord{
+			CreatedAt: statement.at,
+			Initiator: initiator,
+			Operation: operation,
+			Shard:     shard,
+			Statement: statement.sql,
+			Duration:  statement.elapsed.Milliseconds(),
+		}
+		if record.CreatedAt.IsZero() {
+			record.CreatedAt = time.Now()
+		}
+		if statement.err != nil {
+			record.Error = statement.err.Error()
+		}
+		auditor.write(record)
+	}
+
+	return err
+}
+
+// write 写入审计记录（审计失败不影响 DDL 操作本身）
+func (a *DDLAuditor) write(record DDLAuditRecord) {
+	if !a.options.Disabled && a.db != nil {
+		if err := a.db.Table(a.options.TableName).Create(&record).Error; err != nil {
+			a.db.Logger.Error(context.Background(), "failed to write ddl audit record: %v", err)
+		}
+	}
+	if a.options.OnRecord != nil {
+		a.options.OnRecord(record)
+	}
+}
+
+// recordedStatement 记录到的 DDL 语句
+type recordedStatement struct {
+	sql     string
+	at      time.Time
+	elapsed time.Duration
+	err     error
+}
+
+// ddlRecorder 包装日志接口，记录执行过的 DDL 语句
+type ddlRecorder struct {
+	logger.Interface
+	store *ddlRecordStore
+}
+
+// ddlRecordStore 记录到的 DDL 语句（LogMode 派生的日志实例共享同一份记录）
+type ddlRecordStore struct {
+	mu      sync.Mutex
+	records []recordedStatement
+}
+
+// newDDLRecorder 创建 DDL 记录器
+func newDDLRecorder(inner logger.Interface) *ddlRecorder {
+	return &ddlRecorder{Interface: inner, store: &ddlRecordStore{}}
+}
+
+// LogMode 保持包装关系（logger.Interface 接口）
+func (r *ddlRecorder) LogMode(level logger.LogLevel) logger.Interface {
+	return &ddlRecorder{Interface: r.Interface.LogMode(level), store: r.store}
+}
+
+// Trace 记录 DDL 语句后交给原日志处理（logger.Interface 接口）
+func (r *ddlRecorder) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	sql, _ := fc()
+	if isDDLStatement(sql) {
+		r.store.mu.Lock()
+		r.store.records = append(r.store.records, recordedStatement{sql: sql, at: begin, elapsed: time.Since(begin), err: err})
+		r.store.mu.Unlock()
+	}
+	r.Interface.Trace(ctx, begin, fc, err)
+}
+
+// statements 获取记录到的 DDL 语句
+func (r *ddlRecorder) statements() []recordedStatement {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return append([]recordedStatement(nil), r.store.records...)
+}
+
+// isDDLStatement 判断 SQL 是否为 DDL 语句
+func isDDLStatement(sql string) bool {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return false
+	}
+	switch strings.ToUpper(fields[0]) {
+	case "CREATE", "ALTER", "DROP", "RENAME", "TRUNCATE":
+		return true
+	default:
+		return false
+	}
+}
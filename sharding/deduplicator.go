@@ -0,0 +1,181 @@
+package sharding
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// DedupMode 多表连接结果去重的位置
+type DedupMode int
+
+const (
+	DedupInMemory DedupMode = iota // 在内存中按 Deduplicator 去重（默认）
+	DedupDistinct                  // 在数据库中去重：每个表组合的查询使用 SELECT DISTINCT
+	DedupGroupBy                   // 在数据库中去重：每个表组合的查询按 DedupColumns 分组（GROUP BY）
+)
+
+// applySQLDedup 在数据库中去重时为表组合的查询添加 DISTINCT 或 GROUP BY
+func applySQLDedup(query *gorm.DB, config MultiJoinConfig) *gorm.DB {
+	switch config.DedupMode {
+	case DedupDistinct:
+		return query.Distinct()
+	case DedupGroupBy:
+		return query.Group(strings.Join(config.DedupColumns, ", "))
+	default:
+		return query
+	}
+}
+
+// Deduplicator 多表连接结果的去重方式（同一行连接结果可能出现在多个表组合中）
+type Deduplicator interface {
+	// Key 返回结果行的去重键，键相同的行只保留第一行；返回 false 表示该行不参与去重（总是保留）
+	Key(row map[string]interface{}) (string, bool)
+}
+
+// PrimaryKeyDeduplicator 按主键列去重（如 PrimaryKeyDeduplicator("order_id", "payment_id")）
+// 缺少任一列或值为 NULL 的行不参与去重
+func PrimaryKeyDeduplicator(columns ...string) Deduplicator {
+	return primaryKeyDeduplicator{columns: columns}
+}
+
+// FieldsDeduplicator 按优先级依次尝试字段组合，使用第一个所有字段都存在且非空的组合作为去重键，
+// 都不满足时按整行去重（与 MultiJoinConfig.DeduplicateFields 相同）
+func FieldsDeduplicator(fieldGroups ...[]string) Deduplicator {
+	return fieldsDeduplicator{fieldGroups: fieldGroups}
+}
+
+// RowHashDeduplicator 按整行（所有列的值）去重
+func RowHashDeduplicator() Deduplicator {
+	return rowHashDeduplicator{}
+}
+
+// NoDeduplication 不去重，保留所有表组合的结果
+func NoDeduplication() Deduplicator {
+	return noDeduplication{}
+}
+
+// primaryKeyDeduplicator 按主键列去重
+type primaryKeyDeduplicator struct {
+	columns []string
+}
+
+func (d primaryKeyDeduplicator) Key(row map[string]interface{}) (string, bool) {
+	if len(d.columns) == 0 {
+		return "", false
+	}
+	parts := make([]string, len(d.columns))
+	for i, column := range d.columns {
+		value, ok := row[column]
+		if !ok || value == nil {
+			return "", false
+		}
+		parts[i] = fmt.Sprintf("%v", value)
+	}
+	return strings.Join(parts, "\x1f"), true
+}
+
+// fieldsDeduplicator 按优先级的字段组合去重
+type fieldsDeduplicator struct {
+	fieldGroups [][]string
+}
+
+func (d fieldsDeduplicator) Key(row map[string]interface{}) (string, bool) {
+	for _, fields := range d.fieldGroups {
+		keyParts := make([]string, 0, len(fields))
+		for _, field := range fields {
+			value, ok := row[field]
+			if !ok || value == nil {
+				break
+			}
+			strVal := fmt.Sprintf("%v", value)
+			if strVal == "" {
+				break
+			}
+			keyParts = append(keyParts, field+":"+strVal)
+		}
+		if len(keyParts) > 0 && len(keyParts) == len(fields) {
+			return strings.Join(keyParts, "|"), true
+		}
+	}
+	return rowHashDeduplicator{}.Key(row)
+}
+
+// rowHashDeduplicator 按整行去重
+type rowHashDeduplicator struct{}
+
+func (rowHashDeduplicator) Key(row map[string]interface{}) (string, bool) {
+	columns := make([]string, 0, len(row))
+	for column := range row {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	hash := sha256.New()
+	for _, column := range columns {
+		fmt.Fprintf(hash, "%s=%#v\x1f", column, row[column])
+	}
+	return string(hash.Sum(nil)), true
+}
+
+// noDeduplication 不去重
+type noDeduplication struct{}
+
+func (noDeduplication) Key(map[string]interface{}) (string, bool) {
+	return "", false
+}
+
+// deduplicator 获取去重方式：Deduplicator > DeduplicateFields > dest 结构体的主键（GORM primaryKey 标签或 ID 字段） > 整行
+// 在数据库中去重时（DedupMode）默认不再在内存中去重（同一行连接结果只会出现在一个表组合中）
+func (c MultiJoinConfig) deduplicator(db *gorm.DB, dest interface{}) Deduplicator {
+	if c.Deduplicator != nil {
+		return c.Deduplicator
+	}
+	if c.DedupMode != DedupInMemory {
+		return NoDeduplication()
+	}
+	if len(c.DeduplicateFields) > 0 {
+		return FieldsDeduplicator(c.DeduplicateFields...)
+	}
+	if columns := primaryKeyColumns(db, dest); len(columns) > 0 {
+		return PrimaryKeyDeduplicator(columns...)
+	}
+	return RowHashDeduplicator()
+}
+
+// primaryKeyColumns 获取 dest（结构体切片）元素类型的主键列名，dest 不是结构体切片时返回 nil
+func primaryKeyColumns(db *gorm.DB, dest interface{}) []string {
+	if dest == nil {
+		return nil
+	}
+	elemType := reflect.TypeOf(dest)
+	for elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Slice && elemType.Kind() != reflect.Array {
+		return nil
+	}
+	elemType = elemType.Elem()
+	for elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return nil
+	}
+
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(reflect.New(elemType).Interface()); err != nil {
+		return nil
+	}
+	columns := make([]string, 0, len(stmt.Schema.PrimaryFields))
+	for _, field := range stmt.Schema.PrimaryFields {
+		if field.DBName != "" {
+			columns = append(columns, field.DBName)
+		}
+	}
+	return columns
+}
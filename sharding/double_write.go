@@ -0,0 +1,353 @@
+package sharding
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// defaultMaxConflictDecisions 默认保留的冲突处理记录数量
+const defaultMaxConflictDecisions = 1000
+
+// 只有一侧存在的行的处理方式（记录在 ConflictDecision.Resolver 中）
+const (
+	copyMissingResolution   = "copy_missing"
+	reportMissingResolution = "report_missing"
+)
+
+// DoubleWriteConflict 新旧分表布局中同一主键的行不一致
+type DoubleWriteConflict struct {
+	OldTable string      // 旧布局中的分表
+	NewTable string      // 新布局中的分表
+	OldRow   interface{} // 旧布局中的行（不存在时为 nil）
+	NewRow   interface{} // 新布局中的行（不存在时为 nil）
+	Columns  []string    // 值不同的列（某一侧行不存在时为空）
+}
+
+// Missing 判断是否只有一侧存在该行
+func (c DoubleWriteConflict) Missing() bool {
+	return c.OldRow == nil || c.NewRow == nil
+}
+
+// ConflictResolver 冲突处理器，只在两侧都存在且列值不同时调用，返回以哪一行为准
+// 自定义处理器返回 nil 表示两侧都删除该行
+type ConflictResolver struct {
+	Name    string // 处理方式名称（记录在 ConflictDecision 中）
+	Resolve func(conflict DoubleWriteConflict) (interface{}, error)
+}
+
+// PreferNewResolver 以新布局中的行为准
+var PreferNewResolver = ConflictResolver{
+	Name: "prefer_new",
+	Resolve: func(conflict DoubleWriteConflict) (interface{}, error) {
+		return conflict.NewRow, nil
+	},
+}
+
+// PreferOldResolver 以旧布局中的行为准
+var PreferOldResolver = ConflictResolver{
+	Name: "prefer_old",
+	Resolve: func(conflict DoubleWriteConflict) (interface{}, error) {
+		return conflict.OldRow, nil
+	},
+}
+
+// MergeResolver 使用自定义函数合并新旧两行，merge 返回合并后的行（与模型类型相同的结构体指针）
+func MergeResolver(merge func(oldRow, newRow interface{}) (interface{}, error)) ConflictResolver {
+	return ConflictResolver{
+		Name: "merge",
+		Resolve: func(conflict DoubleWriteConflict) (interface{}, error) {
+			return merge(conflict.OldRow, conflict.NewRow)
+		},
+	}
+}
+
+// ConflictDecision 冲突处理记录
+type ConflictDecision struct {
+	Conflict  DoubleWriteConflict
+	Resolver  string      // 使用的处理方式（只有一侧存在时为 copy_missing 或 report_missing）
+	Resolved  interface{} // 最终写入两侧的行（nil 表示删除或未写入）
+	Err       error       // 处理失败的原因
+	DecidedAt time.Time
+}
+
+// DoubleWriteConfig 双写配置
+type DoubleWriteConfig struct {
+	Old        ShardingStrategy                // 旧的分表布局
+	New        ShardingStrategy                // 新的分表布局
+	Resolver   ConflictResolver                // 冲突处理器（默认 PreferNewResolver）
+	OnDecision func(decision ConflictDecision) // 冲突处理后的回调（可选，如写入审计表）
+	// MaxDecisions 内存中保留的最近冲突处理记录数量（默认 1000）
+	MaxDecisions int
+	// ReportMissing 只有一侧存在的行只记录，不复制到缺失的一侧（默认复制）
+	ReportMissing bool
+}
+
+// DoubleWriter 重新分表期间同时写入新旧两种分表布局，并在两侧数据不一致时按 Resolver 处理
+type DoubleWriter struct {
+	config DoubleWriteConfig
+
+	mu        sync.Mutex
+	decisions []ConflictDecision
+}
+
+// NewDoubleWriter 创建双写器
+func NewDoubleWriter(config DoubleWriteConfig) (*DoubleWriter, error) {
+	if config.Old == nil || config.New == nil {
+		return nil, fmt.Errorf("both old and new sharding strategies are required")
+	}
+	if config.Resolver.Resolve == nil {
+		config.Resolver = PreferNewResolver
+	}
+	if config.MaxDecisions <= 0 {
+		config.MaxDecisions = defaultMaxConflictDecisions
+	}
+	return &DoubleWriter{config: config}, nil
+}
+
+// Create 在一个事务中将 value 写入新旧两种布局对应的分表
+func (w *DoubleWriter) Create(db *gorm.DB, value interface{}) error {
+	oldTable, newTable, err := w.tableNames(value)
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Table(oldTable).Create(value).Error; err != nil {
+			return fmt.Errorf("failed to write old table %s: %w", oldTable, err)
+		}
+		if err := tx.Table(newTable).Create(value).Error; err != nil {
+			return fmt.Errorf("failed to write new table %s: %w", newTable, err)
+		}
+		return nil
+	})
+}
+
+// Save 在一个事务中更新（或插入）新旧两种布局中的行
+func (w *DoubleWriter) Save(db *gorm.DB, value interface{}) error {
+	oldTable, newTable, err := w.tableNames(value)
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Table(oldTable).Save(value).Error; err != nil {
+			return fmt.Errorf("failed to write old table %s: %w", oldTable, err)
+		}
+		if err := tx.Table(newTable).Save(value).Error; err != nil {
+			return fmt.Errorf("failed to write new table %s: %w", newTable, err)
+		}
+		return nil
+	})
+}
+
+// Reconcile 比较新旧两种布局中与 value 主键相同的行，列值不同时调用 Resolver 并将结果写回两侧
+// 只有一侧存在的行不调用 Resolver，默认复制到缺失的一侧（ReportMissing 为 true 时只记录）
+// value 需要设置分表键和主键；两侧一致时返回 nil, nil
+func (w *DoubleWriter) Reconcile(db *gorm.DB, value interface{}) (*ConflictDecision, error) {
+	oldTable, newTable, err := w.tableNames(value)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(value); err != nil {
+		return nil, fmt.Errorf("failed to parse model: %w", err)
+	}
+	if len(stmt.Schema.PrimaryFields) == 0 {
+		return nil, fmt.Errorf("model %s has no primary key", stmt.Schema.Name)
+	}
+
+	oldRow, err := loadByPrimaryKey(db, stmt.Schema, oldTable, value)
+	if err != nil {
+		return nil, err
+	}
+	newRow, err := loadByPrimaryKey(db, stmt.Schema, newTable, value)
+	if err != nil {
+		return nil, err
+	}
+	if oldRow == nil && newRow == nil {
+		return nil, nil
+	}
+
+	conflict := DoubleWriteConflict{
+		OldTable: oldTable,
+		NewTable: newTable,
+		OldRow:   oldRow,
+		NewRow:   newRow,
+	}
+	if conflict.Missing() {
+		decision := w.reconcileMissing(db, conflict)
+		w.record(decision)
+		db.Logger.Warn(db.Statement.Context, "double-write row exists only in one of %s and %s, handled with %s", oldTable, newTable, decision.Resolver)
+		if decision.Err != nil {
+			return &decision, fmt.Errorf("failed to reconcile missing row between %s and %s: %w", oldTable, newTable, decision.Err)
+		}
+		return &decision, nil
+	}
+
+	conflict.Columns = diffColumns(db, stmt.Schema, oldRow, newRow)
+	if len(conflict.Columns) == 0 {
+		return nil, nil
+	}
+
+	decision := ConflictDecision{
+		Conflict:  conflict,
+		Resolver:  w.config.Resolver.Name,
+		DecidedAt: time.Now(),
+	}
+	decision.Resolved, decision.Err = w.config.Resolver.Resolve(conflict)
+	if decision.Err == nil {
+		decision.Err = applyResolution(db, conflict, decision.Resolved)
+	}
+	w.record(decision)
+	db.Logger.Warn(db.Statement.Context, "double-write conflict between %s and %s on columns %v resolved with %s", oldTable, newTable, conflict.Columns, decision.Resolver)
+
+	if decision.Err != nil {
+		return &decision, fmt.Errorf("failed to resolve double-write conflict between %s and %s: %w", oldTable, newTable, decision.Err)
+	}
+	return &decision, nil
+}
+
+// reconcileMissing 处理只有一侧存在的行：复制到缺失的一侧，ReportMissing 为 true 时只记录
+func (w *DoubleWriter) reconcileMissing(db *gorm.DB, conflict DoubleWriteConflict) ConflictDecision {
+	decision := ConflictDecision{
+		Conflict:  conflict,
+		Resolver:  reportMissingResolution,
+		DecidedAt: time.Now(),
+	}
+	if w.config.ReportMissing {
+		return decision
+	}
+
+	existing, missingTable := conflict.OldRow, conflict.NewTable
+	if existing == nil {
+		existing, missingTable = conflict.NewRow, conflict.OldTable
+	}
+	decision.Resolver = copyMissingResolution
+	decision.Resolved = existing
+	if err := db.Table(missingTable).Create(existing).Error; err != nil {
+		decision.Err = fmt.Errorf("failed to copy row to table %s: %w", missingTable, err)
+	}
+	return decision
+}
+
+// Decisions 获取最近的冲突处理记录（按处理时间顺序）
+func (w *DoubleWriter) Decisions() []ConflictDecision {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	decisions := make([]ConflictDecision, len(w.decisions))
+	copy(decisions, w.decisions)
+	return decisions
+}
+
+// record 保存冲突处理记录并调用 OnDecision
+func (w *DoubleWriter) record(decision ConflictDecision) {
+	w.mu.Lock()
+	w.decisions = append(w.decisions, decision)
+	if overflow := len(w.decisions) - w.config.MaxDecisions; overflow > 0 {
+		w.decisions = append(w.decisions[:0], w.decisions[overflow:]...)
+	}
+	w.mu.Unlock()
+
+	if w.config.OnDecision != nil {
+		w.config.OnDecision(decision)
+	}
+}
+
+// tableNames 获取 value 在新旧两种布局中对应的分表
+func (w *DoubleWriter) tableNames(value interface{}) (string, string, error) {
+	oldValue, err := w.config.Old.GetShardingValue(value)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get sharding value for old layout: %w", err)
+	}
+	newValue, err := w.config.New.GetShardingValue(value)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get sharding value for new layout: %w", err)
+	}
+
+	oldTable := w.config.Old.GetTableName(w.config.Old.GetBaseTableName(), oldValue)
+	newTable := w.config.New.GetTableName(w.config.New.GetBaseTableName(), newValue)
+	return oldTable, newTable, nil
+}
+
+// loadByPrimaryKey 按 value 的主键从分表中读取一行（不存在时返回 nil）
+func loadByPrimaryKey(db *gorm.DB, modelSchema *schema.Schema, tableName string, value interface{}) (interface{}, error) {
+	reflectValue := reflect.Indirect(reflect.ValueOf(value))
+	query := db.Table(tableName)
+	for _, field := range modelSchema.PrimaryFields {
+		fieldValue, _ := field.ValueOf(db.Statement.Context, reflectValue)
+		query = query.Where(fmt.Sprintf("%s = ?", db.Statement.Quote(field.DBName)), fieldValue)
+	}
+
+	row := reflect.New(modelSchema.ModelType).Interface()
+	if err := query.Take(row).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) || isTableNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to load row from table %s: %w", tableName, err)
+	}
+	return row, nil
+}
+
+// diffColumns 比较两行，返回值不同的列
+func diffColumns(db *gorm.DB, modelSchema *schema.Schema, oldRow, newRow interface{}) []string {
+	oldValue := reflect.Indirect(reflect.ValueOf(oldRow))
+	newValue := reflect.Indirect(reflect.ValueOf(newRow))
+
+	columns := make([]string, 0)
+	for _, field := range modelSchema.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		a, _ := field.ValueOf(db.Statement.Context, oldValue)
+		b, _ := field.ValueOf(db.Statement.Context, newValue)
+		if !fieldValuesEqual(a, b) {
+			columns = append(columns, field.DBName)
+		}
+	}
+	return columns
+}
+
+// fieldValuesEqual 判断两个字段值是否相同（时间按时刻比较）
+func fieldValuesEqual(a, b interface{}) bool {
+	av, bv := derefValue(a), derefValue(b)
+	if !av.IsValid() || !bv.IsValid() {
+		return av.IsValid() == bv.IsValid()
+	}
+	if at, ok := av.Interface().(time.Time); ok {
+		if bt, ok := bv.Interface().(time.Time); ok {
+			return at.Equal(bt)
+		}
+	}
+	return reflect.DeepEqual(av.Interface(), bv.Interface())
+}
+
+// applyResolution 将处理结果写回两侧（两侧的行都存在；resolved 为 nil 时删除两侧的行）
+func applyResolution(db *gorm.DB, conflict DoubleWriteConflict, resolved interface{}) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, tableName := range []string{conflict.OldTable, conflict.NewTable} {
+			if resolved == nil {
+				existing := conflict.OldRow
+				if tableName == conflict.NewTable {
+					existing = conflict.NewRow
+				}
+				if err := tx.Table(tableName).Delete(existing).Error; err != nil {
+					return fmt.Errorf("failed to delete row from table %s: %w", tableName, err)
+				}
+				continue
+			}
+
+			if err := tx.Table(tableName).Save(resolved).Error; err != nil {
+				return fmt.Errorf("failed to write resolved row to table %s: %w", tableName, err)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,267 @@
+package sharding
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrShardTableNotFound 分表不存在（例如时间分表尚未创建）
+	ErrShardTableNotFound = errors.New("sharding: shard table not found")
+
+	// ErrShardColumnNotFound 分表中不存在查询的列（例如多表连接时某个分表缺少字段）
+	ErrShardColumnNotFound = errors.New("sharding: shard column not found")
+
+	// ErrShutdown 组件已关闭（或正在关闭），不再接收新的操作
+	ErrShutdown = errors.New("sharding: shut down")
+
+	// ErrReadOnly 表处于只读模式（如迁移切换期间），拒绝写入
+	ErrReadOnly = errors.New("sharding: table is read-only")
+
+	// ErrInvalidTimeValue 时间分表键的值无法转换为时间（严格模式下返回）
+	ErrInvalidTimeValue = errors.New("sharding: invalid time value")
+
+	// ErrShardDatabaseNotFound 分表没有分配数据源，或数据源未注册到 ShardCluster
+	ErrShardDatabaseNotFound = errors.New("sharding: shard database not found")
+
+	// ErrShardUnavailable 分表所在的数据源未通过健康检查，暂时从跨表操作中排除
+	ErrShardUnavailable = errors.New("sharding: shard database unavailable")
+
+	// ErrCircuitOpen 分表连续失败，熔断器在冷却时间内不再执行该分表
+	ErrCircuitOpen = errors.New("sharding: circuit open")
+
+	// ErrStrategyExists 基础表已注册分表策略
+	ErrStrategyExists = errors.New("sharding: strategy already registered")
+
+	// ErrInvalidJoin 连接配置无效（别名或 JOIN 类型不合法、别名冲突）
+	ErrInvalidJoin = errors.New("sharding: invalid join config")
+
+	// ErrTooManyCombinations 多表连接需要查询的表组合数量超过 MultiJoinConfig.MaxCombinations
+	ErrTooManyCombinations = errors.New("sharding: too many join combinations")
+
+	// ErrJoinKeyNotFound 连接键中没有表的分表键值，无法确定使用哪个分表
+	ErrJoinKeyNotFound = errors.New("sharding: join key not found")
+
+	// ErrNotConfirmed 破坏性的批量操作（删除、清空所有分表）未设置 Confirm: true
+	ErrNotConfirmed = errors.New("sharding: destructive operation not confirmed")
+
+	// ErrArchiveMismatch 归档后归档库与源分表的行数不一致，源分表未删除
+	ErrArchiveMismatch = errors.New("sharding: archive row count mismatch")
+
+	// ErrBackfillMismatch 回填后分表中的行数与源表不一致
+	ErrBackfillMismatch = errors.New("sharding: backfill row count mismatch")
+
+	// ErrAmbiguousStrategy 无法确定模型对应的分表策略（模型的表名没有注册策略）
+	ErrAmbiguousStrategy = errors.New("sharding: ambiguous sharding strategy")
+
+	// ErrAggregateOverflow 跨表合并整数 SUM 时超出 int64 的范围
+	ErrAggregateOverflow = errors.New("sharding: aggregate overflow")
+
+	// ErrTimeRangeRequired 没有指定时间范围，且无法从数据库中列出时间分表的所有分表
+	// （跨表操作不会只访问最近一年的分表而静默跳过更早的分表）
+	ErrTimeRangeRequired = errors.New("sharding: time range required")
+
+	// ErrUnsortedShardResult 分表返回的结果与归并时的比较顺序不一致（字符串按字节比较，NULL 最小），
+	// 通常是字符串排序列使用了非二进制的排序规则，继续归并会得到错误的分页结果
+	ErrUnsortedShardResult = errors.New("sharding: shard result is not in merge order")
+)
+
+// ShardError 单个分表的执行错误
+type ShardError struct {
+	Table string // 失败的分表
+	Err   error  // 该分表返回的错误
+}
+
+func (e *ShardError) Error() string {
+	return fmt.Sprintf("shard %s: %v", e.Table, e.Err)
+}
+
+func (e *ShardError) Unwrap() error {
+	return e.Err
+}
+
+// ShardErrors ContinueOnError 模式下所有失败分表的错误（按分表顺序排列）
+// 跨表操作返回该错误时，结果中仍包含成功的分表的数据；可以使用 errors.As 获取失败的分表
+type ShardErrors []*ShardError
+
+func (e ShardErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, shardErr := range e {
+		messages[i] = shardErr.Error()
+	}
+	return fmt.Sprintf("%d shard(s) failed: %s", len(e), strings.Join(messages, "; "))
+}
+
+// Unwrap 支持 errors.Is/errors.As 匹配任意分表的错误
+func (e ShardErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, shardErr := range e {
+		errs[i] = shardErr
+	}
+	return errs
+}
+
+// Tables 获取失败的分表名称
+func (e ShardErrors) Tables() []string {
+	tables := make([]string, len(e))
+	for i, shardErr := range e {
+		tables[i] = shardErr.Table
+	}
+	return tables
+}
+
+// IsPartialResult 判断跨表操作的错误是否表示部分结果（ContinueOnError 模式下部分分表失败，或分表所在的数据源被健康检查排除），
+// 此时结果中包含成功的分表的数据
+func IsPartialResult(err error) bool {
+	return isPartialShardError(err)
+}
+
+// isPartialShardError 判断是否为 ContinueOnError 模式下部分分表失败的错误（结果中包含成功的分表的数据）
+func isPartialShardError(err error) bool {
+	var shardErrs ShardErrors
+	return errors.As(err, &shardErrs)
+}
+
+// appendShardErrors 将 err 中的 ShardErrors 追加到 shardErr（用于合并多次执行的部分失败）
+func appendShardErrors(shardErr, err error) error {
+	var merged, more ShardErrors
+	errors.As(shardErr, &merged)
+	errors.As(err, &more)
+	merged = append(append(ShardErrors{}, merged...), more...)
+	if len(merged) == 0 {
+		return nil
+	}
+	return merged
+}
+
+// MySQL 错误码
+const (
+	mysqlErrTableExists  = 1050 // ER_TABLE_EXISTS_ERROR: Table already exists
+	mysqlErrBadTable     = 1051 // ER_BAD_TABLE_ERROR: Unknown table
+	mysqlErrBadField     = 1054 // ER_BAD_FIELD_ERROR: Unknown column
+	mysqlErrDupFieldName = 1060 // ER_DUP_FIELDNAME: Duplicate column name
+	mysqlErrDupKeyName   = 1061 // ER_DUP_KEYNAME: Duplicate key name
+	mysqlErrDupEntry     = 1062 // ER_DUP_ENTRY: Duplicate entry
+	mysqlErrNoSuchTable  = 1146 // ER_NO_SUCH_TABLE: Table doesn't exist
+)
+
+// PostgreSQL SQLSTATE
+const (
+	postgresUndefinedTable  = "42P01"
+	postgresUndefinedColumn = "42703"
+	postgresUniqueViolation = "23505"
+	postgresDuplicateObject = "42P07" // duplicate_table（索引与表共用名称空间）
+	postgresDuplicateColumn = "42701"
+)
+
+// sqlStateError 提供 SQLSTATE 的驱动错误（如 pgx 的 *pgconn.PgError）
+type sqlStateError interface {
+	SQLState() string
+}
+
+// ClassifyError 将驱动返回的错误归类为分表相关的哨兵错误
+// 表不存在时返回包装了 ErrShardTableNotFound 的错误，列不存在时返回包装了 ErrShardColumnNotFound 的错误，
+// 其他错误原样返回。可以使用 errors.Is 判断错误类型
+func ClassifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, ErrShardTableNotFound) || errors.Is(err, ErrShardColumnNotFound) {
+		return err
+	}
+
+	// MySQL：使用错误码判断，不依赖错误信息的语言
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case mysqlErrNoSuchTable, mysqlErrBadTable:
+			return fmt.Errorf("%w: %v", ErrShardTableNotFound, err)
+		case mysqlErrBadField:
+			return fmt.Errorf("%w: %v", ErrShardColumnNotFound, err)
+		}
+		return err
+	}
+
+	// PostgreSQL：使用 SQLSTATE 判断
+	var stateErr sqlStateError
+	if errors.As(err, &stateErr) {
+		switch stateErr.SQLState() {
+		case postgresUndefinedTable:
+			return fmt.Errorf("%w: %v", ErrShardTableNotFound, err)
+		case postgresUndefinedColumn:
+			return fmt.Errorf("%w: %v", ErrShardColumnNotFound, err)
+		}
+		return err
+	}
+
+	// 其他驱动：退回到错误信息匹配
+	errMsg := strings.ToLower(err.Error())
+	if strings.Contains(errMsg, "doesn't exist") ||
+		strings.Contains(errMsg, "no such table") ||
+		strings.Contains(errMsg, "unknown table") ||
+		strings.Contains(errMsg, "table") && strings.Contains(errMsg, "not found") {
+		return fmt.Errorf("%w: %v", ErrShardTableNotFound, err)
+	}
+	if strings.Contains(errMsg, "unknown column") || strings.Contains(errMsg, "no such column") {
+		return fmt.Errorf("%w: %v", ErrShardColumnNotFound, err)
+	}
+
+	return err
+}
+
+// isTableNotFoundError 判断是否为分表不存在的错误
+func isTableNotFoundError(err error) bool {
+	return errors.Is(ClassifyError(err), ErrShardTableNotFound)
+}
+
+// isColumnNotFoundError 判断是否为列不存在的错误
+func isColumnNotFoundError(err error) bool {
+	return errors.Is(ClassifyError(err), ErrShardColumnNotFound)
+}
+
+// isDuplicateKeyError 判断是否为违反唯一约束的错误
+func isDuplicateKeyError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return true
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == mysqlErrDupEntry
+	}
+	var stateErr sqlStateError
+	if errors.As(err, &stateErr) {
+		return stateErr.SQLState() == postgresUniqueViolation
+	}
+
+	errMsg := strings.ToLower(err.Error())
+	return strings.Contains(errMsg, "duplicate") || strings.Contains(errMsg, "unique constraint")
+}
+
+// isDuplicateObjectError 判断是否为 DDL 创建的表、索引或列已存在的错误
+func isDuplicateObjectError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == mysqlErrDupKeyName || mysqlErr.Number == mysqlErrDupFieldName ||
+			mysqlErr.Number == mysqlErrTableExists
+	}
+	var stateErr sqlStateError
+	if errors.As(err, &stateErr) {
+		return stateErr.SQLState() == postgresDuplicateObject || stateErr.SQLState() == postgresDuplicateColumn
+	}
+
+	errMsg := strings.ToLower(err.Error())
+	return strings.Contains(errMsg, "duplicate key name") || strings.Contains(errMsg, "duplicate column") ||
+		strings.Contains(errMsg, "already exists")
+}
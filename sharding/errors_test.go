@@ -0,0 +1,59 @@
+package sharding
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// testStateError 提供 SQLSTATE 的驱动错误（与 pgx 的 *pgconn.PgError 相同的接口）
+type testStateError struct{ state string }
+
+func (e *testStateError) Error() string    { return "ERROR (SQLSTATE " + e.state + ")" }
+func (e *testStateError) SQLState() string { return e.state }
+
+func TestClassifyError(t *testing.T) {
+	other := errors.New("connection refused")
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"mysql no such table", &mysql.MySQLError{Number: 1146, Message: "Table 'db.users_9' doesn't exist"}, ErrShardTableNotFound},
+		{"mysql bad table", &mysql.MySQLError{Number: 1051}, ErrShardTableNotFound},
+		{"mysql bad field", &mysql.MySQLError{Number: 1054}, ErrShardColumnNotFound},
+		{"mysql localized message", &mysql.MySQLError{Number: 1146, Message: "表不存在"}, ErrShardTableNotFound},
+		{"mysql other", &mysql.MySQLError{Number: 1062, Message: "Duplicate entry"}, nil},
+		{"wrapped mysql", fmt.Errorf("query users_1: %w", &mysql.MySQLError{Number: 1146}), ErrShardTableNotFound},
+		{"postgres undefined table", &testStateError{postgresUndefinedTable}, ErrShardTableNotFound},
+		{"postgres undefined column", &testStateError{postgresUndefinedColumn}, ErrShardColumnNotFound},
+		{"postgres other", &testStateError{postgresUniqueViolation}, nil},
+		{"sqlite table", errors.New("no such table: users_1"), ErrShardTableNotFound},
+		{"sqlite column", errors.New("no such column: age"), ErrShardColumnNotFound},
+		{"other", other, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ClassifyError(tt.err)
+			if tt.want == nil {
+				if got != tt.err {
+					t.Errorf("got %v, want the original error", got)
+				}
+				return
+			}
+			if !errors.Is(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			// 已归类的错误不会再次包装
+			if ClassifyError(got) != got {
+				t.Errorf("classifying %v again changed it", got)
+			}
+		})
+	}
+
+	if ClassifyError(nil) != nil {
+		t.Error("ClassifyError(nil) != nil")
+	}
+}
@@ -0,0 +1,135 @@
+package sharding
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ExportFormat 导出格式
+type ExportFormat string
+
+const (
+	ExportCSV   ExportFormat = "csv"   // CSV，第一行为列名
+	ExportJSONL ExportFormat = "jsonl" // 每行一个 JSON 对象
+)
+
+// ExportOptions 导出选项
+type ExportOptions struct {
+	Format       ExportFormat // 导出格式（默认 CSV）
+	Columns      []string     // 导出的列（默认为第一个有数据的分表的所有列）
+	IncludeTable bool         // 是否增加 _shard 列，记录每行所在的分表
+
+	ShardQueryOptions // 各分表的执行选项（多数据源、重试、熔断等，分表总是逐个读取）
+}
+
+// exportShardColumn 记录所在分表的列名
+const exportShardColumn = "_shard"
+
+// CrossTableExport 跨表导出，逐个分表流式读取并写入 w，返回导出的行数
+// 导出前按 SetMasking 为该基础表配置的脱敏规则处理每一行，避免调试用的数据导出泄露个人信息
+// 设置 ContinueOnError 时跳过失败的分表，返回导出的行数和 ShardErrors
+func CrossTableExport(db *gorm.DB, strategy ShardingStrategy, w io.Writer, queryBuilder QueryBuilder, options ...ExportOptions) (int64, error) {
+	opts := ExportOptions{}
+	if len(options) > 0 {
+		opts = options[0]
+	}
+	if opts.Format == "" {
+		opts.Format = ExportCSV
+	}
+	if opts.Format != ExportCSV && opts.Format != ExportJSONL {
+		return 0, fmt.Errorf("unsupported export format: %s", opts.Format)
+	}
+
+	rows, err := CrossTableRows(db, strategy, queryBuilder, opts.ShardQueryOptions)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var (
+		count      int64
+		columns    = opts.Columns
+		csvWriter  *csv.Writer
+		jsonWriter = json.NewEncoder(w)
+	)
+	if opts.Format == ExportCSV {
+		csvWriter = csv.NewWriter(w)
+	}
+
+	baseTableName := strategy.GetBaseTableName()
+	for rows.Next() {
+		row := make(map[string]interface{})
+		if err := rows.Scan(&row); err != nil {
+			return count, fmt.Errorf("failed to scan row from table %s: %w", rows.Table(), err)
+		}
+		for column, value := range row {
+			row[column] = exportValue(value)
+		}
+		MaskRow(baseTableName, row)
+		if opts.IncludeTable {
+			row[exportShardColumn] = rows.Table()
+		}
+
+		if columns == nil {
+			columns, err = rows.Columns()
+			if err != nil {
+				return count, err
+			}
+			if opts.IncludeTable {
+				columns = append(columns, exportShardColumn)
+			}
+		}
+
+		if csvWriter != nil {
+			if count == 0 {
+				if err := csvWriter.Write(columns); err != nil {
+					return count, err
+				}
+			}
+			record := make([]string, len(columns))
+			for i, column := range columns {
+				if value := row[column]; value != nil {
+					record[i] = fmt.Sprint(value)
+				}
+			}
+			if err := csvWriter.Write(record); err != nil {
+				return count, err
+			}
+		} else {
+			selected := make(map[string]interface{}, len(columns))
+			for _, column := range columns {
+				selected[column] = row[column]
+			}
+			if err := jsonWriter.Encode(selected); err != nil {
+				return count, err
+			}
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return count, err
+	}
+
+	if csvWriter != nil {
+		csvWriter.Flush()
+		return count, csvWriter.Error()
+	}
+	return count, nil
+}
+
+// exportValue 将扫描到的值转换为适合导出的形式（文本列的 []byte 转换为字符串，时间使用 RFC 3339）
+func exportValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		return v
+	}
+}
@@ -0,0 +1,326 @@
+package sharding
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	fieldEncryptionPluginName = "x2-sharding:field-encryption"
+
+	// encryptedValuePrefix 加密后的列值格式：enc:v1:<密钥 ID>:<base64 密文>
+	encryptedValuePrefix = "enc:v1:"
+)
+
+// FieldEncryptor 字段加解密接口（如基于 KMS 的信封加密）
+// table 为基础表名（不含分表后缀），同一行移动到其他分表后仍可以解密
+type FieldEncryptor interface {
+	// Encrypt 加密字段值，返回密文和加密使用的密钥 ID（不能包含 ':'）
+	Encrypt(ctx context.Context, table, column string, plaintext []byte) (ciphertext []byte, keyID string, err error)
+	// Decrypt 使用密钥 ID 对应的密钥解密字段值
+	Decrypt(ctx context.Context, table, column, keyID string, ciphertext []byte) ([]byte, error)
+}
+
+// FieldEncryptionOptions 字段加密配置
+type FieldEncryptionOptions struct {
+	Encryptor  FieldEncryptor      // 加解密实现
+	Fields     map[string][]string // 基础表名 -> 需要加密的字段（字段名或列名，类型为 string 或 []byte）
+	KeyIDField map[string]string   // 基础表名 -> 记录本行密钥 ID 的字段（可选，写入时设置，读取时按密文回填）
+}
+
+// FieldEncryption 字段加密插件，实现 gorm.Plugin 接口
+// 在分表路由之后、写入之前加密配置的字段，在查询扫描之后解密，因此 CrossTableQuery 等跨表查询合并的结果也是明文。
+// 写入完成后模型中的字段会恢复为明文；不含加密前缀的旧数据按明文原样返回，便于逐步启用加密。
+// 注意：加密字段不能用于 WHERE 条件或排序，也不要加密分表键
+// 用法：db.Use(sharding.NewFieldEncryption(sharding.FieldEncryptionOptions{...}))
+type FieldEncryption struct {
+	options FieldEncryptionOptions
+}
+
+// NewFieldEncryption 创建字段加密插件
+func NewFieldEncryption(options FieldEncryptionOptions) *FieldEncryption {
+	return &FieldEncryption{options: options}
+}
+
+// Name 插件名称（gorm.Plugin 接口）
+func (e *FieldEncryption) Name() string {
+	return fieldEncryptionPluginName
+}
+
+// Initialize 注册加解密回调（gorm.Plugin 接口）
+func (e *FieldEncryption) Initialize(db *gorm.DB) error {
+	if e.options.Encryptor == nil {
+		return fmt.Errorf("field encryptor is required")
+	}
+
+	for baseTableName := range e.options.Fields {
+		baseTableName := baseTableName
+		encrypt := func(db *gorm.DB) { e.apply(db, baseTableName, true) }
+		decrypt := func(db *gorm.DB) { e.apply(db, baseTableName, false) }
+
+		// 加密在分表路由之后执行，写入完成后将模型恢复为明文
+		err := db.Callback().Create().Before("gorm:create").After("sharding:create:"+baseTableName).
+			Register("sharding:encrypt:create:"+baseTableName, encrypt)
+		if err == nil {
+			err = db.Callback().Create().After("gorm:create").Register("sharding:decrypt:create:"+baseTableName, decrypt)
+		}
+		if err == nil {
+			err = db.Callback().Update().Before("gorm:update").After("sharding:update:"+baseTableName).
+				Register("sharding:encrypt:update:"+baseTableName, encrypt)
+		}
+		if err == nil {
+			err = db.Callback().Update().After("gorm:update").Register("sharding:decrypt:update:"+baseTableName, decrypt)
+		}
+		if err == nil {
+			err = db.Callback().Query().After("gorm:query").Register("sharding:decrypt:query:"+baseTableName, decrypt)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to register field encryption callbacks for table %s: %w", baseTableName, err)
+		}
+	}
+	return nil
+}
+
+// DecryptFields 解密 dest 中的加密字段（用于不经过 GORM 查询回调的结果，如 ShardRows.Scan）
+func (e *FieldEncryption) DecryptFields(db *gorm.DB, baseTableName string, dest interface{}) error {
+	if len(e.options.Fields[baseTableName]) == 0 {
+		return nil
+	}
+	stmt := &gorm.Statement{DB: db, Context: db.Statement.Context, Dest: dest}
+	if err := stmt.Parse(dest); err != nil {
+		if errors.Is(err, schema.ErrUnsupportedDataType) {
+			// map 等非结构体结果不处理
+			return nil
+		}
+		return fmt.Errorf("failed to parse model schema: %w", err)
+	}
+	stmt.ReflectValue = reflect.ValueOf(dest)
+	return e.transform(stmt, baseTableName, false)
+}
+
+// getFieldEncryption 获取已注册的字段加密插件
+func getFieldEncryption(db *gorm.DB) *FieldEncryption {
+	if plugin, ok := db.Config.Plugins[fieldEncryptionPluginName]; ok {
+		if encryption, ok := plugin.(*FieldEncryption); ok {
+			return encryption
+		}
+	}
+	return nil
+}
+
+// apply 在回调中对当前语句执行加密或解密
+func (e *FieldEncryption) apply(db *gorm.DB, baseTableName string, encrypt bool) {
+	// 写入失败时同样需要把模型恢复为明文，只在加密前检查错误
+	if encrypt && db.Error != nil {
+		return
+	}
+	if statementBaseTable(db.Statement) != baseTableName {
+		return
+	}
+	if err := e.transform(db.Statement, baseTableName, encrypt); err != nil {
+		db.AddError(err)
+	}
+}
+
+// statementBaseTable 获取语句对应的基础表名（优先使用模型的表名，否则去掉分表后缀）
+func statementBaseTable(stmt *gorm.Statement) string {
+	if stmt.Schema != nil && stmt.Schema.Table != "" {
+		return stmt.Schema.Table
+	}
+	if index := strings.LastIndex(stmt.Table, "_"); index > 0 {
+		return stmt.Table[:index]
+	}
+	return stmt.Table
+}
+
+// transform 加密或解密语句中的模型（结构体、结构体切片）或更新使用的 map
+func (e *FieldEncryption) transform(stmt *gorm.Statement, baseTableName string, encrypt bool) error {
+	ctx := stmt.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	columns := e.options.Fields[baseTableName]
+
+	// Updates(map[string]interface{})：复制 map 后再加密，避免修改调用方的 map
+	if values, ok := stmt.Dest.(map[string]interface{}); ok {
+		if !encrypt {
+			return nil
+		}
+		encrypted := make(map[string]interface{}, len(values))
+		for key, value := range values {
+			encrypted[key] = value
+			if !containsColumn(stmt.Schema, columns, key) {
+				continue
+			}
+			plaintext, ok := fieldBytes(value)
+			if !ok || len(plaintext) == 0 {
+				continue
+			}
+			column := key
+			if stmt.Schema != nil {
+				if field := stmt.Schema.LookUpField(key); field != nil {
+					column = field.DBName
+				}
+			}
+			envelope, _, err := e.encrypt(ctx, baseTableName, column, plaintext)
+			if err != nil {
+				return err
+			}
+			encrypted[key] = envelope
+		}
+		stmt.Dest = encrypted
+		return nil
+	}
+
+	if stmt.Schema == nil {
+		return nil
+	}
+	fields := make([]*schema.Field, 0, len(columns))
+	for _, column := range columns {
+		if field := stmt.Schema.LookUpField(column); field != nil {
+			fields = append(fields, field)
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	var keyIDField *schema.Field
+	if name, ok := e.options.KeyIDField[baseTableName]; ok {
+		keyIDField = stmt.Schema.LookUpField(name)
+	}
+
+	return forEachStructRow(stmt.ReflectValue, func(row reflect.Value) error {
+		for _, field := range fields {
+			value, isZero := field.ValueOf(ctx, row)
+			if isZero {
+				continue
+			}
+			data, ok := fieldBytes(value)
+			if !ok {
+				continue
+			}
+
+			var (
+				result string
+				keyID  string
+				err    error
+			)
+			if encrypt {
+				if strings.HasPrefix(string(data), encryptedValuePrefix) {
+					continue
+				}
+				result, keyID, err = e.encrypt(ctx, baseTableName, field.DBName, data)
+			} else {
+				if !strings.HasPrefix(string(data), encryptedValuePrefix) {
+					continue
+				}
+				result, keyID, err = e.decrypt(ctx, baseTableName, field.DBName, string(data))
+			}
+			if err != nil {
+				return err
+			}
+
+			if _, isBytes := value.([]byte); isBytes {
+				err = field.Set(ctx, row, []byte(result))
+			} else {
+				err = field.Set(ctx, row, result)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to set field %s: %w", field.Name, err)
+			}
+			if keyIDField != nil {
+				if err := keyIDField.Set(ctx, row, keyID); err != nil {
+					return fmt.Errorf("failed to set key id field %s: %w", keyIDField.Name, err)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// encrypt 加密并编码为 enc:v1:<密钥 ID>:<base64 密文>
+func (e *FieldEncryption) encrypt(ctx context.Context, table, column string, plaintext []byte) (string, string, error) {
+	ciphertext, keyID, err := e.options.Encryptor.Encrypt(ctx, table, column, plaintext)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encrypt %s.%s: %w", table, column, err)
+	}
+	if strings.Contains(keyID, ":") {
+		return "", "", fmt.Errorf("invalid key id %q: must not contain ':'", keyID)
+	}
+	return encryptedValuePrefix + keyID + ":" + base64.StdEncoding.EncodeToString(ciphertext), keyID, nil
+}
+
+// decrypt 解码并解密 enc:v1:<密钥 ID>:<base64 密文>
+func (e *FieldEncryption) decrypt(ctx context.Context, table, column, envelope string) (string, string, error) {
+	keyID, encoded, ok := strings.Cut(strings.TrimPrefix(envelope, encryptedValuePrefix), ":")
+	if !ok {
+		return "", "", fmt.Errorf("invalid encrypted value in %s.%s", table, column)
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid encrypted value in %s.%s: %w", table, column, err)
+	}
+	plaintext, err := e.options.Encryptor.Decrypt(ctx, table, column, keyID, ciphertext)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to decrypt %s.%s with key %s: %w", table, column, keyID, err)
+	}
+	return string(plaintext), keyID, nil
+}
+
+// containsColumn 判断 map 的键（字段名或列名）是否为需要加密的字段
+func containsColumn(s *schema.Schema, columns []string, key string) bool {
+	for _, column := range columns {
+		if strings.EqualFold(column, key) {
+			return true
+		}
+		if s != nil {
+			if field := s.LookUpField(column); field != nil && (field.DBName == key || field.Name == key) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fieldBytes 获取 string、[]byte（以及对应指针）类型字段的字节
+func fieldBytes(value interface{}) ([]byte, bool) {
+	switch v := value.(type) {
+	case string:
+		return []byte(v), true
+	case *string:
+		if v == nil {
+			return nil, false
+		}
+		return []byte(*v), true
+	case []byte:
+		return v, true
+	default:
+		return nil, false
+	}
+}
+
+// forEachStructRow 对结构体或结构体切片中的每个结构体执行 fn
+func forEachStructRow(rv reflect.Value, fn func(row reflect.Value) error) error {
+	rv = reflect.Indirect(rv)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			row := reflect.Indirect(rv.Index(i))
+			if row.Kind() == reflect.Struct {
+				if err := fn(row); err != nil {
+					return err
+				}
+			}
+		}
+	case reflect.Struct:
+		return fn(rv)
+	}
+	return nil
+}
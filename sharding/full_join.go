@@ -0,0 +1,118 @@
+package sharding
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// emulatedFullJoinIndex 返回需要模拟的 FULL JOIN 在 config.JoinTables 中的序号
+// 数据库原生支持 FULL OUTER JOIN 或没有 FULL JOIN 时返回 -1；模拟时只支持一个 FULL JOIN
+func emulatedFullJoinIndex(db *gorm.DB, config MultiJoinConfig) (int, error) {
+	index := -1
+	for i, joinInfo := range config.JoinTables {
+		if joinInfo.JoinType != FullJoin {
+			continue
+		}
+		if index >= 0 {
+			return -1, fmt.Errorf("%w: only one FULL JOIN can be emulated on %s", ErrInvalidJoin, db.Dialector.Name())
+		}
+		index = i
+	}
+	if index >= 0 && GetCapabilities(db, nil).FullOuterJoin {
+		return -1, nil
+	}
+	return index, nil
+}
+
+// splitFullJoin 把第 index 个连接表的 FULL JOIN 拆分为两个不重叠的查询：
+// LEFT JOIN（左侧所有行及匹配的右侧行）和 RIGHT JOIN 反连接（右侧没有匹配的行，antiJoin 为其 WHERE 条件）
+func splitFullJoin(db *gorm.DB, config MultiJoinConfig, index int) (left, right MultiJoinConfig, antiJoin string, err error) {
+	column, err := fullJoinLeftColumn(db, config, index)
+	if err != nil {
+		return left, right, "", err
+	}
+
+	left, right = config, config
+	left.JoinTables = append([]JoinInfo(nil), config.JoinTables...)
+	right.JoinTables = append([]JoinInfo(nil), config.JoinTables...)
+	left.JoinTables[index].JoinType = LeftJoin
+	right.JoinTables[index].JoinType = RightJoin
+	return left, right, column + " IS NULL", nil
+}
+
+// fullJoinLeftColumn 获取 FULL JOIN 的 ON 条件中左侧表的连接列（已替换为别名并引用）
+// 右侧行没有匹配时该列为 NULL，用于 RIGHT JOIN 反连接
+func fullJoinLeftColumn(db *gorm.DB, config MultiJoinConfig, index int) (string, error) {
+	joinInfo := config.JoinTables[index]
+
+	// 左侧的表：主表和之前连接的表（条件中可以使用基础表名或别名）
+	aliases := make(map[string]string, 2*(index+1))
+	for _, previous := range append([]JoinInfo{config.MainTable}, config.JoinTables[:index]...) {
+		aliases[strings.ToLower(previous.getBaseTableName())] = previous.getAlias()
+		aliases[strings.ToLower(previous.getAlias())] = previous.getAlias()
+	}
+	current := map[string]bool{
+		strings.ToLower(joinInfo.getBaseTableName()): true,
+		strings.ToLower(joinInfo.getAlias()):         true,
+	}
+
+	for _, on := range joinInfo.joinEqualities() {
+		leftTable, leftCol := on.LeftTable, on.LeftCol
+		if current[strings.ToLower(leftTable)] {
+			leftTable, leftCol = on.RightTable, on.RightCol
+		}
+		if alias, ok := aliases[strings.ToLower(leftTable)]; ok {
+			return db.Statement.Quote(alias) + "." + db.Statement.Quote(leftCol), nil
+		}
+	}
+	return "", fmt.Errorf("%w: emulating FULL JOIN on %s requires an equality ON condition with a joined table", ErrInvalidJoin, joinInfo.getBaseTableName())
+}
+
+// queryFullJoinCombination 对单个表组合执行模拟的 FULL JOIN 查询（LEFT JOIN ∪ RIGHT JOIN 反连接）
+// 两个查询的结果不重叠，合并后的结果再按 Deduplicator 去重
+func queryFullJoinCombination(db *gorm.DB, config MultiJoinConfig, index int, combination []string, queryBuilder QueryBuilder) ([]map[string]interface{}, error) {
+	left, right, antiJoin, err := splitFullJoin(db, config, index)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := queryMultiJoinCombination(db, left, combination, queryBuilder)
+	if err != nil {
+		return nil, err
+	}
+	unmatched, err := queryMultiJoinCombination(db, right, combination, antiJoinBuilder(queryBuilder, antiJoin))
+	if err != nil {
+		return nil, err
+	}
+	return append(results, unmatched...), nil
+}
+
+// countFullJoinCombination 对单个表组合执行模拟的 FULL JOIN 计数（两个不重叠的查询的计数之和）
+func countFullJoinCombination(db *gorm.DB, config MultiJoinConfig, index int, combination []string, countExpr string, queryBuilder QueryBuilder) (int64, error) {
+	left, right, antiJoin, err := splitFullJoin(db, config, index)
+	if err != nil {
+		return 0, err
+	}
+
+	matched, err := countMultiJoinCombination(db, left, combination, countExpr, queryBuilder)
+	if err != nil {
+		return 0, err
+	}
+	unmatched, err := countMultiJoinCombination(db, right, combination, countExpr, antiJoinBuilder(queryBuilder, antiJoin))
+	if err != nil {
+		return 0, err
+	}
+	return matched + unmatched, nil
+}
+
+// antiJoinBuilder 在查询构建器之外添加反连接条件
+func antiJoinBuilder(queryBuilder QueryBuilder, antiJoin string) QueryBuilder {
+	return func(db *gorm.DB) *gorm.DB {
+		if queryBuilder != nil {
+			db = queryBuilder(db)
+		}
+		return db.Where(antiJoin)
+	}
+}
@@ -0,0 +1,118 @@
+package sharding
+
+import (
+	"sort"
+	"strings"
+)
+
+// GeoShardingOptions 地区分表配置
+type GeoShardingOptions struct {
+	Groups  map[string][]string // 地区组 -> 地区代码（如 "eu": {"DE", "FR"}），组名作为分表后缀；地区出现在多个组中时使用组名排序最前的组
+	Regions map[string]string   // 地区代码 -> 分表后缀（单独配置，优先于 Groups）
+	Default string              // 未配置的地区使用的分表后缀（默认 "default"）
+}
+
+// GeoShardingStrategy 地区分表策略，按地区或国家代码路由到地区组的分表（如 orders_eu、orders_us、orders_apac）
+// 地区代码不区分大小写，首尾空格会被忽略
+type GeoShardingStrategy struct {
+	list *ListShardingStrategy // 地区代码（大写）-> 分表后缀
+
+	ShardOptionsMap // 分表级别的建表选项（通过 SetShardOptions 配置）
+}
+
+// NewGeoShardingStrategy 创建地区分表策略
+// baseTableName: 基础表名（如 "orders"）
+// regionField: 地区字段名（如 "region"、"country"）
+func NewGeoShardingStrategy(baseTableName, regionField string, options GeoShardingOptions) *GeoShardingStrategy {
+	strategy := &GeoShardingStrategy{
+		list: NewListShardingStrategy(baseTableName, regionField, nil),
+	}
+	if options.Default != "" {
+		strategy.list.SetDefaultTable(options.Default)
+	}
+	// 按组名顺序分配，出现在多个组中的地区使用第一个组（结果与 map 的遍历顺序无关）
+	groups := make([]string, 0, len(options.Groups))
+	for group := range options.Groups {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	assigned := make(map[string]bool)
+	for _, group := range groups {
+		for _, region := range options.Groups[group] {
+			if !assigned[normalizeRegion(region)] {
+				assigned[normalizeRegion(region)] = true
+				strategy.SetRegion(region, group)
+			}
+		}
+	}
+	for region, suffix := range options.Regions {
+		strategy.SetRegion(region, suffix)
+	}
+	return strategy
+}
+
+// SetRegion 设置地区的分表后缀（地区组名或单独的后缀），可以在运行时调整
+func (s *GeoShardingStrategy) SetRegion(region, suffix string) {
+	s.list.AddMapping(normalizeRegion(region), suffix)
+}
+
+// RemoveRegion 删除地区的配置，之后该地区路由到默认分表
+func (s *GeoShardingStrategy) RemoveRegion(region string) {
+	s.list.RemoveMapping(normalizeRegion(region))
+}
+
+// GroupOf 获取地区所属的分表后缀（未配置时返回默认分表后缀）
+func (s *GeoShardingStrategy) GroupOf(region string) string {
+	s.list.mu.RLock()
+	defer s.list.mu.RUnlock()
+
+	if suffix, ok := s.list.mapping[normalizeRegion(region)]; ok {
+		return suffix
+	}
+	return s.list.defaultSuffix
+}
+
+// TablesForRegions 获取多个地区的数据所在的分表（去重并排序），用于只查询部分地区的跨表查询
+func (s *GeoShardingStrategy) TablesForRegions(regions ...string) []string {
+	seen := make(map[string]bool)
+	tableNames := make([]string, 0, len(regions))
+	for _, region := range regions {
+		tableName := s.GetTableName(s.GetBaseTableName(), region)
+		if !seen[tableName] {
+			seen[tableName] = true
+			tableNames = append(tableNames, tableName)
+		}
+	}
+	sort.Strings(tableNames)
+	return tableNames
+}
+
+// GetTableName 根据地区代码获取实际表名
+func (s *GeoShardingStrategy) GetTableName(baseTableName string, shardingValue interface{}) string {
+	return s.list.GetTableName(baseTableName, normalizeRegion(listValueKey(shardingValue)))
+}
+
+// GetAllTableNames 获取所有分表名称（所有地区组的分表和默认分表，按名称排序）
+func (s *GeoShardingStrategy) GetAllTableNames(baseTableName string) []string {
+	return s.list.GetAllTableNames(baseTableName)
+}
+
+// GetShardingValue 从模型对象中提取地区代码
+func (s *GeoShardingStrategy) GetShardingValue(value interface{}) (interface{}, error) {
+	return s.list.GetShardingValue(value)
+}
+
+// GetBaseTableName 获取基础表名
+func (s *GeoShardingStrategy) GetBaseTableName() string {
+	return s.list.GetBaseTableName()
+}
+
+// GetShardingKey 获取分表键字段名
+func (s *GeoShardingStrategy) GetShardingKey() string {
+	return s.list.GetShardingKey()
+}
+
+// normalizeRegion 规范化地区代码（去掉首尾空格并转为大写）
+func normalizeRegion(region string) string {
+	return strings.ToUpper(strings.TrimSpace(region))
+}
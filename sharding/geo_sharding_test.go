@@ -0,0 +1,43 @@
+package sharding
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGeoShardingOverlappingGroups(t *testing.T) {
+	// 多次创建，结果不受 map 遍历顺序影响：DE 使用组名排序最前的 eu
+	for i := 0; i < 20; i++ {
+		strategy := NewGeoShardingStrategy("orders", "Region", GeoShardingOptions{
+			Groups: map[string][]string{
+				"eu":    {"DE", "FR"},
+				"north": {"de", "SE"},
+				"us":    {"US"},
+			},
+			Regions: map[string]string{"SE": "nordic"},
+		})
+		if got := strategy.GroupOf(" de "); got != "eu" {
+			t.Fatalf("GroupOf(de) = %s, want eu", got)
+		}
+		if got := strategy.GroupOf("se"); got != "nordic" {
+			t.Fatalf("GroupOf(se) = %s, want nordic", got)
+		}
+	}
+}
+
+func TestGeoTablesForRegions(t *testing.T) {
+	strategy := NewGeoShardingStrategy("orders", "Region", GeoShardingOptions{
+		Groups:  map[string][]string{"eu": {"DE", "FR"}, "us": {"US"}},
+		Default: "rest",
+	})
+	want := []string{"orders_eu", "orders_rest", "orders_us"}
+	if got := strategy.TablesForRegions("us", "FR", "de", "BR"); !reflect.DeepEqual(got, want) {
+		t.Errorf("TablesForRegions = %v, want %v", got, want)
+	}
+	for _, region := range []string{"us", "FR", "BR"} {
+		tableName := strategy.GetTableName("orders", region)
+		if got := strategy.TablesForRegions(region); !reflect.DeepEqual(got, []string{tableName}) {
+			t.Errorf("TablesForRegions(%s) = %v, want [%s]", region, got, tableName)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package sharding
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// GroupAggregate 分组聚合表达式
+type GroupAggregate struct {
+	Aggregation
+	Alias string // 结果中的列名（默认为 "函数_列名"，如 sum_amount；COUNT(*) 默认为 count）
+}
+
+// GroupByOptions 跨表分组选项
+type GroupByOptions struct {
+	ShardQueryOptions
+	Having func(row map[string]interface{}) bool // 合并后的 HAVING 条件（可选），返回 false 的分组会被过滤
+}
+
+// CrossTableGroupBy 跨表分组聚合
+// 在每个分表中按分组列执行部分聚合，然后在内存中按分组重新聚合（COUNT/SUM 相加，AVG 通过 SUM/COUNT 重新计算，MIN/MAX 逐个比较），
+// 最后应用 HAVING 条件。HAVING 必须在合并之后判断，不能通过 queryBuilder 下推到分表
+// 返回的每一行包含分组列和聚合列，分组按首次出现的顺序排列；
+// 部分分表失败时（ContinueOnError，或数据源被健康检查排除、分表熔断）返回成功分表的合并结果和 ShardErrors
+func CrossTableGroupBy(
+	db *gorm.DB,
+	strategy ShardingStrategy,
+	groupColumns []string,
+	aggregates []GroupAggregate,
+	queryBuilder QueryBuilder,
+	options ...GroupByOptions,
+) ([]map[string]interface{}, error) {
+	if len(groupColumns) == 0 {
+		return nil, fmt.Errorf("group columns are required")
+	}
+	if len(aggregates) == 0 {
+		return nil, fmt.Errorf("at least one aggregate is required")
+	}
+
+	opts := GroupByOptions{}
+	if len(options) > 0 {
+		opts = options[0]
+	}
+
+	// 构建每个分表的 SELECT：分组列 + 部分聚合列（使用内部别名）
+	selects := make([]string, 0, len(groupColumns)+len(aggregates)*2)
+	groupBy := make([]string, 0, len(groupColumns))
+	for _, column := range groupColumns {
+		quoted := db.Statement.Quote(column)
+		selects = append(selects, quoted+" AS "+db.Statement.Quote(columnName(column)))
+		groupBy = append(groupBy, quoted)
+	}
+
+	aliases := make([]string, len(aggregates))
+	partialAliases := make([][]string, len(aggregates))
+	for i, aggregate := range aggregates {
+		aliases[i] = aggregate.Alias
+		if aliases[i] == "" {
+			aliases[i] = defaultAggregateAlias(aggregate.Aggregation)
+		}
+
+		partialSelects, err := aggregationSelects(db, aggregate.Aggregation)
+		if err != nil {
+			return nil, err
+		}
+		for j, partialSelect := range partialSelects {
+			alias := fmt.Sprintf("__agg_%d_%d", i, j)
+			selects = append(selects, partialSelect+" AS "+alias)
+			partialAliases[i] = append(partialAliases[i], alias)
+		}
+	}
+
+	type groupState struct {
+		values []interface{}
+		states []*aggregateState
+	}
+	groupOrder := make([]string, 0)
+	groups := make(map[string]*groupState)
+
+	tableNames, err := allShardTables(db, strategy)
+	if err != nil {
+		return nil, err
+	}
+	shardRows := make([][]map[string]interface{}, len(tableNames))
+	queryErr := runOnShards(db, tableNames, opts.ShardQueryOptions, func(tx *gorm.DB, index int, tableName string) error {
+		query := tx.Table(tableName)
+		if queryBuilder != nil {
+			query = queryBuilder(query)
+		}
+
+		var rows []map[string]interface{}
+		if err := query.Select(strings.Join(selects, ", ")).Group(strings.Join(groupBy, ", ")).Find(&rows).Error; err != nil {
+			return err
+		}
+		shardRows[index] = rows
+		return nil
+	})
+	if queryErr != nil && !isPartialShardError(queryErr) {
+		return nil, queryErr
+	}
+
+	// 按分表顺序合并（部分分表失败时只包含成功的分表）
+	for _, rows := range shardRows {
+		for _, row := range rows {
+			values := make([]interface{}, len(groupColumns))
+			keyParts := make([]string, len(groupColumns))
+			for i, column := range groupColumns {
+				values[i] = normalizeScannedValue(row[columnName(column)])
+				keyParts[i] = fmt.Sprintf("%T:%v", values[i], values[i])
+			}
+			key := strings.Join(keyParts, "\x00")
+
+			group, ok := groups[key]
+			if !ok {
+				group = &groupState{values: values, states: make([]*aggregateState, len(aggregates))}
+				for i := range group.states {
+					group.states[i] = &aggregateState{}
+				}
+				groups[key] = group
+				groupOrder = append(groupOrder, key)
+			}
+
+			for i, aggregate := range aggregates {
+				partial := make([]interface{}, len(partialAliases[i]))
+				for j, alias := range partialAliases[i] {
+					partial[j] = row[alias]
+				}
+				if err := group.states[i].merge(aggregate.Func, partial); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	results := make([]map[string]interface{}, 0, len(groupOrder))
+	for _, key := range groupOrder {
+		group := groups[key]
+		result := make(map[string]interface{}, len(groupColumns)+len(aggregates))
+		for i, column := range groupColumns {
+			result[columnName(column)] = group.values[i]
+		}
+		for i, aggregate := range aggregates {
+			result[aliases[i]] = group.states[i].result(aggregate.Func)
+		}
+
+		if opts.Having != nil && !opts.Having(result) {
+			continue
+		}
+		results = append(results, result)
+	}
+
+	return results, queryErr
+}
+
+// defaultAggregateAlias 聚合列的默认别名
+func defaultAggregateAlias(aggregation Aggregation) string {
+	name := strings.ToLower(string(aggregation.Func))
+	if aggregation.Column == "" || aggregation.Column == "*" {
+		return name
+	}
+	return name + "_" + columnName(aggregation.Column)
+}
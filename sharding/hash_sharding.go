@@ -1,30 +1,72 @@
 package sharding
 
 import (
+	"encoding/binary"
 	"fmt"
+	"hash/crc32"
 	"hash/fnv"
 	"reflect"
 )
 
+// HashFunc 将编码后的分表键映射为哈希值
+type HashFunc func(data []byte) uint64
+
+// KeyEncoder 将分表键值编码为参与哈希的字节
+type KeyEncoder func(value interface{}) []byte
+
+// HashShardingOptions Hash 分表选项
+// 从其他分表中间件迁移时，可以指定相同的哈希函数（如 xxhash、murmur3、crc32）和键编码方式，保持键到分表的映射不变
+type HashShardingOptions struct {
+	Hash     HashFunc   // 哈希函数（默认 HashFNV1a）
+	Encoder  KeyEncoder // 键编码方式（默认 EncodeKeyDecimal）
+	HashName string     // 哈希方案名称，用于判断两个策略是否共置（使用自定义 Hash 或 Encoder 时需要设置，否则不视为共置）
+}
+
 // HashShardingStrategy 基于 Hash 的分表策略
 type HashShardingStrategy struct {
 	baseTableName string
 	shardingKey   string // 分表键字段名
 	tableCount    int    // 分表数量
+	hash          HashFunc
+	encoder       KeyEncoder
+	hashName      string
+	nameTemplate  *tableNameTemplate // 分表名称模板（为空时为 基础表名_索引）
+
+	ShardOptionsMap // 分表级别的建表选项（通过 SetShardOptions 配置）
 }
 
 // NewHashShardingStrategy 创建 Hash 分表策略
 // baseTableName: 基础表名（如 "users"）
 // shardingKey: 分表键字段名（如 "user_id"）
 // tableCount: 分表数量（如 4，将创建 users_0, users_1, users_2, users_3）
-func NewHashShardingStrategy(baseTableName, shardingKey string, tableCount int) *HashShardingStrategy {
+// options: 哈希函数和键编码方式（可选，默认对十进制字符串计算 FNV-1a）
+func NewHashShardingStrategy(baseTableName, shardingKey string, tableCount int, options ...HashShardingOptions) *HashShardingStrategy {
 	if tableCount <= 0 {
 		tableCount = 1
 	}
+
+	opts := HashShardingOptions{}
+	if len(options) > 0 {
+		opts = options[0]
+	}
+	hashName := opts.HashName
+	if hashName == "" && opts.Hash == nil && opts.Encoder == nil {
+		hashName = "fnv1a"
+	}
+	if opts.Hash == nil {
+		opts.Hash = HashFNV1a
+	}
+	if opts.Encoder == nil {
+		opts.Encoder = EncodeKeyDecimal
+	}
+
 	return &HashShardingStrategy{
 		baseTableName: baseTableName,
 		shardingKey:   shardingKey,
 		tableCount:    tableCount,
+		hash:          opts.Hash,
+		encoder:       opts.Encoder,
+		hashName:      hashName,
 	}
 }
 
@@ -32,18 +74,33 @@ func NewHashShardingStrategy(baseTableName, shardingKey string, tableCount int)
 func (s *HashShardingStrategy) GetTableName(baseTableName string, shardingValue interface{}) string {
 	hashValue := s.hashValue(shardingValue)
 	tableIndex := hashValue % uint64(s.tableCount)
-	return fmt.Sprintf("%s_%d", baseTableName, tableIndex)
+	return formatIndexTableName(s.nameTemplate, baseTableName, int(tableIndex))
 }
 
 // GetAllTableNames 获取所有分表名称
 func (s *HashShardingStrategy) GetAllTableNames(baseTableName string) []string {
 	tableNames := make([]string, s.tableCount)
 	for i := 0; i < s.tableCount; i++ {
-		tableNames[i] = fmt.Sprintf("%s_%d", baseTableName, i)
+		tableNames[i] = formatIndexTableName(s.nameTemplate, baseTableName, i)
 	}
 	return tableNames
 }
 
+// SetNameTemplate 设置分表名称模板（如 "{base}_shard{index:02d}"），必须包含 {index}，需要在注册策略前调用
+func (s *HashShardingStrategy) SetNameTemplate(template string) error {
+	parsed, err := parseIndexTemplate(template)
+	if err != nil {
+		return err
+	}
+	s.nameTemplate = parsed
+	return nil
+}
+
+// tableNameTemplate 获取分表名称模板
+func (s *HashShardingStrategy) tableNameTemplate() *tableNameTemplate {
+	return s.nameTemplate
+}
+
 // GetShardingValue 从模型对象中提取分表键值
 func (s *HashShardingStrategy) GetShardingValue(value interface{}) (interface{}, error) {
 	return ExtractValue(value, s.shardingKey)
@@ -54,43 +111,91 @@ func (s *HashShardingStrategy) GetBaseTableName() string {
 	return s.baseTableName
 }
 
+// GetShardingKey 获取分表键字段名
+func (s *HashShardingStrategy) GetShardingKey() string {
+	return s.shardingKey
+}
+
 // hashValue 计算值的 Hash
 func (s *HashShardingStrategy) hashValue(value interface{}) uint64 {
+	if s.hash == nil || s.encoder == nil {
+		// 直接构造的策略（未通过 NewHashShardingStrategy）使用默认方案
+		return hashShardingValue(value)
+	}
+	return s.hash(s.encoder(value))
+}
+
+// hashShardingValue 使用默认方案（十进制编码 + FNV-1a）计算分表键值的 Hash
+func hashShardingValue(value interface{}) uint64 {
+	return HashFNV1a(EncodeKeyDecimal(value))
+}
+
+// HashFNV1a 64 位 FNV-1a 哈希（默认的哈希函数）
+func HashFNV1a(data []byte) uint64 {
 	hash := fnv.New64a()
-	
-	// 根据不同类型计算 Hash
+	hash.Write(data)
+	return hash.Sum64()
+}
+
+// HashCRC32 CRC32（IEEE）哈希
+func HashCRC32(data []byte) uint64 {
+	return uint64(crc32.ChecksumIEEE(data))
+}
+
+// EncodeKeyDecimal 默认的键编码方式：整数编码为十进制字符串，字符串使用原始字节，其他类型使用 %v 格式化
+func EncodeKeyDecimal(value interface{}) []byte {
 	switch v := value.(type) {
 	case string:
-		hash.Write([]byte(v))
-	case int:
-		hash.Write([]byte(fmt.Sprintf("%d", v)))
-	case int8:
-		hash.Write([]byte(fmt.Sprintf("%d", v)))
-	case int16:
-		hash.Write([]byte(fmt.Sprintf("%d", v)))
-	case int32:
-		hash.Write([]byte(fmt.Sprintf("%d", v)))
-	case int64:
-		hash.Write([]byte(fmt.Sprintf("%d", v)))
-	case uint:
-		hash.Write([]byte(fmt.Sprintf("%d", v)))
-	case uint8:
-		hash.Write([]byte(fmt.Sprintf("%d", v)))
-	case uint16:
-		hash.Write([]byte(fmt.Sprintf("%d", v)))
-	case uint32:
-		hash.Write([]byte(fmt.Sprintf("%d", v)))
-	case uint64:
-		hash.Write([]byte(fmt.Sprintf("%d", v)))
+		return []byte(v)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return []byte(fmt.Sprintf("%d", v))
 	default:
 		// 尝试转换为字符串
 		rv := reflect.ValueOf(value)
 		if rv.Kind() == reflect.Ptr {
 			rv = rv.Elem()
 		}
-		hash.Write([]byte(fmt.Sprintf("%v", rv.Interface())))
+		if !rv.IsValid() {
+			return []byte("<nil>")
+		}
+		return []byte(fmt.Sprintf("%v", rv.Interface()))
 	}
-	
-	return hash.Sum64()
 }
 
+// EncodeKeyBigEndian 整数编码为 8 字节大端序（常见于 Java 系分表中间件），[]byte 使用原始字节，其他类型与 EncodeKeyDecimal 相同
+func EncodeKeyBigEndian(value interface{}) []byte {
+	if n, ok := integerBits(value); ok {
+		return binary.BigEndian.AppendUint64(nil, n)
+	}
+	if b, ok := value.([]byte); ok {
+		return b
+	}
+	return EncodeKeyDecimal(value)
+}
+
+// EncodeKeyLittleEndian 整数编码为 8 字节小端序，[]byte 使用原始字节，其他类型与 EncodeKeyDecimal 相同
+func EncodeKeyLittleEndian(value interface{}) []byte {
+	if n, ok := integerBits(value); ok {
+		return binary.LittleEndian.AppendUint64(nil, n)
+	}
+	if b, ok := value.([]byte); ok {
+		return b
+	}
+	return EncodeKeyDecimal(value)
+}
+
+// integerBits 获取整数值的 64 位表示（负数按补码）
+func integerBits(value interface{}) (uint64, bool) {
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return uint64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return rv.Uint(), true
+	default:
+		return 0, false
+	}
+}
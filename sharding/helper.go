@@ -1,8 +1,12 @@
 package sharding
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"gorm.io/gorm"
@@ -10,55 +14,344 @@ import (
 
 // ShardingHelper 分表辅助工具
 type ShardingHelper struct {
-	db       *gorm.DB
-	strategies map[string]ShardingStrategy // 按基础表名缓存策略
+	db         *gorm.DB
+	strategies map[string]ShardingStrategy // 按基础表名缓存策略（只在原始辅助工具上保存，由 mu 保护）
+	handles    []*gorm.DB                  // 已注册分表回调的数据库实例（见 Bind）
+	root       *ShardingHelper             // WithDB 创建的视图指向原始辅助工具，共享策略和关闭状态
+
+	// 关闭协调
+	mu         sync.RWMutex
+	ctx        context.Context    // 辅助工具执行的操作使用的 context，关闭时取消
+	cancel     context.CancelFunc
+	inflight   sync.WaitGroup // 正在执行的操作
+	closed     bool
+	components []Shutdowner // 关闭时依次关闭的组件（按注册的相反顺序）
+	pools      []*gorm.DB   // 关闭时最后关闭的连接池（按注册的相反顺序）
+}
+
+// Shutdowner 可以在服务退出时关闭的组件（如 JobManager、ReplicaRouter）
+type Shutdowner interface {
+	Shutdown(ctx context.Context) error
 }
 
 // NewShardingHelper 创建分表辅助工具
 func NewShardingHelper(db *gorm.DB) *ShardingHelper {
+	parent := db.Statement.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithCancel(parent)
+
 	return &ShardingHelper{
-		db:        db,
+		db:         db,
 		strategies: make(map[string]ShardingStrategy),
+		handles:    []*gorm.DB{db},
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+}
+
+// Bind 将已注册（以及之后注册）的所有分表策略绑定到另一个数据库实例（如独立创建的只读实例），
+// 使其同样按分表键自动路由，而不需要为每个连接重复注册策略
+func (h *ShardingHelper) Bind(db *gorm.DB) error {
+	root := h.rootHelper()
+	root.mu.Lock()
+	defer root.mu.Unlock()
+
+	for _, handle := range root.handles {
+		// 同一个 gorm.Open 创建的实例（包括 Session）共享回调，不需要重复注册
+		if handle.Callback() == db.Callback() {
+			return nil
+		}
+	}
+	for _, strategy := range root.strategies {
+		if err := RegisterSharding(db, strategy); err != nil {
+			return fmt.Errorf("failed to bind sharding strategy %s: %w", strategy.GetBaseTableName(), err)
+		}
+	}
+	root.handles = append(root.handles, db)
+	return nil
+}
+
+// WithDB 返回使用指定数据库实例执行操作的辅助工具视图（如 helper.WithDB(readDB).FindAll(...)）
+// 视图与原始辅助工具共享策略和关闭状态；需要按分表键自动路由时，先调用 Bind 绑定该实例
+func (h *ShardingHelper) WithDB(db *gorm.DB) *ShardingHelper {
+	root := h.rootHelper()
+	return &ShardingHelper{
+		db:   db,
+		root: root,
+	}
+}
+
+// rootHelper 获取原始辅助工具（视图返回其来源，否则返回自身）
+func (h *ShardingHelper) rootHelper() *ShardingHelper {
+	if h.root != nil {
+		return h.root
+	}
+	return h
+}
+
+// OnShutdown 注册关闭辅助工具时需要关闭的组件（如停止 ReplicaRouter 的后台检查、等待 JobManager 中的任务结束）
+func (h *ShardingHelper) OnShutdown(component Shutdowner) {
+	h = h.rootHelper()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.components = append(h.components, component)
+}
+
+// ManagePool 注册关闭辅助工具时需要关闭的连接池（如各分库、副本的 *gorm.DB）
+func (h *ShardingHelper) ManagePool(db *gorm.DB) {
+	h = h.rootHelper()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.pools = append(h.pools, db)
+}
+
+// Shutdown 关闭辅助工具，之后的操作返回 ErrShutdown。按以下顺序执行：
+//  1. 按注册的相反顺序关闭 OnShutdown 注册的组件（停止定时任务、等待维护任务结束）
+//  2. 取消辅助工具中仍在执行的跨表操作并等待其返回
+//  3. 按注册的相反顺序关闭 ManagePool 注册的连接池
+//
+// ctx 结束时不再等待组件和操作结束，但仍会取消操作并关闭连接池；返回所有步骤中的错误
+func (h *ShardingHelper) Shutdown(ctx context.Context) error {
+	h = h.rootHelper()
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return nil
 	}
+	h.closed = true
+	components := h.components
+	pools := h.pools
+	h.mu.Unlock()
+
+	var errs []error
+	for i := len(components) - 1; i >= 0; i-- {
+		if err := components[i].Shutdown(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	h.cancel()
+	done := make(chan struct{})
+	go func() {
+		h.inflight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		select {
+		case <-done:
+		default:
+			errs = append(errs, fmt.Errorf("waiting for in-flight operations: %w", ctx.Err()))
+		}
+	}
+
+	for i := len(pools) - 1; i >= 0; i-- {
+		if err := closeConnectionPool(pools[i]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close 关闭辅助工具（等待所有组件和操作结束，见 Shutdown）
+func (h *ShardingHelper) Close() error {
+	return h.Shutdown(context.Background())
 }
 
-// RegisterStrategy 注册分表策略
+// begin 开始一个操作，返回使用辅助工具 context 的数据库实例；已关闭时返回 ErrShutdown
+func (h *ShardingHelper) begin() (*gorm.DB, func(), error) {
+	root := h.rootHelper()
+	root.mu.Lock()
+	defer root.mu.Unlock()
+	if root.closed {
+		return nil, nil, ErrShutdown
+	}
+	root.inflight.Add(1)
+	return h.db.WithContext(root.ctx), root.inflight.Done, nil
+}
+
+// closeConnectionPool 关闭 *gorm.DB 底层的连接池
+func closeConnectionPool(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get connection pool: %w", err)
+	}
+	return sqlDB.Close()
+}
+
+// RegisterStrategy 注册分表策略（注册到所有通过 Bind 绑定的数据库实例）
+// 基础表已注册策略时返回 ErrStrategyExists，需要替换时先调用 UnregisterStrategy
 func (h *ShardingHelper) RegisterStrategy(strategy ShardingStrategy) error {
+	root := h.rootHelper()
+	root.mu.Lock()
+	defer root.mu.Unlock()
+
 	baseTableName := strategy.GetBaseTableName()
-	h.strategies[baseTableName] = strategy
-	return RegisterSharding(h.db, strategy)
+	if _, ok := root.strategies[baseTableName]; ok {
+		return fmt.Errorf("%w: %s", ErrStrategyExists, baseTableName)
+	}
+	root.strategies[baseTableName] = strategy
+	for _, handle := range root.handles {
+		if err := RegisterSharding(handle, strategy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UnregisterStrategy 注销分表策略，并从所有通过 Bind 绑定的数据库实例上移除其分表回调
+func (h *ShardingHelper) UnregisterStrategy(baseTableName string) error {
+	root := h.rootHelper()
+	root.mu.Lock()
+	defer root.mu.Unlock()
+
+	if _, ok := root.strategies[baseTableName]; !ok {
+		return fmt.Errorf("strategy not found for table: %s", baseTableName)
+	}
+	delete(root.strategies, baseTableName)
+	var errs []error
+	for _, handle := range root.handles {
+		if err := UnregisterSharding(handle, baseTableName); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
 }
 
 // GetStrategy 获取分表策略
 func (h *ShardingHelper) GetStrategy(baseTableName string) (ShardingStrategy, bool) {
-	strategy, ok := h.strategies[baseTableName]
+	root := h.rootHelper()
+	root.mu.RLock()
+	defer root.mu.RUnlock()
+	strategy, ok := root.strategies[baseTableName]
 	return strategy, ok
 }
 
+// ListStrategies 获取所有已注册的分表策略（按基础表名排序）
+func (h *ShardingHelper) ListStrategies() []ShardingStrategy {
+	root := h.rootHelper()
+	root.mu.RLock()
+	defer root.mu.RUnlock()
+	strategies := make([]ShardingStrategy, 0, len(root.strategies))
+	for _, strategy := range root.strategies {
+		strategies = append(strategies, strategy)
+	}
+	sort.Slice(strategies, func(i, j int) bool {
+		return strategies[i].GetBaseTableName() < strategies[j].GetBaseTableName()
+	})
+	return strategies
+}
+
+// Capabilities 获取已注册的基础表在当前数据库上支持的能力（见 GetCapabilities）
+func (h *ShardingHelper) Capabilities(baseTableName string) (Capabilities, bool) {
+	strategy, ok := h.GetStrategy(baseTableName)
+	if !ok {
+		return Capabilities{}, false
+	}
+	return GetCapabilities(h.db, strategy), true
+}
+
+// SetReadOnly 开启或关闭已注册的基础表的只读模式（见 SetTableReadOnly），用于迁移切换时在运行中切换
+func (h *ShardingHelper) SetReadOnly(baseTableName string, enabled bool) error {
+	if _, ok := h.GetStrategy(baseTableName); !ok {
+		return fmt.Errorf("strategy not found for table: %s", baseTableName)
+	}
+	SetTableReadOnly(baseTableName, enabled)
+	return nil
+}
+
+// AmbiguousStrategyError 模型的表名没有注册策略，无法确定模型对应的分表策略，可通过 errors.Is(err, ErrAmbiguousStrategy) 判断
+// 为模型实现 TableName() 返回基础表名，或使用 CreateWithTable 指定基础表
+type AmbiguousStrategyError struct {
+	Model      string   // 模型的表名（无法解析时为类型名）
+	Candidates []string // 能从模型中提取出分表键值的策略的基础表名
+}
+
+func (e *AmbiguousStrategyError) Error() string {
+	if len(e.Candidates) == 0 {
+		return fmt.Sprintf("%v for %s: no strategy registered for the table", ErrAmbiguousStrategy, e.Model)
+	}
+	return fmt.Sprintf("%v for %s: no strategy registered for the table, candidates %s", ErrAmbiguousStrategy, e.Model, strings.Join(e.Candidates, ", "))
+}
+
+func (e *AmbiguousStrategyError) Unwrap() error {
+	return ErrAmbiguousStrategy
+}
+
 // Create 创建记录（自动路由到正确的分表）
+// 使用模型的表名（GORM schema，如 TableName() 方法）对应的策略；模型表名没有注册策略时返回 *AmbiguousStrategyError，
+// 不会猜测使用其他能提取出分表键值的策略（如 users 和 orders 都有 UserID）
 func (h *ShardingHelper) Create(value interface{}) error {
-	// 尝试从所有已注册的策略中找到匹配的
-	for baseTableName, strategy := range h.strategies {
-		// 简单检查：如果 value 的某个字段名包含 baseTableName
-		// 这里简化处理，实际使用中可能需要更智能的匹配
-		if shardingValue, err := strategy.GetShardingValue(value); err == nil {
-			tableName := strategy.GetTableName(baseTableName, shardingValue)
-			return h.db.Table(tableName).Create(value).Error
+	strategy, err := h.resolveStrategy(value)
+	if err != nil {
+		return err
+	}
+
+	db, done, err := h.begin()
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	shardingValue, err := strategy.GetShardingValue(value)
+	if err != nil {
+		return err
+	}
+	tableName, err := GetTableNameE(strategy, strategy.GetBaseTableName(), shardingValue)
+	if err != nil {
+		return err
+	}
+	return db.Table(tableName).Create(value).Error
+}
+
+// resolveStrategy 根据模型找到对应的策略（见 Create）
+func (h *ShardingHelper) resolveStrategy(value interface{}) (ShardingStrategy, error) {
+	stmt := &gorm.Statement{DB: h.db}
+	if err := stmt.Parse(value); err == nil {
+		if strategy, ok := h.GetStrategy(stmt.Schema.Table); ok {
+			return strategy, nil
 		}
 	}
-	
-	return fmt.Errorf("no matching sharding strategy found")
+
+	// 列出能提取出分表键值的策略，帮助调用方选择正确的基础表
+	ambiguous := &AmbiguousStrategyError{Model: fmt.Sprintf("%T", value)}
+	if stmt.Schema != nil {
+		ambiguous.Model = stmt.Schema.Table
+	}
+	for _, strategy := range h.ListStrategies() {
+		if _, err := strategy.GetShardingValue(value); err == nil {
+			ambiguous.Candidates = append(ambiguous.Candidates, strategy.GetBaseTableName())
+		}
+	}
+	return nil, ambiguous
 }
 
 // CreateWithTable 在指定表创建记录
+// 无法提取分表键值或分表键值无效（如严格模式的时间分表）时返回错误，不会写入基础表
 func (h *ShardingHelper) CreateWithTable(baseTableName string, value interface{}) error {
 	strategy, ok := h.GetStrategy(baseTableName)
 	if !ok {
 		return fmt.Errorf("strategy not found for table: %s", baseTableName)
 	}
 	
-	tableName := GetTableNameWithValue(strategy, value)
-	return h.db.Table(tableName).Create(value).Error
+	db, done, err := h.begin()
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	shardingValue, err := strategy.GetShardingValue(value)
+	if err != nil {
+		return err
+	}
+	tableName, err := GetTableNameE(strategy, strategy.GetBaseTableName(), shardingValue)
+	if err != nil {
+		return err
+	}
+	return db.Table(tableName).Create(value).Error
 }
 
 // Find 查询记录（单表）
@@ -68,8 +361,17 @@ func (h *ShardingHelper) Find(baseTableName string, shardingValue interface{}, d
 		return fmt.Errorf("strategy not found for table: %s", baseTableName)
 	}
 	
-	tableName := strategy.GetTableName(baseTableName, shardingValue)
-	query := h.db.Table(tableName)
+	db, done, err := h.begin()
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	tableName, err := GetTableNameE(strategy, baseTableName, shardingValue)
+	if err != nil {
+		return err
+	}
+	query := db.Table(tableName)
 	
 	if len(conds) > 0 {
 		query = query.Where(conds[0], conds[1:]...)
@@ -85,17 +387,29 @@ func (h *ShardingHelper) FindAll(baseTableName string, dest interface{}, queryBu
 		return fmt.Errorf("strategy not found for table: %s", baseTableName)
 	}
 	
-	return CrossTableQuery(h.db, strategy, dest, queryBuilder)
+	db, done, err := h.begin()
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	return CrossTableQuery(db, strategy, dest, queryBuilder)
 }
 
 // Paginate 跨表分页查询
-func (h *ShardingHelper) Paginate(baseTableName string, dest interface{}, page, pageSize int, queryBuilder QueryBuilder) (*Paginator, error) {
+func (h *ShardingHelper) Paginate(baseTableName string, dest interface{}, page, pageSize int, queryBuilder QueryBuilder, options ...PaginateOptions) (*Paginator, error) {
 	strategy, ok := h.GetStrategy(baseTableName)
 	if !ok {
 		return nil, fmt.Errorf("strategy not found for table: %s", baseTableName)
 	}
 	
-	return CrossTablePaginate(h.db, strategy, dest, page, pageSize, queryBuilder)
+	db, done, err := h.begin()
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	return CrossTablePaginate(db, strategy, dest, page, pageSize, queryBuilder, options...)
 }
 
 // GenerateTableNames 生成所有分表的创建 SQL
@@ -137,7 +451,10 @@ func CreateAllHashTables(db *gorm.DB, baseTableName string, tableCount int, crea
 	
 	for _, tableName := range tableNames {
 		sql := strings.ReplaceAll(createTableSQL, baseTableName, tableName)
-		if err := db.Exec(sql).Error; err != nil {
+		err := runDDL(db, "CreateAllHashTables", tableName, func(tx *gorm.DB) error {
+			return tx.Exec(sql).Error
+		})
+		if err != nil {
 			// 如果表已存在，忽略错误
 			if !strings.Contains(strings.ToLower(err.Error()), "already exists") {
 				return fmt.Errorf("failed to create table %s: %w", tableName, err)
@@ -0,0 +1,381 @@
+package sharding
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// predicateExprPattern 匹配字符串条件中的 "列 运算符" 片段，如 "user_id = ?"、"created_at >= ?"、"name LIKE ?"
+var predicateExprPattern = regexp.MustCompile("(?i)(?:`?\\w+`?\\.)?`?(\\w+)`?\\s*(<=|>=|<>|!=|=|<|>|\\bIN\\b|\\bLIKE\\b|\\bBETWEEN\\b)")
+
+// predicatePattern 一类查询谓词（同一张表上相同的等值列和范围列组合）
+type predicatePattern struct {
+	equalityColumns []string
+	rangeColumns    []string
+	count           int64
+}
+
+// WorkloadCapture 查询负载采集器
+// 记录时间窗口内每个分表上查询所使用的 WHERE 谓词列，用于生成索引建议
+type WorkloadCapture struct {
+	mu        sync.Mutex
+	window    time.Duration
+	startedAt time.Time
+	current   map[string]map[string]*predicatePattern // 表名 -> 谓词签名 -> 统计
+	previous  map[string]map[string]*predicatePattern // 上一个时间窗口的统计
+}
+
+// EnableWorkloadCapture 注册查询回调，开始采集查询负载
+// window: 统计时间窗口，超过后滚动到新窗口（生成建议时使用当前窗口和上一个窗口的数据）
+func EnableWorkloadCapture(db *gorm.DB, window time.Duration) (*WorkloadCapture, error) {
+	if window <= 0 {
+		window = time.Hour
+	}
+
+	capture := &WorkloadCapture{
+		window:    window,
+		startedAt: time.Now(),
+		current:   make(map[string]map[string]*predicatePattern),
+	}
+
+	err := db.Callback().Query().After("gorm:query").Register("sharding:workload_capture", func(db *gorm.DB) {
+		if db.Error != nil || db.DryRun {
+			return
+		}
+		capture.record(db.Statement)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to register workload capture callback: %w", err)
+	}
+	return capture, nil
+}
+
+// Reset 清空已采集的数据
+func (w *WorkloadCapture) Reset() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.startedAt = time.Now()
+	w.current = make(map[string]map[string]*predicatePattern)
+	w.previous = nil
+}
+
+// record 记录一条查询语句的谓词
+func (w *WorkloadCapture) record(stmt *gorm.Statement) {
+	tableName := stmt.Table
+	if tableName == "" || stmt.TableExpr != nil {
+		return
+	}
+
+	equalityColumns, rangeColumns := extractPredicateColumns(stmt)
+	if len(equalityColumns) == 0 && len(rangeColumns) == 0 {
+		return
+	}
+	signature := strings.Join(equalityColumns, ",") + "|" + strings.Join(rangeColumns, ",")
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if time.Since(w.startedAt) > w.window {
+		w.previous = w.current
+		w.current = make(map[string]map[string]*predicatePattern)
+		w.startedAt = time.Now()
+	}
+
+	patterns, ok := w.current[tableName]
+	if !ok {
+		patterns = make(map[string]*predicatePattern)
+		w.current[tableName] = patterns
+	}
+	pattern, ok := patterns[signature]
+	if !ok {
+		pattern = &predicatePattern{equalityColumns: equalityColumns, rangeColumns: rangeColumns}
+		patterns[signature] = pattern
+	}
+	pattern.count++
+}
+
+// snapshot 合并当前窗口和上一个窗口的统计
+func (w *WorkloadCapture) snapshot() map[string]map[string]predicatePattern {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	result := make(map[string]map[string]predicatePattern)
+	for _, window := range []map[string]map[string]*predicatePattern{w.previous, w.current} {
+		for tableName, patterns := range window {
+			if _, ok := result[tableName]; !ok {
+				result[tableName] = make(map[string]predicatePattern)
+			}
+			for signature, pattern := range patterns {
+				merged := result[tableName][signature]
+				merged.equalityColumns = pattern.equalityColumns
+				merged.rangeColumns = pattern.rangeColumns
+				merged.count += pattern.count
+				result[tableName][signature] = merged
+			}
+		}
+	}
+	return result
+}
+
+// AdvisorOptions 索引建议选项
+type AdvisorOptions struct {
+	MinQueries int64 // 谓词组合至少出现的次数（默认 10）
+	SampleRows int   // 估算选择性时每个分表的采样行数（默认 10000，0 以下表示使用默认值）
+}
+
+// IndexSuggestion 索引建议
+type IndexSuggestion struct {
+	Table       string   `json:"table"`       // 分表名称
+	Columns     []string `json:"columns"`     // 建议的索引列（等值列按选择性从高到低，范围列在最后）
+	Queries     int64    `json:"queries"`     // 时间窗口内使用该谓词组合的查询次数
+	Selectivity float64  `json:"selectivity"` // 索引列组合的选择性估算（不同值数量 / 采样行数，越接近 1 越好）
+	SQL         string   `json:"sql"`         // 建议的 CREATE INDEX 语句
+}
+
+// SuggestIndexes 根据采集的查询负载生成每个分表的索引建议
+// 已存在的、以建议列为前缀的索引会被视为已覆盖，不再重复建议
+func (w *WorkloadCapture) SuggestIndexes(db *gorm.DB, options ...AdvisorOptions) ([]IndexSuggestion, error) {
+	opts := AdvisorOptions{}
+	if len(options) > 0 {
+		opts = options[0]
+	}
+	if opts.MinQueries <= 0 {
+		opts.MinQueries = 10
+	}
+	if opts.SampleRows <= 0 {
+		opts.SampleRows = 10000
+	}
+
+	snapshot := w.snapshot()
+	tableNames := make([]string, 0, len(snapshot))
+	for tableName := range snapshot {
+		tableNames = append(tableNames, tableName)
+	}
+	sort.Strings(tableNames)
+
+	var suggestions []IndexSuggestion
+	for _, tableName := range tableNames {
+		existing := getExistingIndexColumns(db, tableName)
+
+		for _, pattern := range snapshot[tableName] {
+			if pattern.count < opts.MinQueries {
+				continue
+			}
+
+			selectivities, total, err := estimateSelectivity(db, tableName, pattern.equalityColumns, opts.SampleRows)
+			if err != nil {
+				if isTableNotFoundError(err) || isColumnNotFoundError(err) {
+					continue
+				}
+				return nil, err
+			}
+
+			// 等值列按选择性从高到低排序，范围列只能放在最后一列
+			equalityColumns := append([]string(nil), pattern.equalityColumns...)
+			sort.SliceStable(equalityColumns, func(i, j int) bool {
+				return selectivities[equalityColumns[i]] > selectivities[equalityColumns[j]]
+			})
+			columns := equalityColumns
+			if len(pattern.rangeColumns) > 0 {
+				columns = append(columns, pattern.rangeColumns[0])
+			}
+			if isIndexCovered(existing, columns) {
+				continue
+			}
+
+			selectivity := 0.0
+			if len(equalityColumns) > 0 && total > 0 {
+				combined, err := countDistinct(db, tableName, equalityColumns, opts.SampleRows)
+				if err != nil {
+					return nil, err
+				}
+				selectivity = float64(combined) / float64(total)
+			}
+
+			suggestions = append(suggestions, IndexSuggestion{
+				Table:       tableName,
+				Columns:     columns,
+				Queries:     pattern.count,
+				Selectivity: selectivity,
+				SQL:         buildCreateIndexSQL(db, tableName, columns),
+			})
+		}
+	}
+
+	// 查询次数多的建议排在前面
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		if suggestions[i].Queries != suggestions[j].Queries {
+			return suggestions[i].Queries > suggestions[j].Queries
+		}
+		return suggestions[i].Table < suggestions[j].Table
+	})
+	return suggestions, nil
+}
+
+// extractPredicateColumns 从 WHERE 子句中提取等值列和范围列（去重并排序），OR 条件中的列不适合建索引，会被忽略
+func extractPredicateColumns(stmt *gorm.Statement) ([]string, []string) {
+	whereClause, ok := stmt.Clauses["WHERE"]
+	if !ok {
+		return nil, nil
+	}
+	where, ok := whereClause.Expression.(clause.Where)
+	if !ok {
+		return nil, nil
+	}
+
+	equality := make(map[string]bool)
+	ranges := make(map[string]bool)
+	var collect func(exprs []clause.Expression)
+	collect = func(exprs []clause.Expression) {
+		for _, expr := range exprs {
+			switch e := expr.(type) {
+			case clause.Eq:
+				equality[columnName(e.Column)] = true
+			case clause.IN:
+				equality[columnName(e.Column)] = true
+			case clause.Gt:
+				ranges[columnName(e.Column)] = true
+			case clause.Gte:
+				ranges[columnName(e.Column)] = true
+			case clause.Lt:
+				ranges[columnName(e.Column)] = true
+			case clause.Lte:
+				ranges[columnName(e.Column)] = true
+			case clause.Like:
+				ranges[columnName(e.Column)] = true
+			case clause.AndConditions:
+				collect(e.Exprs)
+			case clause.Expr:
+				if strings.Contains(strings.ToUpper(e.SQL), " OR ") {
+					continue
+				}
+				for _, match := range predicateExprPattern.FindAllStringSubmatch(e.SQL, -1) {
+					switch strings.ToUpper(match[2]) {
+					case "=", "IN":
+						equality[match[1]] = true
+					case "<>", "!=":
+						// 不等条件无法有效利用索引
+					default:
+						ranges[match[1]] = true
+					}
+				}
+			}
+		}
+	}
+	collect(where.Exprs)
+
+	delete(equality, "")
+	delete(ranges, "")
+	for column := range equality {
+		delete(ranges, column)
+	}
+	return sortedKeys(equality), sortedKeys(ranges)
+}
+
+// sortedKeys 获取排序后的 map 键
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// estimateSelectivity 在采样数据上估算每一列的选择性，返回列名 -> 选择性以及采样行数
+func estimateSelectivity(db *gorm.DB, tableName string, columns []string, sampleRows int) (map[string]float64, int64, error) {
+	stmt := db.Statement
+	selects := []string{"COUNT(*)"}
+	for _, column := range columns {
+		selects = append(selects, "COUNT(DISTINCT "+stmt.Quote(column)+")")
+	}
+
+	sql := fmt.Sprintf("SELECT %s FROM (SELECT * FROM %s LIMIT %d) AS sample",
+		strings.Join(selects, ", "), stmt.Quote(tableName), sampleRows)
+	row := db.Raw(sql).Row()
+
+	counts := make([]int64, len(selects))
+	scanArgs := make([]interface{}, len(selects))
+	for i := range counts {
+		scanArgs[i] = &counts[i]
+	}
+	if err := row.Scan(scanArgs...); err != nil {
+		return nil, 0, err
+	}
+
+	total := counts[0]
+	selectivities := make(map[string]float64, len(columns))
+	for i, column := range columns {
+		if total > 0 {
+			selectivities[column] = float64(counts[i+1]) / float64(total)
+		}
+	}
+	return selectivities, total, nil
+}
+
+// countDistinct 在采样数据上统计列组合的不同值数量
+func countDistinct(db *gorm.DB, tableName string, columns []string, sampleRows int) (int64, error) {
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = db.Statement.Quote(column)
+	}
+
+	var count int64
+	sql := fmt.Sprintf("SELECT COUNT(*) FROM (SELECT DISTINCT %s FROM (SELECT * FROM %s LIMIT %d) AS sample) AS distinct_sample",
+		strings.Join(quoted, ", "), db.Statement.Quote(tableName), sampleRows)
+	err := db.Raw(sql).Scan(&count).Error
+	return count, err
+}
+
+// getExistingIndexColumns 获取表上已有索引的列（获取失败时返回空）
+func getExistingIndexColumns(db *gorm.DB, tableName string) [][]string {
+	indexes, err := db.Migrator().GetIndexes(tableName)
+	if err != nil {
+		return nil
+	}
+
+	result := make([][]string, 0, len(indexes))
+	for _, index := range indexes {
+		result = append(result, index.Columns())
+	}
+	return result
+}
+
+// isIndexCovered 判断建议的列是否已经是某个现有索引的前缀
+func isIndexCovered(existing [][]string, columns []string) bool {
+	for _, indexColumns := range existing {
+		if len(indexColumns) < len(columns) {
+			continue
+		}
+		covered := true
+		for i, column := range columns {
+			if !strings.EqualFold(indexColumns[i], column) {
+				covered = false
+				break
+			}
+		}
+		if covered {
+			return true
+		}
+	}
+	return false
+}
+
+// buildCreateIndexSQL 生成建议的 CREATE INDEX 语句
+func buildCreateIndexSQL(db *gorm.DB, tableName string, columns []string) string {
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = db.Statement.Quote(column)
+	}
+	indexName := "idx_" + tableName + "_" + strings.Join(columns, "_")
+	return fmt.Sprintf("CREATE INDEX %s ON %s (%s)",
+		db.Statement.Quote(indexName), db.Statement.Quote(tableName), strings.Join(quoted, ", "))
+}
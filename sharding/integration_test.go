@@ -0,0 +1,19 @@
+package sharding_test
+
+import (
+	"testing"
+
+	"x2-sharding-module/sharding/internal/testsupport"
+)
+
+// TestIntegration 在 docker 启动的 MySQL/MariaDB 上运行 testsupport 中的所有检查
+// docker 不可用或使用 -short 时跳过；X2_SHARDING_TEST_IMAGES 可以只运行指定的数据库版本
+func TestIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration tests in short mode")
+	}
+	if !testsupport.DockerAvailable() {
+		t.Skip("docker is not available")
+	}
+	testsupport.RunAll(t)
+}
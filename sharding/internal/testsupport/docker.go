@@ -0,0 +1,151 @@
+// Package testsupport 集成测试支持：通过 docker 启动 MySQL 5.7/8.0 和 MariaDB，
+// 为每种分表策略准备分表和数据，并在每个数据库版本上运行跨表查询、连接查询和分页的检查
+package testsupport
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"x2-sharding-module/sharding"
+)
+
+// imagesEnv 指定要运行的数据库版本（逗号分隔的 ServerImage.Name，如 "mysql-8.0,mariadb-10.11"）
+const imagesEnv = "X2_SHARDING_TEST_IMAGES"
+
+// serverPassword 测试数据库的 root 密码
+const serverPassword = "x2sharding"
+
+// ServerImage 数据库镜像
+type ServerImage struct {
+	Name  string // 名称（如 "mysql-8.0"）
+	Image string // docker 镜像
+}
+
+// DefaultMatrix 默认运行的数据库版本
+var DefaultMatrix = []ServerImage{
+	{Name: "mysql-5.7", Image: "mysql:5.7"},
+	{Name: "mysql-8.0", Image: "mysql:8.0"},
+	{Name: "mariadb-10.11", Image: "mariadb:10.11"},
+}
+
+// Matrix 获取要运行的数据库版本（X2_SHARDING_TEST_IMAGES 未设置时为 DefaultMatrix）
+func Matrix() []ServerImage {
+	names := os.Getenv(imagesEnv)
+	if names == "" {
+		return DefaultMatrix
+	}
+	images := make([]ServerImage, 0)
+	for _, name := range strings.Split(names, ",") {
+		for _, image := range DefaultMatrix {
+			if image.Name == strings.TrimSpace(name) {
+				images = append(images, image)
+			}
+		}
+	}
+	return images
+}
+
+// DockerAvailable 判断 docker 是否可用
+func DockerAvailable() bool {
+	if _, err := exec.LookPath("docker"); err != nil {
+		return false
+	}
+	return exec.Command("docker", "info").Run() == nil
+}
+
+// Server 通过 docker 启动的数据库
+type Server struct {
+	Image       ServerImage
+	ContainerID string
+	Addr        string // 映射到本机的地址（如 "127.0.0.1:49153"）
+}
+
+// StartServer 启动数据库容器并等待可以连接（ctx 没有截止时间时最多等待 3 分钟）
+func StartServer(ctx context.Context, image ServerImage) (*Server, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 3*time.Minute)
+		defer cancel()
+	}
+
+	output, err := exec.CommandContext(ctx, "docker", "run", "-d", "--rm",
+		"-e", "MYSQL_ROOT_PASSWORD="+serverPassword,
+		"-e", "MARIADB_ROOT_PASSWORD="+serverPassword,
+		"-p", "127.0.0.1::3306",
+		image.Image,
+	).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start %s: %w", image.Image, commandError(err))
+	}
+	server := &Server{Image: image, ContainerID: strings.TrimSpace(string(output))}
+
+	output, err = exec.CommandContext(ctx, "docker", "port", server.ContainerID, "3306/tcp").Output()
+	if err != nil {
+		server.Stop()
+		return nil, fmt.Errorf("failed to get port of %s: %w", image.Image, commandError(err))
+	}
+	server.Addr = strings.TrimSpace(strings.Split(string(output), "\n")[0])
+
+	if err := server.waitReady(ctx); err != nil {
+		server.Stop()
+		return nil, err
+	}
+	return server, nil
+}
+
+// waitReady 等待数据库可以连接
+func (s *Server) waitReady(ctx context.Context) error {
+	db, err := sql.Open("mysql", s.DSN(""))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	for {
+		if err := db.PingContext(ctx); err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s is not ready: %w", s.Image.Image, ctx.Err())
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+// DSN 获取连接字符串
+func (s *Server) DSN(database string) string {
+	return fmt.Sprintf("root:%s@tcp(%s)/%s?charset=utf8mb4&parseTime=True&loc=Local", serverPassword, s.Addr, database)
+}
+
+// Open 打开数据库连接（数据库不存在时自动创建）
+func (s *Server) Open(database string) (*gorm.DB, error) {
+	return sharding.OpenMySQLWithAutoCreateDB(s.DSN(database), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+}
+
+// Stop 删除数据库容器
+func (s *Server) Stop() error {
+	if err := exec.Command("docker", "rm", "-f", s.ContainerID).Run(); err != nil {
+		return fmt.Errorf("failed to stop %s: %w", s.Image.Image, commandError(err))
+	}
+	return nil
+}
+
+// commandError 在错误中附带命令的标准错误输出
+func commandError(err error) error {
+	if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return err
+}
@@ -0,0 +1,145 @@
+package testsupport
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"x2-sharding-module/sharding"
+)
+
+// Order 测试使用的订单模型（包含所有策略使用的分表键）
+type Order struct {
+	ID        uint      `gorm:"primaryKey"`
+	OrderNo   string    `gorm:"size:32;not null"`
+	UserID    int64     `gorm:"index;not null"`
+	TenantID  string    `gorm:"size:32;not null"`
+	Region    string    `gorm:"size:8;not null"`
+	Amount    int64     `gorm:"not null"`
+	CreatedAt time.Time `gorm:"index"`
+}
+
+// TableName 基础表名
+func (Order) TableName() string {
+	return "orders"
+}
+
+// User 测试使用的用户模型（按 UserID 与订单共置分表，用于连接查询）
+type User struct {
+	ID     uint   `gorm:"primaryKey"`
+	UserID int64  `gorm:"uniqueIndex;not null"`
+	Name   string `gorm:"size:64;not null"`
+}
+
+// TableName 基础表名
+func (User) TableName() string {
+	return "users"
+}
+
+// userCount 测试数据中的用户数量（订单的 UserID 为 1..userCount）
+const userCount = 37
+
+var (
+	testTenants = []string{"acme", "globex", "initech", "umbrella"}
+	testRegions = []string{"CN", "US", "DE", "JP", "FR", "BR"}
+)
+
+// Fixture 一种分表策略的测试数据
+type Fixture struct {
+	Name     string
+	Strategy sharding.ShardingStrategy
+}
+
+// Fixtures 获取所有分表策略的测试数据（基础表均为 orders，运行前需要 Provision）
+func Fixtures() []Fixture {
+	month := sharding.NewTimeShardingStrategy("orders", "CreatedAt", sharding.TimeShardingByMonth)
+	return []Fixture{
+		{Name: "hash", Strategy: sharding.NewHashShardingStrategy("orders", "UserID", 4)},
+		{Name: "jump", Strategy: sharding.NewJumpHashShardingStrategy("orders", "UserID", 4)},
+		{Name: "modulo", Strategy: sharding.NewModuloShardingStrategy("orders", "UserID", 4)},
+		{Name: "range", Strategy: sharding.NewRangeShardingStrategy("orders", "UserID", 10, 4)},
+		{Name: "time", Strategy: month},
+		{Name: "list", Strategy: sharding.NewListShardingStrategy("orders", "Region", map[interface{}]string{"CN": "cn", "US": "us"})},
+		{Name: "geo", Strategy: sharding.NewGeoShardingStrategy("orders", "Region", sharding.GeoShardingOptions{
+			Groups: map[string][]string{"apac": {"CN", "JP"}, "eu": {"DE", "FR"}, "us": {"US"}},
+		})},
+		{Name: "tenant", Strategy: sharding.NewTenantShardingStrategy("orders", "TenantID", 2, "acme")},
+		{Name: "composite", Strategy: sharding.NewCompositeShardingStrategy("orders",
+			sharding.NewHashShardingStrategy("orders", "UserID", 2), month)},
+	}
+}
+
+// SeedOrders 生成确定性的订单数据（创建时间分布在最近 6 个月内）
+func SeedOrders(count int) []Order {
+	now := time.Now()
+	orders := make([]Order, count)
+	for i := range orders {
+		orders[i] = Order{
+			OrderNo:   fmt.Sprintf("O%06d", i),
+			UserID:    int64(i%userCount + 1),
+			TenantID:  testTenants[i%len(testTenants)],
+			Region:    testRegions[i%len(testRegions)],
+			Amount:    int64(i * 10),
+			CreatedAt: now.AddDate(0, -(i % 6), 0).Truncate(time.Second),
+		}
+	}
+	return orders
+}
+
+// Provision 重新创建策略的所有分表并写入 count 条订单，返回写入的订单
+func Provision(db *gorm.DB, fixture Fixture, count int) ([]Order, error) {
+	if err := Drop(db, fixture); err != nil {
+		return nil, err
+	}
+	if err := sharding.AutoMigrate(db, fixture.Strategy, &Order{}); err != nil {
+		return nil, fmt.Errorf("failed to migrate %s fixture: %w", fixture.Name, err)
+	}
+	orders := SeedOrders(count)
+	if err := sharding.CrossTableBatchCreate(db, fixture.Strategy, orders); err != nil {
+		return nil, fmt.Errorf("failed to seed %s fixture: %w", fixture.Name, err)
+	}
+	return orders, nil
+}
+
+// ProvisionUsers 重新创建与 strategy 共置的用户分表并写入所有用户
+func ProvisionUsers(db *gorm.DB, strategy sharding.ShardingStrategy) ([]User, error) {
+	if err := dropTables(db, fixtureTables(strategy)); err != nil {
+		return nil, err
+	}
+	if err := sharding.AutoMigrate(db, strategy, &User{}); err != nil {
+		return nil, fmt.Errorf("failed to migrate users: %w", err)
+	}
+	users := make([]User, userCount)
+	for i := range users {
+		users[i] = User{UserID: int64(i + 1), Name: fmt.Sprintf("user-%d", i+1)}
+	}
+	if err := sharding.CrossTableBatchCreate(db, strategy, users); err != nil {
+		return nil, fmt.Errorf("failed to seed users: %w", err)
+	}
+	return users, nil
+}
+
+// Drop 删除策略的所有分表
+func Drop(db *gorm.DB, fixture Fixture) error {
+	return dropTables(db, fixtureTables(fixture.Strategy))
+}
+
+// fixtureTables 获取策略的所有分表（时间分表为最近一年）
+func fixtureTables(strategy sharding.ShardingStrategy) []string {
+	if timeStrategy, ok := strategy.(*sharding.TimeShardingStrategy); ok {
+		endTime := time.Now()
+		return timeStrategy.GetAllTableNamesInRange(strategy.GetBaseTableName(), endTime.AddDate(-1, 0, 0), endTime)
+	}
+	return strategy.GetAllTableNames(strategy.GetBaseTableName())
+}
+
+// dropTables 删除表（不存在的表被忽略）
+func dropTables(db *gorm.DB, tableNames []string) error {
+	for _, tableName := range tableNames {
+		if err := db.Migrator().DropTable(tableName); err != nil {
+			return fmt.Errorf("failed to drop table %s: %w", tableName, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+package testsupport
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"gorm.io/gorm"
+
+	"x2-sharding-module/sharding"
+)
+
+// T 测试接口（*testing.T 实现了该接口）
+type T interface {
+	Helper()
+	Logf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+	Fatalf(format string, args ...interface{})
+}
+
+// seedCount 每个策略写入的订单数量
+const seedCount = 120
+
+// RunMatrix 在每个数据库版本上执行 run（docker 不可用时跳过），run 中的失败信息带有数据库版本前缀
+func RunMatrix(t T, run func(t T, server *Server, db *gorm.DB)) {
+	t.Helper()
+	if !DockerAvailable() {
+		t.Logf("docker is not available, skipping MySQL matrix")
+		return
+	}
+
+	for _, image := range Matrix() {
+		server, err := StartServer(context.Background(), image)
+		if err != nil {
+			t.Errorf("%v", err)
+			continue
+		}
+		func() {
+			defer server.Stop()
+
+			db, err := server.Open("x2_sharding_test")
+			if err != nil {
+				t.Errorf("[%s] failed to open database: %v", image.Name, err)
+				return
+			}
+			run(prefixT{T: t, prefix: "[" + image.Name + "] "}, server, db)
+		}()
+	}
+}
+
+// RunAll 在每个数据库版本上为每种分表策略运行跨表查询、分页和连接查询的检查
+func RunAll(t T) {
+	t.Helper()
+	RunMatrix(t, func(t T, server *Server, db *gorm.DB) {
+		for _, fixture := range Fixtures() {
+			orders, err := Provision(db, fixture, seedCount)
+			if err != nil {
+				t.Errorf("%v", err)
+				continue
+			}
+			ft := prefixT{T: t, prefix: fixture.Name + ": "}
+			RunCrossTableSuite(ft, db, fixture, orders)
+			RunPaginationSuite(ft, db, fixture, orders)
+			if err := Drop(db, fixture); err != nil {
+				t.Errorf("%v", err)
+			}
+		}
+		RunJoinSuite(t, db)
+	})
+}
+
+// RunCrossTableSuite 检查跨表查询、计数、聚合和分组的结果与写入的数据一致
+func RunCrossTableSuite(t T, db *gorm.DB, fixture Fixture, orders []Order) {
+	t.Helper()
+	strategy := fixture.Strategy
+
+	count, err := sharding.CrossTableCount(db, strategy, nil)
+	if err != nil {
+		t.Errorf("CrossTableCount: %v", err)
+	} else if count != int64(len(orders)) {
+		t.Errorf("CrossTableCount = %d, want %d", count, len(orders))
+	}
+
+	var found []Order
+	if err := sharding.CrossTableQuery(db, strategy, &found, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("user_id = ?", 7)
+	}); err != nil {
+		t.Errorf("CrossTableQuery: %v", err)
+	} else if want := countOrders(orders, func(o Order) bool { return o.UserID == 7 }); len(found) != want {
+		t.Errorf("CrossTableQuery(user_id = 7) returned %d rows, want %d", len(found), want)
+	}
+
+	var wantSum int64
+	for _, order := range orders {
+		wantSum += order.Amount
+	}
+	sum, err := sharding.CrossTableAggregate(db, strategy, sharding.Aggregation{Func: sharding.AggregateSum, Column: "amount"}, nil)
+	if err != nil {
+		t.Errorf("CrossTableAggregate(SUM): %v", err)
+	} else if sum != float64(wantSum) {
+		t.Errorf("CrossTableAggregate(SUM) = %v, want %d", sum, wantSum)
+	}
+
+	exists, err := sharding.CrossTableExists(db, strategy, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("order_no = ?", orders[len(orders)-1].OrderNo)
+	})
+	if err != nil {
+		t.Errorf("CrossTableExists: %v", err)
+	} else if !exists {
+		t.Errorf("CrossTableExists(order_no = %s) = false, want true", orders[len(orders)-1].OrderNo)
+	}
+}
+
+// RunPaginationSuite 检查跨表分页和有序归并分页的总数与顺序
+func RunPaginationSuite(t T, db *gorm.DB, fixture Fixture, orders []Order) {
+	t.Helper()
+	strategy := fixture.Strategy
+
+	var page []Order
+	paginator, err := sharding.CrossTablePaginate(db, strategy, &page, 2, 25, nil)
+	if err != nil {
+		t.Errorf("CrossTablePaginate: %v", err)
+	} else if paginator.Total != int64(len(orders)) || len(page) != 25 {
+		t.Errorf("CrossTablePaginate page 2 = %d rows of %d, want 25 of %d", len(page), paginator.Total, len(orders))
+	}
+
+	expected := make([]string, len(orders))
+	for i, order := range orders {
+		expected[i] = order.OrderNo
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(expected)))
+
+	got := make([]string, 0, len(orders))
+	for pageNo := 1; ; pageNo++ {
+		var rows []Order
+		paginator, err := sharding.CrossTableOrderedPaginate(db, strategy, &rows, pageNo, 40,
+			[]sharding.OrderByColumn{{Column: "order_no", Desc: true}}, nil)
+		if err != nil {
+			t.Errorf("CrossTableOrderedPaginate page %d: %v", pageNo, err)
+			return
+		}
+		for _, row := range rows {
+			got = append(got, row.OrderNo)
+		}
+		if pageNo >= paginator.TotalPages {
+			break
+		}
+	}
+	if fmt.Sprint(got) != fmt.Sprint(expected) {
+		t.Errorf("CrossTableOrderedPaginate returned %d rows out of order, want %d rows by order_no desc", len(got), len(expected))
+	}
+}
+
+// RunJoinSuite 检查共置分表（users 与 orders 都按 UserID Hash 分 4 张表）的连接查询
+func RunJoinSuite(t T, db *gorm.DB) {
+	t.Helper()
+	fixture := Fixtures()[0]
+	orders, err := Provision(db, fixture, seedCount)
+	if err != nil {
+		t.Errorf("%v", err)
+		return
+	}
+	defer Drop(db, fixture)
+
+	userStrategy := sharding.NewHashShardingStrategy("users", "UserID", 4)
+	if _, err := ProvisionUsers(db, userStrategy); err != nil {
+		t.Errorf("%v", err)
+		return
+	}
+	defer dropTables(db, fixtureTables(userStrategy))
+
+	type joinedRow struct {
+		OrderNo string
+		Name    string
+	}
+	var rows []joinedRow
+	err = sharding.CrossTableMultiJoin(db, sharding.MultiJoinConfig{
+		MainTable: sharding.JoinInfo{Strategy: fixture.Strategy},
+		JoinTables: []sharding.JoinInfo{{
+			Strategy:    userStrategy,
+			JoinType:    sharding.InnerJoin,
+			OnCondition: "orders.user_id = users.user_id",
+		}},
+	}, &rows, func(tx *gorm.DB) *gorm.DB {
+		return tx.Select("orders.order_no, users.name")
+	})
+	if err != nil {
+		t.Errorf("CrossTableMultiJoin: %v", err)
+	} else if len(rows) != len(orders) {
+		t.Errorf("CrossTableMultiJoin returned %d rows, want %d", len(rows), len(orders))
+	}
+}
+
+// countOrders 统计满足条件的订单数量
+func countOrders(orders []Order, match func(Order) bool) int {
+	count := 0
+	for _, order := range orders {
+		if match(order) {
+			count++
+		}
+	}
+	return count
+}
+
+// prefixT 为失败信息增加前缀（数据库版本、策略名称）
+type prefixT struct {
+	T
+	prefix string
+}
+
+func (p prefixT) Logf(format string, args ...interface{}) {
+	p.T.Helper()
+	p.T.Logf(p.prefix+format, args...)
+}
+
+func (p prefixT) Errorf(format string, args ...interface{}) {
+	p.T.Helper()
+	p.T.Errorf(p.prefix+format, args...)
+}
+
+func (p prefixT) Fatalf(format string, args ...interface{}) {
+	p.T.Helper()
+	p.T.Fatalf(p.prefix+format, args...)
+}
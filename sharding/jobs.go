@@ -0,0 +1,316 @@
+package sharding
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// JobStatus 任务状态
+type JobStatus string
+
+const (
+	JobPending   JobStatus = "pending"   // 等待执行
+	JobRunning   JobStatus = "running"   // 执行中
+	JobSucceeded JobStatus = "succeeded" // 执行成功
+	JobFailed    JobStatus = "failed"    // 执行失败
+	JobCancelled JobStatus = "cancelled" // 已取消
+)
+
+// defaultIdempotencyKeyTTL 任务结束后幂等键的默认保留时间
+const defaultIdempotencyKeyTTL = 24 * time.Hour
+
+// JobFunc 任务执行函数，应定期检查 ctx 是否已取消，并可通过 job.SetProgress 报告进度
+type JobFunc func(ctx context.Context, job *Job) error
+
+// JobSpec 提交任务的参数
+type JobSpec struct {
+	Name           string  // 任务名称（如 "reshard:orders"）
+	IdempotencyKey string  // 幂等键（可选），相同键的重复提交会返回已有的任务而不是再次执行
+	Run            JobFunc // 任务执行函数
+}
+
+// Job 后台维护任务（如重新分表、数据保留清理）
+type Job struct {
+	ID             string
+	Name           string
+	IdempotencyKey string
+
+	mu         sync.RWMutex
+	status     JobStatus
+	progress   float64
+	err        error
+	createdAt  time.Time
+	startedAt  time.Time
+	finishedAt time.Time
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Status 获取任务状态
+func (j *Job) Status() JobStatus {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.status
+}
+
+// Progress 获取任务进度（0 ~ 1）
+func (j *Job) Progress() float64 {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.progress
+}
+
+// SetProgress 更新任务进度（0 ~ 1）
+func (j *Job) SetProgress(progress float64) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.progress = progress
+}
+
+// Err 获取任务失败的原因
+func (j *Job) Err() error {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.err
+}
+
+// CreatedAt 获取任务提交时间
+func (j *Job) CreatedAt() time.Time {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.createdAt
+}
+
+// StartedAt 获取任务开始执行的时间
+func (j *Job) StartedAt() time.Time {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.startedAt
+}
+
+// FinishedAt 获取任务结束时间
+func (j *Job) FinishedAt() time.Time {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.finishedAt
+}
+
+// Done 返回任务结束时关闭的 channel
+func (j *Job) Done() <-chan struct{} {
+	return j.done
+}
+
+// Wait 等待任务结束，返回任务的错误
+func (j *Job) Wait(ctx context.Context) error {
+	select {
+	case <-j.done:
+		return j.Err()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Cancel 取消任务（任务函数需要响应 ctx 的取消）
+func (j *Job) Cancel() {
+	j.cancel()
+}
+
+// isFinished 判断任务是否已结束
+func (j *Job) isFinished() bool {
+	select {
+	case <-j.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// JobManagerOptions 任务管理器选项
+type JobManagerOptions struct {
+	IdempotencyKeyTTL time.Duration // 任务结束后幂等键的保留时间（默认 24 小时）
+}
+
+// JobManager 后台任务管理器
+// 通过幂等键去重：调度器重试提交相同键的任务时，会返回正在执行或已成功的任务，而不会重复执行；
+// 失败或取消的任务允许使用相同的键重新提交
+type JobManager struct {
+	options JobManagerOptions
+
+	mu       sync.Mutex
+	jobs     map[string]*Job // 任务 ID -> 任务
+	byKey    map[string]*Job // 幂等键 -> 最近一次提交的任务
+	nextID   uint64
+	shutdown bool // 已开始关闭，不再接收新任务
+}
+
+// NewJobManager 创建任务管理器
+func NewJobManager(options ...JobManagerOptions) *JobManager {
+	opts := JobManagerOptions{}
+	if len(options) > 0 {
+		opts = options[0]
+	}
+	if opts.IdempotencyKeyTTL <= 0 {
+		opts.IdempotencyKeyTTL = defaultIdempotencyKeyTTL
+	}
+
+	return &JobManager{
+		options: opts,
+		jobs:    make(map[string]*Job),
+		byKey:   make(map[string]*Job),
+	}
+}
+
+// Submit 提交任务并在后台执行
+// 设置了幂等键且存在相同键的未结束或已成功（在保留时间内）的任务时，直接返回该任务，第二个返回值为 false
+func (m *JobManager) Submit(spec JobSpec) (*Job, bool, error) {
+	if spec.Run == nil {
+		return nil, false, fmt.Errorf("job function is required")
+	}
+
+	m.mu.Lock()
+	if m.shutdown {
+		m.mu.Unlock()
+		return nil, false, ErrShutdown
+	}
+	m.evictExpiredLocked()
+
+	if spec.IdempotencyKey != "" {
+		if existing, ok := m.byKey[spec.IdempotencyKey]; ok {
+			if status := existing.Status(); status != JobFailed && status != JobCancelled {
+				m.mu.Unlock()
+				return existing, false, nil
+			}
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	job := &Job{
+		ID:             fmt.Sprintf("job-%d", atomic.AddUint64(&m.nextID, 1)),
+		Name:           spec.Name,
+		IdempotencyKey: spec.IdempotencyKey,
+		status:         JobPending,
+		createdAt:      time.Now(),
+		cancel:         cancel,
+		done:           make(chan struct{}),
+	}
+	m.jobs[job.ID] = job
+	if spec.IdempotencyKey != "" {
+		m.byKey[spec.IdempotencyKey] = job
+	}
+	m.mu.Unlock()
+
+	go m.run(ctx, job, spec.Run)
+	return job, true, nil
+}
+
+// run 执行任务并记录结果
+func (m *JobManager) run(ctx context.Context, job *Job, fn JobFunc) {
+	defer close(job.done)
+	defer job.cancel()
+
+	job.mu.Lock()
+	job.status = JobRunning
+	job.startedAt = time.Now()
+	job.mu.Unlock()
+
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("job panicked: %v", r)
+			}
+		}()
+		return fn(ctx, job)
+	}()
+
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	job.finishedAt = time.Now()
+	switch {
+	case err == nil:
+		job.status = JobSucceeded
+		job.progress = 1
+	case ctx.Err() != nil:
+		job.status = JobCancelled
+		job.err = err
+	default:
+		job.status = JobFailed
+		job.err = err
+	}
+}
+
+// Shutdown 停止接收新任务并等待正在执行的任务结束
+// ctx 结束时取消仍未结束的任务并返回 ctx 的错误（不再等待任务函数返回）
+func (m *JobManager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	m.shutdown = true
+	pending := make([]*Job, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		if !job.isFinished() {
+			pending = append(pending, job)
+		}
+	}
+	m.mu.Unlock()
+
+	for i, job := range pending {
+		select {
+		case <-job.done:
+		case <-ctx.Done():
+			for _, remaining := range pending[i:] {
+				remaining.Cancel()
+			}
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Get 根据 ID 获取任务
+func (m *JobManager) Get(id string) (*Job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[id]
+	return job, ok
+}
+
+// GetByIdempotencyKey 根据幂等键获取最近一次提交的任务
+func (m *JobManager) GetByIdempotencyKey(key string) (*Job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.byKey[key]
+	return job, ok
+}
+
+// List 获取所有任务（按提交顺序）
+func (m *JobManager) List() []*Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	jobs := make([]*Job, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt().Before(jobs[j].CreatedAt())
+	})
+	return jobs
+}
+
+// evictExpiredLocked 清理结束时间超过保留时间的任务（调用方需持有锁）
+func (m *JobManager) evictExpiredLocked() {
+	deadline := time.Now().Add(-m.options.IdempotencyKeyTTL)
+	for id, job := range m.jobs {
+		if job.isFinished() && job.FinishedAt().Before(deadline) {
+			delete(m.jobs, id)
+			if job.IdempotencyKey != "" && m.byKey[job.IdempotencyKey] == job {
+				delete(m.byKey, job.IdempotencyKey)
+			}
+		}
+	}
+}
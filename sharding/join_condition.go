@@ -0,0 +1,105 @@
+package sharding
+
+import (
+	"strings"
+)
+
+// JoinOn 结构化的等值连接条件 LeftTable.LeftCol = RightTable.RightCol（表名为基础表名，生成 SQL 时替换为别名）
+// 多个条件通过 And 组合：JoinInfo{On: sharding.JoinOn{"users", "id", "orders", "user_id"}.And(...)}
+type JoinOn struct {
+	LeftTable  string
+	LeftCol    string
+	RightTable string
+	RightCol   string
+}
+
+// And 与其他条件组合（AND）
+func (o JoinOn) And(others ...JoinOn) []JoinOn {
+	return append([]JoinOn{o}, others...)
+}
+
+// renderJoinOn 生成 AND 组合的连接条件，表名通过 aliases 替换为别名，quote 不为 nil 时引用标识符
+func renderJoinOn(conditions []JoinOn, aliases map[string]string, quote func(string) string) string {
+	column := func(table, col string) string {
+		if name, ok := aliases[table]; ok {
+			table = name
+		}
+		if quote == nil {
+			return table + "." + col
+		}
+		return quote(table) + "." + quote(col)
+	}
+	parts := make([]string, len(conditions))
+	for i, on := range conditions {
+		parts[i] = column(on.LeftTable, on.LeftCol) + " = " + column(on.RightTable, on.RightCol)
+	}
+	return strings.Join(parts, " AND ")
+}
+
+// rewriteConditionTables 将条件中限定列名的表名（如 users.id、`users`.id 中的 users）替换为别名
+// 只替换完整的标识符（users_profiles.id 不受 users 的影响），跳过字符串字面量和 schema.table.col 中的 table
+func rewriteConditionTables(condition string, aliases map[string]string) string {
+	var b strings.Builder
+	b.Grow(len(condition))
+	for i := 0; i < len(condition); {
+		c := condition[i]
+		switch {
+		case c == '\'' || c == '"':
+			// 字符串字面量原样保留（支持 '' 转义）
+			j := i + 1
+			for j < len(condition) {
+				if condition[j] == c {
+					if j+1 < len(condition) && condition[j+1] == c {
+						j += 2
+						continue
+					}
+					break
+				}
+				j++
+			}
+			end := min(j+1, len(condition))
+			b.WriteString(condition[i:end])
+			i = end
+		case c == '`':
+			j := strings.IndexByte(condition[i+1:], '`')
+			if j < 0 {
+				b.WriteString(condition[i:])
+				return b.String()
+			}
+			name := condition[i+1 : i+1+j]
+			end := i + j + 2
+			if alias, ok := aliases[name]; ok && qualifiesColumn(condition, i, end) {
+				b.WriteString("`" + alias + "`")
+			} else {
+				b.WriteString(condition[i:end])
+			}
+			i = end
+		case isIdentifierChar(c):
+			j := i
+			for j < len(condition) && isIdentifierChar(condition[j]) {
+				j++
+			}
+			name := condition[i:j]
+			if alias, ok := aliases[name]; ok && qualifiesColumn(condition, i, j) {
+				b.WriteString(alias)
+			} else {
+				b.WriteString(name)
+			}
+			i = j
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String()
+}
+
+// qualifiesColumn 判断 condition[start:end] 处的标识符是否为列名的表限定（后面是 "."，前面不是 "."）
+func qualifiesColumn(condition string, start, end int) bool {
+	return end < len(condition) && condition[end] == '.' && (start == 0 || condition[start-1] != '.')
+}
+
+// isIdentifierChar 判断是否为未加引号的标识符字符
+func isIdentifierChar(c byte) bool {
+	return c == '_' || c == '$' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
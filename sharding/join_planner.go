@@ -0,0 +1,227 @@
+package sharding
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	// joinEqualityPattern 匹配 ON 条件中的等值连接 table.col = table.col（允许反引号）
+	joinEqualityPattern = regexp.MustCompile("`?(\\w+)`?\\s*\\.\\s*`?(\\w+)`?\\s*=\\s*`?(\\w+)`?\\s*\\.\\s*`?(\\w+)`?")
+
+	// joinDisjunctionPattern 匹配 OR、NOT（包含时无法确定等值条件一定成立）
+	joinDisjunctionPattern = regexp.MustCompile(`(?i)\b(OR|NOT)\b`)
+)
+
+// joinEqualities 获取 ON 条件中 AND 组合的等值连接条件
+// 原始条件包含 OR、NOT 时返回 nil（其中的等值条件不一定成立，不能用于裁剪）
+func (j JoinInfo) joinEqualities() []JoinOn {
+	if len(j.On) > 0 {
+		return j.On
+	}
+	if j.OnCondition == "" || joinDisjunctionPattern.MatchString(j.OnCondition) {
+		return nil
+	}
+	var equalities []JoinOn
+	for _, match := range joinEqualityPattern.FindAllStringSubmatch(j.OnCondition, -1) {
+		equalities = append(equalities, JoinOn{LeftTable: match[1], LeftCol: match[2], RightTable: match[3], RightCol: match[4]})
+	}
+	return equalities
+}
+
+// isShardKeyColumn 判断列是否为表的分表键（忽略大小写和下划线，引用表总是返回 false）
+func (j JoinInfo) isShardKeyColumn(column string) bool {
+	keyProvider, ok := j.shardingStrategy().(ShardingKeyProvider)
+	return ok && normalizeKey(keyProvider.GetShardingKey()) == normalizeKey(column)
+}
+
+// timeStrategy 获取参与分表的时间分表策略（非时间分表返回 nil）
+func (j JoinInfo) timeStrategy() *TimeShardingStrategy {
+	strategy, _ := j.shardingStrategy().(*TimeShardingStrategy)
+	return strategy
+}
+
+// planJoinGroups 分析 ON 条件中的分表键等值关系，返回每个表所属的分组（tables[0] 为主表）
+// 通过分表键等值连接（可传递，如 users.user_id = orders.user_id、orders.user_id = payments.user_id）
+// 并且分区签名相同的表属于同一组，同组的表只有相同索引的分表中的行才可能匹配；
+// 时间分表通过时间字段等值连接且分表单位相同时属于同一组，同组的表只连接相同时间的分表，
+// config.AlignTimePeriods 为 true 时分表单位相同的时间分表都属于同一组
+func planJoinGroups(config MultiJoinConfig, tableNamesList [][]string) []int {
+	tables := append([]JoinInfo{config.MainTable}, config.JoinTables...)
+
+	// 条件中的表可以是基础表名或别名
+	indexes := make(map[string]int, len(tables)*2)
+	for i, table := range tables {
+		indexes[strings.ToLower(table.getBaseTableName())] = i
+	}
+	for i, table := range tables {
+		indexes[strings.ToLower(table.getAlias())] = i
+	}
+
+	groups := make([]int, len(tables))
+	for i := range groups {
+		groups[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if groups[i] != i {
+			groups[i] = find(groups[i])
+		}
+		return groups[i]
+	}
+
+	for _, table := range tables {
+		for _, on := range table.joinEqualities() {
+			left, ok1 := indexes[strings.ToLower(on.LeftTable)]
+			right, ok2 := indexes[strings.ToLower(on.RightTable)]
+			if !ok1 || !ok2 || left == right {
+				continue
+			}
+			if !tables[left].isShardKeyColumn(on.LeftCol) || !tables[right].isShardKeyColumn(on.RightCol) {
+				continue
+			}
+			if canAlignShards(tables[left], tables[right], len(tableNamesList[left]), len(tableNamesList[right])) {
+				groups[find(left)] = find(right)
+			}
+		}
+	}
+
+	if config.AlignTimePeriods {
+		for i := range tables {
+			for j := i + 1; j < len(tables); j++ {
+				if sameTimeUnit(tables[i], tables[j]) {
+					groups[find(j)] = find(i)
+				}
+			}
+		}
+	}
+
+	for i := range groups {
+		groups[i] = find(i)
+	}
+	return groups
+}
+
+// canAlignShards 判断通过分表键等值连接的两个表是否只需要连接对应的分表
+// 索引分表要求分区签名和分表数量相同，时间分表要求分表单位相同
+func canAlignShards(left, right JoinInfo, leftCount, rightCount int) bool {
+	if left.timeStrategy() != nil || right.timeStrategy() != nil {
+		return sameTimeUnit(left, right)
+	}
+	leftSignature, ok1 := partitionSignature(left.shardingStrategy())
+	rightSignature, ok2 := partitionSignature(right.shardingStrategy())
+	return ok1 && ok2 && leftSignature == rightSignature && leftCount == rightCount
+}
+
+// sameTimeUnit 判断两个表是否为分表单位相同的时间分表
+func sameTimeUnit(left, right JoinInfo) bool {
+	leftStrategy, rightStrategy := left.timeStrategy(), right.timeStrategy()
+	return leftStrategy != nil && rightStrategy != nil && leftStrategy.unit == rightStrategy.unit
+}
+
+// joinDimension 表组合的一个维度：同一分组的表，每一行为这些表一起连接的分表
+type joinDimension struct {
+	members []int      // 分组中的表（在表组合中的位置）
+	rows    [][]string // rows[r][k] 为第 r 种取值时 members[k] 使用的分表
+}
+
+// planJoinDimensions 根据分组生成表组合的维度（主表所在的分组在最前面）
+// 索引分表的分组按相同索引对齐；时间分表的分组按分表名称解析出的时间对齐，只保留所有表都有分表的时间
+func planJoinDimensions(config MultiJoinConfig, tableNamesList [][]string, groups []int) []joinDimension {
+	tables := append([]JoinInfo{config.MainTable}, config.JoinTables...)
+
+	var dimensions []joinDimension
+	dimensionOf := make(map[int]int)
+	for i, group := range groups {
+		d, ok := dimensionOf[group]
+		if !ok {
+			d = len(dimensions)
+			dimensionOf[group] = d
+			dimensions = append(dimensions, joinDimension{})
+		}
+		dimensions[d].members = append(dimensions[d].members, i)
+	}
+
+	for d := range dimensions {
+		members := dimensions[d].members
+		if len(members) > 1 && tables[members[0]].timeStrategy() != nil {
+			dimensions[d].rows = alignTimePeriods(tables, tableNamesList, members)
+			continue
+		}
+		first := tableNamesList[members[0]]
+		rows := make([][]string, len(first))
+		for r := range first {
+			rows[r] = make([]string, len(members))
+			for k, member := range members {
+				rows[r][k] = tableNamesList[member][r]
+			}
+		}
+		dimensions[d].rows = rows
+	}
+	return dimensions
+}
+
+// alignTimePeriods 按分表名称中的时间对齐时间分表（如 logs_202401 与 events_202401）
+func alignTimePeriods(tables []JoinInfo, tableNamesList [][]string, members []int) [][]string {
+	periods := make([]map[time.Time]string, len(members))
+	for k, member := range members {
+		periods[k] = make(map[time.Time]string, len(tableNamesList[member]))
+		for _, tableName := range tableNamesList[member] {
+			if period, ok := tables[member].timeStrategy().tablePeriod(tableName); ok {
+				periods[k][period] = tableName
+			}
+		}
+	}
+
+	var rows [][]string
+	for _, tableName := range tableNamesList[members[0]] {
+		period, ok := tables[members[0]].timeStrategy().tablePeriod(tableName)
+		if !ok {
+			continue
+		}
+		row := make([]string, len(members))
+		for k := range members {
+			if row[k], ok = periods[k][period]; !ok {
+				break
+			}
+		}
+		if ok {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// generatePlannedCombinations 生成各维度的笛卡尔积（最后一个维度变化最快）
+func generatePlannedCombinations(tableCount int, dimensions []joinDimension) [][]string {
+	for _, dimension := range dimensions {
+		if len(dimension.rows) == 0 {
+			return nil
+		}
+	}
+
+	var result [][]string
+	positions := make([]int, len(dimensions))
+	for {
+		combination := make([]string, tableCount)
+		for d, dimension := range dimensions {
+			for k, member := range dimension.members {
+				combination[member] = dimension.rows[positions[d]][k]
+			}
+		}
+		result = append(result, combination)
+
+		d := len(dimensions) - 1
+		for ; d >= 0; d-- {
+			positions[d]++
+			if positions[d] < len(dimensions[d].rows) {
+				break
+			}
+			positions[d] = 0
+		}
+		if d < 0 {
+			return result
+		}
+	}
+}
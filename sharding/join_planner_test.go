@@ -0,0 +1,93 @@
+package sharding
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// combinationsOf 获取多表连接的表组合（测试中的策略都不需要访问数据库）
+func combinationsOf(t *testing.T, config MultiJoinConfig) [][]string {
+	t.Helper()
+	combinations, err := getMultiJoinTableCombinations(nil, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return combinations
+}
+
+func TestJoinColocation(t *testing.T) {
+	config := MultiJoinConfig{
+		MainTable: JoinInfo{Strategy: NewHashShardingStrategy("users", "ID", 2)},
+		JoinTables: []JoinInfo{
+			{Strategy: NewHashShardingStrategy("orders", "UserID", 2), OnCondition: "users.id = orders.user_id"},
+			{Strategy: NewHashShardingStrategy("payments", "UserID", 2), Alias: "p", On: []JoinOn{{"orders", "user_id", "p", "user_id"}}},
+			{TableName: "statuses", OnCondition: "orders.status = statuses.id"},
+		},
+	}
+
+	want := [][]string{
+		{"users_0", "orders_0", "payments_0", "statuses"},
+		{"users_1", "orders_1", "payments_1", "statuses"},
+	}
+	if got := combinationsOf(t, config); !reflect.DeepEqual(got, want) {
+		t.Errorf("colocated: got %v, want %v", got, want)
+	}
+
+	// OR 条件中的等值关系不一定成立，不能裁剪
+	config.JoinTables[0].OnCondition = "users.id = orders.user_id OR orders.user_id = 0"
+	if got := combinationsOf(t, config); len(got) != 4 {
+		t.Errorf("disjunction: got %d combinations, want 4", len(got))
+	}
+
+	config.JoinTables[0].OnCondition = "users.id = orders.user_id"
+	config.DisableColocation = true
+	if got := combinationsOf(t, config); len(got) != 8 {
+		t.Errorf("colocation disabled: got %d combinations, want 8", len(got))
+	}
+}
+
+func TestJoinColocationShardCountMismatch(t *testing.T) {
+	config := MultiJoinConfig{
+		MainTable: JoinInfo{Strategy: NewHashShardingStrategy("users", "ID", 2)},
+		JoinTables: []JoinInfo{
+			{Strategy: NewHashShardingStrategy("orders", "UserID", 4), OnCondition: "users.id = orders.user_id"},
+		},
+	}
+	if got := combinationsOf(t, config); len(got) != 8 {
+		t.Errorf("got %d combinations, want 8", len(got))
+	}
+}
+
+func TestJoinTimeAlignment(t *testing.T) {
+	logs := NewTimeShardingStrategy("logs", "CreatedAt", TimeShardingByMonth)
+	events := NewTimeShardingStrategy("events", "CreatedAt", TimeShardingByMonth)
+	if err := events.SetNameTemplate("{base}_{yyyy}_{mm}"); err != nil {
+		t.Fatal(err)
+	}
+	config := MultiJoinConfig{
+		MainTable:  JoinInfo{Strategy: logs},
+		JoinTables: []JoinInfo{{Strategy: events, OnCondition: "logs.event_id = events.id"}},
+		TimeRanges: map[string]TimeRange{
+			"logs":   {StartTime: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), EndTime: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+			"events": {StartTime: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), EndTime: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+		},
+	}
+
+	// 不是时间字段的等值连接：所有分表两两连接
+	if got := combinationsOf(t, config); len(got) != 9 {
+		t.Errorf("unaligned: got %d combinations, want 9", len(got))
+	}
+
+	want := [][]string{{"logs_202402", "events_2024_02"}, {"logs_202403", "events_2024_03"}}
+	config.JoinTables[0].OnCondition = "logs.created_at = events.created_at"
+	if got := combinationsOf(t, config); !reflect.DeepEqual(got, want) {
+		t.Errorf("time key join: got %v, want %v", got, want)
+	}
+
+	config.JoinTables[0].OnCondition = "logs.event_id = events.id"
+	config.AlignTimePeriods = true
+	if got := combinationsOf(t, config); !reflect.DeepEqual(got, want) {
+		t.Errorf("AlignTimePeriods: got %v, want %v", got, want)
+	}
+}
@@ -3,6 +3,7 @@ package sharding
 import (
 	"fmt"
 	"reflect"
+	"regexp"
 	"strings"
 	"time"
 
@@ -16,10 +17,30 @@ const (
 	InnerJoin JoinType = "INNER"
 	LeftJoin  JoinType = "LEFT"
 	RightJoin JoinType = "RIGHT"
+	FullJoin  JoinType = "FULL" // 全外连接（不支持 FULL OUTER JOIN 的数据库如 MySQL，多表连接查询时通过 LEFT JOIN ∪ RIGHT JOIN 反连接模拟）
 )
 
+// joinAliasPattern 合法的表别名（只允许字母、数字和下划线，不能以数字开头）
+var joinAliasPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// sqlKeyword 校验 JOIN 类型并返回 SQL 关键字（为空时等同于 INNER）
+func (t JoinType) sqlKeyword() (string, error) {
+	switch keyword := JoinType(strings.ToUpper(strings.TrimSpace(string(t)))); keyword {
+	case "", InnerJoin:
+		return string(InnerJoin), nil
+	case LeftJoin, RightJoin:
+		return string(keyword), nil
+	case FullJoin:
+		return "FULL OUTER", nil
+	default:
+		return "", fmt.Errorf("%w: unsupported join type %q", ErrInvalidJoin, string(t))
+	}
+}
+
 // CrossTableJoin 跨表连接查询
 // 支持两个分表的连接查询
+// options: 执行选项（可选），以第一个策略的每个分表为单位通过 runOnShards 执行（连接该分表需要的所有第二个策略的分表）；
+// 指定 Cluster 时在第一个策略的分表所在的数据库上执行，设置 ContinueOnError 时同时返回成功的分表的结果和 ShardErrors
 func CrossTableJoin(
 	db *gorm.DB,
 	strategy1, strategy2 ShardingStrategy,
@@ -27,6 +48,7 @@ func CrossTableJoin(
 	onCondition string, // 例如: "users.id = orders.user_id"
 	dest interface{},
 	queryBuilder QueryBuilder,
+	options ...ShardQueryOptions,
 ) error {
 	// 获取两个策略的所有表名
 	tableNames1 := strategy1.GetAllTableNames(strategy1.GetBaseTableName())
@@ -45,16 +67,31 @@ func CrossTableJoin(
 		tableNames2 = timeStrategy2.GetAllTableNamesInRange(strategy2.GetBaseTableName(), startTime, endTime)
 	}
 
-	// 对于 Hash 分表，通常每个表之间都需要连接
-	// 这里采用笛卡尔积的方式，但实际上应该根据 join 条件进行优化
-	var allResults []map[string]interface{}
+	// 两个策略共置（分区方式相同，且 ON 条件是两边分表键的等值连接）时只连接相同索引的分表
+	// 否则采用笛卡尔积的方式连接所有分表
+	colocated := isColocated(strategy1, strategy2, onCondition) && len(tableNames1) == len(tableNames2)
+
+	joinKeyword, err := joinType.sqlKeyword()
+	if err != nil {
+		return err
+	}
+	if joinType == FullJoin && !GetCapabilities(db, nil).FullOuterJoin {
+		return fmt.Errorf("%w: %s does not support FULL OUTER JOIN, use CrossTableMultiJoin to emulate it", ErrInvalidJoin, db.Dialector.Name())
+	}
+
+	tableResults := make([][]map[string]interface{}, len(tableNames1))
+	queryErr := runOnShards(db, tableNames1, getShardQueryOptions(options), func(tx *gorm.DB, i int, table1 string) error {
+		candidates := tableNames2
+		if colocated {
+			candidates = tableNames2[i : i+1]
+		}
+
+		var shardResults []map[string]interface{}
+		for _, table2 := range candidates {
+			query := tx.Table(table1)
 
-	for _, table1 := range tableNames1 {
-		for _, table2 := range tableNames2 {
-			query := db.Table(table1)
-			
 			// 构建 JOIN 语句
-			joinSQL := fmt.Sprintf("%s JOIN %s ON %s", joinType, table2, onCondition)
+			joinSQL := fmt.Sprintf("%s JOIN %s ON %s", joinKeyword, tx.Statement.Quote(table2), onCondition)
 			query = query.Joins(joinSQL)
 
 			if queryBuilder != nil {
@@ -63,18 +100,53 @@ func CrossTableJoin(
 
 			var results []map[string]interface{}
 			if err := query.Find(&results).Error; err != nil {
-				if !strings.Contains(err.Error(), "doesn't exist") {
+				if !isTableNotFoundError(err) {
 					return err
 				}
 				continue
 			}
 
-			allResults = append(allResults, results...)
+			shardResults = append(shardResults, results...)
 		}
+		tableResults[i] = shardResults
+		return nil
+	})
+	if queryErr != nil && !isPartialShardError(queryErr) {
+		return queryErr
 	}
 
-	// 将结果转换为目标类型
-	return convertResults(allResults, dest)
+	// 按分表顺序合并结果并转换为目标类型
+	var allResults []map[string]interface{}
+	for _, results := range tableResults {
+		allResults = append(allResults, results...)
+	}
+	if err := convertResults(allResults, dest); err != nil {
+		return err
+	}
+	return queryErr
+}
+
+// CrossTableJoinWithStatic 分表与未分表的普通表（如 users）的连接查询
+// 每个分表都连接同一个普通表，不需要为普通表创建单表策略；onCondition 中使用分表的基础表名和 staticTable（如 "orders.user_id = users.id"）
+// 结果不去重（与 CrossTableJoin 相同）
+func CrossTableJoinWithStatic(
+	db *gorm.DB,
+	strategy ShardingStrategy,
+	staticTable string,
+	joinType JoinType,
+	onCondition string,
+	dest interface{},
+	queryBuilder QueryBuilder,
+	options ...ShardQueryOptions,
+) error {
+	config := MultiJoinConfig{
+		MainTable: JoinInfo{Strategy: strategy},
+		JoinTables: []JoinInfo{
+			{TableName: staticTable, JoinType: joinType, OnCondition: onCondition},
+		},
+		Deduplicator: NoDeduplication(),
+	}
+	return CrossTableMultiJoin(db, config, dest, queryBuilder, options...)
 }
 
 // CrossTableJoinOptimized 优化的跨表连接查询
@@ -86,12 +158,13 @@ func CrossTableJoinOptimized(
 	joinKey string, // JOIN 的键字段，用于确定哪些表需要连接
 	dest interface{},
 	queryBuilder QueryBuilder,
+	options ...ShardQueryOptions,
 ) error {
 	// 这种方法需要根据实际的业务逻辑来确定哪些表需要连接
 	// 例如：如果两个表都是基于 user_id 分表的，则 users_0 应该只连接 orders_0
 	
 	// 简化为对所有可能的表对进行连接
-	return CrossTableJoin(db, strategy1, strategy2, joinType, joinKey, dest, queryBuilder)
+	return CrossTableJoin(db, strategy1, strategy2, joinType, joinKey, dest, queryBuilder, options...)
 }
 
 // convertResults 将 map 结果转换为目标类型
@@ -0,0 +1,69 @@
+package sharding
+
+import "fmt"
+
+// JumpHashShardingStrategy 基于 Jump Consistent Hash（Lamping & Veach）的分表策略
+// 与哈希环相比不需要保存虚拟节点，只需要分表数量；分表数量从 n 增加到 n+1 时，
+// 只有约 1/(n+1) 的数据需要移动，并且只会移动到新增的分表中。
+// 注意：只支持在末尾增加（或删除）分表，不支持删除中间的分表
+type JumpHashShardingStrategy struct {
+	baseTableName string
+	shardingKey   string // 分表键字段名
+	tableCount    int    // 分表数量
+
+	ShardOptionsMap // 分表级别的建表选项（通过 SetShardOptions 配置）
+}
+
+// NewJumpHashShardingStrategy 创建 Jump Consistent Hash 分表策略
+// tableCount: 分表数量（如 4，将创建 users_0, users_1, users_2, users_3）
+func NewJumpHashShardingStrategy(baseTableName, shardingKey string, tableCount int) *JumpHashShardingStrategy {
+	if tableCount <= 0 {
+		tableCount = 1
+	}
+	return &JumpHashShardingStrategy{
+		baseTableName: baseTableName,
+		shardingKey:   shardingKey,
+		tableCount:    tableCount,
+	}
+}
+
+// GetTableName 根据分表键值获取实际表名
+func (s *JumpHashShardingStrategy) GetTableName(baseTableName string, shardingValue interface{}) string {
+	tableIndex := jumpHash(hashShardingValue(shardingValue), s.tableCount)
+	return fmt.Sprintf("%s_%d", baseTableName, tableIndex)
+}
+
+// GetAllTableNames 获取所有分表名称
+func (s *JumpHashShardingStrategy) GetAllTableNames(baseTableName string) []string {
+	tableNames := make([]string, s.tableCount)
+	for i := 0; i < s.tableCount; i++ {
+		tableNames[i] = fmt.Sprintf("%s_%d", baseTableName, i)
+	}
+	return tableNames
+}
+
+// GetShardingValue 从模型对象中提取分表键值
+func (s *JumpHashShardingStrategy) GetShardingValue(value interface{}) (interface{}, error) {
+	return ExtractValue(value, s.shardingKey)
+}
+
+// GetBaseTableName 获取基础表名
+func (s *JumpHashShardingStrategy) GetBaseTableName() string {
+	return s.baseTableName
+}
+
+// GetShardingKey 获取分表键字段名
+func (s *JumpHashShardingStrategy) GetShardingKey() string {
+	return s.shardingKey
+}
+
+// jumpHash Jump Consistent Hash：将 key 映射到 [0, buckets) 中的一个桶
+func jumpHash(key uint64, buckets int) int {
+	var b, j int64 = -1, 0
+	for j < int64(buckets) {
+		b = j
+		key = key*2862933555777941757 + 1
+		j = int64(float64(b+1) * (float64(int64(1)<<31) / float64((key>>33)+1)))
+	}
+	return int(b)
+}
@@ -0,0 +1,133 @@
+package sharding
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// defaultListTableSuffix 未匹配任何映射的值默认路由到的分表后缀
+const defaultListTableSuffix = "default"
+
+// ListShardingStrategy 列表分表策略，按显式的值映射路由（如国家代码、租户等级）
+// 例如：{"CN": "cn", "US": "us"} 将 CN 路由到 users_cn，US 路由到 users_us，其他值路由到 users_default
+// 值按字符串形式比较（整数 1 与字符串 "1" 视为同一个值）；映射可以在运行时增删
+type ListShardingStrategy struct {
+	baseTableName string
+	shardingKey   string
+
+	mu            sync.RWMutex
+	mapping       map[string]string // 值 -> 分表后缀
+	defaultSuffix string            // 未匹配时使用的分表后缀
+
+	ShardOptionsMap // 分表级别的建表选项（通过 SetShardOptions 配置）
+}
+
+// NewListShardingStrategy 创建列表分表策略
+// mapping: 值 -> 分表后缀（分表名为 基础表名_后缀），未匹配的值路由到 基础表名_default（可通过 SetDefaultTable 修改）
+func NewListShardingStrategy(baseTableName, shardingKey string, mapping map[interface{}]string) *ListShardingStrategy {
+	strategy := &ListShardingStrategy{
+		baseTableName: baseTableName,
+		shardingKey:   shardingKey,
+		mapping:       make(map[string]string, len(mapping)),
+		defaultSuffix: defaultListTableSuffix,
+	}
+	for value, suffix := range mapping {
+		strategy.mapping[listValueKey(value)] = suffix
+	}
+	return strategy
+}
+
+// SetDefaultTable 设置未匹配任何映射的值路由到的分表后缀
+func (s *ListShardingStrategy) SetDefaultTable(suffix string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.defaultSuffix = suffix
+}
+
+// AddMapping 添加或修改值的映射（已写入旧分表的数据需要自行迁移）
+func (s *ListShardingStrategy) AddMapping(value interface{}, suffix string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mapping[listValueKey(value)] = suffix
+}
+
+// RemoveMapping 删除值的映射，之后该值路由到默认分表
+func (s *ListShardingStrategy) RemoveMapping(value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.mapping, listValueKey(value))
+}
+
+// Mapping 获取当前的映射（值的字符串形式 -> 分表后缀）
+func (s *ListShardingStrategy) Mapping() map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	mapping := make(map[string]string, len(s.mapping))
+	for value, suffix := range s.mapping {
+		mapping[value] = suffix
+	}
+	return mapping
+}
+
+// GetTableName 根据分表键值获取实际表名
+func (s *ListShardingStrategy) GetTableName(baseTableName string, shardingValue interface{}) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	suffix, ok := s.mapping[listValueKey(shardingValue)]
+	if !ok {
+		suffix = s.defaultSuffix
+	}
+	return fmt.Sprintf("%s_%s", baseTableName, suffix)
+}
+
+// GetAllTableNames 获取所有分表名称（映射中的所有分表和默认分表，按名称排序）
+func (s *ListShardingStrategy) GetAllTableNames(baseTableName string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	suffixes := map[string]bool{s.defaultSuffix: true}
+	for _, suffix := range s.mapping {
+		suffixes[suffix] = true
+	}
+
+	tableNames := make([]string, 0, len(suffixes))
+	for suffix := range suffixes {
+		tableNames = append(tableNames, fmt.Sprintf("%s_%s", baseTableName, suffix))
+	}
+	sort.Strings(tableNames)
+	return tableNames
+}
+
+// GetShardingValue 从模型对象中提取分表键值
+func (s *ListShardingStrategy) GetShardingValue(value interface{}) (interface{}, error) {
+	return ExtractValue(value, s.shardingKey)
+}
+
+// GetBaseTableName 获取基础表名
+func (s *ListShardingStrategy) GetBaseTableName() string {
+	return s.baseTableName
+}
+
+// GetShardingKey 获取分表键字段名
+func (s *ListShardingStrategy) GetShardingKey() string {
+	return s.shardingKey
+}
+
+// listValueKey 获取值在映射中的键（解引用指针后的字符串形式）
+func listValueKey(value interface{}) string {
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return "<nil>"
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return "<nil>"
+	}
+	return fmt.Sprint(rv.Interface())
+}
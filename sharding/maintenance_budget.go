@@ -0,0 +1,65 @@
+package sharding
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MaintenanceBudget 前台请求中顺带触发的维护操作（如插入时检查分表是否存在、自动建表）的时间预算
+// 维护操作使用独立的 context，不会占用超过 Timeout 的请求时间
+type MaintenanceBudget struct {
+	Timeout time.Duration // 单次维护操作最多占用的时间（0 表示不限制，只受请求 context 约束）
+	Detach  bool          // 不随请求 context 取消或超时（只受 Timeout 约束），避免请求被取消时建表执行到一半
+}
+
+// maintenanceBudgetKey 请求级维护预算在 context 中的键
+type maintenanceBudgetKey struct{}
+
+var defaultMaintenanceBudget struct {
+	mu     sync.RWMutex
+	budget MaintenanceBudget
+}
+
+// SetMaintenanceBudget 设置默认的维护操作时间预算（注册时未指定预算的分表使用）
+func SetMaintenanceBudget(budget MaintenanceBudget) {
+	defaultMaintenanceBudget.mu.Lock()
+	defer defaultMaintenanceBudget.mu.Unlock()
+	defaultMaintenanceBudget.budget = budget
+}
+
+// WithMaintenanceBudget 为单个请求指定维护操作的时间预算，优先于注册时和默认的预算
+// 用法：db.WithContext(WithMaintenanceBudget(ctx, MaintenanceBudget{Timeout: 50 * time.Millisecond})).Create(&order)
+func WithMaintenanceBudget(ctx context.Context, budget MaintenanceBudget) context.Context {
+	return context.WithValue(ctx, maintenanceBudgetKey{}, budget)
+}
+
+// resolveMaintenanceBudget 获取生效的维护预算：请求 context > 注册时指定 > 默认
+func resolveMaintenanceBudget(ctx context.Context, registered *MaintenanceBudget) MaintenanceBudget {
+	if ctx != nil {
+		if budget, ok := ctx.Value(maintenanceBudgetKey{}).(MaintenanceBudget); ok {
+			return budget
+		}
+	}
+	if registered != nil {
+		return *registered
+	}
+
+	defaultMaintenanceBudget.mu.RLock()
+	defer defaultMaintenanceBudget.mu.RUnlock()
+	return defaultMaintenanceBudget.budget
+}
+
+// maintenanceContext 根据预算从请求 context 派生维护操作使用的 context（保留 context 中的值，如 DDL 发起者）
+func maintenanceContext(parent context.Context, budget MaintenanceBudget) (context.Context, context.CancelFunc) {
+	if parent == nil {
+		parent = context.Background()
+	}
+	if budget.Detach {
+		parent = context.WithoutCancel(parent)
+	}
+	if budget.Timeout > 0 {
+		return context.WithTimeout(parent, budget.Timeout)
+	}
+	return context.WithCancel(parent)
+}
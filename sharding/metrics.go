@@ -0,0 +1,50 @@
+package sharding
+
+import (
+	"sync"
+	"time"
+)
+
+// ShardMetrics 分表路由和跨表操作的指标收集器，通过 SetShardMetrics 设置
+// prommetrics 包提供了基于 Prometheus 的实现；方法会被并发调用，实现需要是并发安全的
+type ShardMetrics interface {
+	// ObserveShardQuery 跨表操作在单个分表上的一次执行（包括重试的每一次）
+	ObserveShardQuery(tableName string, duration time.Duration, err error)
+	// ObserveFanOut 一次跨表操作涉及的分表数量（多表连接为表组合数量）
+	ObserveFanOut(width int)
+	// IncSkippedMissingTable 分表不存在而被跳过（多表连接为表组合中的主表）
+	IncSkippedMissingTable(tableName string)
+	// AddDedupDropped 跨表合并结果时去重丢弃的行数
+	AddDedupDropped(count int)
+}
+
+// defaultShardMetrics 全局指标收集器
+var defaultShardMetrics struct {
+	mu      sync.RWMutex
+	metrics ShardMetrics
+}
+
+// SetShardMetrics 设置全局指标收集器（nil 表示不收集）
+func SetShardMetrics(metrics ShardMetrics) {
+	defaultShardMetrics.mu.Lock()
+	defer defaultShardMetrics.mu.Unlock()
+	defaultShardMetrics.metrics = metrics
+}
+
+// shardMetrics 获取全局指标收集器（未设置时为不收集的实现）
+func shardMetrics() ShardMetrics {
+	defaultShardMetrics.mu.RLock()
+	defer defaultShardMetrics.mu.RUnlock()
+	if defaultShardMetrics.metrics == nil {
+		return noopShardMetrics{}
+	}
+	return defaultShardMetrics.metrics
+}
+
+// noopShardMetrics 不收集指标
+type noopShardMetrics struct{}
+
+func (noopShardMetrics) ObserveShardQuery(string, time.Duration, error) {}
+func (noopShardMetrics) ObserveFanOut(int)                              {}
+func (noopShardMetrics) IncSkippedMissingTable(string)                  {}
+func (noopShardMetrics) AddDedupDropped(int)                            {}
@@ -0,0 +1,111 @@
+package sharding
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// PlannedMigration 单个分表在迁移时将要执行的 DDL
+type PlannedMigration struct {
+	Table      string   `json:"table"`
+	Statements []string `json:"statements"` // 为空表示分表结构已是最新
+}
+
+// AutoMigrateDryRun 生成 AutoMigrate 对每个分表将要执行的 CREATE/ALTER 语句，不修改数据库，便于 DBA 先审核
+// 查询表结构的语句照常执行（需要能连接数据库），DDL 只记录不执行；选项与 AutoMigrate 相同（忽略 Concurrency）
+func AutoMigrateDryRun(db *gorm.DB, strategy ShardingStrategy, model interface{}, options ...AutoMigrateOptions) ([]PlannedMigration, error) {
+	baseTableName := strategy.GetBaseTableName()
+	tableNames := strategy.GetAllTableNames(baseTableName)
+	if len(tableNames) == 0 || (len(tableNames) == 1 && tableNames[0] == baseTableName) {
+		timeStrategy, ok := strategy.(*TimeShardingStrategy)
+		if !ok {
+			return nil, fmt.Errorf("no tables to migrate for strategy %s", baseTableName)
+		}
+		tableNames = timeMigrationTableNames(timeStrategy, options...)
+	}
+
+	skipIfExists := len(options) > 0 && options[0].SkipIfExists
+	return planMigrations(db, strategy, tableNames, model, skipIfExists)
+}
+
+// planMigrations 生成每个分表的迁移语句（SkipIfExists 时跳过已存在的分表）
+func planMigrations(db *gorm.DB, strategy ShardingStrategy, tableNames []string, model interface{}, skipIfExists bool) ([]PlannedMigration, error) {
+	plans := make([]PlannedMigration, 0, len(tableNames))
+	for _, tableName := range tableNames {
+		if skipIfExists && tableExists(db, tableName) {
+			continue
+		}
+		statements, err := planMigration(db, strategy, tableName, model)
+		if err != nil {
+			return plans, fmt.Errorf("failed to plan migration of table %s: %w", tableName, err)
+		}
+		plans = append(plans, PlannedMigration{Table: tableName, Statements: statements})
+	}
+	return plans, nil
+}
+
+// planMigration 在记录 DDL 的连接上执行 AutoMigrate，返回将要执行的语句
+func planMigration(db *gorm.DB, strategy ShardingStrategy, tableName string, model interface{}) ([]string, error) {
+	ctx := db.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	tx := db.WithContext(ctx)
+	pool := &dryRunConnPool{ConnPool: tx.Statement.ConnPool, dialector: tx.Dialector}
+	tx.Statement.ConnPool = pool
+
+	tx, err := withShardTableOptions(tx, strategy, tableName)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Table(tableName).AutoMigrate(model); err != nil {
+		return nil, err
+	}
+	return pool.planned(), nil
+}
+
+// logMigrationPlan AutoMigrateOptions.DryRun 时通过 db.Logger 输出每个分表将要执行的语句
+func logMigrationPlan(db *gorm.DB, strategy ShardingStrategy, tableNames []string, model interface{}, skipIfExists bool) error {
+	plans, err := planMigrations(db, strategy, tableNames, model, skipIfExists)
+	if err != nil {
+		return err
+	}
+	ctx := db.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	for _, plan := range plans {
+		for _, statement := range plan.Statements {
+			db.Logger.Info(ctx, "[dry run] %s: %s", plan.Table, statement)
+		}
+	}
+	return nil
+}
+
+// dryRunConnPool 记录 DDL 而不执行的连接：查询照常执行（AutoMigrate 需要读取表结构），Exec 只记录语句
+type dryRunConnPool struct {
+	gorm.ConnPool
+	dialector  gorm.Dialector
+	mu         sync.Mutex
+	statements []string
+}
+
+// ExecContext 记录语句，不执行（gorm.ConnPool 接口）
+func (p *dryRunConnPool) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	p.mu.Lock()
+	p.statements = append(p.statements, p.dialector.Explain(query, args...))
+	p.mu.Unlock()
+	return driver.RowsAffected(0), nil
+}
+
+// planned 获取记录的语句
+func (p *dryRunConnPool) planned() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.statements...)
+}
@@ -0,0 +1,131 @@
+package sharding
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// multiJoinCacheMaxEntries 多表连接结果缓存的最大条目数
+const multiJoinCacheMaxEntries = 64
+
+// multiJoinCacheEntry 缓存条目
+type multiJoinCacheEntry struct {
+	results   []map[string]interface{}
+	expiresAt time.Time
+}
+
+// multiJoinCacheKey 缓存键：数据库的连接池（同一个数据库的会话和 WithContext 共用连接池）、多数据源和查询指纹，
+// 不同数据库上相同的查询不会共用结果
+type multiJoinCacheKey struct {
+	connPool    gorm.ConnPool
+	cluster     *ShardCluster
+	fingerprint string
+}
+
+// multiJoinResultCache 多表连接去重结果缓存（有界，按插入顺序淘汰）
+type multiJoinResultCache struct {
+	mu      sync.Mutex
+	entries map[multiJoinCacheKey]multiJoinCacheEntry
+	order   []multiJoinCacheKey // 插入顺序，用于淘汰最早的条目
+}
+
+// defaultMultiJoinCache 默认的多表连接结果缓存
+var defaultMultiJoinCache = &multiJoinResultCache{
+	entries: make(map[multiJoinCacheKey]multiJoinCacheEntry),
+}
+
+// get 获取未过期的缓存结果（返回副本，调用方修改结果不影响缓存）
+func (c *multiJoinResultCache) get(key multiJoinCacheKey) ([]map[string]interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expiresAt) {
+		c.remove(key)
+		return nil, false
+	}
+	return cloneMultiJoinResults(entry.results), true
+}
+
+// set 写入缓存结果的副本，超过最大条目数时淘汰最早的条目
+func (c *multiJoinResultCache) set(key multiJoinCacheKey, results []map[string]interface{}, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.entries[key]; ok {
+		c.remove(key)
+	}
+	for len(c.order) >= multiJoinCacheMaxEntries {
+		c.remove(c.order[0])
+	}
+
+	c.entries[key] = multiJoinCacheEntry{
+		results:   cloneMultiJoinResults(results),
+		expiresAt: time.Now().Add(ttl),
+	}
+	c.order = append(c.order, key)
+}
+
+// remove 删除缓存条目（调用方需持有锁）
+func (c *multiJoinResultCache) remove(key multiJoinCacheKey) {
+	delete(c.entries, key)
+	for i, k := range c.order {
+		if k == key {
+			c.order = append(c.order[:i], c.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// cloneMultiJoinResults 深拷贝查询结果（每一行的 map 和 []byte 值）
+func cloneMultiJoinResults(results []map[string]interface{}) []map[string]interface{} {
+	cloned := make([]map[string]interface{}, len(results))
+	for i, row := range results {
+		clonedRow := make(map[string]interface{}, len(row))
+		for column, value := range row {
+			if b, ok := value.([]byte); ok {
+				value = append([]byte(nil), b...)
+			}
+			clonedRow[column] = value
+		}
+		cloned[i] = clonedRow
+	}
+	return cloned
+}
+
+// multiJoinCacheKeyOf 获取多表连接查询的缓存键
+func multiJoinCacheKeyOf(db *gorm.DB, config MultiJoinConfig, tableCombinations [][]string, queryBuilder QueryBuilder, deduplicator Deduplicator, options ShardQueryOptions) multiJoinCacheKey {
+	return multiJoinCacheKey{
+		connPool:    db.Config.ConnPool,
+		cluster:     options.Cluster,
+		fingerprint: multiJoinFingerprint(db, config, tableCombinations, queryBuilder, deduplicator),
+	}
+}
+
+// multiJoinFingerprint 计算多表连接查询的指纹（基于所有表组合生成的 SQL、去重方式和影响结果的去重、计数配置）
+func multiJoinFingerprint(db *gorm.DB, config MultiJoinConfig, tableCombinations [][]string, queryBuilder QueryBuilder, deduplicator Deduplicator) string {
+	hash := sha256.New()
+	for _, combination := range tableCombinations {
+		sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+			query := buildMultiJoinQuery(tx, config, combination)
+			if queryBuilder != nil {
+				query = queryBuilder(query)
+			}
+			return query.Find(&[]map[string]interface{}{})
+		})
+		hash.Write([]byte(sql))
+		hash.Write([]byte{0})
+	}
+	hash.Write([]byte(fmt.Sprintf("%T%v", deduplicator, deduplicator)))
+	// 影响去重和计数结果的配置
+	fmt.Fprintf(hash, "\x00%d\x00%q\x00%q\x00%q\x00%t", config.DedupMode, config.DedupColumns, config.DeduplicateFields, config.CountKey, config.CountInMemory)
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,105 @@
+package sharding
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// newTestMultiJoinCache 创建空的多表连接结果缓存
+func newTestMultiJoinCache() *multiJoinResultCache {
+	return &multiJoinResultCache{entries: make(map[multiJoinCacheKey]multiJoinCacheEntry)}
+}
+
+func TestMultiJoinCacheReturnsCopies(t *testing.T) {
+	cache := newTestMultiJoinCache()
+	key := multiJoinCacheKey{fingerprint: "a"}
+	results := []map[string]interface{}{{"id": int64(1), "name": []byte("alice")}}
+	cache.set(key, results, time.Minute)
+
+	// 修改写入缓存的结果和读取到的结果都不影响缓存
+	results[0]["id"] = int64(2)
+	results[0]["name"].([]byte)[0] = 'X'
+	got, ok := cache.get(key)
+	if !ok {
+		t.Fatal("cache miss")
+	}
+	got[0]["id"] = int64(3)
+	got[0]["name"].([]byte)[1] = 'Y'
+
+	got, _ = cache.get(key)
+	if got[0]["id"] != int64(1) || string(got[0]["name"].([]byte)) != "alice" {
+		t.Errorf("cached row = %v, want the original row", got[0])
+	}
+}
+
+func TestMultiJoinCacheExpiryAndEviction(t *testing.T) {
+	cache := newTestMultiJoinCache()
+	expired := multiJoinCacheKey{fingerprint: "expired"}
+	cache.set(expired, nil, -time.Second)
+	if _, ok := cache.get(expired); ok {
+		t.Error("expired entry returned")
+	}
+	if len(cache.entries) != 0 || len(cache.order) != 0 {
+		t.Errorf("expired entry not removed: %d entries, %d keys", len(cache.entries), len(cache.order))
+	}
+
+	for i := 0; i <= multiJoinCacheMaxEntries; i++ {
+		cache.set(multiJoinCacheKey{fingerprint: fmt.Sprint(i)}, nil, time.Minute)
+	}
+	if len(cache.entries) != multiJoinCacheMaxEntries {
+		t.Errorf("got %d entries, want %d", len(cache.entries), multiJoinCacheMaxEntries)
+	}
+	if _, ok := cache.get(multiJoinCacheKey{fingerprint: "0"}); ok {
+		t.Error("oldest entry not evicted")
+	}
+	if _, ok := cache.get(multiJoinCacheKey{fingerprint: fmt.Sprint(multiJoinCacheMaxEntries)}); !ok {
+		t.Error("newest entry evicted")
+	}
+}
+
+func TestMultiJoinCacheKey(t *testing.T) {
+	db := newDryRunDB(t)
+	config := MultiJoinConfig{
+		MainTable:  JoinInfo{Strategy: NewHashShardingStrategy("users", "ID", 2)},
+		JoinTables: []JoinInfo{{Strategy: NewHashShardingStrategy("orders", "UserID", 2), JoinType: InnerJoin, OnCondition: "users.id = orders.user_id"}},
+	}
+	combinations := combinationsOf(t, config)
+	byUser := func(id int) QueryBuilder {
+		return func(tx *gorm.DB) *gorm.DB { return tx.Where("users.id = ?", id) }
+	}
+	keyOf := func(db *gorm.DB, config MultiJoinConfig, queryBuilder QueryBuilder) multiJoinCacheKey {
+		return multiJoinCacheKeyOf(db, config, combinations, queryBuilder, config.deduplicator(db, nil), ShardQueryOptions{})
+	}
+
+	base := keyOf(db, config, byUser(1))
+	if keyOf(db, config, byUser(1)) != base {
+		t.Error("identical queries have different keys")
+	}
+	if keyOf(db.WithContext(context.Background()), config, byUser(1)) != base {
+		t.Error("sessions of the same db have different keys")
+	}
+	if keyOf(newDryRunDB(t), config, byUser(1)) == base {
+		t.Error("different dbs share a key")
+	}
+	if keyOf(db, config, byUser(2)) == base {
+		t.Error("different query values share a key")
+	}
+
+	changes := map[string]func(config *MultiJoinConfig){
+		"DedupMode":     func(config *MultiJoinConfig) { config.DedupMode = DedupDistinct },
+		"DedupColumns":  func(config *MultiJoinConfig) { config.DedupColumns = []string{"users.id"} },
+		"CountKey":      func(config *MultiJoinConfig) { config.CountKey = "users.id" },
+		"CountInMemory": func(config *MultiJoinConfig) { config.CountInMemory = true },
+	}
+	for name, change := range changes {
+		changed := config
+		change(&changed)
+		if keyOf(db, changed, byUser(1)) == base {
+			t.Errorf("changing %s does not change the key", name)
+		}
+	}
+}
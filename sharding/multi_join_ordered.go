@@ -0,0 +1,178 @@
+package sharding
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"gorm.io/gorm"
+)
+
+// CrossTableMultiJoinOrderedPaginate 多表连接查询的有序分页（k 路归并）
+// 每个表组合只查询 ORDER BY ... LIMIT offset+pageSize 条数据，然后在内存中按排序列归并、去重，
+// 只需读取 O(表组合数 × (offset+pageSize)) 条数据，而不是加载全部结果后再分页；翻到很深的页时使用 CrossTableMultiJoinSeekOptimized
+// orderBy: 排序列（如 "orders.created_at"，为空时从 queryBuilder 的 Order() 中解析）
+// 总数通过 CrossTableMultiJoinCount 在数据库中计算
+func CrossTableMultiJoinOrderedPaginate(
+	db *gorm.DB,
+	config MultiJoinConfig,
+	dest interface{},
+	page, pageSize int,
+	orderBy []OrderByColumn,
+	queryBuilder QueryBuilder,
+	options ...PaginateOptions,
+) (*Paginator, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	if err := validateMultiJoinConfig(config); err != nil {
+		return nil, err
+	}
+
+	// 未指定排序列时，从查询构建器中解析；此时 ORDER BY 已由查询构建器添加
+	pushOrder := true
+	if len(orderBy) == 0 {
+		orderBy = parseOrderByFromQuery(db, queryBuilder)
+		pushOrder = false
+	}
+	if len(orderBy) == 0 {
+		return nil, fmt.Errorf("order by columns are required for ordered merge")
+	}
+	opts := getPaginateOptions(options)
+
+	total, err := crossTableMultiJoinCount(db, config, queryBuilder, dest, opts.ShardQueryOptions)
+	if err != nil && !isPartialShardError(err) {
+		return nil, err
+	}
+	shardErr := err
+
+	totalPages := int(total) / pageSize
+	if int(total)%pageSize > 0 {
+		totalPages++
+	}
+	page, outOfRange, err := resolvePage(page, totalPages, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// 每个表组合只需要前 offset+pageSize 条数据
+	offset := (page - 1) * pageSize
+	combinationResults, err := queryEachMultiJoinCombination(db, config, func(tx *gorm.DB) *gorm.DB {
+		if queryBuilder != nil {
+			tx = queryBuilder(tx)
+		}
+		if pushOrder {
+			for _, column := range orderBy {
+				tx = tx.Order(sortOrderBy(tx, tx.Statement.Quote(column.Column), column.Desc))
+			}
+		}
+		return tx.Limit(offset + pageSize)
+	}, opts.ShardQueryOptions)
+	if err != nil {
+		if !isPartialShardError(err) {
+			return nil, err
+		}
+		shardErr = appendShardErrors(shardErr, err)
+	}
+
+	// 结果中的列名不带表名（如 orders.created_at 为 created_at）
+	keyColumns := make([]string, len(orderBy))
+	for i, column := range orderBy {
+		keyColumns[i] = columnName(column.Column)
+	}
+	sortKeys := func(value reflect.Value) []interface{} {
+		row := value.Interface().(map[string]interface{})
+		keys := make([]interface{}, len(keyColumns))
+		for i, column := range keyColumns {
+			keys[i] = row[column]
+		}
+		return keys
+	}
+
+	// 模拟的 FULL JOIN 的结果由两个各自有序的查询结果拼接而成，需要重新排序
+	if index, err := emulatedFullJoinIndex(db, config); err != nil {
+		return nil, err
+	} else if index >= 0 {
+		for _, results := range combinationResults {
+			sort.SliceStable(results, func(i, j int) bool {
+				return compareSortKeys(sortKeys(reflect.ValueOf(results[i])), sortKeys(reflect.ValueOf(results[j])), orderBy) < 0
+			})
+		}
+	}
+
+	shardResults := make([]reflect.Value, len(combinationResults))
+	fetched := 0
+	for i, results := range combinationResults {
+		shardResults[i] = reflect.ValueOf(results)
+		fetched += len(results)
+	}
+
+	// 归并后去重，取 [offset, offset+pageSize) 区间的数据
+	deduplicator := config.deduplicator(db, dest)
+	seenKeys := make(map[string]bool)
+	pageResults := make([]map[string]interface{}, 0, pageSize)
+	position, dropped := 0, 0
+	merged, err := kWayMergeBy(shardResults, orderBy, sortKeys, fetched)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range merged {
+		result := row.value.Interface().(map[string]interface{})
+		if key, ok := deduplicator.Key(result); ok {
+			if seenKeys[key] {
+				dropped++
+				continue
+			}
+			seenKeys[key] = true
+		}
+		if position >= offset {
+			pageResults = append(pageResults, result)
+			if len(pageResults) == pageSize {
+				break
+			}
+		}
+		position++
+	}
+	shardMetrics().AddDedupDropped(dropped)
+
+	if err := convertResults(pageResults, dest); err != nil {
+		return nil, err
+	}
+
+	return &Paginator{
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      total,
+		TotalPages: totalPages,
+		OutOfRange: outOfRange,
+		Data:       dest,
+		Partial:    shardErr != nil,
+	}, shardErr
+}
+
+// queryEachMultiJoinCombination 通过 runOnCombinations 对每个表组合执行连接查询，按表组合的顺序返回各自的结果
+// 设置 ContinueOnError 时失败的表组合结果为空，同时返回 ShardErrors
+func queryEachMultiJoinCombination(db *gorm.DB, config MultiJoinConfig, queryBuilder QueryBuilder, options ShardQueryOptions) ([][]map[string]interface{}, error) {
+	tableCombinations, err := multiJoinCombinations(db, config)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([][]map[string]interface{}, len(tableCombinations))
+	err = runOnCombinations(db, config, tableCombinations, options, func(tx *gorm.DB, index int, combination []string) error {
+		combinationResults, err := queryMultiJoinCombination(tx, config, combination, queryBuilder)
+		if err != nil {
+			return fmt.Errorf("query error on tables %v: %w", combination, err)
+		}
+		results[index] = combinationResults
+		return nil
+	})
+	if err != nil && !isPartialShardError(err) {
+		return nil, err
+	}
+	return results, err
+}
@@ -1,111 +1,267 @@
 package sharding
 
 import (
+	"database/sql"
 	"fmt"
-	"reflect"
-	"strconv"
 	"strings"
 	"time"
 
 	"gorm.io/gorm"
 )
 
-// CrossTableMultiJoinCount 多表连接查询的计数（自动去重）
-// 为了确保计数准确，先查询所有结果，然后去重计数
+// CrossTableMultiJoinCount 多表连接查询的计数，与 CrossTableMultiJoin 返回的（去重后的）行数一致
+// 在内存中去重时统计去重后的结果（启用 CacheTTL 时与分页复用同一份结果）；
+// 没有去重（Deduplicator 为 NoDeduplication()）时在每个表组合中执行 COUNT(DISTINCT config.CountKey)（未设置时为 COUNT(*)）并累加，
+// 不需要读取数据行，设置 config.CountInMemory 时仍然查询所有结果后计数；
+// config.DedupMode 为数据库去重时统计每个表组合去重后的行数并累加
+// options: 表组合的执行选项（可选）；设置 ContinueOnError 时同时返回成功的表组合的计数和 ShardErrors
 func CrossTableMultiJoinCount(
 	db *gorm.DB,
 	config MultiJoinConfig,
 	queryBuilder QueryBuilder,
+	options ...ShardQueryOptions,
 ) (int64, error) {
-	// 为了准确计数并去重，先查询所有结果，然后去重计数
-	// 这样可以确保计数和查询结果一致
-	var tempResults []map[string]interface{}
+	return crossTableMultiJoinCount(db, config, queryBuilder, nil, getShardQueryOptions(options))
+}
 
-	// 获取主表的所有分表名称
-	mainTableNames := getTableNamesWithTimeRange(config.MainTable.Strategy, config.MainTable.Strategy.GetBaseTableName(), config.TimeRanges)
+// crossTableMultiJoinCount 多表连接查询计数的实现，dest 用于确定内存计数时的默认去重方式（与查询结果一致）
+func crossTableMultiJoinCount(db *gorm.DB, config MultiJoinConfig, queryBuilder QueryBuilder, dest interface{}, options ShardQueryOptions) (int64, error) {
+	if err := validateMultiJoinConfig(config); err != nil {
+		return 0, err
+	}
 
-	// 获取所有连接表的分表名称
-	joinTableNamesList := make([][]string, len(config.JoinTables))
-	for i, joinInfo := range config.JoinTables {
-		joinTableNamesList[i] = getTableNamesWithTimeRange(joinInfo.Strategy, joinInfo.Strategy.GetBaseTableName(), config.TimeRanges)
+	if config.DedupMode != DedupInMemory {
+		return countMultiJoinCombinations(db, config, queryBuilder, options)
 	}
 
-	// 构建表名到别名的映射
-	mainBaseName := config.MainTable.Strategy.GetBaseTableName()
-	mainAlias := config.MainTable.Alias
-	if mainAlias == "" {
-		mainAlias = mainBaseName
+	// 没有去重时各表组合的行数之和就是结果的行数，使用 COUNT 不需要读取数据行
+	deduplicator := config.deduplicator(db, dest)
+	if _, ok := deduplicator.(noDeduplication); ok && !config.CountInMemory {
+		return countMultiJoinCombinations(db, config, queryBuilder, options)
 	}
 
-	joinAliases := make([]string, len(config.JoinTables))
-	for i, joinInfo := range config.JoinTables {
-		if joinInfo.Alias != "" {
-			joinAliases[i] = joinInfo.Alias
-		} else {
-			joinAliases[i] = joinInfo.Strategy.GetBaseTableName()
+	// 查询所有结果并去重后计数，保证计数和查询结果一致
+	deduplicatedResults, err := executeMultiJoinCombinations(db, config, queryBuilder, deduplicator, nil, options)
+	return int64(len(deduplicatedResults)), err
+}
+
+// countMultiJoinCombinations 在每个表组合中执行 COUNT 并累加
+func countMultiJoinCombinations(db *gorm.DB, config MultiJoinConfig, queryBuilder QueryBuilder, options ShardQueryOptions) (int64, error) {
+	_, counts, err := countEachMultiJoinCombination(db, config, queryBuilder, options)
+	var total int64
+	for _, count := range counts {
+		total += count
+	}
+	return total, err
+}
+
+// countEachMultiJoinCombination 通过 runOnCombinations 在每个表组合中执行 COUNT，返回表组合和对应的行数
+// 设置 ContinueOnError 时失败的表组合计数为 0，同时返回 ShardErrors
+func countEachMultiJoinCombination(db *gorm.DB, config MultiJoinConfig, queryBuilder QueryBuilder, options ShardQueryOptions) ([][]string, []int64, error) {
+	countExpr := "COUNT(*)"
+	if config.CountKey != "" {
+		countExpr = "COUNT(DISTINCT " + config.CountKey + ")"
+	}
+
+	tableCombinations, err := multiJoinCombinations(db, config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	counts := make([]int64, len(tableCombinations))
+	err = runOnCombinations(db, config, tableCombinations, options, func(tx *gorm.DB, index int, combination []string) error {
+		count, err := countMultiJoinCombination(tx, config, combination, countExpr, queryBuilder)
+		if err != nil {
+			return fmt.Errorf("count error on tables %v: %w", combination, err)
 		}
+		counts[index] = count
+		return nil
+	})
+	if err != nil && !isPartialShardError(err) {
+		return nil, nil, err
 	}
+	return tableCombinations, counts, err
+}
 
-	// 对所有可能的表组合进行连接查询
-	tableCombinations := generateTableCombinations(mainTableNames, joinTableNamesList)
+// countMultiJoinCombination 对单个表组合执行 COUNT，表不存在或列不存在时返回 0
+func countMultiJoinCombination(
+	db *gorm.DB,
+	config MultiJoinConfig,
+	combination []string,
+	countExpr string,
+	queryBuilder QueryBuilder,
+) (int64, error) {
+	if index, err := emulatedFullJoinIndex(db, config); err != nil || index >= 0 {
+		if err != nil {
+			return 0, err
+		}
+		return countFullJoinCombination(db, config, index, combination, countExpr, queryBuilder)
+	}
 
-	for _, combination := range tableCombinations {
-		mainTableName := combination[0]
-		
-		// 为主表设置别名
-		query := db.Table(fmt.Sprintf("%s AS %s", mainTableName, mainAlias))
+	query := buildMultiJoinQuery(db, config, combination)
+	if queryBuilder != nil {
+		query = queryBuilder(query)
+	}
 
-		// 依次添加 JOIN
-		for i := 0; i < len(config.JoinTables); i++ {
-			joinInfo := config.JoinTables[i]
-			joinTableName := combination[i+1]
-			joinAlias := joinAliases[i]
+	// 计数时忽略查询构建器中的排序和分页
+	delete(query.Statement.Clauses, "ORDER BY")
+	delete(query.Statement.Clauses, "LIMIT")
 
-			// 替换 ON 条件中的基础表名为别名
-			onCondition := replaceTableNamesInCondition(joinInfo.OnCondition, mainBaseName, mainAlias, joinInfo.Strategy.GetBaseTableName(), joinAlias)
+	var row *sql.Row
+	if config.DedupMode != DedupInMemory {
+		// 在数据库中去重时统计去重后的行数
+		row = db.Session(&gorm.Session{NewDB: true}).Raw("SELECT COUNT(*) FROM (?) AS sharding_dedup", applySQLDedup(query, config)).Row()
+	} else {
+		row = query.Select(countExpr).Row()
+	}
 
-			joinSQL := fmt.Sprintf("%s JOIN %s AS %s ON %s", joinInfo.JoinType, joinTableName, joinAlias, onCondition)
-			query = query.Joins(joinSQL)
+	var count int64
+	if err := row.Scan(&count); err != nil {
+		if isTableNotFoundError(err) || isColumnNotFoundError(err) {
+			return 0, nil
 		}
+		return 0, err
+	}
+	return count, nil
+}
 
-		// 应用查询构建器
-		if queryBuilder != nil {
-			query = queryBuilder(query)
+// CrossTableMultiJoinPaginate 多表连接查询的分页（自动去重）
+// 在内存中去重时与 CrossTableMultiJoinPaginateSinglePass 相同，总数和当前页取自同一份去重后的结果
+// （启用 CacheTTL 时与计数复用缓存），总数与分页的数据一致
+func CrossTableMultiJoinPaginate(
+	db *gorm.DB,
+	config MultiJoinConfig,
+	dest interface{},
+	page, pageSize int,
+	queryBuilder QueryBuilder,
+	options ...PaginateOptions,
+) (*Paginator, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	if config.DedupMode != DedupInMemory {
+		return paginateMultiJoinInDatabase(db, config, dest, page, pageSize, queryBuilder, options...)
+	}
+	return CrossTableMultiJoinPaginateSinglePass(db, config, dest, page, pageSize, queryBuilder, options...)
+}
+
+// paginateMultiJoinInDatabase 在数据库中去重时的分页
+// 先统计每个表组合去重后的行数，再按表组合的顺序只查询当前页涉及的表组合（使用 OFFSET / LIMIT）
+func paginateMultiJoinInDatabase(
+	db *gorm.DB,
+	config MultiJoinConfig,
+	dest interface{},
+	page, pageSize int,
+	queryBuilder QueryBuilder,
+	options ...PaginateOptions,
+) (*Paginator, error) {
+	if err := validateMultiJoinConfig(config); err != nil {
+		return nil, err
+	}
+	opts := getPaginateOptions(options)
+
+	tableCombinations, counts, err := countEachMultiJoinCombination(db, config, queryBuilder, opts.ShardQueryOptions)
+	if err != nil && !isPartialShardError(err) {
+		return nil, err
+	}
+	shardErr := err
+	var total int64
+	for _, count := range counts {
+		total += count
+	}
+
+	totalPages := int(total) / pageSize
+	if int(total)%pageSize > 0 {
+		totalPages++
+	}
+	page, outOfRange, err := resolvePage(page, totalPages, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// 模拟的 FULL JOIN 由两个查询组成，不能把 OFFSET/LIMIT 下推到查询中，读取表组合的全部结果后再截取
+	fullJoinIndex, err := emulatedFullJoinIndex(db, config)
+	if err != nil {
+		return nil, err
+	}
+
+	// 跳过当前页之前的表组合，从当前页所在的表组合开始读取
+	offset := int64((page - 1) * pageSize)
+	remaining := pageSize
+	pageResults := make([]map[string]interface{}, 0, pageSize)
+	for i, combination := range tableCombinations {
+		if remaining == 0 {
+			break
+		}
+		if offset >= counts[i] {
+			offset -= counts[i]
+			continue
 		}
 
-		// 执行查询（获取数据用于去重计数）
+		combinationOffset, limit := int(offset), remaining
 		var results []map[string]interface{}
-		if err := query.Find(&results).Error; err != nil {
-			errMsg := strings.ToLower(err.Error())
-			if strings.Contains(errMsg, "doesn't exist") ||
-				strings.Contains(errMsg, "unknown table") ||
-				strings.Contains(errMsg, "table") && strings.Contains(errMsg, "not found") ||
-				strings.Contains(errMsg, "unknown column") {
-				continue // 表不存在或列不存在，跳过
+		err := runOnCombinations(db, config, [][]string{combination}, opts.ShardQueryOptions, func(tx *gorm.DB, _ int, combination []string) error {
+			var err error
+			results, err = queryMultiJoinCombination(tx, config, combination, func(tx *gorm.DB) *gorm.DB {
+				if queryBuilder != nil {
+					tx = queryBuilder(tx)
+				}
+				if fullJoinIndex >= 0 {
+					return tx
+				}
+				return tx.Offset(combinationOffset).Limit(limit)
+			})
+			if err != nil {
+				return fmt.Errorf("query error on tables %v: %w", combination, err)
+			}
+			return nil
+		})
+		if err != nil {
+			if !isPartialShardError(err) {
+				return nil, err
 			}
-			return 0, fmt.Errorf("count error on tables %v: %w", combination, err)
+			// 失败的表组合没有数据，继续从下一个表组合读取
+			shardErr = appendShardErrors(shardErr, err)
+			offset = 0
+			continue
 		}
-
-		tempResults = append(tempResults, results...)
+		if fullJoinIndex >= 0 {
+			results = results[min(combinationOffset, len(results)):]
+			results = results[:min(limit, len(results))]
+		}
+		pageResults = append(pageResults, results...)
+		remaining -= len(results)
+		offset = 0
 	}
 
-	// 对结果进行去重，然后返回去重后的数量
-	deduplicateFields := config.DeduplicateFields
-	if len(deduplicateFields) == 0 {
-		deduplicateFields = GetDefaultDeduplicateFields()
+	if err := convertResults(pageResults, dest); err != nil {
+		return nil, err
 	}
-	deduplicatedResults := deduplicateResults(tempResults, deduplicateFields)
 
-	return int64(len(deduplicatedResults)), nil
+	return &Paginator{
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      total,
+		TotalPages: totalPages,
+		OutOfRange: outOfRange,
+		Data:       dest,
+		Partial:    shardErr != nil,
+	}, shardErr
 }
 
-// CrossTableMultiJoinPaginate 多表连接查询的分页（自动去重）
-func CrossTableMultiJoinPaginate(
+// CrossTableMultiJoinPaginateSinglePass 多表连接查询的单次分页（自动去重）
+// 总数和当前页数据来自同一次查询结果，不会因为两次查询之间数据发生变化而导致总数与分页数据不一致
+func CrossTableMultiJoinPaginateSinglePass(
 	db *gorm.DB,
 	config MultiJoinConfig,
 	dest interface{},
 	page, pageSize int,
 	queryBuilder QueryBuilder,
+	options ...PaginateOptions,
 ) (*Paginator, error) {
 	if page < 1 {
 		page = 1
@@ -114,35 +270,56 @@ func CrossTableMultiJoinPaginate(
 		pageSize = 10
 	}
 
-	// 先获取总数（已自动去重）
-	total, err := CrossTableMultiJoinCount(db, config, queryBuilder)
-	if err != nil {
+	if err := validateMultiJoinConfig(config); err != nil {
 		return nil, err
 	}
 
-	// 计算总页数
+	opts := getPaginateOptions(options)
+
+	// 只执行一次多表连接查询（已自动去重）
+	guard := newResultGuard(opts)
+	allResults, err := executeMultiJoinCombinations(db, config, queryBuilder, config.deduplicator(db, dest), guard, opts.ShardQueryOptions)
+	if err != nil && !isPartialShardError(err) {
+		return nil, err
+	}
+	shardErr := err
+
+	// 从同一份结果中计算总数和总页数
+	total := int64(len(allResults))
 	totalPages := int(total) / pageSize
 	if int(total)%pageSize > 0 {
 		totalPages++
 	}
 
-	// 执行多表连接查询（获取所有数据，已自动去重）
-	err = CrossTableMultiJoin(db, config, dest, queryBuilder)
+	// 处理页码超出总页数的情况
+	page, outOfRange, err := resolvePage(page, totalPages, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	// 手动分页（因为数据已经在内存中）
-	// 注意：这种方式对于大数据量可能不够高效
-	paginatedData := paginateSlice(dest, page, pageSize)
+	// 先对 map 结果分页，只转换当前页的数据
+	offset := (page - 1) * pageSize
+	end := offset + pageSize
+	if offset > len(allResults) {
+		offset = len(allResults)
+	}
+	if end > len(allResults) {
+		end = len(allResults)
+	}
+
+	if err := convertResults(allResults[offset:end], dest); err != nil {
+		return nil, err
+	}
 
 	return &Paginator{
 		Page:       page,
 		PageSize:   pageSize,
 		Total:      total,
 		TotalPages: totalPages,
-		Data:       paginatedData,
-	}, nil
+		OutOfRange: outOfRange,
+		Data:       dest,
+		Partial:    shardErr != nil,
+	}, shardErr
 }
 
 // CrossTableMultiJoinPaginateOptimized 优化的多表连接查询分页（使用优化的连接）
@@ -154,6 +331,7 @@ func CrossTableMultiJoinPaginateOptimized(
 	dest interface{},
 	page, pageSize int,
 	queryBuilder QueryBuilder,
+	options ...PaginateOptions,
 ) (*Paginator, error) {
 	if page < 1 {
 		page = 1
@@ -162,55 +340,34 @@ func CrossTableMultiJoinPaginateOptimized(
 		pageSize = 10
 	}
 
-	// 构建表名到别名的映射
-	mainBaseName := config.MainTable.Strategy.GetBaseTableName()
-	mainAlias := config.MainTable.Alias
-	if mainAlias == "" {
-		mainAlias = mainBaseName
+	if err := validateMultiJoinConfig(config); err != nil {
+		return nil, err
 	}
 
-	// 获取主表的表名（分表名）
-	mainTableName := getTableNameByKey(config.MainTable.Strategy, mainBaseName, joinKeys)
-	
-	// 为主表设置别名（使用基础表名作为别名，这样在 WHERE 条件中可以使用 users.user_id）
-	query := db.Table(fmt.Sprintf("%s AS %s", mainTableName, mainAlias))
-
-	// 获取所有连接表的表名和别名
-	joinTableNames := make([]string, len(config.JoinTables))
-	joinAliases := make([]string, len(config.JoinTables))
-	for i, joinInfo := range config.JoinTables {
-		joinTableNames[i] = getTableNameByKey(joinInfo.Strategy, joinInfo.Strategy.GetBaseTableName(), joinKeys)
-		if joinInfo.Alias != "" {
-			joinAliases[i] = joinInfo.Alias
-		} else {
-			joinAliases[i] = joinInfo.Strategy.GetBaseTableName()
-		}
+	combination, err := getOptimizedCombination(db, config, joinKeys)
+	if err != nil {
+		return nil, err
 	}
+	opts := getPaginateOptions(options)
 
-	// 添加 JOIN
-	for i, joinInfo := range config.JoinTables {
-		joinAlias := joinAliases[i]
-		
-		// 替换 ON 条件中的基础表名为别名
-		onCondition := replaceTableNamesInCondition(
-			joinInfo.OnCondition,
-			mainBaseName, mainAlias,
-			joinInfo.Strategy.GetBaseTableName(), joinAlias,
-		)
-
-		joinSQL := fmt.Sprintf("%s JOIN %s AS %s ON %s", joinInfo.JoinType, joinTableNames[i], joinAlias, onCondition)
-		query = query.Joins(joinSQL)
-	}
+	// 为主表和连接表设置别名（使用基础表名作为别名，这样在 WHERE 条件中可以使用 users.user_id）
+	buildQuery := func(tx *gorm.DB) *gorm.DB {
+		query := buildMultiJoinQuery(tx, config, combination)
 
-	// 应用查询构建器
-	// 注意：在 queryBuilder 中应该使用别名（基础表名），如 users.user_id
-	if queryBuilder != nil {
-		query = queryBuilder(query)
+		// 应用查询构建器
+		// 注意：在 queryBuilder 中应该使用别名（基础表名），如 users.user_id
+		if queryBuilder != nil {
+			query = queryBuilder(query)
+		}
+		return query
 	}
 
 	// 先获取总数
 	var total int64
-	if err := query.Count(&total).Error; err != nil {
+	err = runOnCombinations(db, config, [][]string{combination}, opts.ShardQueryOptions, func(tx *gorm.DB, _ int, _ []string) error {
+		return buildQuery(tx).Count(&total).Error
+	})
+	if err != nil {
 		return nil, fmt.Errorf("count error: %w", err)
 	}
 
@@ -220,12 +377,18 @@ func CrossTableMultiJoinPaginateOptimized(
 		totalPages++
 	}
 
-	// 应用分页
-	offset := (page - 1) * pageSize
-	query = query.Offset(offset).Limit(pageSize)
+	// 处理页码超出总页数的情况
+	page, outOfRange, err := resolvePage(page, totalPages, opts)
+	if err != nil {
+		return nil, err
+	}
 
-	// 执行查询
-	if err := query.Find(dest).Error; err != nil {
+	// 应用分页并执行查询
+	offset := (page - 1) * pageSize
+	err = runOnCombinations(db, config, [][]string{combination}, opts.ShardQueryOptions, func(tx *gorm.DB, _ int, _ []string) error {
+		return buildQuery(tx).Offset(offset).Limit(pageSize).Find(dest).Error
+	})
+	if err != nil {
 		return nil, fmt.Errorf("query error: %w", err)
 	}
 
@@ -234,16 +397,119 @@ func CrossTableMultiJoinPaginateOptimized(
 		PageSize:   pageSize,
 		Total:      total,
 		TotalPages: totalPages,
+		OutOfRange: outOfRange,
 		Data:       dest,
 	}, nil
 }
 
+// SeekOptions 基于游标（keyset）的分页选项
+type SeekOptions struct {
+	SortColumn string      // 排序列（应唯一且有索引，并包含在查询结果中），例如 "orders.id"；只允许列名或 表别名.列名
+	Desc       bool        // 是否倒序
+	AfterValue interface{} // 上一页最后一条记录的排序列值（首页传 nil）
+	PageSize   int         // 每页数量
+
+	ShardQueryOptions // 表组合的执行选项（超时、重试、多数据源等）
+}
+
+// SeekResult 基于游标的分页结果
+type SeekResult struct {
+	PageSize  int         `json:"page_size"`  // 每页数量
+	HasMore   bool        `json:"has_more"`   // 是否还有下一页
+	LastValue interface{} `json:"last_value"` // 本页最后一条记录的排序列值，作为下一页的 AfterValue
+	Data      interface{} `json:"data"`       // 数据列表
+}
+
+// CrossTableMultiJoinSeekOptimized 基于游标（keyset）的优化多表连接分页
+// 使用 WHERE sort_column > AfterValue ORDER BY sort_column LIMIT pageSize 代替 OFFSET，
+// 翻到很深的页时也不需要扫描前面的数据
+func CrossTableMultiJoinSeekOptimized(
+	db *gorm.DB,
+	config MultiJoinConfig,
+	joinKeys map[string]interface{}, // 连接键值映射，用于确定需要连接的表
+	dest interface{},
+	options SeekOptions,
+	queryBuilder QueryBuilder,
+) (*SeekResult, error) {
+	if options.SortColumn == "" {
+		return nil, fmt.Errorf("sort column is required for seek pagination")
+	}
+	if !validSortColumn(options.SortColumn) {
+		return nil, fmt.Errorf("invalid sort column for seek pagination: %q", options.SortColumn)
+	}
+	pageSize := options.PageSize
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	if err := validateMultiJoinConfig(config); err != nil {
+		return nil, err
+	}
+
+	combination, err := getOptimizedCombination(db, config, joinKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []map[string]interface{}
+	err = runOnCombinations(db, config, [][]string{combination}, options.ShardQueryOptions, func(tx *gorm.DB, _ int, combination []string) error {
+		query := buildMultiJoinQuery(tx, config, combination)
+
+		// 应用查询构建器
+		// 注意：在 queryBuilder 中应该使用别名（基础表名），如 users.user_id
+		if queryBuilder != nil {
+			query = queryBuilder(query)
+		}
+
+		// 从上一页最后一条记录之后继续查询
+		// 排序列已校验，按 表别名.列名 分别引用
+		sortColumn := query.Statement.Quote(options.SortColumn)
+		operator, direction := ">", "ASC"
+		if options.Desc {
+			operator, direction = "<", "DESC"
+		}
+		if options.AfterValue != nil {
+			query = query.Where(fmt.Sprintf("%s %s ?", sortColumn, operator), options.AfterValue)
+		}
+
+		// 多取一条用于判断是否还有下一页
+		results = nil
+		query = query.Order(sortColumn + " " + direction).Limit(pageSize + 1)
+		return query.Find(&results).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+
+	hasMore := len(results) > pageSize
+	if hasMore {
+		results = results[:pageSize]
+	}
+
+	var lastValue interface{}
+	if len(results) > 0 {
+		lastValue = results[len(results)-1][columnName(options.SortColumn)]
+	}
+
+	if err := convertResults(results, dest); err != nil {
+		return nil, err
+	}
+
+	return &SeekResult{
+		PageSize:  pageSize,
+		HasMore:   hasMore,
+		LastValue: lastValue,
+		Data:      dest,
+	}, nil
+}
+
 // CrossTableMultiJoinCountWithTimeRange 多表连接查询的计数（支持时间范围）
 func CrossTableMultiJoinCountWithTimeRange(
 	db *gorm.DB,
 	config MultiJoinConfig,
 	queryBuilder QueryBuilder,
 	startValue, endValue interface{}, // 时间范围值（支持多种类型）
+	options ...ShardQueryOptions,
 ) (int64, error) {
 	// 如果是时间分表，需要更新 TimeRanges
 	if startValue != nil && endValue != nil {
@@ -261,26 +527,26 @@ func CrossTableMultiJoinCountWithTimeRange(
 		} else {
 			// 尝试通过策略获取表名来推断时间
 			// 使用 GetTableName 方法间接获取时间
-			startTime = convertValueToTime(startValue)
+			startTime = convertValueToTime(config.MainTable.Strategy, startValue)
 		}
 
 		// 转换结束时间
 		if et, ok := endValue.(time.Time); ok {
 			endTime = et
 		} else {
-			endTime = convertValueToTime(endValue)
+			endTime = convertValueToTime(config.MainTable.Strategy, endValue)
 		}
 
 		// 为所有时间分表设置时间范围
-		baseTableName := config.MainTable.Strategy.GetBaseTableName()
+		baseTableName := config.MainTable.getBaseTableName()
 		config.TimeRanges[baseTableName] = TimeRange{
 			StartTime: startTime,
 			EndTime:   endTime,
 		}
 
 		for _, joinInfo := range config.JoinTables {
-			if _, ok := joinInfo.Strategy.(*TimeShardingStrategy); ok {
-				joinBaseName := joinInfo.Strategy.GetBaseTableName()
+			if _, ok := joinInfo.shardingStrategy().(*TimeShardingStrategy); ok {
+				joinBaseName := joinInfo.getBaseTableName()
 				config.TimeRanges[joinBaseName] = TimeRange{
 					StartTime: startTime,
 					EndTime:   endTime,
@@ -289,7 +555,7 @@ func CrossTableMultiJoinCountWithTimeRange(
 		}
 	}
 
-	return CrossTableMultiJoinCount(db, config, queryBuilder)
+	return CrossTableMultiJoinCount(db, config, queryBuilder, options...)
 }
 
 // CrossTableMultiJoinPaginateWithTimeRange 多表连接查询的分页（支持时间范围）
@@ -300,6 +566,7 @@ func CrossTableMultiJoinPaginateWithTimeRange(
 	page, pageSize int,
 	queryBuilder QueryBuilder,
 	startValue, endValue interface{}, // 时间范围值（支持多种类型）
+	options ...PaginateOptions,
 ) (*Paginator, error) {
 	// 如果是时间分表，需要更新 TimeRanges
 	if startValue != nil && endValue != nil {
@@ -314,26 +581,26 @@ func CrossTableMultiJoinPaginateWithTimeRange(
 		if st, ok := startValue.(time.Time); ok {
 			startTime = st
 		} else {
-			startTime = convertValueToTime(startValue)
+			startTime = convertValueToTime(config.MainTable.Strategy, startValue)
 		}
 
 		// 转换结束时间
 		if et, ok := endValue.(time.Time); ok {
 			endTime = et
 		} else {
-			endTime = convertValueToTime(endValue)
+			endTime = convertValueToTime(config.MainTable.Strategy, endValue)
 		}
 
 		// 为所有时间分表设置时间范围
-		baseTableName := config.MainTable.Strategy.GetBaseTableName()
+		baseTableName := config.MainTable.getBaseTableName()
 		config.TimeRanges[baseTableName] = TimeRange{
 			StartTime: startTime,
 			EndTime:   endTime,
 		}
 
 		for _, joinInfo := range config.JoinTables {
-			if _, ok := joinInfo.Strategy.(*TimeShardingStrategy); ok {
-				joinBaseName := joinInfo.Strategy.GetBaseTableName()
+			if _, ok := joinInfo.shardingStrategy().(*TimeShardingStrategy); ok {
+				joinBaseName := joinInfo.getBaseTableName()
 				config.TimeRanges[joinBaseName] = TimeRange{
 					StartTime: startTime,
 					EndTime:   endTime,
@@ -342,102 +609,32 @@ func CrossTableMultiJoinPaginateWithTimeRange(
 		}
 	}
 
-	return CrossTableMultiJoinPaginate(db, config, dest, page, pageSize, queryBuilder)
+	return CrossTableMultiJoinPaginate(db, config, dest, page, pageSize, queryBuilder, options...)
 }
 
-// convertValueToTime 将各种类型的时间值转换为 time.Time（辅助函数）
-func convertValueToTime(value interface{}) time.Time {
-	if value == nil {
-		return time.Now()
+// convertValueToTime 将各种类型的时间值转换为 time.Time（辅助函数，无法转换时使用当前时间）
+// 主表为时间分表时使用该策略的时间格式，否则使用默认格式
+func convertValueToTime(strategy ShardingStrategy, value interface{}) time.Time {
+	if timeStrategy, ok := strategy.(*TimeShardingStrategy); ok {
+		return timeStrategy.convertToTime(value)
 	}
-
-	switch v := value.(type) {
-	case time.Time:
-		return v
-	case *time.Time:
-		if v != nil {
-			return *v
-		}
-		return time.Now()
-	case int:
-		return time.Unix(int64(v), 0)
-	case int32:
-		return time.Unix(int64(v), 0)
-	case int64:
-		// 判断是秒还是毫秒
-		if v > 1e10 {
-			return time.Unix(v/1000, (v%1000)*1e6)
-		}
-		return time.Unix(v, 0)
-	case uint:
-		return time.Unix(int64(v), 0)
-	case uint32:
-		return time.Unix(int64(v), 0)
-	case uint64:
-		if v > 1e10 {
-			return time.Unix(int64(v/1000), int64((v%1000)*1e6))
-		}
-		return time.Unix(int64(v), 0)
-	case string:
-		return parseStringTimeInMultiJoin(v)
-	default:
-		// 尝试通过反射获取值
-		rv := reflect.ValueOf(value)
-		if rv.Kind() == reflect.Ptr {
-			if rv.IsNil() {
-				return time.Now()
-			}
-			rv = rv.Elem()
-		}
-		if rv.CanInt() {
-			timestamp := rv.Int()
-			if timestamp > 1e10 {
-				return time.Unix(timestamp/1000, (timestamp%1000)*1e6)
-			}
-			return time.Unix(timestamp, 0)
-		}
-		if rv.CanUint() {
-			timestamp := rv.Uint()
-			if timestamp > 1e10 {
-				return time.Unix(int64(timestamp/1000), int64((timestamp%1000)*1e6))
-			}
-			return time.Unix(int64(timestamp), 0)
-		}
+	t, err := defaultTimeConverter.Convert(value)
+	if err != nil {
 		return time.Now()
 	}
+	return t
 }
 
-// parseStringTimeInMultiJoin 解析字符串时间（辅助函数）
-func parseStringTimeInMultiJoin(str string) time.Time {
-	if str == "" {
-		return time.Now()
-	}
-
-	// 尝试多种时间格式
-	formats := []string{
-		"2006-01-02 15:04:05",
-		"2006-01-02T15:04:05Z07:00",
-		"2006-01-02T15:04:05",
-		"2006-01-02 15:04:05.000",
-		"2006-01-02",
-		time.RFC3339,
-		time.RFC3339Nano,
+// validSortColumn 判断排序列是否为列名或 表别名.列名（每一部分与表别名的规则相同），防止拼接到 SQL 中的排序列被注入
+func validSortColumn(column string) bool {
+	parts := strings.Split(column, ".")
+	if len(parts) > 2 {
+		return false
 	}
-
-	for _, format := range formats {
-		if t, err := time.Parse(format, str); err == nil {
-			return t
-		}
-	}
-
-	// 尝试作为 Unix 时间戳字符串解析
-	if timestamp, err := strconv.ParseInt(str, 10, 64); err == nil {
-		if timestamp > 1e10 {
-			return time.Unix(timestamp/1000, (timestamp%1000)*1e6)
+	for _, part := range parts {
+		if !joinAliasPattern.MatchString(part) {
+			return false
 		}
-		return time.Unix(timestamp, 0)
 	}
-
-	return time.Now()
+	return true
 }
-
@@ -2,18 +2,186 @@ package sharding
 
 import (
 	"fmt"
+	"reflect"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"gorm.io/gorm"
 )
 
 // JoinInfo 连接信息
+// 未分表的维表（如商品、状态）作为引用表（广播表）连接：Strategy 为 nil 并指定 TableName，
+// 或设置 ReferenceTable；引用表在每个表组合中按原样连接，不参与表组合的展开
 type JoinInfo struct {
-	Strategy    ShardingStrategy // 分表策略
-	JoinType    JoinType         // JOIN 类型
-	OnCondition string           // ON 条件，例如: "users.id = orders.user_id"
-	Alias       string           // 表别名（可选）
+	Strategy       ShardingStrategy // 分表策略（为 nil 时表示未分表的普通表）
+	TableName      string           // 物理表名（Strategy 为 nil 或 ReferenceTable 时使用，例如: "products"）
+	ReferenceTable bool             // 引用表：忽略 Strategy 的分表规则，连接 TableName（为空时为策略的基础表名）
+	JoinType       JoinType         // JOIN 类型
+	OnCondition    string           // ON 条件，例如: "users.id = orders.user_id"
+	On             []JoinOn         // 结构化的 ON 条件（AND 组合，优先于 OnCondition）
+	Alias          string           // 表别名（可选）
+	Model          interface{}      // 模型（可选），用于按连接键值选择分表时将分表键字段名解析为列名（如 gorm column 标签）
+}
+
+// getOnCondition 获取 ON 条件，aliases 为基础表名到别名的映射（nil 时保留基础表名），
+// quote 为结构化条件中标识符的引用方式（nil 时不加引号）
+func (j JoinInfo) getOnCondition(aliases map[string]string, quote func(string) string) string {
+	if len(j.On) > 0 {
+		return renderJoinOn(j.On, aliases, quote)
+	}
+	if len(aliases) == 0 {
+		return j.OnCondition
+	}
+	return rewriteConditionTables(j.OnCondition, aliases)
+}
+
+// getBaseTableName 获取基础表名（普通表返回物理表名）
+func (j JoinInfo) getBaseTableName() string {
+	if j.Strategy == nil || j.ReferenceTable && j.TableName != "" {
+		return j.TableName
+	}
+	return j.Strategy.GetBaseTableName()
+}
+
+// shardingStrategy 获取参与分表的策略（引用表返回 nil）
+func (j JoinInfo) shardingStrategy() ShardingStrategy {
+	if j.ReferenceTable {
+		return nil
+	}
+	return j.Strategy
+}
+
+// getAlias 获取表别名（未指定时使用基础表名）
+func (j JoinInfo) getAlias() string {
+	if j.Alias != "" {
+		return j.Alias
+	}
+	return j.getBaseTableName()
+}
+
+// getTableNames 获取所有分表名称（普通表和引用表只有一个表）
+// 时间分表没有指定时间范围时使用数据库中已存在的所有分表（allShardTables）
+func (j JoinInfo) getTableNames(db *gorm.DB, timeRanges map[string]TimeRange) ([]string, error) {
+	strategy := j.shardingStrategy()
+	if strategy == nil {
+		return []string{j.getBaseTableName()}, nil
+	}
+	if _, hasRange := timeRanges[strategy.GetBaseTableName()]; hasRange {
+		return getTableNamesWithTimeRange(strategy, strategy.GetBaseTableName(), timeRanges), nil
+	}
+	return allShardTables(db, strategy)
+}
+
+// getTableNameByKey 根据连接键值获取表名（普通表和引用表直接返回物理表名）
+// 使用 joinKeys 中与分表键匹配的值，没有匹配的键时返回 ErrJoinKeyNotFound
+func (j JoinInfo) getTableNameByKey(db *gorm.DB, joinKeys map[string]interface{}) (string, error) {
+	strategy := j.shardingStrategy()
+	if strategy == nil {
+		return j.getBaseTableName(), nil
+	}
+	value, ok := j.lookupJoinKey(db, joinKeys)
+	if !ok {
+		key := "unknown"
+		if keyProvider, ok := strategy.(ShardingKeyProvider); ok {
+			key = keyProvider.GetShardingKey()
+		}
+		return "", fmt.Errorf("%w: no value for sharding key %s of %s", ErrJoinKeyNotFound, key, strategy.GetBaseTableName())
+	}
+	return GetTableNameE(strategy, strategy.GetBaseTableName(), value)
+}
+
+// lookupJoinKey 在 joinKeys 中查找分表键的值（名称忽略大小写和下划线）
+// 优先使用以表名或别名限定的键（如 "orders.user_id"），其次使用未限定的键（如 "user_id"）
+func (j JoinInfo) lookupJoinKey(db *gorm.DB, joinKeys map[string]interface{}) (interface{}, bool) {
+	names := make(map[string]bool)
+	for _, name := range j.shardKeyNames(db) {
+		names[normalizeKey(name)] = true
+	}
+	qualifiers := map[string]bool{
+		strings.ToLower(j.getBaseTableName()): true,
+		strings.ToLower(j.getAlias()):         true,
+	}
+
+	// 按键名排序，保证结果确定
+	keys := make([]string, 0, len(joinKeys))
+	for key := range joinKeys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var unqualified interface{}
+	found := false
+	for _, key := range keys {
+		value := joinKeys[key]
+		if value == nil {
+			continue
+		}
+		qualifier, column, qualified := strings.Cut(key, ".")
+		if !qualified {
+			if names[normalizeKey(key)] && !found {
+				unqualified, found = value, true
+			}
+			continue
+		}
+		if qualifiers[strings.ToLower(qualifier)] && names[normalizeKey(column)] {
+			return value, true
+		}
+	}
+	return unqualified, found
+}
+
+// shardKeyNames 获取分表键可以匹配的名称（分表键，以及 Model 中对应字段的字段名和列名）
+func (j JoinInfo) shardKeyNames(db *gorm.DB) []string {
+	keyProvider, ok := j.shardingStrategy().(ShardingKeyProvider)
+	if !ok {
+		return nil
+	}
+	key := keyProvider.GetShardingKey()
+	names := []string{key}
+	if j.Model != nil && db != nil {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(j.Model); err == nil {
+			if field := stmt.Schema.LookUpField(key); field != nil {
+				names = append(names, field.Name, field.DBName)
+			}
+		}
+	}
+	return names
+}
+
+// validateMultiJoinConfig 校验多表连接配置，确保每个表都有分表策略或物理表名，
+// 别名和 JOIN 类型合法，且别名互不冲突
+func validateMultiJoinConfig(config MultiJoinConfig) error {
+	if config.MainTable.Strategy == nil && config.MainTable.TableName == "" {
+		return fmt.Errorf("main table must have a strategy or a table name")
+	}
+	for i, joinInfo := range config.JoinTables {
+		if joinInfo.Strategy == nil && joinInfo.TableName == "" {
+			return fmt.Errorf("join table %d must have a strategy or a table name", i)
+		}
+		if _, err := joinInfo.JoinType.sqlKeyword(); err != nil {
+			return fmt.Errorf("join table %d: %w", i, err)
+		}
+	}
+	if config.DedupMode == DedupGroupBy && len(config.DedupColumns) == 0 {
+		return fmt.Errorf("%w: DedupGroupBy requires DedupColumns", ErrInvalidJoin)
+	}
+
+	// 别名不区分大小写（部分数据库和文件系统下表别名大小写不敏感）
+	seen := make(map[string]string, len(config.JoinTables)+1)
+	for _, joinInfo := range append([]JoinInfo{config.MainTable}, config.JoinTables...) {
+		alias := joinInfo.getAlias()
+		if !joinAliasPattern.MatchString(alias) {
+			return fmt.Errorf("%w: invalid alias %q for table %s", ErrInvalidJoin, alias, joinInfo.getBaseTableName())
+		}
+		if other, ok := seen[strings.ToLower(alias)]; ok {
+			return fmt.Errorf("%w: alias %q is used by both %s and %s", ErrInvalidJoin, alias, other, joinInfo.getBaseTableName())
+		}
+		seen[strings.ToLower(alias)] = joinInfo.getBaseTableName()
+	}
+	return nil
 }
 
 // TimeRange 时间范围（用于时间分表）
@@ -27,14 +195,50 @@ type MultiJoinConfig struct {
 	MainTable  JoinInfo              // 主表
 	JoinTables []JoinInfo            // 需要连接的表列表
 	TimeRanges map[string]TimeRange  // 时间分表的时间范围（可选）
-	// DeduplicateFields 去重字段配置（可选）
-	// 如果不设置，将使用默认的去重字段配置
+	// Deduplicator 结果的去重方式（可选，如 PrimaryKeyDeduplicator、RowHashDeduplicator、NoDeduplication）
+	// 不设置时依次使用 DeduplicateFields、目标结构体的主键（GORM primaryKey 标签或 ID 字段），都没有时按整行去重
+	Deduplicator Deduplicator
+	// DedupMode 去重的位置（可选，默认 DedupInMemory）
+	// DedupDistinct / DedupGroupBy 在数据库中对每个表组合的查询去重，计数和分页不需要把全部结果加载到内存中：
+	// 计数为每个表组合去重后的行数之和，分页按表组合的顺序只查询当前页涉及的表组合
+	DedupMode DedupMode
+	// DedupColumns DedupGroupBy 时分组的列（如 "orders.id"），查询构建器中 Select 的列需要与之兼容（ONLY_FULL_GROUP_BY）
+	DedupColumns []string
+	// DeduplicateFields 去重字段配置（可选，Deduplicator 为空时使用，同 FieldsDeduplicator）
 	// 字段组合按优先级顺序，从最精确到最通用
 	// 例如：[][]string{{"id"}, {"user_id", "order_id"}, {"user_id"}}
 	DeduplicateFields [][]string
+	// Concurrency 并发执行的表组合查询数量（可选，<= 1 时串行执行）
+	Concurrency int
+	// DisableColocation 禁用共置连接优化（可选）
+	// 默认情况下，ON 条件中通过分表键等值连接（可传递）且分区方式相同的表只连接相同索引的分表
+	// （如 users.id = orders.user_id 时 users_0 只连接 orders_0），其余的表取笛卡尔积
+	DisableColocation bool
+	// AlignTimePeriods 分表单位相同的时间分表只连接相同时间的分表（可选，如 logs_202401 只连接 events_202401）
+	// 默认只有 ON 条件中时间字段等值连接的时间分表按时间对齐；适用于关联的记录总是在同一时间单位内写入的场景，
+	// 跨时间单位的关联记录不会被连接。DisableColocation 为 true 时不生效
+	AlignTimePeriods bool
+	// CacheTTL 去重结果的缓存有效期（可选，0 表示不缓存）
+	// 启用后，计数和分页等相同查询在有效期内复用同一份去重后的结果；缓存按数据库区分，返回的是结果的副本
+	CacheTTL time.Duration
+	// CountKey 计数时使用的去重键表达式（可选，如 "orders.id" 或 "users.id, orders.id"）
+	// 没有在内存中去重（Deduplicator 为 NoDeduplication()）时，计数在每个表组合中执行 COUNT(DISTINCT CountKey) 并累加，
+	// 为空时使用 COUNT(*)；键应能唯一标识一行连接结果，否则同一个键出现在多个表组合中时会被重复计数
+	CountKey string
+	// CountInMemory 没有在内存中去重时，计数仍然查询所有结果后计数（而不是在数据库中 COUNT）
+	CountInMemory bool
+	// CircuitBreaker 表组合级别的熔断器（可选）
+	// 连续失败的表组合在冷却时间内不再执行，查询和计数返回其他表组合的结果和包装了 ErrCircuitOpen 的 ShardErrors
+	CircuitBreaker *CircuitBreaker
+	// MaxCombinations 允许查询的最大表组合数量（可选，0 表示不限制）
+	// 超过时查询和计数立即返回 ErrTooManyCombinations，而不是对数据库发起大量连接查询
+	MaxCombinations int
 }
 
-// GetDefaultDeduplicateFields 获取默认的去重字段配置
+// GetDefaultDeduplicateFields 获取示例去重字段配置（用户、订单、支付）
+//
+// Deprecated: 不再作为默认的去重方式，默认按目标结构体的主键或整行去重；
+// 需要按字段组合去重时通过 DeduplicateFields 或 FieldsDeduplicator 显式指定
 func GetDefaultDeduplicateFields() [][]string {
 	return [][]string{
 		{"id"},                                    // 单一主键
@@ -51,97 +255,269 @@ func GetDefaultDeduplicateFields() [][]string {
 
 // CrossTableMultiJoin 多表跨表连接查询
 // 支持 3 个及以上分表的连接查询
+// options: 表组合的执行选项（并发、超时、重试、多数据源等，可选）；设置 ContinueOnError 时同时返回成功的表组合的结果和 ShardErrors
 func CrossTableMultiJoin(
 	db *gorm.DB,
 	config MultiJoinConfig,
 	dest interface{},
 	queryBuilder QueryBuilder,
+	options ...ShardQueryOptions,
 ) error {
-	// 获取主表的所有分表名称
-	mainTableNames := getTableNamesWithTimeRange(config.MainTable.Strategy, config.MainTable.Strategy.GetBaseTableName(), config.TimeRanges)
+	return crossTableMultiJoin(db, config, dest, queryBuilder, nil, getShardQueryOptions(options))
+}
 
-	// 获取所有连接表的分表名称
-	joinTableNamesList := make([][]string, len(config.JoinTables))
-	for i, joinInfo := range config.JoinTables {
-		joinTableNamesList[i] = getTableNamesWithTimeRange(joinInfo.Strategy, joinInfo.Strategy.GetBaseTableName(), config.TimeRanges)
+// crossTableMultiJoin 多表跨表连接查询的实现，guard 不为 nil 时在加载的数据超过限制后中止
+func crossTableMultiJoin(
+	db *gorm.DB,
+	config MultiJoinConfig,
+	dest interface{},
+	queryBuilder QueryBuilder,
+	guard *resultGuard,
+	options ShardQueryOptions,
+) error {
+	if err := validateMultiJoinConfig(config); err != nil {
+		return err
 	}
 
-	// 构建表名到别名的映射
-	mainBaseName := config.MainTable.Strategy.GetBaseTableName()
-	mainAlias := config.MainTable.Alias
-	if mainAlias == "" {
-		mainAlias = mainBaseName // 默认使用基础表名作为别名
+	// 对所有表组合执行连接查询并去重
+	allResults, queryErr := executeMultiJoinCombinations(db, config, queryBuilder, config.deduplicator(db, dest), guard, options)
+	if queryErr != nil && !isPartialShardError(queryErr) {
+		return queryErr
 	}
 
-	// 为连接表构建别名映射
-	joinAliases := make([]string, len(config.JoinTables))
-	for i, joinInfo := range config.JoinTables {
-		if joinInfo.Alias != "" {
-			joinAliases[i] = joinInfo.Alias
-		} else {
-			joinAliases[i] = joinInfo.Strategy.GetBaseTableName() // 默认使用基础表名作为别名
-		}
+	// 将结果转换为目标类型
+	if err := convertResults(allResults, dest); err != nil {
+		return err
 	}
+	return queryErr
+}
 
-	var allResults []map[string]interface{}
+// runOnCombinations 通过 runOnShards 在每个表组合上执行 task（并发、超时、重试、熔断），表组合已经按路由提示筛选过
+// 未设置 options.Parallelism 时使用 config.Concurrency；指定 Cluster 时在主表的分表所在的数据库上执行，
+// 同一个表组合中的分表需要位于同一个数据库；config.CircuitBreaker 按表组合熔断
+func runOnCombinations(
+	db *gorm.DB,
+	config MultiJoinConfig,
+	combinations [][]string,
+	options ShardQueryOptions,
+	task func(tx *gorm.DB, index int, combination []string) error,
+) error {
+	if options.Parallelism < 1 {
+		options.Parallelism = config.Concurrency
+	}
+	options.hinted = true
 
+	mainTables := make([]string, len(combinations))
+	for i, combination := range combinations {
+		mainTables[i] = combination[0]
+	}
+	return runOnShards(db, mainTables, options, func(tx *gorm.DB, index int, _ string) error {
+		combination := combinations[index]
+		return config.CircuitBreaker.Do(strings.Join(combination, "+"), func() error {
+			return task(tx, index, combination)
+		})
+	})
+}
+
+// executeMultiJoinCombinations 对所有可能的表组合执行连接查询，返回去重后的结果
+// 表组合通过 runOnCombinations 执行，各组合的结果按完成顺序流式去重；并发执行时结果顺序不保证与表组合顺序一致
+// guard 不为 nil 时，去重后的结果超过行数或内存限制会中止查询；
+// 设置 ContinueOnError 时返回成功的表组合的结果和 ShardErrors（部分结果不缓存）
+func executeMultiJoinCombinations(
+	db *gorm.DB,
+	config MultiJoinConfig,
+	queryBuilder QueryBuilder,
+	deduplicator Deduplicator,
+	guard *resultGuard,
+	options ShardQueryOptions,
+) ([]map[string]interface{}, error) {
 	// 对所有可能的表组合进行连接查询
-	tableCombinations := generateTableCombinations(mainTableNames, joinTableNamesList)
-
-	for _, combination := range tableCombinations {
-		mainTableName := combination[0]
-		
-		// 为主表设置别名（使用基础表名作为别名，这样在 WHERE 条件中可以使用 users.user_id）
-		query := db.Table(fmt.Sprintf("%s AS %s", mainTableName, mainAlias))
-
-		// 依次添加 JOIN
-		for i := 0; i < len(config.JoinTables); i++ {
-			joinInfo := config.JoinTables[i]
-			joinTableName := combination[i+1] // 连接表名
-			joinAlias := joinAliases[i]
-
-			// 替换 ON 条件中的基础表名为别名
-			onCondition := replaceTableNamesInCondition(
-				joinInfo.OnCondition, 
-				mainBaseName, mainAlias, 
-				joinInfo.Strategy.GetBaseTableName(), joinAlias,
-			)
-
-			joinSQL := fmt.Sprintf("%s JOIN %s AS %s ON %s", joinInfo.JoinType, joinTableName, joinAlias, onCondition)
-			query = query.Joins(joinSQL)
+	tableCombinations, err := multiJoinCombinations(db, config)
+	if err != nil {
+		return nil, err
+	}
+
+	// 启用结果缓存时，相同查询在有效期内复用去重后的结果
+	var cacheKey multiJoinCacheKey
+	if config.CacheTTL > 0 {
+		cacheKey = multiJoinCacheKeyOf(db, config, tableCombinations, queryBuilder, deduplicator, options)
+		if results, ok := defaultMultiJoinCache.get(cacheKey); ok {
+			if err := guard.add(reflect.ValueOf(results)); err != nil {
+				return nil, err
+			}
+			return results, nil
 		}
+	}
 
-		// 应用查询构建器
-		// 注意：在 queryBuilder 中应该使用别名（基础表名），如 users.user_id，而不是 users_0.user_id
-		if queryBuilder != nil {
-			query = queryBuilder(query)
+	// 流式去重：按完成顺序合并各组合的结果
+	var mu sync.Mutex
+	seenKeys := make(map[string]bool)
+	allResults := make([]map[string]interface{}, 0)
+	dropped := 0
+
+	queryErr := runOnCombinations(db, config, tableCombinations, options, func(tx *gorm.DB, index int, combination []string) error {
+		results, err := queryMultiJoinCombination(tx, config, combination, queryBuilder)
+		if err != nil {
+			return fmt.Errorf("query error on tables %v: %w", combination, err)
 		}
 
-		// 执行查询
-		var results []map[string]interface{}
-		if err := query.Find(&results).Error; err != nil {
-			errMsg := strings.ToLower(err.Error())
-			if strings.Contains(errMsg, "doesn't exist") ||
-				strings.Contains(errMsg, "unknown table") ||
-				strings.Contains(errMsg, "table") && strings.Contains(errMsg, "not found") ||
-				strings.Contains(errMsg, "unknown column") {
-				continue // 表不存在或列不存在，跳过
+		mu.Lock()
+		defer mu.Unlock()
+		for _, result := range results {
+			key, ok := deduplicator.Key(result)
+			if ok && seenKeys[key] {
+				dropped++
+				continue
+			}
+			if ok {
+				seenKeys[key] = true
+			}
+			allResults = append(allResults, result)
+			if err := guard.add(reflect.ValueOf(result)); err != nil {
+				return err
 			}
-			return fmt.Errorf("query error on tables %v: %w", combination, err)
 		}
+		return nil
+	})
+	shardMetrics().AddDedupDropped(dropped)
 
-		allResults = append(allResults, results...)
+	if queryErr != nil {
+		if !isPartialShardError(queryErr) {
+			return nil, queryErr
+		}
+		return allResults, queryErr
 	}
 
-	// 对结果进行去重
-	deduplicateFields := config.DeduplicateFields
-	if len(deduplicateFields) == 0 {
-		deduplicateFields = GetDefaultDeduplicateFields()
+	if config.CacheTTL > 0 {
+		defaultMultiJoinCache.set(cacheKey, allResults, config.CacheTTL)
 	}
-	allResults = deduplicateResults(allResults, deduplicateFields)
 
-	// 将结果转换为目标类型
-	return convertResults(allResults, dest)
+	return allResults, nil
+}
+
+// multiJoinCombinations 获取本次查询需要执行的表组合（应用路由提示），超过 MaxCombinations 时返回错误
+func multiJoinCombinations(db *gorm.DB, config MultiJoinConfig) ([][]string, error) {
+	combinations, err := getMultiJoinTableCombinations(db, config)
+	if err != nil {
+		return nil, err
+	}
+	combinations = hintedCombinations(db.Statement.Context, combinations)
+	if config.MaxCombinations > 0 && len(combinations) > config.MaxCombinations {
+		return nil, fmt.Errorf("%w: joining %s requires %d table combinations (limit %d); "+
+			"narrow TimeRanges, use colocated strategies, or query by join keys with CrossTableMultiJoinOptimized / CrossTableMultiJoinPaginateOptimized",
+			ErrTooManyCombinations, config.MainTable.getBaseTableName(), len(combinations), config.MaxCombinations)
+	}
+	return combinations, nil
+}
+
+// getMultiJoinTableCombinations 获取多表连接需要查询的所有表组合
+func getMultiJoinTableCombinations(db *gorm.DB, config MultiJoinConfig) ([][]string, error) {
+	// 主表和所有连接表的分表名称
+	tableNamesList := make([][]string, 0, len(config.JoinTables)+1)
+	for _, joinInfo := range append([]JoinInfo{config.MainTable}, config.JoinTables...) {
+		tableNames, err := joinInfo.getTableNames(db, config.TimeRanges)
+		if err != nil {
+			return nil, err
+		}
+		tableNamesList = append(tableNamesList, tableNames)
+	}
+
+	// 通过分表键等值连接、分区方式相同的表只连接相同索引的分表（如 users_0 只连接 orders_0），
+	// 时间分表只连接相同时间的分表（如 logs_202401 只连接 events_202401）
+	if !config.DisableColocation {
+		groups := planJoinGroups(config, tableNamesList)
+		return generatePlannedCombinations(len(tableNamesList), planJoinDimensions(config, tableNamesList, groups)), nil
+	}
+
+	return generateTableCombinations(tableNamesList[0], tableNamesList[1:]), nil
+}
+
+// queryMultiJoinCombination 对单个表组合执行连接查询
+// 表不存在或列不存在时返回空结果
+func queryMultiJoinCombination(
+	db *gorm.DB,
+	config MultiJoinConfig,
+	combination []string,
+	queryBuilder QueryBuilder,
+) ([]map[string]interface{}, error) {
+	if index, err := emulatedFullJoinIndex(db, config); err != nil || index >= 0 {
+		if err != nil {
+			return nil, err
+		}
+		return queryFullJoinCombination(db, config, index, combination, queryBuilder)
+	}
+
+	query := buildMultiJoinQuery(db, config, combination)
+
+	// 应用查询构建器
+	// 注意：在 queryBuilder 中应该使用别名（基础表名），如 users.user_id，而不是 users_0.user_id
+	if queryBuilder != nil {
+		query = queryBuilder(query)
+	}
+	query = applySQLDedup(query, config)
+
+	// 执行查询
+	var results []map[string]interface{}
+	if err := query.Find(&results).Error; err != nil {
+		if isTableNotFoundError(err) {
+			shardMetrics().IncSkippedMissingTable(combination[0])
+			return nil, nil // 表不存在，跳过
+		}
+		if isColumnNotFoundError(err) {
+			return nil, nil // 列不存在，跳过
+		}
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// buildMultiJoinQuery 根据表组合构建多表连接查询（不包含查询构建器中的条件）
+// combination[0] 为主表的分表名，combination[i+1] 为第 i 个连接表的分表名
+// 表名和别名使用数据库方言引用，config 需要先通过 validateMultiJoinConfig 校验
+func buildMultiJoinQuery(db *gorm.DB, config MultiJoinConfig, combination []string) *gorm.DB {
+	quote := func(name string) string { return db.Statement.Quote(name) }
+	mainAlias := config.MainTable.getAlias() // 默认使用基础表名作为别名
+
+	// 为主表设置别名（使用基础表名作为别名，这样在 WHERE 条件中可以使用 users.user_id）
+	query := db.Table(quote(combination[0]) + " AS " + quote(mainAlias))
+	query.Statement.Table = mainAlias // 引用后的别名无法被 GORM 识别，与未引用时保持一致
+
+	// 基础表名到别名的映射（包含主表和已连接的表）
+	aliases := map[string]string{config.MainTable.getBaseTableName(): mainAlias}
+
+	// 依次添加 JOIN
+	for i, joinInfo := range config.JoinTables {
+		joinTableName := combination[i+1] // 连接表名
+		joinAlias := joinInfo.getAlias()
+		aliases[joinInfo.getBaseTableName()] = joinAlias
+
+		// 替换 ON 条件中的基础表名为别名
+		onCondition := joinInfo.getOnCondition(aliases, quote)
+
+		joinKeyword, _ := joinInfo.JoinType.sqlKeyword()
+		if joinInfo.JoinType == FullJoin && !GetCapabilities(db, nil).FullOuterJoin {
+			// 需要模拟的 FULL JOIN 由 queryMultiJoinCombination 拆分后再构建
+			query.AddError(fmt.Errorf("%w: %s does not support FULL OUTER JOIN", ErrInvalidJoin, db.Dialector.Name()))
+		}
+		joinSQL := fmt.Sprintf("%s JOIN %s AS %s ON %s", joinKeyword, quote(joinTableName), quote(joinAlias), onCondition)
+		query = query.Joins(joinSQL)
+	}
+
+	return query
+}
+
+// getOptimizedCombination 根据连接键值确定每个表应该使用的分表，返回唯一的表组合
+func getOptimizedCombination(db *gorm.DB, config MultiJoinConfig, joinKeys map[string]interface{}) ([]string, error) {
+	combination := make([]string, 0, len(config.JoinTables)+1)
+	for _, joinInfo := range append([]JoinInfo{config.MainTable}, config.JoinTables...) {
+		tableName, err := joinInfo.getTableNameByKey(db, joinKeys)
+		if err != nil {
+			return nil, err
+		}
+		combination = append(combination, tableName)
+	}
+	return combination, nil
 }
 
 // generateTableCombinations 生成所有可能的表组合
@@ -194,8 +570,8 @@ func generateCombinationsRecursive(mainTableNames []string, joinTableNamesList [
 
 // getTableNamesWithTimeRange 获取表名列表（考虑时间范围）
 func getTableNamesWithTimeRange(strategy ShardingStrategy, baseTableName string, timeRanges map[string]TimeRange) []string {
-	// 检查是否是时间分表
-	timeStrategy, ok := strategy.(*TimeShardingStrategy)
+	// 检查是否是时间分表（包括含时间分表的组合分表）
+	timeStrategy, ok := strategy.(timeRangeSharding)
 	if !ok {
 		// 非时间分表，直接获取所有表名
 		return strategy.GetAllTableNames(baseTableName)
@@ -206,7 +582,14 @@ func getTableNamesWithTimeRange(strategy ShardingStrategy, baseTableName string,
 		return timeStrategy.GetAllTableNamesInRange(baseTableName, timeRange.StartTime, timeRange.EndTime)
 	}
 
-	// 没有指定时间范围，使用默认（最近一年）
+	// 没有指定时间范围时，开启了分表发现的时间分表使用数据库中已存在的分表
+	if strategy, ok := strategy.(*TimeShardingStrategy); ok {
+		if tableNames, ok := strategy.discoveredTables(); ok {
+			return tableNames
+		}
+	}
+
+	// 否则使用默认（最近一年）
 	endTime := time.Now()
 	startTime := endTime.AddDate(-1, 0, 0)
 	return timeStrategy.GetAllTableNamesInRange(baseTableName, startTime, endTime)
@@ -221,155 +604,32 @@ func CrossTableMultiJoinOptimized(
 	joinKeys map[string]interface{}, // 连接键值映射，用于确定需要连接的表
 	dest interface{},
 	queryBuilder QueryBuilder,
+	options ...ShardQueryOptions,
 ) error {
 	// 根据连接键值，确定每个表应该使用哪个分表
 	// 例如：如果 joinKeys 包含 user_id=123，且所有表都基于 user_id 分表
 	// 那么只需要连接 users_1, orders_1, payments_1 等相同索引的分表
 
-	mainBaseName := config.MainTable.Strategy.GetBaseTableName()
-	mainAlias := config.MainTable.Alias
-	if mainAlias == "" {
-		mainAlias = mainBaseName
+	if err := validateMultiJoinConfig(config); err != nil {
+		return err
 	}
 
-	// 获取主表的表名
-	mainTableName := getTableNameByKey(config.MainTable.Strategy, mainBaseName, joinKeys)
-
-	// 获取所有连接表的表名
-	joinTableNames := make([]string, len(config.JoinTables))
-	joinAliases := make([]string, len(config.JoinTables))
-	for i, joinInfo := range config.JoinTables {
-		joinTableNames[i] = getTableNameByKey(joinInfo.Strategy, joinInfo.Strategy.GetBaseTableName(), joinKeys)
-		if joinInfo.Alias != "" {
-			joinAliases[i] = joinInfo.Alias
-		} else {
-			joinAliases[i] = joinInfo.Strategy.GetBaseTableName()
-		}
+	combination, err := getOptimizedCombination(db, config, joinKeys)
+	if err != nil {
+		return err
 	}
 
-	// 构建查询（使用别名）
-	query := db.Table(fmt.Sprintf("%s AS %s", mainTableName, mainAlias))
-
-	// 添加 JOIN
-	for i, joinInfo := range config.JoinTables {
-		joinAlias := joinAliases[i]
-		
-		// 替换 ON 条件中的表名
-		onCondition := replaceTableNamesInCondition(
-			joinInfo.OnCondition,
-			mainBaseName, mainAlias,
-			joinInfo.Strategy.GetBaseTableName(), joinAlias,
-		)
-
-		joinSQL := fmt.Sprintf("%s JOIN %s AS %s ON %s", joinInfo.JoinType, joinTableNames[i], joinAlias, onCondition)
-		query = query.Joins(joinSQL)
-	}
+	return runOnCombinations(db, config, [][]string{combination}, getShardQueryOptions(options), func(tx *gorm.DB, _ int, combination []string) error {
+		// 构建查询（使用别名）
+		query := buildMultiJoinQuery(tx, config, combination)
 
-	// 应用查询构建器
-	if queryBuilder != nil {
-		query = queryBuilder(query)
-	}
-
-	// 执行查询
-	return query.Find(dest).Error
-}
-
-// getTableNameByKey 根据连接键值获取表名
-func getTableNameByKey(strategy ShardingStrategy, baseTableName string, joinKeys map[string]interface{}) string {
-	// 尝试从 joinKeys 中提取分表键值
-	// 遍历所有可能的键值，使用第一个非空值
-	for _, value := range joinKeys {
-		if value != nil {
-			return strategy.GetTableName(baseTableName, value)
-		}
-	}
-
-	// 如果没有找到匹配的键，返回基础表名
-	return baseTableName
-}
-
-// replaceTableNamesInCondition 替换条件中的基础表名为别名
-func replaceTableNamesInCondition(condition string, mainBaseName, mainAlias, joinBaseName, joinAlias string) string {
-	result := condition
-	
-	// 替换主表名
-	if mainBaseName != mainAlias {
-		result = strings.ReplaceAll(result, mainBaseName+".", mainAlias+".")
-	}
-	
-	// 替换连接表名
-	if joinBaseName != joinAlias {
-		result = strings.ReplaceAll(result, joinBaseName+".", joinAlias+".")
-	}
-	
-	return result
-}
-
-// generateResultKey 生成结果的唯一键（用于去重）
-// keyFieldGroups 是按优先级排序的字段组合列表
-func generateResultKey(result map[string]interface{}, keyFieldGroups [][]string) string {
-	// 尝试使用字段组合
-	for _, fields := range keyFieldGroups {
-		keyParts := make([]string, 0, len(fields))
-		allFound := true
-		
-		for _, field := range fields {
-			if val, ok := result[field]; ok && val != nil {
-				strVal := fmt.Sprintf("%v", val)
-				if strVal != "" && strVal != "<nil>" {
-					keyParts = append(keyParts, fmt.Sprintf("%s:%v", field, val))
-				} else {
-					allFound = false
-					break
-				}
-			} else {
-				allFound = false
-				break
-			}
-		}
-		
-		if allFound && len(keyParts) > 0 {
-			return strings.Join(keyParts, "|")
-		}
-	}
-	
-	// 如果没有找到常见唯一字段组合，使用所有非 nil 字段的组合
-	keyParts := make([]string, 0)
-	for k, v := range result {
-		if v != nil {
-			strVal := fmt.Sprintf("%v", v)
-			if strVal != "<nil>" && strVal != "" {
-				keyParts = append(keyParts, fmt.Sprintf("%s:%v", k, v))
-			}
+		// 应用查询构建器
+		if queryBuilder != nil {
+			query = queryBuilder(query)
 		}
-	}
-	
-	if len(keyParts) == 0 {
-		// 如果没有找到任何有效字段，使用空字符串（可能导致重复，但比崩溃好）
-		return ""
-	}
-	
-	return strings.Join(keyParts, "|")
-}
 
-// deduplicateResults 对结果进行去重
-// keyFieldGroups 是按优先级排序的字段组合列表，用于生成唯一键
-func deduplicateResults(results []map[string]interface{}, keyFieldGroups [][]string) []map[string]interface{} {
-	if len(results) == 0 {
-		return results
-	}
-	
-	seenKeys := make(map[string]bool)
-	deduplicated := make([]map[string]interface{}, 0, len(results))
-	
-	for _, result := range results {
-		key := generateResultKey(result, keyFieldGroups)
-		if !seenKeys[key] {
-			seenKeys[key] = true
-			deduplicated = append(deduplicated, result)
-		}
-	}
-	
-	return deduplicated
+		// 执行查询
+		return query.Find(dest).Error
+	})
 }
 
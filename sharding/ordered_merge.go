@@ -0,0 +1,512 @@
+package sharding
+
+import (
+	"bytes"
+	"container/heap"
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// OrderByColumn 排序列
+type OrderByColumn struct {
+	Column string // 列名（如 "created_at"，也可以是模型字段名 "CreatedAt"）
+	Desc   bool   // 是否倒序
+}
+
+// CrossTableOrderedPaginate 跨表有序分页（k 路归并）
+// 每个分表只查询 ORDER BY ... LIMIT offset+pageSize 条数据，然后在内存中按排序列归并，
+// 保证第 N 页的数据与单表排序分页的结果一致，且只需读取 O(分表数 × (offset+pageSize)) 条数据
+// orderBy: 排序列（为空时从 queryBuilder 的 Order() 中解析）
+// 部分分表失败时（ContinueOnError，或数据源被健康检查排除、分表熔断）同时返回成功分表的归并结果和 ShardErrors
+func CrossTableOrderedPaginate(
+	db *gorm.DB,
+	strategy ShardingStrategy,
+	dest interface{},
+	page, pageSize int,
+	orderBy []OrderByColumn,
+	queryBuilder QueryBuilder,
+	options ...PaginateOptions,
+) (*Paginator, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	opts := getPaginateOptions(options)
+
+	// 先获取总数
+	total, err := CrossTableCount(db, strategy, queryBuilder, opts.ShardQueryOptions)
+	if err != nil && !isPartialShardError(err) {
+		return nil, err
+	}
+	shardErr := err
+
+	// 计算总页数
+	totalPages := int(total) / pageSize
+	if int(total)%pageSize > 0 {
+		totalPages++
+	}
+
+	// 处理页码超出总页数的情况
+	page, outOfRange, err := resolvePage(page, totalPages, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	offset := (page - 1) * pageSize
+	if err := crossTableOrderedQuery(db, strategy, dest, offset, pageSize, orderBy, queryBuilder, opts.ShardQueryOptions); err != nil {
+		if !isPartialShardError(err) {
+			return nil, err
+		}
+		shardErr = err
+	}
+
+	return &Paginator{
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      total,
+		TotalPages: totalPages,
+		OutOfRange: outOfRange,
+		Data:       dest,
+		Partial:    shardErr != nil,
+	}, shardErr
+}
+
+// crossTableOrderedQuery 在所有分表中执行有序查询，归并后取 [offset, offset+limit) 区间的数据写入 dest
+// limit < 0 表示不分页，归并所有分表的全部结果
+func crossTableOrderedQuery(
+	db *gorm.DB,
+	strategy ShardingStrategy,
+	dest interface{},
+	offset, limit int,
+	orderBy []OrderByColumn,
+	queryBuilder QueryBuilder,
+	options ShardQueryOptions,
+) error {
+	destValue := reflect.ValueOf(dest)
+	if destValue.Kind() != reflect.Ptr || destValue.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("dest must be a pointer to slice")
+	}
+	destElem := destValue.Elem()
+	elemType := destElem.Type().Elem()
+
+	// 未指定排序列时，从查询构建器中解析
+	if len(orderBy) == 0 {
+		orderBy = parseOrderByFromQuery(db, queryBuilder)
+	}
+	if len(orderBy) == 0 {
+		return fmt.Errorf("order by columns are required for ordered merge")
+	}
+
+	sortFields, err := resolveSortFields(db, elemType, orderBy)
+	if err != nil {
+		return err
+	}
+
+	// 每个分表只需要前 offset+limit 条数据
+	tableNames, err := allShardTables(db, strategy)
+	if err != nil {
+		return err
+	}
+	tableResults := make([]reflect.Value, len(tableNames))
+	queryErr := runOnShards(db, tableNames, options, func(tx *gorm.DB, index int, tableName string) error {
+		query := tx.Table(tableName)
+		if queryBuilder != nil {
+			query = queryBuilder(query)
+		}
+		// 查询构建器中的 ORDER BY 按列的排序规则排序，替换为与归并一致的排序
+		// （使用解析后的列名，orderBy 中可以是模型字段名，如 CreatedAt）
+		delete(query.Statement.Clauses, "ORDER BY")
+		for i, column := range orderBy {
+			query = query.Order(sortOrderBy(query, sortColumnExpr(query, sortFields[i]), column.Desc))
+		}
+
+		if limit >= 0 {
+			query = query.Limit(offset + limit)
+		}
+
+		results := reflect.New(reflect.SliceOf(elemType))
+		if err := query.Find(results.Interface()).Error; err != nil {
+			return err
+		}
+		tableResults[index] = results.Elem()
+		return nil
+	})
+	if queryErr != nil && !isPartialShardError(queryErr) {
+		return queryErr
+	}
+
+	shardResults := make([]reflect.Value, 0, len(tableResults))
+	fetched := 0
+	for _, results := range tableResults {
+		if results.IsValid() {
+			shardResults = append(shardResults, results)
+			fetched += results.Len()
+		}
+	}
+	if limit >= 0 {
+		fetched = offset + limit
+	}
+
+	merged, err := kWayMerge(db, shardResults, orderBy, sortFields, fetched)
+	if err != nil {
+		return err
+	}
+	if offset > len(merged) {
+		offset = len(merged)
+	}
+
+	result := reflect.MakeSlice(destElem.Type(), 0, len(merged)-offset)
+	for _, row := range merged[offset:] {
+		result = reflect.Append(result, row.value)
+	}
+	destElem.Set(result)
+	return queryErr
+}
+
+// resolveSortFields 解析切片元素类型对应的模型 schema，并查找排序列对应的字段，用于从结果中读取排序键
+func resolveSortFields(db *gorm.DB, elemType reflect.Type, orderBy []OrderByColumn) ([]*schema.Field, error) {
+	modelType := elemType
+	for modelType.Kind() == reflect.Ptr {
+		modelType = modelType.Elem()
+	}
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(reflect.New(modelType).Interface()); err != nil {
+		return nil, fmt.Errorf("failed to parse model schema: %w", err)
+	}
+
+	sortFields := make([]*schema.Field, len(orderBy))
+	for i, column := range orderBy {
+		field := stmt.Schema.LookUpField(columnName(column.Column))
+		if field == nil {
+			return nil, fmt.Errorf("order by column %s not found in %s", column.Column, stmt.Schema.Name)
+		}
+		sortFields[i] = field
+	}
+	return sortFields, nil
+}
+
+// parseOrderByFromQuery 从查询构建器设置的 ORDER BY 子句中解析排序列
+// 支持 Order("created_at DESC, id") 以及 Order(clause.OrderByColumn{...}) 两种写法
+func parseOrderByFromQuery(db *gorm.DB, queryBuilder QueryBuilder) []OrderByColumn {
+	if queryBuilder == nil {
+		return nil
+	}
+
+	stmt := queryBuilder(db.Session(&gorm.Session{DryRun: true, NewDB: true})).Statement
+	orderByClause, ok := stmt.Clauses["ORDER BY"]
+	if !ok {
+		return nil
+	}
+	orderByExpr, ok := orderByClause.Expression.(clause.OrderBy)
+	if !ok {
+		return nil
+	}
+
+	var result []OrderByColumn
+	for _, column := range orderByExpr.Columns {
+		if !column.Column.Raw {
+			result = append(result, OrderByColumn{Column: column.Column.Name, Desc: column.Desc})
+			continue
+		}
+		// 原始 SQL 形式："created_at DESC, id ASC"
+		for _, part := range strings.Split(column.Column.Name, ",") {
+			fields := strings.Fields(part)
+			if len(fields) == 0 {
+				continue
+			}
+			desc := len(fields) > 1 && strings.EqualFold(fields[1], "DESC")
+			result = append(result, OrderByColumn{Column: fields[0], Desc: desc})
+		}
+	}
+	return result
+}
+
+// mergedRow 归并结果中的一行及其来源分表
+type mergedRow struct {
+	shard int
+	value reflect.Value
+	keys  []interface{}
+}
+
+// mergeCursor 归并时每个分表的读取位置
+type mergeCursor struct {
+	shard int
+	index int
+	keys  []interface{}
+}
+
+// mergeHeap 按排序列比较的最小堆
+type mergeHeap struct {
+	cursors []*mergeCursor
+	orderBy []OrderByColumn
+}
+
+func (h *mergeHeap) Len() int { return len(h.cursors) }
+
+func (h *mergeHeap) Less(i, j int) bool {
+	if c := compareSortKeys(h.cursors[i].keys, h.cursors[j].keys, h.orderBy); c != 0 {
+		return c < 0
+	}
+	// 排序列相同时按分表顺序，保证结果稳定
+	return h.cursors[i].shard < h.cursors[j].shard
+}
+
+func (h *mergeHeap) Swap(i, j int) { h.cursors[i], h.cursors[j] = h.cursors[j], h.cursors[i] }
+
+func (h *mergeHeap) Push(x interface{}) { h.cursors = append(h.cursors, x.(*mergeCursor)) }
+
+func (h *mergeHeap) Pop() interface{} {
+	old := h.cursors
+	n := len(old)
+	item := old[n-1]
+	h.cursors = old[:n-1]
+	return item
+}
+
+// sortColumnExpr 获取排序列在查询中使用的表达式
+// 字符串列按字节排序和比较（与归并时的 strings.Compare 一致），不受列的排序规则（如大小写不敏感的 utf8mb4_general_ci）影响；
+// 其他方言使用原列，分表返回的顺序与归并不一致时归并返回 ErrUnsortedShardResult
+func sortColumnExpr(db *gorm.DB, field *schema.Field) string {
+	column := db.Statement.Quote(field.DBName)
+	if field.DataType != schema.String {
+		return column
+	}
+	switch db.Dialector.Name() {
+	case "mysql":
+		return "CAST(" + column + " AS BINARY)"
+	case "postgres":
+		return column + ` COLLATE "C"`
+	case "sqlite":
+		return column + " COLLATE BINARY"
+	case "sqlserver":
+		return column + " COLLATE Latin1_General_BIN2"
+	default:
+		return column
+	}
+}
+
+// sortOrderBy 构建分表查询的排序列（expr 为已引用的列或 sortColumnExpr 的结果），
+// NULL 默认排在最后的数据库（Capabilities.NullsLast）显式指定 NULLS FIRST / NULLS LAST，使 NULL 与归并时一样按最小值排序
+func sortOrderBy(db *gorm.DB, expr string, desc bool) clause.OrderByColumn {
+	nullsLast := GetCapabilities(db, nil).NullsLast
+	switch {
+	case desc && nullsLast:
+		expr += " DESC NULLS LAST"
+	case desc:
+		expr += " DESC"
+	case nullsLast:
+		expr += " NULLS FIRST"
+	}
+	return clause.OrderByColumn{Column: clause.Column{Name: expr, Raw: true}}
+}
+
+// kWayMerge 对已按排序列排好序的各分表结果做 k 路归并，最多返回 limit 条
+func kWayMerge(db *gorm.DB, shardResults []reflect.Value, orderBy []OrderByColumn, sortFields []*schema.Field, limit int) ([]mergedRow, error) {
+	ctx := db.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	sortKeys := func(value reflect.Value) []interface{} {
+		keys := make([]interface{}, len(sortFields))
+		for i, field := range sortFields {
+			keys[i], _ = field.ValueOf(ctx, value)
+		}
+		return keys
+	}
+
+	return kWayMergeBy(shardResults, orderBy, sortKeys, limit)
+}
+
+// kWayMergeBy 对已排好序的各分表结果做 k 路归并，sortKeys 读取一行的排序键，最多返回 limit 条
+// 分表内相邻两行的顺序与 compareSortKeys 不一致时返回 ErrUnsortedShardResult
+func kWayMergeBy(shardResults []reflect.Value, orderBy []OrderByColumn, sortKeys func(reflect.Value) []interface{}, limit int) ([]mergedRow, error) {
+	h := &mergeHeap{orderBy: orderBy}
+	for shard, results := range shardResults {
+		if results.Len() > 0 {
+			h.cursors = append(h.cursors, &mergeCursor{shard: shard, index: 0, keys: sortKeys(results.Index(0))})
+		}
+	}
+	heap.Init(h)
+
+	merged := make([]mergedRow, 0, limit)
+	for h.Len() > 0 && len(merged) < limit {
+		cursor := heap.Pop(h).(*mergeCursor)
+		results := shardResults[cursor.shard]
+		merged = append(merged, mergedRow{shard: cursor.shard, value: results.Index(cursor.index), keys: cursor.keys})
+
+		cursor.index++
+		if cursor.index < results.Len() {
+			keys := sortKeys(results.Index(cursor.index))
+			if compareSortKeys(cursor.keys, keys, orderBy) > 0 {
+				return nil, fmt.Errorf("%w: row %d of shard %d sorts before the previous row; use a binary collation for string sort columns",
+					ErrUnsortedShardResult, cursor.index, cursor.shard)
+			}
+			cursor.keys = keys
+			heap.Push(h, cursor)
+		}
+	}
+	return merged, nil
+}
+
+// compareSortKeys 按排序列依次比较两行的排序键
+func compareSortKeys(a, b []interface{}, orderBy []OrderByColumn) int {
+	for i := range orderBy {
+		c := compareValues(a[i], b[i])
+		if orderBy[i].Desc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// compareValues 比较两个值的大小（支持整数、浮点数、字符串、时间、字节切片和布尔值，nil 最小）
+// 指针和 driver.Valuer（如 sql.NullInt64、sql.NullTime、DECIMAL 类型）先展开为数据库中的值，无效的值（NULL）最小
+func compareValues(a, b interface{}) int {
+	av, aValuer := unwrapValue(a)
+	bv, bValuer := unwrapValue(b)
+	if !av.IsValid() || !bv.IsValid() {
+		switch {
+		case !av.IsValid() && !bv.IsValid():
+			return 0
+		case !av.IsValid():
+			return -1
+		default:
+			return 1
+		}
+	}
+
+	if at, ok := av.Interface().(time.Time); ok {
+		if bt, ok := bv.Interface().(time.Time); ok {
+			return at.Compare(bt)
+		}
+	}
+
+	// Valuer 以字符串表示的数字（如 DECIMAL 类型）按数值比较
+	if (aValuer && av.Kind() == reflect.String) || (bValuer && bv.Kind() == reflect.String) {
+		if ar, ok := toRat(av); ok {
+			if br, ok := toRat(bv); ok {
+				return ar.Cmp(br)
+			}
+		}
+	}
+
+	switch {
+	case av.CanInt() && bv.CanInt():
+		return compareOrdered(av.Int(), bv.Int())
+	case av.CanUint() && bv.CanUint():
+		return compareOrdered(av.Uint(), bv.Uint())
+	case (av.CanInt() || av.CanUint() || av.CanFloat()) && (bv.CanInt() || bv.CanUint() || bv.CanFloat()):
+		return compareOrdered(toFloat(av), toFloat(bv))
+	case av.Kind() == reflect.String && bv.Kind() == reflect.String:
+		return strings.Compare(av.String(), bv.String())
+	case av.Kind() == reflect.Bool && bv.Kind() == reflect.Bool:
+		return compareOrdered(boolToInt(av.Bool()), boolToInt(bv.Bool()))
+	case av.Kind() == reflect.Slice && bv.Kind() == reflect.Slice &&
+		av.Type().Elem().Kind() == reflect.Uint8 && bv.Type().Elem().Kind() == reflect.Uint8:
+		return bytes.Compare(av.Bytes(), bv.Bytes())
+	default:
+		return strings.Compare(fmt.Sprintf("%v", av.Interface()), fmt.Sprintf("%v", bv.Interface()))
+	}
+}
+
+// derefValue 解引用指针并展开 driver.Valuer，nil 和 NULL 返回无效值
+func derefValue(value interface{}) reflect.Value {
+	rv, _ := unwrapValue(value)
+	return rv
+}
+
+// unwrapValue 解引用指针并展开 driver.Valuer（值或指针接收者），返回数据库中的值以及是否经过 Valuer 展开
+// nil、空指针和 Valuer 返回的 NULL 为无效值
+func unwrapValue(value interface{}) (reflect.Value, bool) {
+	valuer := false
+	for depth := 0; ; depth++ {
+		rv := reflect.ValueOf(value)
+		for rv.IsValid() && rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				return reflect.Value{}, valuer
+			}
+			rv = rv.Elem()
+		}
+		if !rv.IsValid() || depth >= 4 {
+			return rv, valuer
+		}
+
+		v, ok := rv.Interface().(driver.Valuer)
+		if !ok && rv.CanAddr() {
+			v, ok = rv.Addr().Interface().(driver.Valuer)
+		}
+		if !ok {
+			return rv, valuer
+		}
+		next, err := v.Value()
+		if err != nil {
+			return rv, valuer
+		}
+		value, valuer = next, true
+	}
+}
+
+// toRat 将数值或数字字符串转换为 big.Rat
+func toRat(rv reflect.Value) (*big.Rat, bool) {
+	switch {
+	case rv.CanInt():
+		return new(big.Rat).SetInt64(rv.Int()), true
+	case rv.CanUint():
+		return new(big.Rat).SetInt(new(big.Int).SetUint64(rv.Uint())), true
+	case rv.CanFloat():
+		r := new(big.Rat).SetFloat64(rv.Float())
+		return r, r != nil
+	case rv.Kind() == reflect.String:
+		return new(big.Rat).SetString(rv.String())
+	default:
+		return nil, false
+	}
+}
+
+// compareOrdered 比较两个可排序的值
+func compareOrdered[T int64 | uint64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// toFloat 将数值转换为 float64
+func toFloat(rv reflect.Value) float64 {
+	switch {
+	case rv.CanInt():
+		return float64(rv.Int())
+	case rv.CanUint():
+		return float64(rv.Uint())
+	default:
+		return rv.Float()
+	}
+}
+
+// boolToInt 将布尔值转换为整数（false < true）
+func boolToInt(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,83 @@
+package sharding
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCompareValues(t *testing.T) {
+	now := time.Now()
+	five := int64(5)
+	tests := []struct {
+		name string
+		a, b interface{}
+		want int
+	}{
+		{"int", int64(1), int64(2), -1},
+		{"int and float", int32(3), 2.5, 1},
+		{"large int", int64(1<<62 + 1), int64(1 << 62), 1},
+		{"bytes", []byte("ab"), []byte("b"), -1},
+		{"string bytewise", "B", "a", -1},
+		{"time", now, now.Add(time.Second), -1},
+		{"bool", false, true, -1},
+		{"nil lowest", nil, int64(-1), -1},
+		{"nil equal", nil, nil, 0},
+		{"pointer", &five, int64(5), 0},
+		{"nil pointer", (*int64)(nil), int64(0), -1},
+		{"valuer", sql.NullInt64{Int64: 7, Valid: true}, int64(6), 1},
+		{"invalid valuer", sql.NullInt64{}, int64(0), -1},
+		{"null time", sql.NullTime{Time: now, Valid: true}, now, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := compareValues(tt.a, tt.b); got != tt.want {
+				t.Errorf("compareValues(%v, %v) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+			if got := compareValues(tt.b, tt.a); got != -tt.want {
+				t.Errorf("compareValues(%v, %v) = %d, want %d", tt.b, tt.a, got, -tt.want)
+			}
+		})
+	}
+}
+
+// mergeKeys 测试用的排序键：每一行本身就是排序键
+func mergeKeys(row reflect.Value) []interface{} {
+	return row.Interface().([]interface{})
+}
+
+func TestKWayMergeBy(t *testing.T) {
+	orderBy := []OrderByColumn{{Column: "score", Desc: true}, {Column: "id"}}
+	shards := []reflect.Value{
+		reflect.ValueOf([][]interface{}{{int64(9), int64(1)}, {int64(5), int64(4)}, {nil, int64(7)}}),
+		reflect.ValueOf([][]interface{}{}),
+		reflect.ValueOf([][]interface{}{{int64(9), int64(0)}, {int64(5), int64(2)}, {int64(1), int64(3)}}),
+	}
+
+	merged, err := kWayMergeBy(shards, orderBy, mergeKeys, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []int64
+	for _, row := range merged {
+		ids = append(ids, row.keys[1].(int64))
+	}
+	if want := []int64{0, 1, 2, 4, 3}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("merged ids = %v, want %v", ids, want)
+	}
+	if merged[0].shard != 2 || merged[1].shard != 0 {
+		t.Errorf("merged shards = %d, %d, want 2, 0", merged[0].shard, merged[1].shard)
+	}
+}
+
+func TestKWayMergeByUnsorted(t *testing.T) {
+	orderBy := []OrderByColumn{{Column: "name"}}
+	shards := []reflect.Value{
+		reflect.ValueOf([][]interface{}{{"a"}, {"B"}}),
+	}
+	if _, err := kWayMergeBy(shards, orderBy, mergeKeys, 10); !errors.Is(err, ErrUnsortedShardResult) {
+		t.Errorf("got error %v, want ErrUnsortedShardResult", err)
+	}
+}
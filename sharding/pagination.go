@@ -1,18 +1,78 @@
 package sharding
 
 import (
+	"errors"
+	"fmt"
 	"reflect"
+	"sync"
 
 	"gorm.io/gorm"
 )
 
 // Paginator 分页器
 type Paginator struct {
-	Page       int         `json:"page"`        // 当前页码（从1开始）
-	PageSize   int         `json:"page_size"`   // 每页数量
-	Total      int64       `json:"total"`       // 总记录数
-	TotalPages int         `json:"total_pages"` // 总页数
-	Data       interface{} `json:"data"`        // 数据列表
+	Page       int         `json:"page"`         // 当前页码（从1开始）
+	PageSize   int         `json:"page_size"`    // 每页数量
+	Total      int64       `json:"total"`        // 总记录数
+	TotalPages int         `json:"total_pages"`  // 总页数
+	Data       interface{} `json:"data"`         // 数据列表
+	OutOfRange bool        `json:"out_of_range"` // 请求的页码是否超出总页数
+	Partial    bool        `json:"partial"`      // 部分分表失败或被排除，结果只包含成功的分表的数据（同时返回 ShardErrors）
+}
+
+// ErrPageOutOfRange 页码超出总页数（PageOverflowError 模式下返回）
+var ErrPageOutOfRange = errors.New("page out of range")
+
+// PageOverflowBehavior 页码超出总页数时的处理方式
+type PageOverflowBehavior int
+
+const (
+	PageOverflowEmpty PageOverflowBehavior = iota // 返回空数据，并设置 Paginator.OutOfRange（默认）
+	PageOverflowClamp                             // 自动调整到最后一页
+	PageOverflowError                             // 返回 ErrPageOutOfRange
+)
+
+// PaginateOptions 分页选项
+type PaginateOptions struct {
+	Overflow PageOverflowBehavior // 页码超出总页数时的处理方式
+	// MaxRows 内存分页最多加载的行数（0 表示不限制），超过时返回 ErrResultTooLarge
+	MaxRows int
+	// MaxMemoryBytes 内存分页加载数据的估算内存上限（0 表示不限制），超过时返回 ErrResultTooLarge
+	MaxMemoryBytes int64
+
+	ShardQueryOptions // 各分表的执行选项（并发、超时、重试等）
+}
+
+// ErrResultTooLarge 内存分页加载的数据超过 MaxRows 或 MaxMemoryBytes 限制
+var ErrResultTooLarge = errors.New("result set too large for in-memory pagination")
+
+// getPaginateOptions 获取分页选项（未指定时使用默认值）
+func getPaginateOptions(options []PaginateOptions) PaginateOptions {
+	if len(options) > 0 {
+		return options[0]
+	}
+	return PaginateOptions{}
+}
+
+// resolvePage 根据总页数和溢出处理方式确定实际页码
+// 返回实际页码、是否超出范围以及错误（PageOverflowError 模式）
+func resolvePage(page, totalPages int, options PaginateOptions) (int, bool, error) {
+	lastPage := totalPages
+	if lastPage < 1 {
+		lastPage = 1
+	}
+	if page <= lastPage {
+		return page, false, nil
+	}
+
+	switch options.Overflow {
+	case PageOverflowClamp:
+		return lastPage, false, nil
+	case PageOverflowError:
+		return page, true, fmt.Errorf("%w: page %d, total pages %d", ErrPageOutOfRange, page, totalPages)
+	default:
+		return page, true, nil
+	}
 }
 
 // CrossTablePaginate 跨表分页查询
@@ -22,12 +82,14 @@ type Paginator struct {
 // page: 页码（从1开始）
 // pageSize: 每页数量
 // queryBuilder: 查询构建器
+// 设置 ContinueOnError 时，部分分表失败会同时返回成功分表的分页结果和 ShardErrors
 func CrossTablePaginate(
 	db *gorm.DB,
 	strategy ShardingStrategy,
 	dest interface{},
 	page, pageSize int,
 	queryBuilder QueryBuilder,
+	options ...PaginateOptions,
 ) (*Paginator, error) {
 	if page < 1 {
 		page = 1
@@ -36,11 +98,14 @@ func CrossTablePaginate(
 		pageSize = 10
 	}
 
+	opts := getPaginateOptions(options)
+
 	// 先获取总数
-	total, err := CrossTableCount(db, strategy, queryBuilder)
-	if err != nil {
+	total, err := CrossTableCount(db, strategy, queryBuilder, opts.ShardQueryOptions)
+	if err != nil && !isPartialShardError(err) {
 		return nil, err
 	}
+	shardErr := err
 
 	// 计算总页数
 	totalPages := int(total) / pageSize
@@ -48,12 +113,25 @@ func CrossTablePaginate(
 		totalPages++
 	}
 
-	// 跨表查询所有数据
-	err = CrossTableQuery(db, strategy, dest, queryBuilder)
+	// 处理页码超出总页数的情况
+	page, outOfRange, err := resolvePage(page, totalPages, opts)
 	if err != nil {
 		return nil, err
 	}
 
+	// 跨表查询所有数据（超过行数或内存限制时中止）
+	guard := newResultGuard(opts)
+	if err := guard.checkTotal(total); err != nil {
+		return nil, err
+	}
+	err = crossTableQueryWithTimeRange(db, strategy, dest, queryBuilder, nil, nil, guard, opts.ShardQueryOptions)
+	if err != nil {
+		if !isPartialShardError(err) {
+			return nil, err
+		}
+		shardErr = err
+	}
+
 	// 手动分页（因为数据已经在内存中）
 	// 注意：这种方式对于大数据量可能不够高效，建议使用基于游标的分页
 	paginatedData := paginateSlice(dest, page, pageSize)
@@ -63,8 +141,10 @@ func CrossTablePaginate(
 		PageSize:   pageSize,
 		Total:      total,
 		TotalPages: totalPages,
+		OutOfRange: outOfRange,
 		Data:       paginatedData,
-	}, nil
+		Partial:    shardErr != nil,
+	}, shardErr
 }
 
 // CrossTablePaginateUnion 使用 UNION ALL 的跨表分页（更高效）
@@ -74,6 +154,7 @@ func CrossTablePaginateUnion(
 	dest interface{},
 	page, pageSize int,
 	queryBuilder QueryBuilder,
+	options ...PaginateOptions,
 ) (*Paginator, error) {
 	if page < 1 {
 		page = 1
@@ -82,11 +163,14 @@ func CrossTablePaginateUnion(
 		pageSize = 10
 	}
 
+	opts := getPaginateOptions(options)
+
 	// 先获取总数
-	total, err := CrossTableCount(db, strategy, queryBuilder)
-	if err != nil {
+	total, err := CrossTableCount(db, strategy, queryBuilder, opts.ShardQueryOptions)
+	if err != nil && !isPartialShardError(err) {
 		return nil, err
 	}
+	shardErr := err
 
 	// 计算总页数
 	totalPages := int(total) / pageSize
@@ -94,45 +178,112 @@ func CrossTablePaginateUnion(
 		totalPages++
 	}
 
+	// 处理页码超出总页数的情况
+	page, outOfRange, err := resolvePage(page, totalPages, opts)
+	if err != nil {
+		return nil, err
+	}
+
 	// 计算偏移量
 	offset := (page - 1) * pageSize
 
-	// 使用 UNION ALL 查询并分页
-	// 注意：需要在 UNION ALL 外层添加 LIMIT 和 OFFSET
-	err = CrossTableQueryUnionWithPagination(db, strategy, dest, offset, pageSize, queryBuilder)
-	if err != nil {
+	// 使用 UNION ALL 查询并分页（设置了分表执行选项时与 CrossTableQueryUnion 一样在每个分表上查询）
+	var queryOptions []ShardQueryOptions
+	if !reflect.ValueOf(opts.ShardQueryOptions).IsZero() {
+		queryOptions = append(queryOptions, opts.ShardQueryOptions)
+	}
+	err = CrossTableQueryUnionWithPagination(db, strategy, dest, offset, pageSize, queryBuilder, queryOptions...)
+	if err != nil && !isPartialShardError(err) {
 		return nil, err
 	}
+	shardErr = appendShardErrors(shardErr, err)
 
 	return &Paginator{
 		Page:       page,
 		PageSize:   pageSize,
 		Total:      total,
 		TotalPages: totalPages,
+		OutOfRange: outOfRange,
 		Data:       dest,
-	}, nil
+		Partial:    shardErr != nil,
+	}, shardErr
 }
 
 // CrossTableQueryUnionWithPagination 带分页的 UNION ALL 查询
+// 在数据库中对合并结果执行 ORDER BY/LIMIT/OFFSET，排序条件取自 queryBuilder 中的 Order；limit < 0 表示不限制条数。
+// 指定 options 时与 CrossTableQueryUnion 一样通过 runOnShards 在每个分表上查询（每个分表最多 offset+limit 条）：
+// 有 ORDER BY 时在内存中按排序列归并，否则按分表顺序合并，然后取 [offset, offset+limit) 区间；
+// 部分分表失败时（ContinueOnError 等）返回成功分表的结果和 ShardErrors
 func CrossTableQueryUnionWithPagination(
 	db *gorm.DB,
 	strategy ShardingStrategy,
 	dest interface{},
 	offset, limit int,
 	queryBuilder QueryBuilder,
+	options ...ShardQueryOptions,
 ) error {
-	// 由于 GORM 的 UNION ALL 支持有限，这里采用更简单的方法：
-	// 先查询所有数据到内存，然后进行分页
-	// 对于大数据量场景，建议使用基于游标的分页或其他优化方案
+	if offset < 0 {
+		offset = 0
+	}
+	if limit < 0 {
+		limit = -1
+	}
+	if len(options) > 0 {
+		if len(parseOrderByFromQuery(db, queryBuilder)) > 0 {
+			return crossTableOrderedQuery(db, strategy, dest, offset, limit, nil, queryBuilder, options[0])
+		}
+		return crossTableWindowQuery(db, strategy, dest, offset, limit, queryBuilder, options[0])
+	}
 
-	err := CrossTableQuery(db, strategy, dest, queryBuilder)
+	unionSQL, vars, err := buildUnionQuery(db, strategy, dest, queryBuilder, offset, limit)
 	if err != nil {
 		return err
 	}
+	return db.Raw(unionSQL, vars...).Find(dest).Error
+}
 
-	// 手动分页
-	// 注意：这里假设 dest 是一个切片，实际使用时需要根据类型进行转换
-	return nil
+// crossTableWindowQuery 在每个分表上查询（每个分表最多 offset+limit 条），按分表顺序合并后取 [offset, offset+limit) 区间写入 dest
+// limit < 0 表示不限制条数
+func crossTableWindowQuery(
+	db *gorm.DB,
+	strategy ShardingStrategy,
+	dest interface{},
+	offset, limit int,
+	queryBuilder QueryBuilder,
+	options ShardQueryOptions,
+) error {
+	destValue := reflect.ValueOf(dest)
+	if destValue.Kind() != reflect.Ptr || destValue.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("dest must be a pointer to slice")
+	}
+
+	shardQuery := queryBuilder
+	if limit >= 0 {
+		// 合并结果的前 offset+limit 条一定在每个分表的前 offset+limit 条中
+		shardQuery = func(tx *gorm.DB) *gorm.DB {
+			if queryBuilder != nil {
+				tx = queryBuilder(tx)
+			}
+			return tx.Limit(offset + limit)
+		}
+	}
+	results := reflect.New(destValue.Elem().Type())
+	err := crossTableQueryWithTimeRange(db, strategy, results.Interface(), shardQuery, nil, nil, nil, options)
+	if err != nil && !isPartialShardError(err) {
+		return err
+	}
+
+	rows := results.Elem()
+	end := rows.Len()
+	if limit >= 0 && offset+limit < end {
+		end = offset + limit
+	}
+	start := offset
+	if start > end {
+		start = end
+	}
+	destValue.Elem().Set(rows.Slice(start, end))
+	return err
 }
 
 // paginateSlice 对切片进行分页（辅助函数）
@@ -176,3 +327,100 @@ func paginateSlice(slice interface{}, page, pageSize int) interface{} {
 	sliceElem.Set(newSlice)
 	return slice
 }
+
+// resultGuard 内存分页的行数和内存限制
+type resultGuard struct {
+	mu       sync.Mutex
+	maxRows  int
+	maxBytes int64
+	rows     int
+	bytes    int64
+}
+
+// newResultGuard 根据分页选项创建限制，未设置任何限制时返回 nil
+func newResultGuard(options PaginateOptions) *resultGuard {
+	if options.MaxRows <= 0 && options.MaxMemoryBytes <= 0 {
+		return nil
+	}
+	return &resultGuard{maxRows: options.MaxRows, maxBytes: options.MaxMemoryBytes}
+}
+
+// checkTotal 已知总数时提前检查行数限制，避免加载数据后才发现超限
+func (g *resultGuard) checkTotal(total int64) error {
+	if g == nil || g.maxRows <= 0 || total <= int64(g.maxRows) {
+		return nil
+	}
+	return fmt.Errorf("%w: %d matching rows exceed MaxRows %d, narrow the query or use CrossTableCursorPaginate",
+		ErrResultTooLarge, total, g.maxRows)
+}
+
+// add 累加已加载的数据（rows 为切片或单行），超过限制时返回错误
+func (g *resultGuard) add(rows reflect.Value) error {
+	if g == nil {
+		return nil
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if rows.Kind() == reflect.Slice {
+		g.rows += rows.Len()
+	} else {
+		g.rows++
+	}
+	if g.maxRows > 0 && g.rows > g.maxRows {
+		return fmt.Errorf("%w: loaded more than MaxRows %d rows, narrow the query or use CrossTableCursorPaginate",
+			ErrResultTooLarge, g.maxRows)
+	}
+
+	if g.maxBytes > 0 {
+		g.bytes += estimateValueSize(rows)
+		if g.bytes > g.maxBytes {
+			return fmt.Errorf("%w: loaded about %d bytes, exceeding MaxMemoryBytes %d, narrow the query or use CrossTableCursorPaginate",
+				ErrResultTooLarge, g.bytes, g.maxBytes)
+		}
+	}
+	return nil
+}
+
+// estimateValueSize 估算值占用的内存（字节），用于内存限制判断，不追求精确
+func estimateValueSize(v reflect.Value) int64 {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return 0
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return 8
+		}
+		return 8 + estimateValueSize(v.Elem())
+	case reflect.String:
+		return 16 + int64(v.Len())
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return 24 + int64(v.Len())
+		}
+		size := int64(24)
+		for i := 0; i < v.Len(); i++ {
+			size += estimateValueSize(v.Index(i))
+		}
+		return size
+	case reflect.Map:
+		size := int64(48)
+		iter := v.MapRange()
+		for iter.Next() {
+			size += estimateValueSize(iter.Key()) + estimateValueSize(iter.Value())
+		}
+		return size
+	case reflect.Struct:
+		size := int64(v.Type().Size())
+		for i := 0; i < v.NumField(); i++ {
+			switch field := v.Field(i); field.Kind() {
+			case reflect.String, reflect.Slice, reflect.Map, reflect.Ptr, reflect.Interface:
+				// 间接引用的数据不包含在 Type().Size() 中
+				size += estimateValueSize(field) - int64(field.Type().Size())
+			}
+		}
+		return size
+	default:
+		return int64(v.Type().Size())
+	}
+}
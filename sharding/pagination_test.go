@@ -0,0 +1,70 @@
+package sharding
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestResolvePage(t *testing.T) {
+	tests := []struct {
+		name           string
+		page, total    int
+		overflow       PageOverflowBehavior
+		wantPage       int
+		wantOutOfRange bool
+		wantErr        error
+	}{
+		{"in range", 2, 3, PageOverflowEmpty, 2, false, nil},
+		{"last page", 3, 3, PageOverflowError, 3, false, nil},
+		{"no data", 1, 0, PageOverflowError, 1, false, nil},
+		{"overflow empty", 5, 3, PageOverflowEmpty, 5, true, nil},
+		{"overflow clamp", 5, 3, PageOverflowClamp, 3, false, nil},
+		{"overflow clamp no data", 5, 0, PageOverflowClamp, 1, false, nil},
+		{"overflow error", 5, 3, PageOverflowError, 5, true, ErrPageOutOfRange},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, outOfRange, err := resolvePage(tt.page, tt.total, PaginateOptions{Overflow: tt.overflow})
+			if page != tt.wantPage || outOfRange != tt.wantOutOfRange || !errors.Is(err, tt.wantErr) {
+				t.Errorf("got (%d, %v, %v), want (%d, %v, %v)", page, outOfRange, err, tt.wantPage, tt.wantOutOfRange, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResultGuard(t *testing.T) {
+	if guard := newResultGuard(PaginateOptions{}); guard != nil {
+		t.Fatalf("guard without limits = %+v, want nil", guard)
+	}
+	var guard *resultGuard
+	if err := guard.add(reflect.ValueOf(make([]testUser, 100))); err != nil {
+		t.Errorf("nil guard: got error %v", err)
+	}
+
+	guard = newResultGuard(PaginateOptions{MaxRows: 3})
+	if err := guard.checkTotal(3); err != nil {
+		t.Errorf("checkTotal(3): got error %v", err)
+	}
+	if err := guard.checkTotal(4); !errors.Is(err, ErrResultTooLarge) {
+		t.Errorf("checkTotal(4): got error %v, want ErrResultTooLarge", err)
+	}
+	if err := guard.add(reflect.ValueOf(make([]testUser, 2))); err != nil {
+		t.Fatal(err)
+	}
+	if err := guard.add(reflect.ValueOf(testUser{})); err != nil {
+		t.Fatal(err)
+	}
+	if err := guard.add(reflect.ValueOf(testUser{})); !errors.Is(err, ErrResultTooLarge) {
+		t.Errorf("fourth row: got error %v, want ErrResultTooLarge", err)
+	}
+
+	guard = newResultGuard(PaginateOptions{MaxMemoryBytes: 64})
+	if err := guard.checkTotal(1 << 20); err != nil {
+		t.Errorf("memory guard checkTotal: got error %v", err)
+	}
+	row := map[string]interface{}{"name": string(make([]byte, 100))}
+	if err := guard.add(reflect.ValueOf(row)); !errors.Is(err, ErrResultTooLarge) {
+		t.Errorf("large row: got error %v, want ErrResultTooLarge", err)
+	}
+}
@@ -0,0 +1,88 @@
+package sharding
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// TableConfig 单个分表的插件配置
+type TableConfig struct {
+	Strategy        ShardingStrategy // 分表策略
+	AutoCreateTable bool             // 插入数据时是否自动创建分表
+	Model           interface{}      // 用于自动创建表的模型（AutoCreateTable 为 true 时必填）
+
+	MaintenanceBudget *MaintenanceBudget // 插入时检查和创建分表的时间预算（nil 时使用 SetMaintenanceBudget 设置的默认预算）
+}
+
+// Config 分表插件配置
+type Config struct {
+	Tables          []TableConfig // 需要注册的分表配置
+	CheckPrivileges bool          // 存在自动创建表的配置时，初始化前检查 CREATE/ALTER 权限
+
+	ValidateOnStartup bool                  // 初始化时调用 ValidateConfig 检查配置与数据库是否一致，不一致时初始化失败
+	ValidateOptions   ValidateConfigOptions // 启动检查的选项（如分表键样本）
+}
+
+// Sharding 分表插件，实现 gorm.Plugin 接口
+// 用法：db.Use(sharding.New(sharding.Config{...}))
+type Sharding struct {
+	config Config
+}
+
+// New 创建分表插件
+func New(config Config) *Sharding {
+	return &Sharding{config: config}
+}
+
+// Name 插件名称（gorm.Plugin 接口）
+func (s *Sharding) Name() string {
+	return "x2-sharding"
+}
+
+// Initialize 注册所有分表策略（gorm.Plugin 接口）
+func (s *Sharding) Initialize(db *gorm.DB) error {
+	if s.config.CheckPrivileges && s.hasAutoCreateTable() {
+		if err := CheckPrivileges(db, PrivilegeCreate, PrivilegeAlter); err != nil {
+			return err
+		}
+	}
+
+	registered := make(map[string]bool)
+	for _, table := range s.config.Tables {
+		if table.Strategy == nil {
+			return fmt.Errorf("sharding strategy is required")
+		}
+
+		baseTableName := table.Strategy.GetBaseTableName()
+		if registered[baseTableName] {
+			return fmt.Errorf("duplicate sharding strategy for table %s", baseTableName)
+		}
+		if table.AutoCreateTable && table.Model == nil {
+			return fmt.Errorf("model is required for auto create table %s", baseTableName)
+		}
+
+		if err := registerSharding(db, table.Strategy, table.AutoCreateTable, table.Model, table.MaintenanceBudget); err != nil {
+			return fmt.Errorf("failed to register sharding strategy %s: %w", baseTableName, err)
+		}
+		registered[baseTableName] = true
+	}
+
+	if s.config.ValidateOnStartup {
+		if err := ValidateConfig(db, s.config, s.config.ValidateOptions); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// hasAutoCreateTable 是否存在启用了自动创建表的配置
+func (s *Sharding) hasAutoCreateTable() bool {
+	for _, table := range s.config.Tables {
+		if table.AutoCreateTable {
+			return true
+		}
+	}
+	return false
+}
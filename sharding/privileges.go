@@ -0,0 +1,212 @@
+package sharding
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Privilege 数据库权限
+type Privilege string
+
+const (
+	PrivilegeCreate Privilege = "CREATE"
+	PrivilegeDrop   Privilege = "DROP"
+	PrivilegeAlter  Privilege = "ALTER"
+)
+
+// ddlPrivileges 自动创建分表等 DDL 操作需要的权限
+var ddlPrivileges = []Privilege{PrivilegeCreate, PrivilegeDrop, PrivilegeAlter}
+
+// ErrInsufficientPrivileges 当前连接缺少执行操作所需的权限
+var ErrInsufficientPrivileges = errors.New("insufficient privileges")
+
+// MissingPrivilegesError 缺少权限的详细信息，可通过 errors.Is(err, ErrInsufficientPrivileges) 判断
+type MissingPrivilegesError struct {
+	Database string      // 目标数据库
+	Missing  []Privilege // 缺少的权限
+}
+
+func (e *MissingPrivilegesError) Error() string {
+	missing := make([]string, len(e.Missing))
+	for i, privilege := range e.Missing {
+		missing[i] = string(privilege)
+	}
+	return fmt.Sprintf("insufficient privileges on database %s: missing %s", e.Database, strings.Join(missing, ", "))
+}
+
+func (e *MissingPrivilegesError) Unwrap() error {
+	return ErrInsufficientPrivileges
+}
+
+// grantPattern 匹配 SHOW GRANTS 的输出，如 "GRANT SELECT, CREATE ON `app`.* TO `user`@`%`"
+var grantPattern = regexp.MustCompile(`(?i)^GRANT\s+(.+?)\s+ON\s+(\S+)\s+TO\s`)
+
+// CheckPrivileges 检查当前连接在当前数据库上是否拥有指定权限（默认检查 CREATE、DROP、ALTER）
+// MySQL 通过 SHOW GRANTS 判断，无法通过授权语句确定时（如通过角色授权）使用临时表探测；
+// PostgreSQL 只检查 schema 的 CREATE 权限（DROP/ALTER 取决于表的所有者）
+// 缺少权限时返回 *MissingPrivilegesError
+func CheckPrivileges(db *gorm.DB, privileges ...Privilege) error {
+	if len(privileges) == 0 {
+		privileges = ddlPrivileges
+	}
+
+	switch db.Dialector.Name() {
+	case "mysql":
+		return checkMySQLPrivileges(db, privileges)
+	case "postgres":
+		return checkPostgresPrivileges(db, privileges)
+	default:
+		return nil
+	}
+}
+
+// checkMySQLPrivileges 检查 MySQL 权限
+func checkMySQLPrivileges(db *gorm.DB, privileges []Privilege) error {
+	var database string
+	if err := db.Raw("SELECT DATABASE()").Scan(&database).Error; err != nil {
+		return fmt.Errorf("failed to get current database: %w", err)
+	}
+
+	var grants []string
+	if err := db.Raw("SHOW GRANTS FOR CURRENT_USER()").Scan(&grants).Error; err != nil {
+		return fmt.Errorf("failed to show grants: %w", err)
+	}
+
+	granted := parseMySQLGrants(grants, database)
+	missing := make([]Privilege, 0)
+	for _, privilege := range privileges {
+		if !granted[privilege] {
+			missing = append(missing, privilege)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	// 通过角色授权的权限不会出现在 SHOW GRANTS 的输出中，使用临时表探测
+	if hasRoleGrants(grants) {
+		missing = probeMySQLPrivileges(db, missing)
+		if len(missing) == 0 {
+			return nil
+		}
+	}
+
+	return &MissingPrivilegesError{Database: database, Missing: missing}
+}
+
+// parseMySQLGrants 解析 SHOW GRANTS 的输出，返回在指定数据库上拥有的权限
+func parseMySQLGrants(grants []string, database string) map[Privilege]bool {
+	granted := make(map[Privilege]bool)
+	for _, grant := range grants {
+		matches := grantPattern.FindStringSubmatch(strings.TrimSpace(grant))
+		if len(matches) < 3 || !grantScopeMatches(matches[2], database) {
+			continue
+		}
+
+		for _, privilege := range strings.Split(matches[1], ",") {
+			privilege = strings.ToUpper(strings.TrimSpace(privilege))
+			if privilege == "ALL" || privilege == "ALL PRIVILEGES" {
+				for _, p := range ddlPrivileges {
+					granted[p] = true
+				}
+				continue
+			}
+			granted[Privilege(privilege)] = true
+		}
+	}
+	return granted
+}
+
+// grantScopeMatches 判断授权范围（*.* 或 `db`.*）是否覆盖指定数据库，表级授权不计入
+func grantScopeMatches(scope, database string) bool {
+	if scope == "*.*" {
+		return true
+	}
+	if !strings.HasSuffix(scope, ".*") {
+		return false
+	}
+
+	pattern := strings.Trim(strings.TrimSuffix(scope, ".*"), "`'\"")
+	// 数据库名中的 % 和 _ 是通配符，\_ 和 \% 表示字面量
+	var expr strings.Builder
+	expr.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			expr.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		case c == '%':
+			expr.WriteString(".*")
+		case c == '_':
+			expr.WriteString(".")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	expr.WriteString("$")
+
+	matched, err := regexp.MatchString(expr.String(), database)
+	return err == nil && matched
+}
+
+// hasRoleGrants 判断是否存在角色授权（如 "GRANT `app_role`@`%` TO `user`@`%`"）
+func hasRoleGrants(grants []string) bool {
+	for _, grant := range grants {
+		upper := strings.ToUpper(strings.TrimSpace(grant))
+		if strings.HasPrefix(upper, "GRANT ") && !strings.Contains(upper, " ON ") {
+			return true
+		}
+	}
+	return false
+}
+
+// probeMySQLPrivileges 通过创建、修改、删除临时表探测权限，返回仍然缺少的权限
+func probeMySQLPrivileges(db *gorm.DB, privileges []Privilege) []Privilege {
+	probeTable := db.Statement.Quote(fmt.Sprintf("__sharding_privilege_probe_%d", os.Getpid()))
+	allowed := make(map[Privilege]bool)
+
+	if db.Exec("CREATE TABLE IF NOT EXISTS "+probeTable+" (id INT)").Error == nil {
+		allowed[PrivilegeCreate] = true
+		if db.Exec("ALTER TABLE "+probeTable+" ADD COLUMN probe INT").Error == nil {
+			allowed[PrivilegeAlter] = true
+		}
+		if db.Exec("DROP TABLE "+probeTable).Error == nil {
+			allowed[PrivilegeDrop] = true
+		}
+	}
+
+	missing := make([]Privilege, 0)
+	for _, privilege := range privileges {
+		if !allowed[privilege] {
+			missing = append(missing, privilege)
+		}
+	}
+	return missing
+}
+
+// checkPostgresPrivileges 检查 PostgreSQL 当前 schema 的 CREATE 权限
+func checkPostgresPrivileges(db *gorm.DB, privileges []Privilege) error {
+	var schemaName string
+	if err := db.Raw("SELECT current_schema()").Scan(&schemaName).Error; err != nil {
+		return fmt.Errorf("failed to get current schema: %w", err)
+	}
+
+	for _, privilege := range privileges {
+		if privilege != PrivilegeCreate {
+			continue
+		}
+		var allowed bool
+		if err := db.Raw("SELECT has_schema_privilege(current_schema(), 'CREATE')").Scan(&allowed).Error; err != nil {
+			return fmt.Errorf("failed to check schema privileges: %w", err)
+		}
+		if !allowed {
+			return &MissingPrivilegesError{Database: schemaName, Missing: []Privilege{PrivilegeCreate}}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+// Package prommetrics 基于 Prometheus 的分表指标（sharding.ShardMetrics 的实现）
+// 单独的模块（x2-sharding-module/sharding/prommetrics），不使用指标时不会引入 Prometheus 依赖
+package prommetrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"x2-sharding-module/sharding"
+)
+
+// Options 指标配置
+type Options struct {
+	Namespace     string            // 指标名称的命名空间（默认 "sharding"）
+	ConstLabels   prometheus.Labels // 所有指标的固定标签（可选，如 service）
+	Buckets       []float64         // 分表执行耗时（秒）的直方图桶（默认 prometheus.DefBuckets）
+	FanOutBuckets []float64         // 扇出宽度的直方图桶（默认 1, 2, 4, ..., 512）
+}
+
+// Metrics 分表指标：
+// {namespace}_shard_queries_total{table,status}、{namespace}_shard_query_duration_seconds{table}、
+// {namespace}_fanout_width、{namespace}_skipped_missing_tables_total{table}、{namespace}_dedup_dropped_rows_total
+type Metrics struct {
+	queries      *prometheus.CounterVec
+	latency      *prometheus.HistogramVec
+	fanOut       prometheus.Histogram
+	skipped      *prometheus.CounterVec
+	dedupDropped prometheus.Counter
+}
+
+// New 创建分表指标并注册到 registerer（不设置为 sharding 包的全局指标收集器）
+func New(registerer prometheus.Registerer, options ...Options) (*Metrics, error) {
+	opts := Options{}
+	if len(options) > 0 {
+		opts = options[0]
+	}
+	if opts.Namespace == "" {
+		opts.Namespace = "sharding"
+	}
+	if len(opts.Buckets) == 0 {
+		opts.Buckets = prometheus.DefBuckets
+	}
+	if len(opts.FanOutBuckets) == 0 {
+		opts.FanOutBuckets = prometheus.ExponentialBuckets(1, 2, 10)
+	}
+
+	m := &Metrics{
+		queries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   opts.Namespace,
+			Name:        "shard_queries_total",
+			Help:        "Executions of cross-table operations on each shard table.",
+			ConstLabels: opts.ConstLabels,
+		}, []string{"table", "status"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   opts.Namespace,
+			Name:        "shard_query_duration_seconds",
+			Help:        "Duration of cross-table operations on each shard table.",
+			ConstLabels: opts.ConstLabels,
+			Buckets:     opts.Buckets,
+		}, []string{"table"}),
+		fanOut: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace:   opts.Namespace,
+			Name:        "fanout_width",
+			Help:        "Number of shard tables (or join combinations) touched by a cross-table call.",
+			ConstLabels: opts.ConstLabels,
+			Buckets:     opts.FanOutBuckets,
+		}),
+		skipped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   opts.Namespace,
+			Name:        "skipped_missing_tables_total",
+			Help:        "Shard tables skipped because they do not exist.",
+			ConstLabels: opts.ConstLabels,
+		}, []string{"table"}),
+		dedupDropped: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   opts.Namespace,
+			Name:        "dedup_dropped_rows_total",
+			Help:        "Rows dropped by deduplication when merging cross-table results.",
+			ConstLabels: opts.ConstLabels,
+		}),
+	}
+
+	for _, collector := range []prometheus.Collector{m.queries, m.latency, m.fanOut, m.skipped, m.dedupDropped} {
+		if err := registerer.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// Register 创建分表指标并注册到 registerer，然后设置为 sharding 包的全局指标收集器
+func Register(registerer prometheus.Registerer, options ...Options) (*Metrics, error) {
+	m, err := New(registerer, options...)
+	if err != nil {
+		return nil, err
+	}
+	sharding.SetShardMetrics(m)
+	return m, nil
+}
+
+// ObserveShardQuery 记录单个分表的一次执行（sharding.ShardMetrics 接口）
+func (m *Metrics) ObserveShardQuery(tableName string, duration time.Duration, err error) {
+	status := "ok"
+	if err != nil {
+		status = "error"
+	}
+	m.queries.WithLabelValues(tableName, status).Inc()
+	m.latency.WithLabelValues(tableName).Observe(duration.Seconds())
+}
+
+// ObserveFanOut 记录一次跨表操作的扇出宽度（sharding.ShardMetrics 接口）
+func (m *Metrics) ObserveFanOut(width int) {
+	m.fanOut.Observe(float64(width))
+}
+
+// IncSkippedMissingTable 记录不存在而被跳过的分表（sharding.ShardMetrics 接口）
+func (m *Metrics) IncSkippedMissingTable(tableName string) {
+	m.skipped.WithLabelValues(tableName).Inc()
+}
+
+// AddDedupDropped 记录去重丢弃的行数（sharding.ShardMetrics 接口）
+func (m *Metrics) AddDedupDropped(count int) {
+	if count > 0 {
+		m.dedupDropped.Add(float64(count))
+	}
+}
+
+var _ sharding.ShardMetrics = (*Metrics)(nil)
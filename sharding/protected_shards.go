@@ -0,0 +1,168 @@
+package sharding
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrShardProtected 分表受保护，拒绝执行删除、清空等破坏性操作
+var ErrShardProtected = errors.New("shard table is protected")
+
+// protectedShards 受保护的分表列表
+type protectedShards struct {
+	mu       sync.RWMutex
+	patterns map[string]bool               // 表名或通配符（path.Match 语法，如 "logs_2024*"）
+	checkers []func(tableName string) bool // 动态判断（如当前月份的时间分表）
+}
+
+// defaultProtectedShards 全局受保护的分表列表
+var defaultProtectedShards = &protectedShards{
+	patterns: make(map[string]bool),
+}
+
+// ProtectShards 将分表标记为受保护，支持通配符（如 "sys_*"、"logs_2024*"）
+// 受保护的分表不会被保留策略、压缩、TRUNCATE 和 DROP 等操作处理，即使策略或程序错误选中了它们
+func ProtectShards(tableNames ...string) {
+	defaultProtectedShards.mu.Lock()
+	defer defaultProtectedShards.mu.Unlock()
+
+	for _, tableName := range tableNames {
+		defaultProtectedShards.patterns[tableName] = true
+	}
+}
+
+// UnprotectShards 取消分表的保护（需与 ProtectShards 传入的名称或通配符一致）
+func UnprotectShards(tableNames ...string) {
+	defaultProtectedShards.mu.Lock()
+	defer defaultProtectedShards.mu.Unlock()
+
+	for _, tableName := range tableNames {
+		delete(defaultProtectedShards.patterns, tableName)
+	}
+}
+
+// ProtectShardsFunc 添加动态保护规则，返回 true 的分表受保护
+func ProtectShardsFunc(checker func(tableName string) bool) {
+	defaultProtectedShards.mu.Lock()
+	defer defaultProtectedShards.mu.Unlock()
+
+	defaultProtectedShards.checkers = append(defaultProtectedShards.checkers, checker)
+}
+
+// ProtectCurrentTimeShard 保护时间分表策略中当前时间所在的分表（如本月的 logs_202401）
+// 当前分表随时间变化，每次检查时重新计算
+func ProtectCurrentTimeShard(strategy *TimeShardingStrategy) {
+	ProtectShardsFunc(func(tableName string) bool {
+		return tableName == strategy.GetTableName(strategy.GetBaseTableName(), time.Now())
+	})
+}
+
+// IsShardProtected 判断分表是否受保护
+func IsShardProtected(tableName string) bool {
+	defaultProtectedShards.mu.RLock()
+	defer defaultProtectedShards.mu.RUnlock()
+
+	if defaultProtectedShards.patterns[tableName] {
+		return true
+	}
+	for pattern := range defaultProtectedShards.patterns {
+		if matched, err := path.Match(pattern, tableName); err == nil && matched {
+			return true
+		}
+	}
+	for _, checker := range defaultProtectedShards.checkers {
+		if checker(tableName) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkShardNotProtected 破坏性操作前检查分表是否受保护
+func checkShardNotProtected(operation, tableName string) error {
+	if IsShardProtected(tableName) {
+		return fmt.Errorf("refusing to %s table %s: %w", operation, tableName, ErrShardProtected)
+	}
+	return nil
+}
+
+// DropShardTable 删除分表（受保护的分表返回 ErrShardProtected）
+func DropShardTable(db *gorm.DB, tableName string) error {
+	if err := checkShardNotProtected("drop", tableName); err != nil {
+		return err
+	}
+	defer forgetAutoCreatedTable(db, tableName)
+	return runDDL(db, "DropShardTable", tableName, func(tx *gorm.DB) error {
+		return tx.Exec("DROP TABLE IF EXISTS " + tx.Statement.Quote(tableName)).Error
+	})
+}
+
+// TruncateShardTable 清空分表（受保护的分表返回 ErrShardProtected）
+func TruncateShardTable(db *gorm.DB, tableName string) error {
+	if err := checkShardNotProtected("truncate", tableName); err != nil {
+		return err
+	}
+	return runDDL(db, "TruncateShardTable", tableName, func(tx *gorm.DB) error {
+		return tx.Exec("TRUNCATE TABLE " + tx.Statement.Quote(tableName)).Error
+	})
+}
+
+// ShardLifecycleOptions 删除、清空策略所有分表的选项
+type ShardLifecycleOptions struct {
+	Confirm       bool                  // 必须为 true，否则返回 ErrNotConfirmed（防止误删）
+	TimeRange     *AutoMigrateTimeRange // 时间分表的时间范围（为空时为数据库中已存在的所有分表）
+	SkipProtected bool                  // 跳过受保护的分表（默认有受保护的分表时不执行任何操作，返回 ErrShardProtected）
+}
+
+// DropAllShardTables 删除策略的所有分表（用于测试清理、重置环境），返回删除的分表
+func DropAllShardTables(db *gorm.DB, strategy ShardingStrategy, options ShardLifecycleOptions) ([]string, error) {
+	return forEachShardTable(db, strategy, "drop", options, DropShardTable)
+}
+
+// TruncateAllShardTables 清空策略的所有分表（不存在的分表跳过），返回清空的分表
+func TruncateAllShardTables(db *gorm.DB, strategy ShardingStrategy, options ShardLifecycleOptions) ([]string, error) {
+	return forEachShardTable(db, strategy, "truncate", options, func(db *gorm.DB, tableName string) error {
+		if err := TruncateShardTable(db, tableName); err != nil && !isTableNotFoundError(err) {
+			return err
+		}
+		return nil
+	})
+}
+
+// forEachShardTable 确认并检查受保护的分表后，对策略的每个分表执行破坏性操作
+func forEachShardTable(db *gorm.DB, strategy ShardingStrategy, operation string, options ShardLifecycleOptions, fn func(db *gorm.DB, tableName string) error) ([]string, error) {
+	if !options.Confirm {
+		return nil, fmt.Errorf("refusing to %s all tables of %s: %w", operation, strategy.GetBaseTableName(), ErrNotConfirmed)
+	}
+
+	tableNames, err := shardTablesInRange(db, strategy, options.TimeRange)
+	if err != nil {
+		return nil, err
+	}
+
+	// 先检查所有分表，避免处理到一半才遇到受保护的分表
+	targets := make([]string, 0, len(tableNames))
+	for _, tableName := range tableNames {
+		if err := checkShardNotProtected(operation, tableName); err != nil {
+			if options.SkipProtected {
+				continue
+			}
+			return nil, err
+		}
+		targets = append(targets, tableName)
+	}
+
+	done := make([]string, 0, len(targets))
+	for _, tableName := range targets {
+		if err := fn(db, tableName); err != nil {
+			return done, fmt.Errorf("failed to %s table %s: %w", operation, tableName, err)
+		}
+		done = append(done, tableName)
+	}
+	return done, nil
+}
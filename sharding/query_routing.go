@@ -0,0 +1,238 @@
+package sharding
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ShardingKeyProvider 提供分表键字段名的策略（可选接口）
+// 实现该接口的策略可以根据 WHERE 条件中的分表键自动路由查询
+type ShardingKeyProvider interface {
+	// GetShardingKey 获取分表键字段名
+	GetShardingKey() string
+}
+
+// whereConditionPattern 匹配 "user_id = ?"、"users.user_id IN ?"、"`user_id` IN (?)" 等简单条件
+var whereConditionPattern = regexp.MustCompile("(?i)^\\s*(?:`?\\w+`?\\.)?`?(\\w+)`?\\s*(=|IN)\\s*\\(?\\s*\\?\\s*\\)?\\s*$")
+
+// routeQueryByWhere 根据 WHERE 条件中的分表键值自动改写查询的表名
+// 单个分表时直接替换表名；多个分表（IN 条件跨分表）时使用 UNION ALL 子查询
+// 已通过 Table() 指定表名的查询不会被改写
+func routeQueryByWhere(db *gorm.DB, strategy ShardingStrategy) {
+	stmt := db.Statement
+	baseTableName := strategy.GetBaseTableName()
+	if !isUnroutedStatement(stmt, baseTableName) {
+		return
+	}
+	if tableName, ok := forcedTable(stmt.Context, strategy); ok {
+		stmt.Table = tableName
+		return
+	}
+
+	tableNames, err := getWhereTableNames(stmt, strategy)
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+	if len(tableNames) == 0 {
+		return
+	}
+
+	if len(tableNames) == 1 {
+		stmt.Table = tableNames[0]
+		return
+	}
+
+	// 多个分表：使用基础表名作为 UNION ALL 子查询的别名，保证 WHERE/ORDER BY 中的列引用仍然有效
+	queries := make([]string, 0, len(tableNames))
+	for _, tableName := range tableNames {
+		queries = append(queries, "SELECT * FROM "+stmt.Quote(tableName))
+	}
+	stmt.TableExpr = &clause.Expr{
+		SQL: "(" + strings.Join(queries, " UNION ALL ") + ") AS " + stmt.Quote(baseTableName),
+	}
+}
+
+// routeWriteStatement 为 UPDATE/DELETE 语句自动路由到分表
+// 优先使用模型中的分表键值（如 db.Save(&user)），模型中没有有效值时使用 WHERE 条件中的分表键值；
+// WHERE 条件跨多个分表时返回错误，此时应使用 CrossTableUpdate/CrossTableDelete
+func routeWriteStatement(db *gorm.DB, strategy ShardingStrategy) {
+	stmt := db.Statement
+	baseTableName := strategy.GetBaseTableName()
+	if !isUnroutedStatement(stmt, baseTableName) {
+		return
+	}
+	if tableName, ok := forcedTable(stmt.Context, strategy); ok {
+		stmt.Table = tableName
+		return
+	}
+
+	for _, model := range []interface{}{stmt.Model, stmt.Dest} {
+		if shardingValue, ok := getModelShardingValue(strategy, model); ok {
+			tableName, err := GetTableNameE(strategy, baseTableName, shardingValue)
+			if err != nil {
+				db.AddError(err)
+				return
+			}
+			stmt.Table = tableName
+			return
+		}
+	}
+
+	tableNames, err := getWhereTableNames(stmt, strategy)
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+	switch len(tableNames) {
+	case 0:
+		return
+	case 1:
+		stmt.Table = tableNames[0]
+	default:
+		db.AddError(fmt.Errorf("sharding key values span multiple shard tables %v, use CrossTableUpdate or CrossTableDelete instead", tableNames))
+	}
+}
+
+// isUnroutedStatement 判断语句是否作用于策略的基础表且尚未指定分表
+func isUnroutedStatement(stmt *gorm.Statement, baseTableName string) bool {
+	if stmt.Schema == nil || stmt.Schema.Table != baseTableName {
+		return false
+	}
+	return stmt.Table == baseTableName && stmt.TableExpr == nil
+}
+
+// getModelShardingValue 从单个模型对象中提取分表键值，零值视为未设置
+func getModelShardingValue(strategy ShardingStrategy, model interface{}) (interface{}, bool) {
+	if model == nil {
+		return nil, false
+	}
+	rv := reflect.ValueOf(model)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, false
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, false
+	}
+
+	shardingValue, err := strategy.GetShardingValue(model)
+	if err != nil || shardingValue == nil || reflect.ValueOf(shardingValue).IsZero() {
+		return nil, false
+	}
+	return shardingValue, true
+}
+
+// getWhereTableNames 根据 WHERE 条件中的分表键值计算目标分表（去重并保持顺序），分表键值无效时返回策略的路由错误
+func getWhereTableNames(stmt *gorm.Statement, strategy ShardingStrategy) ([]string, error) {
+	keyProvider, ok := strategy.(ShardingKeyProvider)
+	if !ok {
+		return nil, nil
+	}
+
+	// 分表键可能是字段名（UserID）或列名（user_id），优先通过模型 schema 解析出列名
+	shardingKey := keyProvider.GetShardingKey()
+	if field := stmt.Schema.LookUpField(shardingKey); field != nil && field.DBName != "" {
+		shardingKey = field.DBName
+	}
+
+	shardingValues, ok := extractWhereShardingValues(stmt, shardingKey)
+	if !ok || len(shardingValues) == 0 {
+		return nil, nil
+	}
+
+	baseTableName := strategy.GetBaseTableName()
+	tableNames := make([]string, 0, len(shardingValues))
+	seen := make(map[string]bool)
+	for _, value := range shardingValues {
+		tableName, err := GetTableNameE(strategy, baseTableName, value)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[tableName] {
+			seen[tableName] = true
+			tableNames = append(tableNames, tableName)
+		}
+	}
+	return tableNames, nil
+}
+
+// extractWhereShardingValues 从 WHERE 子句中提取分表键的值
+// 支持 clause.Eq、clause.IN 以及 "key = ?"、"key IN ?" 形式的字符串条件，只检查顶层的 AND 条件
+func extractWhereShardingValues(stmt *gorm.Statement, shardingKey string) ([]interface{}, bool) {
+	whereClause, ok := stmt.Clauses["WHERE"]
+	if !ok {
+		return nil, false
+	}
+	where, ok := whereClause.Expression.(clause.Where)
+	if !ok {
+		return nil, false
+	}
+
+	for _, expr := range where.Exprs {
+		switch e := expr.(type) {
+		case clause.Eq:
+			if matchShardingKey(columnName(e.Column), shardingKey) {
+				return []interface{}{e.Value}, true
+			}
+		case clause.IN:
+			if matchShardingKey(columnName(e.Column), shardingKey) {
+				return e.Values, true
+			}
+		case clause.Expr:
+			matches := whereConditionPattern.FindStringSubmatch(e.SQL)
+			if len(matches) < 2 || len(e.Vars) != 1 || !matchShardingKey(matches[1], shardingKey) {
+				continue
+			}
+			return expandValues(e.Vars[0]), true
+		}
+	}
+
+	return nil, false
+}
+
+// columnName 获取条件中的列名（去除表名前缀和反引号）
+func columnName(column interface{}) string {
+	var name string
+	switch c := column.(type) {
+	case string:
+		name = c
+	case clause.Column:
+		name = c.Name
+	default:
+		return ""
+	}
+
+	if idx := strings.LastIndex(name, "."); idx >= 0 {
+		name = name[idx+1:]
+	}
+	return strings.Trim(name, "`\"")
+}
+
+// matchShardingKey 判断列名是否与分表键匹配（支持字段名和下划线列名）
+func matchShardingKey(column, shardingKey string) bool {
+	if column == "" || shardingKey == "" {
+		return false
+	}
+	return strings.EqualFold(column, shardingKey) || column == toSnakeCase(shardingKey)
+}
+
+// expandValues 将切片参数展开为值列表（[]byte 视为单个值）
+func expandValues(value interface{}) []interface{} {
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() != reflect.Uint8 {
+		values := make([]interface{}, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			values[i] = rv.Index(i).Interface()
+		}
+		return values
+	}
+	return []interface{}{value}
+}
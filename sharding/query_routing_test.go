@@ -0,0 +1,101 @@
+package sharding
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestRouteQueryByWhere(t *testing.T) {
+	db := newDryRunDB(t)
+	strategy := NewHashShardingStrategy("users", "UserID", 4)
+	if err := RegisterSharding(db, strategy); err != nil {
+		t.Fatal(err)
+	}
+	table := func(value int64) string { return strategy.GetTableName("users", value) }
+	// 与 1 在同一个分表和不在同一个分表中的分表键值
+	same, other := int64(0), int64(0)
+	for value := int64(2); same == 0 || other == 0; value++ {
+		if table(value) == table(1) && same == 0 {
+			same = value
+		} else if table(value) != table(1) && other == 0 {
+			other = value
+		}
+	}
+	union := fmt.Sprintf("(SELECT * FROM `%s` UNION ALL SELECT * FROM `%s`) AS `users`", table(1), table(other))
+
+	tests := []struct {
+		name  string
+		query func(tx *gorm.DB) *gorm.DB
+		want  string
+	}{
+		{
+			name:  "equal",
+			query: func(tx *gorm.DB) *gorm.DB { return tx.Where("user_id = ?", 5).Find(&[]testUser{}) },
+			want:  "SELECT * FROM `" + table(5) + "` WHERE user_id = 5",
+		},
+		{
+			name:  "struct condition",
+			query: func(tx *gorm.DB) *gorm.DB { return tx.Where(&testUser{UserID: 6}).Find(&[]testUser{}) },
+			want:  fmt.Sprintf("SELECT * FROM `%s` WHERE `%s`.`user_id` = 6", table(6), table(6)),
+		},
+		{
+			name:  "in across shards",
+			query: func(tx *gorm.DB) *gorm.DB { return tx.Where("user_id IN ?", []int64{1, other}).Find(&[]testUser{}) },
+			want:  fmt.Sprintf("SELECT * FROM %s WHERE user_id IN (1,%d)", union, other),
+		},
+		{
+			name:  "in same shard",
+			query: func(tx *gorm.DB) *gorm.DB { return tx.Where("user_id IN ?", []int64{1, same}).Find(&[]testUser{}) },
+			want:  fmt.Sprintf("SELECT * FROM `%s` WHERE user_id IN (1,%d)", table(1), same),
+		},
+		{
+			name:  "explicit table",
+			query: func(tx *gorm.DB) *gorm.DB { return tx.Table("users_3").Where("user_id = ?", 5).Find(&[]testUser{}) },
+			want:  "SELECT * FROM `users_3` WHERE user_id = 5",
+		},
+		{
+			name:  "no sharding key",
+			query: func(tx *gorm.DB) *gorm.DB { return tx.Where("name = ?", "a").Find(&[]testUser{}) },
+			want:  "SELECT * FROM `users` WHERE name = 'a'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := db.ToSQL(tt.query); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRouteWriteStatement(t *testing.T) {
+	db := newDryRunDB(t)
+	strategy := NewHashShardingStrategy("users", "UserID", 4)
+	if err := RegisterSharding(db, strategy); err != nil {
+		t.Fatal(err)
+	}
+
+	sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB { return tx.Delete(&testUser{ID: 3, UserID: 6}) })
+	if want := "DELETE FROM `" + strategy.GetTableName("users", int64(6)) + "`"; !strings.HasPrefix(sql, want) {
+		t.Errorf("delete by model: got %q, want prefix %q", sql, want)
+	}
+
+	sql = db.ToSQL(func(tx *gorm.DB) *gorm.DB { return tx.Delete(&testUser{}, "user_id = ?", 9) })
+	if want := "DELETE FROM `" + strategy.GetTableName("users", 9) + "`"; !strings.HasPrefix(sql, want) {
+		t.Errorf("delete by where: got %q, want prefix %q", sql, want)
+	}
+
+	var values []int
+	for value := 1; len(values) < 2; value++ {
+		if len(values) == 0 || strategy.GetTableName("users", value) != strategy.GetTableName("users", values[0]) {
+			values = append(values, value)
+		}
+	}
+	err := db.Model(&testUser{}).Where("user_id IN ?", values).Update("name", "a").Error
+	if err == nil || !strings.Contains(err.Error(), "span multiple shard tables") {
+		t.Errorf("update across shards: got error %v", err)
+	}
+}
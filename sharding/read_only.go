@@ -0,0 +1,66 @@
+package sharding
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// readOnlyTables 只读模式开关
+type readOnlyTables struct {
+	mu     sync.RWMutex
+	all    bool            // 所有表只读
+	tables map[string]bool // 只读的基础表名
+}
+
+// defaultReadOnlyTables 全局只读模式开关
+var defaultReadOnlyTables = &readOnlyTables{
+	tables: make(map[string]bool),
+}
+
+// SetReadOnly 开启或关闭全局只读模式（如迁移切换期间），开启后所有分表的写入返回 ErrReadOnly，读取不受影响
+func SetReadOnly(enabled bool) {
+	defaultReadOnlyTables.mu.Lock()
+	defer defaultReadOnlyTables.mu.Unlock()
+	defaultReadOnlyTables.all = enabled
+}
+
+// SetTableReadOnly 开启或关闭基础表（所有分表）的只读模式
+func SetTableReadOnly(baseTableName string, enabled bool) {
+	defaultReadOnlyTables.mu.Lock()
+	defer defaultReadOnlyTables.mu.Unlock()
+
+	if enabled {
+		defaultReadOnlyTables.tables[baseTableName] = true
+	} else {
+		delete(defaultReadOnlyTables.tables, baseTableName)
+	}
+}
+
+// IsReadOnly 判断基础表是否处于只读模式（全局只读或该表只读）
+func IsReadOnly(baseTableName string) bool {
+	defaultReadOnlyTables.mu.RLock()
+	defer defaultReadOnlyTables.mu.RUnlock()
+	return defaultReadOnlyTables.all || defaultReadOnlyTables.tables[baseTableName]
+}
+
+// ReadOnlyTables 获取单独设置为只读的基础表（按名称排序，不包含全局只读）
+func ReadOnlyTables() []string {
+	defaultReadOnlyTables.mu.RLock()
+	defer defaultReadOnlyTables.mu.RUnlock()
+
+	tables := make([]string, 0, len(defaultReadOnlyTables.tables))
+	for baseTableName := range defaultReadOnlyTables.tables {
+		tables = append(tables, baseTableName)
+	}
+	sort.Strings(tables)
+	return tables
+}
+
+// checkWritable 写入前检查基础表是否处于只读模式
+func checkWritable(baseTableName string) error {
+	if IsReadOnly(baseTableName) {
+		return fmt.Errorf("refusing to write table %s: %w", baseTableName, ErrReadOnly)
+	}
+	return nil
+}
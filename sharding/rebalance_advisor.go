@@ -0,0 +1,295 @@
+package sharding
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"gorm.io/gorm"
+)
+
+// RebalanceOptions 重新平衡建议的选项
+type RebalanceOptions struct {
+	CandidateCounts    []int             // 评估的分表数量（默认按策略类型生成，如 Hash 为当前数量的 2 倍和 1.5 倍）
+	TargetRowsPerTable int64             // 每个分表的目标行数（可选），设置后按总行数额外评估所需的分表数量，并优先推荐不少于该数量的方案
+	KeyColumn          string            // 范围分表的分表键列名（默认为分表键字段名的下划线形式）
+	Stats              ShardStatsOptions // 统计分表时使用的选项
+}
+
+// RebalanceOption 一个重新平衡方案
+type RebalanceOption struct {
+	TableCount   int     `json:"table_count"`
+	RangeSize    int64   `json:"range_size,omitempty"`    // 范围分表：每个分表的范围大小（NewRangeShardingStrategy 的 rangeSize）
+	Boundaries   []int64 `json:"boundaries,omitempty"`    // 范围分表：使每个分表行数相同的起始边界（第 2 个分表起），供自定义策略参考
+	KeyMovement  float64 `json:"key_movement"`            // 需要移动到其他分表的数据比例（0 ~ 1）
+	ExpectedSkew float64 `json:"expected_skew,omitempty"` // 范围分表：按当前数据分布估算的行数倾斜度（Hash 分表为 0，热点键导致的倾斜不会因增加分表而消除）
+}
+
+// RebalanceAdvice 重新平衡建议
+type RebalanceAdvice struct {
+	StrategyType string            `json:"strategy_type"` // hash、jump_hash、modulo、range
+	Stats        *ShardStatsReport `json:"stats"`
+	Options      []RebalanceOption `json:"options"`     // 按分表数量排序
+	Recommended  int               `json:"recommended"` // 推荐的方案在 Options 中的序号（没有方案时为 -1）
+}
+
+// AdviseRebalance 根据分表统计（ShardStats）为 Hash、Jump Hash、取模和范围分表策略给出重新平衡方案：
+// 每个方案包括新的分表数量（范围分表还包括新的范围大小和均衡边界）以及需要移动的数据比例，
+// 范围分表按各分表的行数和分表键的最小、最大值估算新方案的倾斜度；其他策略返回错误
+func AdviseRebalance(db *gorm.DB, strategy ShardingStrategy, options ...RebalanceOptions) (*RebalanceAdvice, error) {
+	opts := RebalanceOptions{}
+	if len(options) > 0 {
+		opts = options[0]
+	}
+
+	var (
+		strategyType string
+		tableCount   int
+		movement     func(from, to int) float64
+	)
+	switch s := strategy.(type) {
+	case *HashShardingStrategy:
+		strategyType, tableCount, movement = "hash", s.tableCount, moduloKeyMovement
+	case *ModuloShardingStrategy:
+		strategyType, tableCount, movement = "modulo", s.modulo, moduloKeyMovement
+	case *JumpHashShardingStrategy:
+		strategyType, tableCount, movement = "jump_hash", s.tableCount, jumpHashKeyMovement
+	case *RangeShardingStrategy:
+		strategyType, tableCount = "range", s.tableCount
+	default:
+		return nil, fmt.Errorf("rebalance advice is not supported for strategy %T", strategy)
+	}
+
+	stats, err := ShardStats(db, strategy, opts.Stats)
+	if err != nil {
+		return nil, err
+	}
+	advice := &RebalanceAdvice{StrategyType: strategyType, Stats: stats, Recommended: -1}
+
+	var required int
+	if opts.TargetRowsPerTable > 0 {
+		required = int((stats.TotalRows + opts.TargetRowsPerTable - 1) / opts.TargetRowsPerTable)
+	}
+	counts := rebalanceCandidates(strategyType, tableCount, required, opts.CandidateCounts)
+
+	if rangeStrategy, ok := strategy.(*RangeShardingStrategy); ok {
+		segments, err := loadRangeSegments(db, rangeStrategy, stats, opts.KeyColumn)
+		if err != nil {
+			return nil, err
+		}
+		for _, count := range counts {
+			advice.Options = append(advice.Options, planRangeRebalance(segments, count))
+		}
+	} else {
+		for _, count := range counts {
+			advice.Options = append(advice.Options, RebalanceOption{TableCount: count, KeyMovement: movement(tableCount, count)})
+		}
+	}
+
+	advice.Recommended = recommendRebalance(advice.Options, strategyType == "range", tableCount, required)
+	return advice, nil
+}
+
+// rebalanceCandidates 生成要评估的分表数量（去重、排序）
+func rebalanceCandidates(strategyType string, tableCount, required int, candidates []int) []int {
+	if len(candidates) == 0 {
+		switch strategyType {
+		case "range":
+			// 保持分表数量只调整范围大小，或增加一倍
+			candidates = []int{tableCount, tableCount * 2}
+		case "jump_hash":
+			candidates = []int{tableCount + 1, tableCount * 2}
+		default:
+			candidates = []int{tableCount + (tableCount+1)/2, tableCount * 2}
+		}
+		if required > 0 {
+			candidates = append(candidates, required)
+		}
+	}
+
+	seen := make(map[int]bool, len(candidates))
+	var counts []int
+	for _, count := range candidates {
+		if count <= 0 || seen[count] || (count == tableCount && strategyType != "range") {
+			continue
+		}
+		seen[count] = true
+		counts = append(counts, count)
+	}
+	sort.Ints(counts)
+	return counts
+}
+
+// recommendRebalance 推荐方案：优先满足目标行数的方案，范围分表选择倾斜度最小的方案，其他策略选择移动数据最少的方案
+func recommendRebalance(options []RebalanceOption, bySkew bool, tableCount, required int) int {
+	best := -1
+	for i, option := range options {
+		if required > 0 && option.TableCount < required {
+			continue
+		}
+		if !bySkew && option.TableCount < tableCount {
+			continue
+		}
+		if best < 0 {
+			best = i
+			continue
+		}
+		current := options[best]
+		switch {
+		case bySkew && option.ExpectedSkew < current.ExpectedSkew-1e-9:
+			best = i
+		case (!bySkew || math.Abs(option.ExpectedSkew-current.ExpectedSkew) <= 1e-9) && option.KeyMovement < current.KeyMovement:
+			best = i
+		}
+	}
+	return best
+}
+
+// moduloKeyMovement 取模（Hash 取模）的分表数量从 from 变为 to 时需要移动的数据比例：
+// 键的哈希值在模 lcm(from, to) 下均匀分布时，只有 min(from, to) 个余数映射到相同序号的分表
+func moduloKeyMovement(from, to int) float64 {
+	if from == to {
+		return 0
+	}
+	lcm := from / gcd(from, to) * to
+	return 1 - float64(min(from, to))/float64(lcm)
+}
+
+// jumpHashKeyMovement Jump Consistent Hash 的分表数量从 from 变为 to 时需要移动的数据比例（只移动到新增的分表或从删除的分表移出）
+func jumpHashKeyMovement(from, to int) float64 {
+	if from == to {
+		return 0
+	}
+	return float64(abs(to-from)) / float64(max(from, to))
+}
+
+// gcd 最大公约数
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// abs 绝对值
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// rangeSegment 范围分表中一个分表的数据分布（假设在 [low, high] 内均匀分布）
+type rangeSegment struct {
+	index     int   // 当前分表序号
+	low, high int64 // 分表键的最小、最大值
+	rows      int64
+}
+
+// loadRangeSegments 读取范围分表每个非空分表的分表键最小、最大值
+func loadRangeSegments(db *gorm.DB, strategy *RangeShardingStrategy, stats *ShardStatsReport, keyColumn string) ([]rangeSegment, error) {
+	if keyColumn == "" {
+		keyColumn = toSnakeCase(strategy.shardingKey)
+	}
+	quoted := db.Statement.Quote(keyColumn)
+
+	position := make(map[string]int)
+	for i, tableName := range strategy.GetAllTableNames(strategy.baseTableName) {
+		position[tableName] = i
+	}
+
+	var segments []rangeSegment
+	for _, table := range stats.Tables {
+		if table.Missing || table.Rows == 0 {
+			continue
+		}
+		var bounds struct {
+			Low  *int64
+			High *int64
+		}
+		err := db.Table(table.Table).Select(fmt.Sprintf("MIN(%s) AS low, MAX(%s) AS high", quoted, quoted)).Scan(&bounds).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key range of table %s: %w", table.Table, err)
+		}
+		if bounds.Low == nil || bounds.High == nil {
+			continue
+		}
+		segments = append(segments, rangeSegment{index: position[table.Table], low: *bounds.Low, high: *bounds.High, rows: table.Rows})
+	}
+	return segments, nil
+}
+
+// planRangeRebalance 评估 count 个分表的范围分表方案：范围大小使最大的分表键落在最后一个分表中，
+// 按各分表内均匀分布估算每个新分表的行数、倾斜度和需要移动的数据，并计算使行数均衡的边界
+func planRangeRebalance(segments []rangeSegment, count int) RebalanceOption {
+	option := RebalanceOption{TableCount: count}
+	var maxKey, total int64
+	for _, segment := range segments {
+		if segment.high > maxKey {
+			maxKey = segment.high
+		}
+		total += segment.rows
+	}
+	option.RangeSize = maxKey/int64(count) + 1
+	if total == 0 {
+		return option
+	}
+
+	// 新分表 j 的范围为 [j*rangeSize, (j+1)*rangeSize)，第一个分表包括负数，最后一个分表包括更大的值
+	rows := make([]float64, count)
+	var moved float64
+	for _, segment := range segments {
+		width := float64(segment.high-segment.low) + 1
+		for j := 0; j < count; j++ {
+			low, high := float64(j)*float64(option.RangeSize), float64(j+1)*float64(option.RangeSize)
+			if j == 0 {
+				low = math.Inf(-1)
+			}
+			if j == count-1 {
+				high = math.Inf(1)
+			}
+			overlap := math.Min(high, float64(segment.high)+1) - math.Max(low, float64(segment.low))
+			if overlap <= 0 {
+				continue
+			}
+			share := float64(segment.rows) * overlap / width
+			rows[j] += share
+			if j != segment.index {
+				moved += share
+			}
+		}
+	}
+
+	var maxRows float64
+	for _, r := range rows {
+		maxRows = math.Max(maxRows, r)
+	}
+	option.ExpectedSkew = maxRows / (float64(total) / float64(count))
+	option.KeyMovement = moved / float64(total)
+	option.Boundaries = rangeQuantiles(segments, total, count)
+	return option
+}
+
+// rangeQuantiles 按各分表内均匀分布计算使 count 个分表行数相同的边界
+func rangeQuantiles(segments []rangeSegment, total int64, count int) []int64 {
+	sorted := append([]rangeSegment(nil), segments...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].low < sorted[j].low })
+
+	boundaries := make([]int64, 0, count-1)
+	var cumulative float64
+	next := 1
+	for _, segment := range sorted {
+		width := float64(segment.high-segment.low) + 1
+		for next < count {
+			target := float64(total) * float64(next) / float64(count)
+			if cumulative+float64(segment.rows) < target {
+				break
+			}
+			offset := (target - cumulative) / float64(segment.rows) * width
+			boundaries = append(boundaries, segment.low+int64(math.Ceil(offset)))
+			next++
+		}
+		cumulative += float64(segment.rows)
+	}
+	return boundaries
+}
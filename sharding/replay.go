@@ -0,0 +1,424 @@
+package sharding
+
+import (
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// captureStartKey 记录语句开始执行时间的实例设置键
+	captureStartKey = "sharding:capture:start"
+
+	// captureTimeFormat 采集时间参数使用的格式（MySQL DATETIME 字面量）
+	captureTimeFormat = "2006-01-02 15:04:05.999999"
+)
+
+// CapturedOperation 采集的一次逻辑操作（一条作用于分表的语句）
+type CapturedOperation struct {
+	Time           time.Time     `json:"time"`
+	Operation      string        `json:"operation"`                 // query、create、update、delete
+	BaseTable      string        `json:"base_table"`                // 基础表名
+	Strategy       string        `json:"strategy"`                  // 采集时的策略指纹（StrategyFingerprint）
+	Fingerprint    string        `json:"fingerprint"`               // 语句指纹（分表名替换为基础表名后的 SQL 的哈希，同一个查询构建器得到相同的指纹）
+	SQL            string        `json:"sql"`                       // 分表名替换为基础表名后的 SQL
+	Vars           []interface{} `json:"vars,omitempty"`            // SQL 参数
+	ShardingValues []interface{} `json:"sharding_values,omitempty"` // 语句使用的分表键值（模型或 WHERE 条件中的值）
+	Tables         []string      `json:"tables"`                    // 语句访问的分表
+	Duration       time.Duration `json:"duration"`                  // 执行耗时
+	RowsAffected   int64         `json:"rows_affected"`             // 返回或影响的行数
+	Error          string        `json:"error,omitempty"`           // 执行错误
+}
+
+// OperationCapture 逻辑操作采集器，将作用于分表的语句逐行以 JSON 写入 w，用于在新的分表拓扑上重放
+type OperationCapture struct {
+	mu           sync.Mutex
+	encoder      *json.Encoder
+	strategies   []ShardingStrategy
+	fingerprints map[string]string // 基础表名 -> 策略指纹
+	stopped      atomic.Bool
+	count        int64
+	err          error
+}
+
+// EnableOperationCapture 注册回调，开始采集 strategies 对应的基础表上的查询和写入
+// 采集的 SQL 参数中时间按 "2006-01-02 15:04:05.999999" 格式记录，二进制参数按 base64 记录
+func EnableOperationCapture(db *gorm.DB, w io.Writer, strategies ...ShardingStrategy) (*OperationCapture, error) {
+	capture := &OperationCapture{
+		encoder:      json.NewEncoder(w),
+		strategies:   strategies,
+		fingerprints: make(map[string]string, len(strategies)),
+	}
+	for _, strategy := range strategies {
+		capture.fingerprints[strategy.GetBaseTableName()] = StrategyFingerprint(strategy)
+	}
+
+	start := func(db *gorm.DB) {
+		db.InstanceSet(captureStartKey, time.Now())
+	}
+	record := func(operation string) func(*gorm.DB) {
+		return func(db *gorm.DB) { capture.record(db, operation) }
+	}
+	err := db.Callback().Create().Before("gorm:create").Register("sharding:capture:start:create", start)
+	if err == nil {
+		err = db.Callback().Create().After("gorm:create").Register("sharding:capture:create", record("create"))
+	}
+	if err == nil {
+		err = db.Callback().Query().Before("gorm:query").Register("sharding:capture:start:query", start)
+	}
+	if err == nil {
+		err = db.Callback().Query().After("gorm:query").Register("sharding:capture:query", record("query"))
+	}
+	if err == nil {
+		err = db.Callback().Update().Before("gorm:update").Register("sharding:capture:start:update", start)
+	}
+	if err == nil {
+		err = db.Callback().Update().After("gorm:update").Register("sharding:capture:update", record("update"))
+	}
+	if err == nil {
+		err = db.Callback().Delete().Before("gorm:delete").Register("sharding:capture:start:delete", start)
+	}
+	if err == nil {
+		err = db.Callback().Delete().After("gorm:delete").Register("sharding:capture:delete", record("delete"))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to register operation capture callbacks: %w", err)
+	}
+	return capture, nil
+}
+
+// Stop 停止采集（回调仍然保留，但不再记录）
+func (c *OperationCapture) Stop() {
+	c.stopped.Store(true)
+}
+
+// Count 已采集的操作数量
+func (c *OperationCapture) Count() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.count
+}
+
+// Err 写入采集文件时遇到的第一个错误（发生错误后不再记录）
+func (c *OperationCapture) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// record 记录一条执行完成的语句
+func (c *OperationCapture) record(db *gorm.DB, operation string) {
+	if c.stopped.Load() || db.DryRun {
+		return
+	}
+	stmt := db.Statement
+	strategy := c.match(stmt)
+	if strategy == nil {
+		return
+	}
+	rawSQL := stmt.SQL.String()
+	if rawSQL == "" {
+		return
+	}
+
+	// 将分表名替换为基础表名，重放时再替换为新拓扑中的分表
+	baseTableName := strategy.GetBaseTableName()
+	var tables []string
+	template := rawSQL
+	if stmt.TableExpr != nil {
+		tables, _ = getWhereTableNames(stmt, strategy)
+		template = strings.Replace(rawSQL, stmt.TableExpr.SQL, stmt.Quote(baseTableName), 1)
+	} else {
+		tables = []string{stmt.Table}
+		template = strings.ReplaceAll(rawSQL, stmt.Quote(stmt.Table), stmt.Quote(baseTableName))
+	}
+
+	op := CapturedOperation{
+		Time:           time.Now(),
+		Operation:      operation,
+		BaseTable:      baseTableName,
+		Strategy:       c.fingerprints[baseTableName],
+		Fingerprint:    sqlFingerprint(template),
+		SQL:            template,
+		ShardingValues: captureShardingValues(stmt, strategy, operation),
+		Tables:         tables,
+		RowsAffected:   db.RowsAffected,
+	}
+	for _, v := range stmt.Vars {
+		op.Vars = append(op.Vars, captureValue(v))
+	}
+	if started, ok := db.InstanceGet(captureStartKey); ok {
+		if t, ok := started.(time.Time); ok {
+			op.Duration = time.Since(t)
+		}
+	}
+	if db.Error != nil {
+		op.Error = db.Error.Error()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return
+	}
+	if err := c.encoder.Encode(op); err != nil {
+		c.err = fmt.Errorf("failed to write captured operation: %w", err)
+		return
+	}
+	c.count++
+}
+
+// match 查找语句对应的策略（优先使用模型的表名，否则按分表名前缀匹配最长的基础表名）
+func (c *OperationCapture) match(stmt *gorm.Statement) ShardingStrategy {
+	var matched ShardingStrategy
+	for _, strategy := range c.strategies {
+		baseTableName := strategy.GetBaseTableName()
+		if stmt.Schema != nil && stmt.Schema.Table != "" {
+			if stmt.Schema.Table == baseTableName {
+				return strategy
+			}
+			continue
+		}
+		if stmt.Table == baseTableName || strings.HasPrefix(stmt.Table, baseTableName+"_") {
+			if matched == nil || len(baseTableName) > len(matched.GetBaseTableName()) {
+				matched = strategy
+			}
+		}
+	}
+	return matched
+}
+
+// captureShardingValues 获取语句使用的分表键值（模型中的值优先，其次是 WHERE 条件中的值）
+func captureShardingValues(stmt *gorm.Statement, strategy ShardingStrategy, operation string) []interface{} {
+	var values []interface{}
+	if operation == "create" {
+		rv := reflect.Indirect(reflect.ValueOf(stmt.Dest))
+		if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+			for i := 0; i < rv.Len(); i++ {
+				if value, ok := getModelShardingValue(strategy, rv.Index(i).Interface()); ok {
+					values = append(values, captureValue(value))
+				}
+			}
+			return values
+		}
+	}
+	if operation != "query" {
+		for _, model := range []interface{}{stmt.Model, stmt.Dest} {
+			if value, ok := getModelShardingValue(strategy, model); ok {
+				return []interface{}{captureValue(value)}
+			}
+		}
+	}
+
+	keyProvider, ok := strategy.(ShardingKeyProvider)
+	if !ok {
+		return nil
+	}
+	shardingKey := keyProvider.GetShardingKey()
+	if stmt.Schema != nil {
+		if field := stmt.Schema.LookUpField(shardingKey); field != nil && field.DBName != "" {
+			shardingKey = field.DBName
+		}
+	}
+	whereValues, _ := extractWhereShardingValues(stmt, shardingKey)
+	for _, value := range whereValues {
+		values = append(values, captureValue(value))
+	}
+	return values
+}
+
+// captureValue 将参数转换为可以写入 JSON 并在重放时使用的值
+func captureValue(value interface{}) interface{} {
+	if valuer, ok := value.(driver.Valuer); ok {
+		if rv := reflect.ValueOf(valuer); rv.Kind() == reflect.Ptr && rv.IsNil() {
+			return nil
+		}
+		v, err := valuer.Value()
+		if err != nil {
+			return nil
+		}
+		value = v
+	}
+	switch v := value.(type) {
+	case time.Time:
+		return v.Format(captureTimeFormat)
+	case *time.Time:
+		if v == nil {
+			return nil
+		}
+		return v.Format(captureTimeFormat)
+	case []byte:
+		if utf8.Valid(v) {
+			return string(v)
+		}
+		return v
+	default:
+		return value
+	}
+}
+
+// sqlFingerprint 计算 SQL 的指纹
+func sqlFingerprint(sql string) string {
+	sum := sha256.Sum256([]byte(sql))
+	return hex.EncodeToString(sum[:8])
+}
+
+// ReplayOptions 重放选项
+type ReplayOptions struct {
+	ShardQueryOptions // 每个操作在分表上的并发度、超时和重试
+
+	IncludeWrites bool                      // 是否重放写操作（默认只重放查询，写操作计入 Skipped）
+	OnResult      func(result ReplayResult) // 每个操作重放完成后调用（可选）
+}
+
+// ReplayResult 一个操作的重放结果
+type ReplayResult struct {
+	Operation CapturedOperation // 采集的操作
+	Tables    []string          // 在新拓扑中访问的分表
+	Duration  time.Duration     // 重放耗时
+	Rows      int64             // 查询返回的行数或写入影响的行数
+	Skipped   bool              // 是否跳过（未提供基础表的策略、未启用写操作重放或无法确定写入的分表）
+	Err       error             // 重放错误
+}
+
+// ReplayReport 重放汇总
+type ReplayReport struct {
+	Total            int           // 读取的操作总数
+	Replayed         int           // 重放成功的操作数
+	Skipped          int           // 跳过的操作数
+	Failed           int           // 重放失败的操作数
+	CapturedTables   int           // 已重放的操作在采集时访问的分表总数
+	ReplayedTables   int           // 已重放的操作在新拓扑中访问的分表总数
+	CapturedDuration time.Duration // 已重放的操作在采集时的总耗时
+	ReplayDuration   time.Duration // 已重放的操作的总耗时
+}
+
+// ReplayOperations 读取 EnableOperationCapture 采集的操作，按 strategies 描述的新拓扑重新执行，
+// 用于在预发环境中评估重新分表方案（访问的分表数量、耗时变化）
+// 有分表键值的操作只在新拓扑中对应的分表上执行，否则在所有分表上执行
+func ReplayOperations(db *gorm.DB, r io.Reader, strategies []ShardingStrategy, options ...ReplayOptions) (*ReplayReport, error) {
+	opts := ReplayOptions{}
+	if len(options) > 0 {
+		opts = options[0]
+	}
+	byBaseTable := make(map[string]ShardingStrategy, len(strategies))
+	for _, strategy := range strategies {
+		byBaseTable[strategy.GetBaseTableName()] = strategy
+	}
+
+	report := &ReplayReport{}
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	for {
+		var op CapturedOperation
+		if err := decoder.Decode(&op); err != nil {
+			if errors.Is(err, io.EOF) {
+				return report, nil
+			}
+			return report, fmt.Errorf("failed to decode captured operation %d: %w", report.Total+1, err)
+		}
+		report.Total++
+
+		result := replayOperation(db, byBaseTable[op.BaseTable], op, opts)
+		switch {
+		case result.Skipped:
+			report.Skipped++
+		case result.Err != nil:
+			report.Failed++
+		default:
+			report.Replayed++
+			report.CapturedTables += len(op.Tables)
+			report.ReplayedTables += len(result.Tables)
+			report.CapturedDuration += op.Duration
+			report.ReplayDuration += result.Duration
+		}
+		if opts.OnResult != nil {
+			opts.OnResult(result)
+		}
+	}
+}
+
+// replayOperation 在新拓扑上重放一个操作
+func replayOperation(db *gorm.DB, strategy ShardingStrategy, op CapturedOperation, opts ReplayOptions) ReplayResult {
+	result := ReplayResult{Operation: op}
+	if strategy == nil || (op.Operation != "query" && !opts.IncludeWrites) {
+		result.Skipped = true
+		return result
+	}
+
+	baseTableName := strategy.GetBaseTableName()
+	if len(op.ShardingValues) > 0 {
+		seen := make(map[string]bool)
+		for _, value := range op.ShardingValues {
+			tableName := strategy.GetTableName(baseTableName, replayValue(value))
+			if !seen[tableName] {
+				seen[tableName] = true
+				result.Tables = append(result.Tables, tableName)
+			}
+		}
+	} else if op.Operation != "create" {
+		result.Tables = getTableNamesWithTimeRange(strategy, baseTableName, nil)
+	}
+	// 批量插入的数据在新拓扑中分布到多个分表时无法拆分 SQL
+	if op.Operation == "create" && len(result.Tables) != 1 {
+		result.Skipped = true
+		return result
+	}
+
+	vars := make([]interface{}, len(op.Vars))
+	for i, v := range op.Vars {
+		vars[i] = replayValue(v)
+	}
+
+	var rows atomic.Int64
+	quotedBase := db.Statement.Quote(baseTableName)
+	started := time.Now()
+	result.Err = runOnShards(db, result.Tables, opts.ShardQueryOptions, func(tx *gorm.DB, _ int, tableName string) error {
+		sql := strings.ReplaceAll(op.SQL, quotedBase, tx.Statement.Quote(tableName))
+		if op.Operation != "query" {
+			res := tx.Exec(sql, vars...)
+			rows.Add(res.RowsAffected)
+			return res.Error
+		}
+
+		sqlRows, err := tx.Raw(sql, vars...).Rows()
+		if err != nil {
+			return err
+		}
+		defer sqlRows.Close()
+		for sqlRows.Next() {
+			rows.Add(1)
+		}
+		return sqlRows.Err()
+	})
+	result.Duration = time.Since(started)
+	result.Rows = rows.Load()
+	return result
+}
+
+// replayValue 将 JSON 中的数字转换为整数或浮点数
+func replayValue(value interface{}) interface{} {
+	number, ok := value.(json.Number)
+	if !ok {
+		return value
+	}
+	if i, err := number.Int64(); err == nil {
+		return i
+	}
+	if f, err := number.Float64(); err == nil {
+		return f
+	}
+	return number.String()
+}
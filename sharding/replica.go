@@ -0,0 +1,273 @@
+package sharding
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// defaultReplicaCheckInterval 默认的复制延迟检查间隔
+const defaultReplicaCheckInterval = 5 * time.Second
+
+// ReplicaConfig 读写分离配置
+type ReplicaConfig struct {
+	Replicas      []*gorm.DB    // 只读副本
+	MaxLag        time.Duration // 最大允许的复制延迟，超过时该副本不再接收读请求（0 表示不检查延迟）
+	CheckInterval time.Duration // 复制延迟检查间隔（默认 5 秒）
+}
+
+// ReplicaRouter 读写分离路由器
+// 定期检查每个副本的复制延迟（MySQL 的 Seconds_Behind_Master / PostgreSQL 的回放延迟），
+// Reader() 在延迟未超过 MaxLag 的副本间轮询，所有副本都不可用时自动回退到主库
+type ReplicaRouter struct {
+	primary *gorm.DB
+	config  ReplicaConfig
+
+	mu      sync.RWMutex
+	lags    []time.Duration // 最近一次检查到的复制延迟
+	healthy []bool          // 副本是否可以接收读请求
+
+	next     uint64
+	stopOnce sync.Once
+	stop     chan struct{}
+	wg       sync.WaitGroup // 后台检查 goroutine
+}
+
+// NewReplicaRouter 创建读写分离路由器
+// 未调用 Start 或 Refresh 之前，设置了 MaxLag 的副本视为不可用，读请求全部走主库
+func NewReplicaRouter(primary *gorm.DB, config ReplicaConfig) *ReplicaRouter {
+	if config.CheckInterval <= 0 {
+		config.CheckInterval = defaultReplicaCheckInterval
+	}
+
+	healthy := make([]bool, len(config.Replicas))
+	for i := range healthy {
+		healthy[i] = config.MaxLag <= 0
+	}
+
+	return &ReplicaRouter{
+		primary: primary,
+		config:  config,
+		lags:    make([]time.Duration, len(config.Replicas)),
+		healthy: healthy,
+		stop:    make(chan struct{}),
+	}
+}
+
+// Start 立即检查一次复制延迟，然后在后台按 CheckInterval 定期检查
+func (r *ReplicaRouter) Start() {
+	r.Refresh(context.Background())
+
+	// 停止时取消正在执行的检查
+	ctx, cancel := context.WithCancel(context.Background())
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		defer cancel()
+
+		ticker := time.NewTicker(r.config.CheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				r.Refresh(ctx)
+			case <-r.stop:
+				return
+			}
+		}
+	}()
+	go func() {
+		<-r.stop
+		cancel()
+	}()
+}
+
+// Stop 停止后台检查
+func (r *ReplicaRouter) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.stop)
+	})
+}
+
+// Shutdown 停止后台检查并等待正在执行的检查结束（ctx 结束时不再等待）
+func (r *ReplicaRouter) Shutdown(ctx context.Context) error {
+	r.Stop()
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close 停止后台检查并关闭所有副本的连接池（主库的连接池由调用方关闭）
+func (r *ReplicaRouter) Close() error {
+	if err := r.Shutdown(context.Background()); err != nil {
+		return err
+	}
+
+	var errs []error
+	for i, replica := range r.config.Replicas {
+		if err := closeConnectionPool(replica); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close replica %d: %w", i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Refresh 检查所有副本的复制延迟并更新可用状态
+// 检查失败（连接失败、复制中断等）的副本视为不可用
+func (r *ReplicaRouter) Refresh(ctx context.Context) {
+	lags := make([]time.Duration, len(r.config.Replicas))
+	healthy := make([]bool, len(r.config.Replicas))
+
+	for i, replica := range r.config.Replicas {
+		lag, err := measureReplicationLag(ctx, replica)
+		if err != nil {
+			lags[i] = -1
+			continue
+		}
+		lags[i] = lag
+		healthy[i] = r.config.MaxLag <= 0 || lag <= r.config.MaxLag
+	}
+
+	r.mu.Lock()
+	r.lags = lags
+	r.healthy = healthy
+	r.mu.Unlock()
+}
+
+// Reader 获取用于读操作的数据库连接（可直接传给 CrossTableQuery 等跨表读方法）
+func (r *ReplicaRouter) Reader() *gorm.DB {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	n := len(r.config.Replicas)
+	if n == 0 {
+		return r.primary
+	}
+
+	start := int(atomic.AddUint64(&r.next, 1) % uint64(n))
+	for i := 0; i < n; i++ {
+		index := (start + i) % n
+		if r.healthy[index] {
+			return r.config.Replicas[index]
+		}
+	}
+	return r.primary
+}
+
+// Writer 获取用于写操作的数据库连接（主库）
+func (r *ReplicaRouter) Writer() *gorm.DB {
+	return r.primary
+}
+
+// ReplicaLags 获取最近一次检查到的各副本复制延迟（检查失败时为 -1）
+func (r *ReplicaRouter) ReplicaLags() []time.Duration {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	lags := make([]time.Duration, len(r.lags))
+	copy(lags, r.lags)
+	return lags
+}
+
+// measureReplicationLag 查询副本的复制延迟
+func measureReplicationLag(ctx context.Context, db *gorm.DB) (time.Duration, error) {
+	switch db.Dialector.Name() {
+	case "postgres":
+		return measurePostgresLag(ctx, db)
+	case "mysql":
+		return measureMySQLLag(ctx, db)
+	default:
+		return 0, fmt.Errorf("replication lag check is not supported for dialect %s", db.Dialector.Name())
+	}
+}
+
+// measurePostgresLag 查询 PostgreSQL 备库的回放延迟（主库返回 0）
+func measurePostgresLag(ctx context.Context, db *gorm.DB) (time.Duration, error) {
+	var seconds float64
+	err := db.WithContext(ctx).Raw(
+		"SELECT CASE WHEN pg_is_in_recovery() THEN COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0) ELSE 0 END",
+	).Scan(&seconds).Error
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// measureMySQLLag 查询 MySQL 从库的复制延迟
+// 优先使用 SHOW REPLICA STATUS（MySQL 8.0.22+），失败时回退到 SHOW SLAVE STATUS；
+// 不是从库（结果为空）时返回 0，复制中断（延迟为 NULL）时返回错误
+func measureMySQLLag(ctx context.Context, db *gorm.DB) (time.Duration, error) {
+	status, err := queryReplicaStatus(ctx, db, "SHOW REPLICA STATUS")
+	if err != nil {
+		if status, err = queryReplicaStatus(ctx, db, "SHOW SLAVE STATUS"); err != nil {
+			return 0, err
+		}
+	}
+	if status == nil {
+		return 0, nil
+	}
+
+	for _, column := range []string{"Seconds_Behind_Source", "Seconds_Behind_Master"} {
+		value, ok := status[column]
+		if !ok {
+			continue
+		}
+		if !value.Valid {
+			return 0, fmt.Errorf("replication is not running")
+		}
+		seconds, err := strconv.ParseInt(value.String, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s value %q: %w", column, value.String, err)
+		}
+		return time.Duration(seconds) * time.Second, nil
+	}
+	return 0, fmt.Errorf("replication lag column not found in replica status")
+}
+
+// queryReplicaStatus 执行复制状态查询并返回第一行（列名 -> 值），结果为空时返回 nil
+func queryReplicaStatus(ctx context.Context, db *gorm.DB, query string) (map[string]sql.NullString, error) {
+	rows, err := db.WithContext(ctx).Raw(query).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+
+	values := make([]sql.NullString, len(columns))
+	scanArgs := make([]interface{}, len(columns))
+	for i := range values {
+		scanArgs[i] = &values[i]
+	}
+	if err := rows.Scan(scanArgs...); err != nil {
+		return nil, err
+	}
+
+	status := make(map[string]sql.NullString, len(columns))
+	for i, column := range columns {
+		status[column] = values[i]
+	}
+	return status, nil
+}
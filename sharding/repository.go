@@ -0,0 +1,137 @@
+package sharding
+
+import (
+	"fmt"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repository 单个分表模型的仓储，封装分表名称的计算
+//
+//	users := sharding.NewRepository[User](db, userStrategy)
+//	err := users.Create(&User{UserID: 1001, Name: "Alice"})
+//	list, err := users.FindByShardKey(1001)
+//	page, err := users.Paginate(1, 20, func(db *gorm.DB) *gorm.DB { return db.Order("id DESC") })
+type Repository[T any] struct {
+	db       *gorm.DB
+	strategy ShardingStrategy
+	options  ShardQueryOptions // 跨表操作的执行选项
+
+	keyOnce   sync.Once
+	keyColumn string // 分表键对应的列名
+	keyErr    error
+}
+
+// NewRepository 创建分表模型的仓储
+func NewRepository[T any](db *gorm.DB, strategy ShardingStrategy, options ...ShardQueryOptions) *Repository[T] {
+	return &Repository[T]{
+		db:       db,
+		strategy: strategy,
+		options:  getShardQueryOptions(options),
+	}
+}
+
+// Create 写入记录（按分表键值路由，多条记录时按分表分组批量写入）
+func (r *Repository[T]) Create(values ...*T) error {
+	return Create(r.db, r.strategy, values...)
+}
+
+// FindByShardKey 在分表键值对应的分表中查询分表键等于 shardingValue 的记录，conds 为附加条件（同 db.Where）
+func (r *Repository[T]) FindByShardKey(shardingValue interface{}, conds ...interface{}) ([]T, error) {
+	tableName, err := GetTableNameE(r.strategy, r.strategy.GetBaseTableName(), shardingValue)
+	if err != nil {
+		return nil, err
+	}
+	query, err := r.whereShardKey(r.db.Table(tableName), shardingValue)
+	if err != nil {
+		return nil, err
+	}
+	if len(conds) > 0 {
+		query = query.Where(conds[0], conds[1:]...)
+	}
+
+	var results []T
+	if err := query.Find(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// FindAll 跨表查询所有满足条件的记录（见 Query）
+func (r *Repository[T]) FindAll(queryBuilder QueryBuilder) ([]T, error) {
+	return Query[T](r.db, r.strategy, queryBuilder, r.options)
+}
+
+// Update 更新分表键等于 shardingValue 且满足 queryBuilder 条件的记录，返回影响行数
+// values 同 db.Updates（结构体或 map）
+func (r *Repository[T]) Update(shardingValue interface{}, values interface{}, queryBuilder QueryBuilder) (int64, error) {
+	builder, err := r.shardKeyBuilder(shardingValue, queryBuilder)
+	if err != nil {
+		return 0, err
+	}
+	return CrossTableUpdateByShardingValue(r.db, r.strategy, shardingValue, values, builder, r.options)
+}
+
+// Delete 删除分表键等于 shardingValue 且满足 queryBuilder 条件的记录，返回影响行数
+func (r *Repository[T]) Delete(shardingValue interface{}, queryBuilder QueryBuilder) (int64, error) {
+	builder, err := r.shardKeyBuilder(shardingValue, queryBuilder)
+	if err != nil {
+		return 0, err
+	}
+	return CrossTableDeleteByShardingValue(r.db, r.strategy, shardingValue, builder, r.options)
+}
+
+// Paginate 跨表分页查询（见 Paginate），未指定 options 时使用仓储的执行选项
+func (r *Repository[T]) Paginate(page, pageSize int, queryBuilder QueryBuilder, options ...PaginateOptions) (*Page[T], error) {
+	if len(options) == 0 {
+		options = []PaginateOptions{{ShardQueryOptions: r.options}}
+	}
+	return Paginate[T](r.db, r.strategy, page, pageSize, queryBuilder, options...)
+}
+
+// shardKeyBuilder 在 queryBuilder 的条件之外限定分表键等于 shardingValue
+func (r *Repository[T]) shardKeyBuilder(shardingValue interface{}, queryBuilder QueryBuilder) (QueryBuilder, error) {
+	if _, err := r.shardKeyColumn(); err != nil {
+		return nil, err
+	}
+	return func(db *gorm.DB) *gorm.DB {
+		db, _ = r.whereShardKey(db, shardingValue)
+		if queryBuilder != nil {
+			db = queryBuilder(db)
+		}
+		return db
+	}, nil
+}
+
+// whereShardKey 添加分表键等于 shardingValue 的条件
+func (r *Repository[T]) whereShardKey(db *gorm.DB, shardingValue interface{}) (*gorm.DB, error) {
+	column, err := r.shardKeyColumn()
+	if err != nil {
+		return nil, err
+	}
+	return db.Where(clause.Eq{Column: clause.Column{Name: column}, Value: shardingValue}), nil
+}
+
+// shardKeyColumn 获取分表键对应的列名（分表键可以是字段名或列名）
+func (r *Repository[T]) shardKeyColumn() (string, error) {
+	r.keyOnce.Do(func() {
+		provider, ok := r.strategy.(ShardingKeyProvider)
+		if !ok {
+			r.keyErr = fmt.Errorf("strategy for %s does not expose its sharding key", r.strategy.GetBaseTableName())
+			return
+		}
+		key := provider.GetShardingKey()
+		stmt := &gorm.Statement{DB: r.db}
+		if err := stmt.Parse(new(T)); err != nil {
+			r.keyErr = fmt.Errorf("failed to parse model schema: %w", err)
+			return
+		}
+		r.keyColumn = key
+		if field := stmt.Schema.LookUpField(key); field != nil && field.DBName != "" {
+			r.keyColumn = field.DBName
+		}
+	})
+	return r.keyColumn, r.keyErr
+}
@@ -0,0 +1,451 @@
+package sharding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	// defaultReshardProgressTable 默认的重新分表进度表名
+	defaultReshardProgressTable = "sharding_reshard_progress"
+
+	// defaultReshardMaxIssues 一致性报告中默认保留的问题行数量
+	defaultReshardMaxIssues = 100
+)
+
+// reshardWriteKey 标记重新分表写入新布局的 context 键，自动路由不改写这些语句的分表
+type reshardWriteKey struct{}
+
+// withReshardWrite 标记 context 中的写入为重新分表的写入
+func withReshardWrite(ctx context.Context) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, reshardWriteKey{}, true)
+}
+
+// isReshardWrite 判断是否为重新分表的写入
+func isReshardWrite(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	value, _ := ctx.Value(reshardWriteKey{}).(bool)
+	return value
+}
+
+// ReshardOptions 重新分表配置
+type ReshardOptions struct {
+	ProgressTable string                                  // 复制进度表名（默认 sharding_reshard_progress）
+	BatchSize     int                                     // 每批从旧分表读取的行数（默认 1000）
+	Concurrency   int                                     // 每批并发写入的新分表数量（默认 1）
+	KeyColumn     string                                  // 唯一列（默认 "id"），用于分批复制和一致性检查时按行比较
+	Writer        BackfillOptions                         // 写入新分表使用的 BackfillWriter 选项（限速、复制延迟等）
+	SkipCheck     bool                                    // 复制完成后不生成一致性报告
+	MaxIssues     int                                     // 一致性报告中保留的问题行数量（默认 100，计数不受限制）
+	OnProgress    func(oldTable string, copiedRows int64) // 每批写入后调用（可选）
+}
+
+// ReshardResult 重新分表结果
+type ReshardResult struct {
+	Tables      []*BackfillResult         `json:"tables"`                // 每个旧分表的复制结果
+	Rows        int64                     `json:"rows"`                  // 复制的总行数
+	Consistency *ReshardConsistencyReport `json:"consistency,omitempty"` // 一致性报告（SkipCheck 时为空）
+}
+
+// ReshardRowIssue 新旧布局中不一致的一行
+type ReshardRowIssue struct {
+	Key      string   `json:"key"`               // KeyColumn 的值
+	OldTable string   `json:"old_table"`         // 旧布局中的分表
+	NewTable string   `json:"new_table"`         // 新布局中应在的分表
+	Columns  []string `json:"columns,omitempty"` // 值不同的列（新布局中缺少该行时为空）
+}
+
+// ReshardConsistencyReport 切换前新旧分表布局的一致性报告
+type ReshardConsistencyReport struct {
+	OldRows         int64             `json:"old_rows"`         // 旧布局的总行数
+	NewRows         int64             `json:"new_rows"`         // 新布局的总行数
+	MissingCount    int64             `json:"missing_count"`    // 新布局中缺少的行数
+	MismatchedCount int64             `json:"mismatched_count"` // 新旧布局中值不同的行数
+	Missing         []ReshardRowIssue `json:"missing"`          // 新布局中缺少的行（最多 MaxIssues 行）
+	Mismatched      []ReshardRowIssue `json:"mismatched"`       // 值不同的行（最多 MaxIssues 行）
+}
+
+// Consistent 新旧布局是否一致（可以切换到新布局）
+func (r *ReshardConsistencyReport) Consistent() bool {
+	return r.OldRows == r.NewRows && r.MissingCount == 0 && r.MismatchedCount == 0
+}
+
+// Reshard 在线重新分表（如 4 个 Hash 分表扩容到 8 个）：把旧布局每个分表中的数据按新策略复制到新布局的分表，
+// 复制过程与 BackfillFromTable 相同（分批、限速、可中断后继续，已存在的行不覆盖），完成后生成一致性报告
+// 新旧布局的分表名不能重复（新策略使用不同的基础表名或名称模板）；复制期间通过 EnableReshardDualWrite 双写新旧布局，
+// 一致性报告通过后再切换到新策略并调用 ReshardDualWrite.Disable
+func Reshard(ctx context.Context, db *gorm.DB, oldStrategy, newStrategy ShardingStrategy, model interface{}, options ...ReshardOptions) (*ReshardResult, error) {
+	opts := reshardOptions(options...)
+
+	oldTables, err := knownShardTables(db.WithContext(ctx), oldStrategy)
+	if err != nil {
+		return nil, err
+	}
+	newTables, err := knownShardTables(db.WithContext(ctx), newStrategy)
+	if err != nil {
+		return nil, err
+	}
+	oldSet := make(map[string]bool, len(oldTables))
+	for _, tableName := range oldTables {
+		oldSet[tableName] = true
+	}
+	for _, tableName := range newTables {
+		if oldSet[tableName] {
+			return nil, fmt.Errorf("old and new layouts share table %s, use a different base table name or name template", tableName)
+		}
+	}
+
+	// 写入新布局的分表时不按旧策略自动路由（模型的表名通常是旧布局的基础表名）
+	ctx = withReshardWrite(ctx)
+	result := &ReshardResult{}
+	for _, oldTable := range oldTables {
+		if !tableExists(db.WithContext(ctx), oldTable) {
+			continue
+		}
+		backfillOptions := BackfillTableOptions{
+			ProgressTable: opts.ProgressTable,
+			BatchSize:     opts.BatchSize,
+			Concurrency:   opts.Concurrency,
+			KeyColumn:     opts.KeyColumn,
+			SkipVerify:    true, // 新布局的分表包含多个旧分表的数据，复制完成后统一检查
+			Writer:        opts.Writer,
+		}
+		if opts.OnProgress != nil {
+			tableName := oldTable
+			backfillOptions.OnProgress = func(copiedRows int64) {
+				opts.OnProgress(tableName, copiedRows)
+			}
+		}
+		tableResult, err := BackfillFromTable(ctx, db, oldTable, newStrategy, model, backfillOptions)
+		if tableResult != nil {
+			result.Tables = append(result.Tables, tableResult)
+			result.Rows += tableResult.Rows
+		}
+		if err != nil {
+			return result, fmt.Errorf("failed to reshard table %s: %w", oldTable, err)
+		}
+	}
+
+	if !opts.SkipCheck {
+		report, err := CheckReshardConsistency(ctx, db, oldStrategy, newStrategy, model, opts)
+		if err != nil {
+			return result, err
+		}
+		result.Consistency = report
+	}
+	return result, nil
+}
+
+// CheckReshardConsistency 逐行比较旧布局中的每一行与其在新布局中应在分表中的同一行（按 KeyColumn），
+// 并比较两种布局的总行数，用于切换前确认新布局的数据完整
+func CheckReshardConsistency(ctx context.Context, db *gorm.DB, oldStrategy, newStrategy ShardingStrategy, model interface{}, options ...ReshardOptions) (*ReshardConsistencyReport, error) {
+	opts := reshardOptions(options...)
+	db = db.WithContext(ctx)
+
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, fmt.Errorf("failed to parse model: %w", err)
+	}
+	keyField := stmt.Schema.LookUpField(opts.KeyColumn)
+	if keyField == nil {
+		return nil, fmt.Errorf("model has no column %s, set ReshardOptions.KeyColumn", opts.KeyColumn)
+	}
+
+	report := &ReshardConsistencyReport{}
+	oldTables, err := knownShardTables(db, oldStrategy)
+	if err != nil {
+		return nil, err
+	}
+	for _, oldTable := range oldTables {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		if err := compareReshardTable(ctx, db, stmt.Schema, keyField, oldTable, newStrategy, opts, report); err != nil {
+			return report, err
+		}
+	}
+
+	newTables, err := knownShardTables(db, newStrategy)
+	if err != nil {
+		return nil, err
+	}
+	for _, newTable := range newTables {
+		var count int64
+		err := db.Table(newTable).Count(&count).Error
+		if isTableNotFoundError(err) {
+			continue
+		}
+		if err != nil {
+			return report, fmt.Errorf("failed to count table %s: %w", newTable, err)
+		}
+		report.NewRows += count
+	}
+	return report, nil
+}
+
+// compareReshardTable 分批读取旧分表，与新布局中对应分表的同一行比较
+func compareReshardTable(ctx context.Context, db *gorm.DB, modelSchema *schema.Schema, keyField *schema.Field, oldTable string, newStrategy ShardingStrategy, opts ReshardOptions, report *ReshardConsistencyReport) error {
+	sliceType := reflect.SliceOf(modelSchema.ModelType)
+	quotedKey := db.Statement.Quote(keyField.DBName)
+
+	var lastKey interface{}
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		rows := reflect.New(sliceType)
+		query := db.Table(oldTable).Order(quotedKey).Limit(opts.BatchSize)
+		if lastKey != nil {
+			query = query.Where(quotedKey+" > ?", lastKey)
+		}
+		err := query.Find(rows.Interface()).Error
+		if isTableNotFoundError(err) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read table %s: %w", oldTable, err)
+		}
+		batch := rows.Elem()
+		if batch.Len() == 0 {
+			return nil
+		}
+		report.OldRows += int64(batch.Len())
+		lastKey, _ = keyField.ValueOf(ctx, batch.Index(batch.Len()-1))
+
+		// 按新布局的分表分组，每个分表按 KeyColumn 批量读取
+		groups := make(map[string][]reflect.Value)
+		var order []string
+		for i := 0; i < batch.Len(); i++ {
+			row := batch.Index(i)
+			shardingValue, err := newStrategy.GetShardingValue(row.Addr().Interface())
+			if err != nil {
+				return fmt.Errorf("failed to get sharding value of row in table %s: %w", oldTable, err)
+			}
+			newTable, err := GetTableNameE(newStrategy, newStrategy.GetBaseTableName(), shardingValue)
+			if err != nil {
+				return err
+			}
+			if _, ok := groups[newTable]; !ok {
+				order = append(order, newTable)
+			}
+			groups[newTable] = append(groups[newTable], row)
+		}
+
+		for _, newTable := range order {
+			oldRows := groups[newTable]
+			keys := make([]interface{}, len(oldRows))
+			for i, row := range oldRows {
+				keys[i], _ = keyField.ValueOf(ctx, row)
+			}
+
+			newRows := reflect.New(sliceType)
+			err := db.Table(newTable).Where(quotedKey+" IN ?", keys).Find(newRows.Interface()).Error
+			if err != nil && !isTableNotFoundError(err) {
+				return fmt.Errorf("failed to read table %s: %w", newTable, err)
+			}
+			existing := make(map[string]reflect.Value, newRows.Elem().Len())
+			for i := 0; i < newRows.Elem().Len(); i++ {
+				row := newRows.Elem().Index(i)
+				key, _ := keyField.ValueOf(ctx, row)
+				existing[fmt.Sprintf("%v", key)] = row
+			}
+
+			for i, row := range oldRows {
+				key := fmt.Sprintf("%v", keys[i])
+				issue := ReshardRowIssue{Key: key, OldTable: oldTable, NewTable: newTable}
+				newRow, ok := existing[key]
+				if !ok {
+					report.MissingCount++
+					if len(report.Missing) < opts.MaxIssues {
+						report.Missing = append(report.Missing, issue)
+					}
+					continue
+				}
+				if columns := diffColumns(db, modelSchema, row.Addr().Interface(), newRow.Addr().Interface()); len(columns) > 0 {
+					report.MismatchedCount++
+					if len(report.Mismatched) < opts.MaxIssues {
+						issue.Columns = columns
+						report.Mismatched = append(report.Mismatched, issue)
+					}
+				}
+			}
+		}
+
+		if batch.Len() < opts.BatchSize {
+			return nil
+		}
+	}
+}
+
+// reshardOptions 获取重新分表配置并设置默认值
+func reshardOptions(options ...ReshardOptions) ReshardOptions {
+	opts := ReshardOptions{}
+	if len(options) > 0 {
+		opts = options[0]
+	}
+	if opts.ProgressTable == "" {
+		opts.ProgressTable = defaultReshardProgressTable
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultBackfillBatchSize
+	}
+	if opts.KeyColumn == "" {
+		opts.KeyColumn = defaultBackfillKeyColumn
+	}
+	if opts.MaxIssues <= 0 {
+		opts.MaxIssues = defaultReshardMaxIssues
+	}
+	return opts
+}
+
+// ReshardDualWriteStats 双写统计
+type ReshardDualWriteStats struct {
+	Mirrored int64 `json:"mirrored"` // 同步到新布局的写入
+	Skipped  int64 `json:"skipped"`  // 无法同步的写入（没有主键的批量更新或删除），需要依靠复制和一致性报告发现
+}
+
+// ReshardDualWrite 重新分表期间的双写窗口：通过 GORM 回调把写入旧布局的语句同步到新布局
+// 插入和按模型更新的行在同一个事务中写入新布局对应的分表（已存在时覆盖），按模型删除的行同时从新布局删除；
+// 需要使用 GORM 的默认事务（SkipDefaultTransaction 时两次写入不在同一个事务中）
+type ReshardDualWrite struct {
+	db       *gorm.DB
+	old      ShardingStrategy
+	new      ShardingStrategy
+	mirrored int64
+	skipped  int64
+}
+
+// EnableReshardDualWrite 开启从 oldStrategy 到 newStrategy 的双写，切换到新布局后调用 Disable
+func EnableReshardDualWrite(db *gorm.DB, oldStrategy, newStrategy ShardingStrategy) (*ReshardDualWrite, error) {
+	if oldStrategy == nil || newStrategy == nil {
+		return nil, fmt.Errorf("both old and new sharding strategies are required")
+	}
+	d := &ReshardDualWrite{db: db, old: oldStrategy, new: newStrategy}
+	name := d.callbackName
+
+	err := db.Callback().Create().After("gorm:create").Before("gorm:commit_or_rollback_transaction").
+		Register(name("create"), func(tx *gorm.DB) { d.mirror(tx, false) })
+	if err != nil {
+		return nil, err
+	}
+	err = db.Callback().Update().After("gorm:update").Before("gorm:commit_or_rollback_transaction").
+		Register(name("update"), func(tx *gorm.DB) { d.mirror(tx, false) })
+	if err != nil {
+		return nil, errors.Join(err, d.Disable())
+	}
+	err = db.Callback().Delete().After("gorm:delete").Before("gorm:commit_or_rollback_transaction").
+		Register(name("delete"), func(tx *gorm.DB) { d.mirror(tx, true) })
+	if err != nil {
+		return nil, errors.Join(err, d.Disable())
+	}
+	return d, nil
+}
+
+// Disable 关闭双写（移除回调）
+func (d *ReshardDualWrite) Disable() error {
+	return errors.Join(
+		d.db.Callback().Create().Remove(d.callbackName("create")),
+		d.db.Callback().Update().Remove(d.callbackName("update")),
+		d.db.Callback().Delete().Remove(d.callbackName("delete")),
+	)
+}
+
+// Stats 获取双写统计
+func (d *ReshardDualWrite) Stats() ReshardDualWriteStats {
+	return ReshardDualWriteStats{
+		Mirrored: atomic.LoadInt64(&d.mirrored),
+		Skipped:  atomic.LoadInt64(&d.skipped),
+	}
+}
+
+// callbackName 双写回调名称（包含旧布局的基础表名）
+func (d *ReshardDualWrite) callbackName(operation string) string {
+	return "sharding:reshard:" + operation + ":" + d.old.GetBaseTableName()
+}
+
+// mirror 把写入旧布局的行同步到新布局：插入和更新从旧分表读取写入后的行并覆盖到新分表，删除时从新分表删除
+func (d *ReshardDualWrite) mirror(db *gorm.DB, deleted bool) {
+	stmt := db.Statement
+	if db.Error != nil || stmt.Schema == nil || isReshardWrite(stmt.Context) ||
+		statementBaseTable(stmt) != d.old.GetBaseTableName() || len(stmt.Schema.PrimaryFields) == 0 {
+		return
+	}
+
+	var rows []reflect.Value
+	switch value := stmt.ReflectValue; value.Kind() {
+	case reflect.Struct:
+		rows = append(rows, value)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			rows = append(rows, reflect.Indirect(value.Index(i)))
+		}
+	}
+
+	ctx := withReshardWrite(stmt.Context)
+	tx := db.Session(&gorm.Session{NewDB: true, Context: ctx})
+	tx.Statement.ConnPool = stmt.ConnPool
+	for _, row := range rows {
+		if !hasPrimaryKey(ctx, stmt.Schema, row) {
+			// 没有主键的批量更新或删除无法确定受影响的行
+			atomic.AddInt64(&d.skipped, 1)
+			db.Logger.Warn(stmt.Context, "sharding: reshard dual write skipped a statement on %s without primary key", stmt.Table)
+			continue
+		}
+		if err := d.mirrorRow(tx, stmt, row, deleted); err != nil {
+			db.AddError(fmt.Errorf("failed to mirror write to new layout: %w", err))
+			return
+		}
+		atomic.AddInt64(&d.mirrored, 1)
+	}
+}
+
+// mirrorRow 同步一行到新布局对应的分表
+func (d *ReshardDualWrite) mirrorRow(tx *gorm.DB, stmt *gorm.Statement, row reflect.Value, deleted bool) error {
+	value := row.Addr().Interface()
+	shardingValue, err := d.new.GetShardingValue(value)
+	if err != nil {
+		return err
+	}
+	newTable, err := GetTableNameE(d.new, d.new.GetBaseTableName(), shardingValue)
+	if err != nil {
+		return err
+	}
+
+	if deleted {
+		return tx.Table(newTable).Delete(value).Error
+	}
+
+	// 按模型更新时只有部分字段，从旧分表读取写入后的完整行
+	current, err := loadByPrimaryKey(tx, stmt.Schema, stmt.Table, value)
+	if err != nil || current == nil {
+		return err
+	}
+	// 在独立的连接上建表，避免 DDL 隐式提交当前事务
+	if err := ensureShardTable(d.db.Session(&gorm.Session{NewDB: true, Context: tx.Statement.Context}), d.new, newTable, current); err != nil {
+		return err
+	}
+	return tx.Table(newTable).Clauses(clause.OnConflict{UpdateAll: true}).Create(current).Error
+}
+
+// hasPrimaryKey 判断行是否设置了主键
+func hasPrimaryKey(ctx context.Context, modelSchema *schema.Schema, row reflect.Value) bool {
+	for _, field := range modelSchema.PrimaryFields {
+		if _, zero := field.ValueOf(ctx, row); zero {
+			return false
+		}
+	}
+	return true
+}
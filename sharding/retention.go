@@ -0,0 +1,139 @@
+package sharding
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// defaultArchivePrefix 归档分表的默认前缀
+const defaultArchivePrefix = "archive_"
+
+// RetentionAction 过期分表的处理方式
+type RetentionAction int
+
+const (
+	RetentionDrop    RetentionAction = iota // 删除过期分表
+	RetentionArchive                        // 将过期分表重命名为带归档前缀的表（如 archive_logs_202301），不再参与查询
+)
+
+// RetentionPolicy 时间分表的保留策略
+type RetentionPolicy struct {
+	KeepPeriods   int             // 保留的时间单位数量（包括当前时间单位，如按月分表时 12 表示保留最近 12 个月）
+	Action        RetentionAction // 过期分表的处理方式
+	ArchivePrefix string          // 归档分表名的前缀（默认 "archive_"）
+}
+
+// RetentionResult 单个过期分表的处理结果
+type RetentionResult struct {
+	Table      string          `json:"table"`
+	Action     RetentionAction `json:"action"`
+	ArchivedAs string          `json:"archived_as,omitempty"` // 归档后的表名
+	Skipped    bool            `json:"skipped,omitempty"`     // 受保护的分表，未处理
+	Err        error           `json:"-"`
+}
+
+// SetRetentionPolicy 设置过期分表的保留策略（由 RunRetention 执行）
+func (s *TimeShardingStrategy) SetRetentionPolicy(policy RetentionPolicy) error {
+	if policy.KeepPeriods < 1 {
+		return fmt.Errorf("retention policy must keep at least 1 period")
+	}
+	if policy.Action != RetentionDrop && policy.Action != RetentionArchive {
+		return fmt.Errorf("unsupported retention action %d", policy.Action)
+	}
+	if policy.Action == RetentionArchive && policy.ArchivePrefix == "" {
+		policy.ArchivePrefix = defaultArchivePrefix
+	}
+	s.retention = &policy
+	return nil
+}
+
+// RunRetention 按保留策略处理过期的分表：从数据库中查找早于保留窗口的分表，删除或重命名为归档表
+// 受保护的分表跳过（Skipped）；某个分表失败后继续处理其余分表，失败的分表汇总为 ShardErrors
+func (s *TimeShardingStrategy) RunRetention(db *gorm.DB) ([]RetentionResult, error) {
+	if s.retention == nil {
+		return nil, fmt.Errorf("no retention policy for %s", s.baseTableName)
+	}
+	policy := *s.retention
+
+	tableNames, err := s.expiredTables(db, policy.KeepPeriods)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		results   []RetentionResult
+		shardErrs ShardErrors
+	)
+	for _, tableName := range tableNames {
+		result := RetentionResult{Table: tableName, Action: policy.Action}
+		if IsShardProtected(tableName) {
+			result.Skipped = true
+			results = append(results, result)
+			continue
+		}
+
+		switch policy.Action {
+		case RetentionArchive:
+			result.ArchivedAs = policy.ArchivePrefix + tableName
+			result.Err = archiveShardTable(db, tableName, result.ArchivedAs)
+		default:
+			result.Err = DropShardTable(db, tableName)
+		}
+		if result.Err != nil {
+			shardErrs = append(shardErrs, &ShardError{Table: tableName, Err: result.Err})
+		}
+		results = append(results, result)
+	}
+
+	if len(shardErrs) > 0 {
+		return results, shardErrs
+	}
+	return results, nil
+}
+
+// expiredTables 从数据库中查找早于保留窗口（包括当前时间单位在内的 keepPeriods 个时间单位）的分表
+func (s *TimeShardingStrategy) expiredTables(db *gorm.DB, keepPeriods int) ([]string, error) {
+	tableNames, err := knownShardTables(db, s)
+	if err != nil {
+		return nil, err
+	}
+	cutoff := s.addPeriods(s.periodStart(time.Now()), -(keepPeriods - 1))
+
+	var expired []string
+	for _, tableName := range tableNames {
+		if period, ok := s.tablePeriod(tableName); ok && period.Before(cutoff) {
+			expired = append(expired, tableName)
+		}
+	}
+	return expired, nil
+}
+
+// RetentionJob 返回执行保留策略的后台任务（可提交到 JobManager），依次处理每个策略并报告进度
+func RetentionJob(db *gorm.DB, strategies ...*TimeShardingStrategy) JobFunc {
+	return func(ctx context.Context, job *Job) error {
+		for i, strategy := range strategies {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if _, err := strategy.RunRetention(db.WithContext(ctx)); err != nil {
+				return fmt.Errorf("retention of %s: %w", strategy.GetBaseTableName(), err)
+			}
+			job.SetProgress(float64(i+1) / float64(len(strategies)))
+		}
+		return nil
+	}
+}
+
+// archiveShardTable 将分表重命名为归档表（受保护的分表返回 ErrShardProtected）
+func archiveShardTable(db *gorm.DB, tableName, archiveName string) error {
+	if err := checkShardNotProtected("archive", tableName); err != nil {
+		return err
+	}
+	defer forgetAutoCreatedTable(db, tableName)
+	return runDDL(db, "ArchiveShardTable", tableName, func(tx *gorm.DB) error {
+		return tx.Migrator().RenameTable(tableName, archiveName)
+	})
+}
@@ -0,0 +1,149 @@
+package sharding
+
+import (
+	"context"
+	"sort"
+)
+
+// RoutingHint 路由提示，强制跨表操作和自动路由只使用指定的分表（用于调试、定向修复、按分表重放流量）
+// 通过 context 传递：db.WithContext(WithRoutingHint(ctx, ForceShards(0, 3)))
+type RoutingHint struct {
+	tables []string // 强制使用的分表名称
+	shards []int    // 强制使用的分表序号
+}
+
+// ForceTable 强制使用指定名称的分表
+// 跨表操作只在这些分表中执行（操作原本的分表中不包含其中任何一个时不受影响，如同一个请求中对其他基础表的操作）；
+// 自动路由时，属于该策略的第一个分表替代按分表键计算的分表
+func ForceTable(tableNames ...string) RoutingHint {
+	return RoutingHint{tables: tableNames}
+}
+
+// ForceShards 强制使用指定序号的分表（从 0 开始，如 users_0、users_3）
+// 跨表操作只在操作原本的分表列表中对应序号的分表中执行（时间分表为时间范围内的第几个分表）；
+// 自动路由时，第一个序号对应的分表替代按分表键计算的分表（时间分表不支持）
+func ForceShards(indexes ...int) RoutingHint {
+	return RoutingHint{shards: indexes}
+}
+
+// routingHintKey 路由提示在 context 中的键
+type routingHintKey struct{}
+
+// WithRoutingHint 为 context 中的操作指定路由提示
+func WithRoutingHint(ctx context.Context, hint RoutingHint) context.Context {
+	return context.WithValue(ctx, routingHintKey{}, hint)
+}
+
+// getRoutingHint 获取 context 中的路由提示
+func getRoutingHint(ctx context.Context) (RoutingHint, bool) {
+	if ctx == nil {
+		return RoutingHint{}, false
+	}
+	hint, ok := ctx.Value(routingHintKey{}).(RoutingHint)
+	return hint, ok && (len(hint.tables) > 0 || len(hint.shards) > 0)
+}
+
+// selectShards 按 context 中的路由提示筛选要执行的分表，返回分表在 tableNames 中的序号
+func selectShards(ctx context.Context, tableNames []string) []int {
+	indexes := make([]int, 0, len(tableNames))
+	hint, ok := getRoutingHint(ctx)
+	if !ok {
+		for i := range tableNames {
+			indexes = append(indexes, i)
+		}
+		return indexes
+	}
+
+	if len(hint.tables) > 0 {
+		forced := make(map[string]bool, len(hint.tables))
+		for _, tableName := range hint.tables {
+			forced[tableName] = true
+		}
+		for i, tableName := range tableNames {
+			if forced[tableName] {
+				indexes = append(indexes, i)
+			}
+		}
+		if len(indexes) == 0 {
+			// 提示针对的是其他基础表
+			for i := range tableNames {
+				indexes = append(indexes, i)
+			}
+		}
+		return indexes
+	}
+
+	seen := make(map[int]bool, len(hint.shards))
+	for _, index := range hint.shards {
+		if index >= 0 && index < len(tableNames) && !seen[index] {
+			seen[index] = true
+			indexes = append(indexes, index)
+		}
+	}
+	sort.Ints(indexes)
+	return indexes
+}
+
+// hintedTables 按 context 中的路由提示筛选分表（不需要保持序号的操作使用，如 UNION ALL 查询）
+func hintedTables(ctx context.Context, tableNames []string) []string {
+	indexes := selectShards(ctx, tableNames)
+	if len(indexes) == len(tableNames) {
+		return tableNames
+	}
+	selected := make([]string, len(indexes))
+	for i, index := range indexes {
+		selected[i] = tableNames[index]
+	}
+	return selected
+}
+
+// hintedCombinations 按 context 中的 ForceTable 提示筛选多表连接的表组合（只保留包含指定分表的组合），
+// ForceShards 不适用于多表连接
+func hintedCombinations(ctx context.Context, combinations [][]string) [][]string {
+	hint, ok := getRoutingHint(ctx)
+	if !ok || len(hint.tables) == 0 {
+		return combinations
+	}
+	forced := make(map[string]bool, len(hint.tables))
+	for _, tableName := range hint.tables {
+		forced[tableName] = true
+	}
+	selected := make([][]string, 0, len(combinations))
+	for _, combination := range combinations {
+		for _, tableName := range combination {
+			if forced[tableName] {
+				selected = append(selected, combination)
+				break
+			}
+		}
+	}
+	if len(selected) == 0 {
+		// 提示针对的是其他基础表
+		return combinations
+	}
+	return selected
+}
+
+// forcedTable 获取路由提示为策略指定的分表（自动路由使用）
+func forcedTable(ctx context.Context, strategy ShardingStrategy) (string, bool) {
+	hint, ok := getRoutingHint(ctx)
+	if !ok {
+		return "", false
+	}
+	for _, tableName := range hint.tables {
+		if isKnownShardTable(strategy, tableName) {
+			return tableName, true
+		}
+	}
+	if len(hint.tables) > 0 || len(hint.shards) == 0 {
+		return "", false
+	}
+	if _, ok := strategy.(timeRangeSharding); ok {
+		return "", false
+	}
+	tableNames := strategy.GetAllTableNames(strategy.GetBaseTableName())
+	if index := hint.shards[0]; index >= 0 && index < len(tableNames) {
+		return tableNames[index], true
+	}
+	return "", false
+}
@@ -0,0 +1,142 @@
+package sharding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// ErrCompensationFailed Saga 的补偿操作失败，已执行的步骤没有全部撤销，需要人工处理
+var ErrCompensationFailed = errors.New("sharding: saga compensation failed")
+
+// SagaFunc Saga 步骤的操作或补偿，tx 为分表所在数据库上的本地事务（已通过 Table() 指定分表）
+type SagaFunc func(tx *gorm.DB) error
+
+// SagaOptions Saga 配置
+type SagaOptions struct {
+	Cluster           *ShardCluster // 多数据源（可选），指定后每个步骤在分表所在的数据库上执行
+	CompensationRetry RetryPolicy   // 补偿操作的重试策略（补偿应当是幂等的）
+}
+
+// sagaStep Saga 中的一个步骤
+type sagaStep struct {
+	name          string
+	strategy      ShardingStrategy
+	shardingValue interface{}
+	action        SagaFunc
+	compensate    SagaFunc
+	tableName     string // 执行时解析的分表名称
+}
+
+// SagaError Saga 执行失败的详细信息
+// 可通过 errors.Is(err, ErrCompensationFailed) 判断是否有步骤没有撤销
+type SagaError struct {
+	Step            string   // 失败的步骤
+	Err             error    // 步骤的错误
+	Compensated     []string // 已补偿的步骤（按补偿顺序）
+	CompensationErr error    // 补偿失败的错误（所有步骤都已撤销时为 nil）
+}
+
+func (e *SagaError) Error() string {
+	if e.CompensationErr != nil {
+		return fmt.Sprintf("saga step %s failed: %v; %v", e.Step, e.Err, e.CompensationErr)
+	}
+	return fmt.Sprintf("saga step %s failed: %v", e.Step, e.Err)
+}
+
+func (e *SagaError) Unwrap() []error {
+	if e.CompensationErr != nil {
+		return []error{e.Err, e.CompensationErr}
+	}
+	return []error{e.Err}
+}
+
+// Saga 跨分表业务操作的补偿事务（XA 之外的应用层方案）
+// 每个步骤根据策略和分表键值定位到一个分表，在该分表所在的数据库上用本地事务执行操作；
+// 某个步骤失败时，按相反顺序执行已完成步骤的补偿操作，返回 *SagaError：
+//
+//	err := NewSaga(db).
+//		Step("create_order", ordersStrategy, userID, createOrder, deleteOrder).
+//		Step("create_payment", paymentsStrategy, userID, createPayment, cancelPayment).
+//		Step("deduct_balance", usersStrategy, userID, deductBalance, nil).
+//		Execute()
+//
+// Saga 不提供隔离性：其他操作可以看到已执行、尚未补偿的步骤的数据；补偿操作应当是幂等的
+type Saga struct {
+	db      *gorm.DB
+	options SagaOptions
+	steps   []*sagaStep
+}
+
+// NewSaga 创建 Saga，db 提供 context（以及未指定 Cluster 时执行步骤的连接）
+func NewSaga(db *gorm.DB, options ...SagaOptions) *Saga {
+	opts := SagaOptions{}
+	if len(options) > 0 {
+		opts = options[0]
+	}
+	return &Saga{db: db, options: opts}
+}
+
+// Step 添加步骤，compensate 为 nil 表示该步骤不需要补偿（如最后一个步骤或只读步骤）
+func (s *Saga) Step(name string, strategy ShardingStrategy, shardingValue interface{}, action, compensate SagaFunc) *Saga {
+	s.steps = append(s.steps, &sagaStep{
+		name:          name,
+		strategy:      strategy,
+		shardingValue: shardingValue,
+		action:        action,
+		compensate:    compensate,
+	})
+	return s
+}
+
+// Execute 按顺序执行所有步骤，失败时按相反顺序补偿已完成的步骤
+func (s *Saga) Execute() error {
+	// 先解析所有步骤的分表，避免执行到一半才发现分表键值无效
+	for _, step := range s.steps {
+		tableName, err := GetTableNameE(step.strategy, step.strategy.GetBaseTableName(), step.shardingValue)
+		if err != nil {
+			return fmt.Errorf("saga step %s: %w", step.name, err)
+		}
+		step.tableName = tableName
+	}
+
+	ctx := s.db.Statement.Context
+	for i, step := range s.steps {
+		err := s.run(ctx, step, step.action, ShardQueryOptions{})
+		if err == nil {
+			continue
+		}
+
+		sagaErr := &SagaError{Step: step.name, Err: err}
+		// 调用方取消也要完成补偿
+		compensateCtx := context.WithoutCancel(ctx)
+		var errs []error
+		for j := i - 1; j >= 0; j-- {
+			done := s.steps[j]
+			if done.compensate == nil {
+				continue
+			}
+			if err := s.run(compensateCtx, done, done.compensate, ShardQueryOptions{RetryPolicy: s.options.CompensationRetry}); err != nil {
+				errs = append(errs, fmt.Errorf("%w: step %s: %w", ErrCompensationFailed, done.name, err))
+				continue
+			}
+			sagaErr.Compensated = append(sagaErr.Compensated, done.name)
+		}
+		sagaErr.CompensationErr = errors.Join(errs...)
+		return sagaErr
+	}
+	return nil
+}
+
+// run 在步骤的分表上用本地事务执行 fn
+func (s *Saga) run(ctx context.Context, step *sagaStep, fn SagaFunc, options ShardQueryOptions) error {
+	options.Cluster = s.options.Cluster
+	options.requireTables = true
+	return runShardWithRetry(ctx, s.db, 0, step.tableName, options, func(tx *gorm.DB, _ int, tableName string) error {
+		return tx.Transaction(func(tx *gorm.DB) error {
+			return fn(tx.Table(tableName).Session(&gorm.Session{}))
+		})
+	})
+}
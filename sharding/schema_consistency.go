@@ -0,0 +1,236 @@
+package sharding
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// SchemaIssue 分表结构不一致的一项
+type SchemaIssue struct {
+	Table    string `json:"table"`              // 不一致的分表
+	Kind     string `json:"kind"`               // 类型：missing_table、missing_column、extra_column、column_type、missing_index、extra_index、index_definition、charset
+	Object   string `json:"object,omitempty"`   // 列名或索引名
+	Expected string `json:"expected,omitempty"` // 大多数分表的定义
+	Actual   string `json:"actual,omitempty"`   // 该分表的定义
+}
+
+// SchemaConsistencyReport 分表结构一致性检查报告
+type SchemaConsistencyReport struct {
+	Tables []string      `json:"tables"` // 检查的分表
+	Issues []SchemaIssue `json:"issues"` // 不一致的项（按分表顺序排列）
+}
+
+// Consistent 是否所有分表的结构一致
+func (r *SchemaConsistencyReport) Consistent() bool {
+	return len(r.Issues) == 0
+}
+
+// shardTableDefinition 从 information_schema 读取的分表定义
+type shardTableDefinition struct {
+	collation string
+	columns   map[string]string // 列名（小写） -> 列类型、是否可空、字符集和排序规则
+	indexes   map[string]string // 索引名（小写） -> 是否唯一及索引列
+}
+
+// CheckSchemaConsistency 读取 information_schema 中每个分表的列、索引和字符集，以大多数分表的定义为准报告不一致的分表
+// 适用于长期手动 ALTER 后检查分表是否一致（与模型比较使用 SchemaDiff）；目前只支持 MySQL
+func CheckSchemaConsistency(db *gorm.DB, strategy ShardingStrategy) (*SchemaConsistencyReport, error) {
+	if dialect := db.Dialector.Name(); dialect != "mysql" {
+		return nil, fmt.Errorf("schema consistency check is not supported for dialect %s", dialect)
+	}
+
+	tableNames, err := knownShardTables(db, strategy)
+	if err != nil {
+		return nil, err
+	}
+	definitions, err := loadShardTableDefinitions(db, tableNames)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &SchemaConsistencyReport{Tables: tableNames}
+	var existing []string
+	for _, tableName := range tableNames {
+		if _, ok := definitions[tableName]; ok {
+			existing = append(existing, tableName)
+		} else {
+			report.Issues = append(report.Issues, SchemaIssue{Table: tableName, Kind: "missing_table"})
+		}
+	}
+	if len(existing) < 2 {
+		return report, nil
+	}
+
+	expectedCollation := majorityValue(existing, func(tableName string) (string, bool) {
+		return definitions[tableName].collation, true
+	})
+	for _, tableName := range existing {
+		if collation := definitions[tableName].collation; collation != expectedCollation {
+			report.Issues = append(report.Issues, SchemaIssue{Table: tableName, Kind: "charset", Expected: expectedCollation, Actual: collation})
+		}
+	}
+
+	report.Issues = append(report.Issues, compareShardObjects(existing, func(tableName string) map[string]string {
+		return definitions[tableName].columns
+	}, "missing_column", "extra_column", "column_type")...)
+	report.Issues = append(report.Issues, compareShardObjects(existing, func(tableName string) map[string]string {
+		return definitions[tableName].indexes
+	}, "missing_index", "extra_index", "index_definition")...)
+
+	position := make(map[string]int, len(tableNames))
+	for i, tableName := range tableNames {
+		position[tableName] = i
+	}
+	sort.SliceStable(report.Issues, func(i, j int) bool {
+		return position[report.Issues[i].Table] < position[report.Issues[j].Table]
+	})
+	return report, nil
+}
+
+// compareShardObjects 比较各分表的列或索引：至少一半的分表中存在的对象视为应该存在，定义以大多数分表为准
+func compareShardObjects(tableNames []string, objectsOf func(tableName string) map[string]string, missingKind, extraKind, mismatchKind string) []SchemaIssue {
+	counts := make(map[string]int)
+	var names []string
+	for _, tableName := range tableNames {
+		for name := range objectsOf(tableName) {
+			if counts[name] == 0 {
+				names = append(names, name)
+			}
+			counts[name]++
+		}
+	}
+	sort.Strings(names)
+
+	var issues []SchemaIssue
+	for _, name := range names {
+		expected := 2*counts[name] >= len(tableNames)
+		definition := majorityValue(tableNames, func(tableName string) (string, bool) {
+			value, ok := objectsOf(tableName)[name]
+			return value, ok
+		})
+		for _, tableName := range tableNames {
+			actual, ok := objectsOf(tableName)[name]
+			switch {
+			case expected && !ok:
+				issues = append(issues, SchemaIssue{Table: tableName, Kind: missingKind, Object: name, Expected: definition})
+			case !expected && ok:
+				issues = append(issues, SchemaIssue{Table: tableName, Kind: extraKind, Object: name, Actual: actual})
+			case expected && actual != definition:
+				issues = append(issues, SchemaIssue{Table: tableName, Kind: mismatchKind, Object: name, Expected: definition, Actual: actual})
+			}
+		}
+	}
+	return issues
+}
+
+// majorityValue 获取出现次数最多的值（次数相同时取分表顺序中最先出现的值）
+func majorityValue(tableNames []string, valueOf func(tableName string) (string, bool)) string {
+	counts := make(map[string]int)
+	var best string
+	for _, tableName := range tableNames {
+		value, ok := valueOf(tableName)
+		if !ok {
+			continue
+		}
+		counts[value]++
+		if counts[value] > counts[best] || counts[best] == 0 {
+			best = value
+		}
+	}
+	return best
+}
+
+// loadShardTableDefinitions 从 information_schema 批量读取分表的排序规则、列和索引（不存在的分表不在结果中）
+func loadShardTableDefinitions(db *gorm.DB, tableNames []string) (map[string]*shardTableDefinition, error) {
+	definitions := make(map[string]*shardTableDefinition, len(tableNames))
+	if len(tableNames) == 0 {
+		return definitions, nil
+	}
+
+	var tables []struct {
+		TableName      string
+		TableCollation *string
+	}
+	err := db.Raw("SELECT table_name AS table_name, table_collation AS table_collation FROM information_schema.tables "+
+		"WHERE table_schema = DATABASE() AND table_name IN ?", tableNames).Scan(&tables).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to read information_schema.tables: %w", err)
+	}
+	for _, table := range tables {
+		definition := &shardTableDefinition{columns: make(map[string]string), indexes: make(map[string]string)}
+		if table.TableCollation != nil {
+			definition.collation = *table.TableCollation
+		}
+		definitions[table.TableName] = definition
+	}
+
+	var columns []struct {
+		TableName        string
+		ColumnName       string
+		ColumnType       string
+		IsNullable       string
+		CharacterSetName *string
+		CollationName    *string
+	}
+	err = db.Raw("SELECT table_name AS table_name, column_name AS column_name, column_type AS column_type, "+
+		"is_nullable AS is_nullable, character_set_name AS character_set_name, collation_name AS collation_name "+
+		"FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name IN ?", tableNames).Scan(&columns).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to read information_schema.columns: %w", err)
+	}
+	for _, column := range columns {
+		definition, ok := definitions[column.TableName]
+		if !ok {
+			continue
+		}
+		parts := []string{column.ColumnType}
+		if column.IsNullable == "NO" {
+			parts = append(parts, "NOT NULL")
+		}
+		if column.CharacterSetName != nil {
+			parts = append(parts, "CHARACTER SET "+*column.CharacterSetName)
+		}
+		if column.CollationName != nil {
+			parts = append(parts, "COLLATE "+*column.CollationName)
+		}
+		definition.columns[strings.ToLower(column.ColumnName)] = strings.Join(parts, " ")
+	}
+
+	var indexColumns []struct {
+		TableName  string
+		IndexName  string
+		NonUnique  int
+		ColumnName *string
+	}
+	err = db.Raw("SELECT table_name AS table_name, index_name AS index_name, non_unique AS non_unique, column_name AS column_name "+
+		"FROM information_schema.statistics WHERE table_schema = DATABASE() AND table_name IN ? "+
+		"ORDER BY table_name, index_name, seq_in_index", tableNames).Scan(&indexColumns).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to read information_schema.statistics: %w", err)
+	}
+	for _, indexColumn := range indexColumns {
+		definition, ok := definitions[indexColumn.TableName]
+		if !ok {
+			continue
+		}
+		// GORM 生成的索引名包含分表名（如 idx_orders_0_name），替换为占位符后再比较
+		name := strings.ToLower(strings.ReplaceAll(indexColumn.IndexName, indexColumn.TableName, shardTablePlaceholder))
+		column := "(expression)"
+		if indexColumn.ColumnName != nil {
+			column = *indexColumn.ColumnName
+		}
+		if existing, ok := definition.indexes[name]; ok {
+			definition.indexes[name] = strings.TrimSuffix(existing, ")") + ", " + column + ")"
+			continue
+		}
+		kind := "INDEX"
+		if indexColumn.NonUnique == 0 {
+			kind = "UNIQUE"
+		}
+		definition.indexes[name] = kind + " (" + column + ")"
+	}
+	return definitions, nil
+}
@@ -0,0 +1,183 @@
+package sharding
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// TableSchemaDiff 单个分表与模型的结构差异
+type TableSchemaDiff struct {
+	Table          string   `json:"table"`
+	MissingTable   bool     `json:"missing_table,omitempty"`   // 分表不存在
+	MissingColumns []string `json:"missing_columns,omitempty"` // 模型中有、分表中没有的列
+	ExtraColumns   []string `json:"extra_columns,omitempty"`   // 分表中有、模型中没有的列
+	MissingIndexes []string `json:"missing_indexes,omitempty"` // 模型中定义、分表中没有的索引
+	ExtraIndexes   []string `json:"extra_indexes,omitempty"`   // 分表中有、模型中没有定义的索引（不包括主键）
+}
+
+// HasDrift 是否存在差异
+func (d TableSchemaDiff) HasDrift() bool {
+	return d.MissingTable || len(d.MissingColumns) > 0 || len(d.ExtraColumns) > 0 ||
+		len(d.MissingIndexes) > 0 || len(d.ExtraIndexes) > 0
+}
+
+// SchemaDiff 比较策略的所有分表与模型的列和索引，返回存在差异的分表（没有差异时返回空）
+// 模型经过多次修改后，对 N 个分表执行 AutoMigrate 可能留下索引不一致的分表（AutoMigrate 不会删除列和索引，中途失败时部分分表未迁移）
+// 固定分表数量的策略检查所有分表（不存在的分表标记为 MissingTable），时间分表检查数据库中已存在的分表
+func SchemaDiff(db *gorm.DB, strategy ShardingStrategy, model interface{}) ([]TableSchemaDiff, error) {
+	tableNames, err := knownShardTables(db, strategy)
+	if err != nil {
+		return nil, err
+	}
+
+	var diffs []TableSchemaDiff
+	for _, tableName := range tableNames {
+		// 按分表名解析模型：GORM 生成的索引名包含表名（如 idx_orders_0_name）
+		stmt := &gorm.Statement{DB: db, Table: tableName}
+		if err := stmt.ParseWithSpecialTableName(model, tableName); err != nil {
+			return nil, fmt.Errorf("failed to parse model: %w", err)
+		}
+		diff, err := diffTableSchema(db, tableName, stmt.Schema)
+		if err != nil {
+			return nil, fmt.Errorf("failed to inspect table %s: %w", tableName, err)
+		}
+		if diff.HasDrift() {
+			diffs = append(diffs, diff)
+		}
+	}
+	return diffs, nil
+}
+
+// knownShardTables 获取策略的所有分表（时间分表为数据库中已存在的分表）
+func knownShardTables(db *gorm.DB, strategy ShardingStrategy) ([]string, error) {
+	if _, ok := strategy.(*TimeShardingStrategy); !ok {
+		return strategy.GetAllTableNames(strategy.GetBaseTableName()), nil
+	}
+	return DiscoverExistingTables(db, strategy)
+}
+
+// shardTablesInRange 获取策略的所有分表，时间分表指定 timeRange 时为该时间范围内的分表
+func shardTablesInRange(db *gorm.DB, strategy ShardingStrategy, timeRange *AutoMigrateTimeRange) ([]string, error) {
+	if timeStrategy, ok := strategy.(*TimeShardingStrategy); ok && timeRange != nil {
+		return timeStrategy.GetAllTableNamesInRange(strategy.GetBaseTableName(), timeRange.StartTime, timeRange.EndTime), nil
+	}
+	return knownShardTables(db, strategy)
+}
+
+// diffTableSchema 比较单个分表的列和索引（列名、索引名不区分大小写）
+func diffTableSchema(db *gorm.DB, tableName string, modelSchema *schema.Schema) (TableSchemaDiff, error) {
+	diff := TableSchemaDiff{Table: tableName}
+	migrator := db.Migrator()
+	if !migrator.HasTable(tableName) {
+		diff.MissingTable = true
+		return diff, nil
+	}
+
+	columnTypes, err := migrator.ColumnTypes(tableName)
+	if err != nil {
+		return diff, err
+	}
+	expectedColumns := make(map[string]bool, len(modelSchema.DBNames))
+	for _, column := range modelSchema.DBNames {
+		expectedColumns[strings.ToLower(column)] = true
+	}
+	actualColumns := make(map[string]bool, len(columnTypes))
+	for _, columnType := range columnTypes {
+		name := columnType.Name()
+		actualColumns[strings.ToLower(name)] = true
+		if !expectedColumns[strings.ToLower(name)] {
+			diff.ExtraColumns = append(diff.ExtraColumns, name)
+		}
+	}
+	for _, column := range modelSchema.DBNames {
+		if !actualColumns[strings.ToLower(column)] {
+			diff.MissingColumns = append(diff.MissingColumns, column)
+		}
+	}
+
+	actualIndexes, err := migrator.GetIndexes(tableName)
+	if err != nil {
+		return diff, err
+	}
+	expectedIndexes := modelSchema.ParseIndexes()
+	expected := make(map[string]bool, len(expectedIndexes))
+	for _, index := range expectedIndexes {
+		expected[strings.ToLower(index.Name)] = true
+	}
+	existing := make(map[string]bool, len(actualIndexes))
+	for _, index := range actualIndexes {
+		existing[strings.ToLower(index.Name())] = true
+		if primary, ok := index.PrimaryKey(); ok && primary || strings.EqualFold(index.Name(), "PRIMARY") {
+			continue
+		}
+		if !expected[strings.ToLower(index.Name())] {
+			diff.ExtraIndexes = append(diff.ExtraIndexes, index.Name())
+		}
+	}
+	for _, index := range expectedIndexes {
+		if !existing[strings.ToLower(index.Name)] {
+			diff.MissingIndexes = append(diff.MissingIndexes, index.Name)
+		}
+	}
+	return diff, nil
+}
+
+// ReconcileOptions 修复分表结构差异的选项
+type ReconcileOptions struct {
+	DropExtraColumns bool // 删除模型中没有的列（默认只报告，不删除数据）
+	DropExtraIndexes bool // 删除模型中没有定义的索引
+	CheckPrivileges  bool // 修复前检查 CREATE/ALTER 权限，缺少权限时直接返回错误
+}
+
+// AutoMigrateWithReconcile 比较所有分表与模型的结构（SchemaDiff），并修复存在差异的分表：
+// 缺少的分表、列和索引通过 AutoMigrate 补齐，多余的列和索引按选项删除；返回修复前的差异
+func AutoMigrateWithReconcile(db *gorm.DB, strategy ShardingStrategy, model interface{}, options ...ReconcileOptions) ([]TableSchemaDiff, error) {
+	opts := ReconcileOptions{}
+	if len(options) > 0 {
+		opts = options[0]
+	}
+
+	// 预先检查权限，避免修复到一半才失败
+	if opts.CheckPrivileges {
+		if err := CheckPrivileges(db, PrivilegeCreate, PrivilegeAlter); err != nil {
+			return nil, err
+		}
+	}
+
+	diffs, err := SchemaDiff(db, strategy, model)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, diff := range diffs {
+		if diff.MissingTable || len(diff.MissingColumns) > 0 || len(diff.MissingIndexes) > 0 {
+			if err := migrateTable(db, strategy, diff.Table, model, false); err != nil {
+				return diffs, fmt.Errorf("failed to migrate table %s: %w", diff.Table, err)
+			}
+		}
+		if opts.DropExtraIndexes {
+			for _, index := range diff.ExtraIndexes {
+				err := runDDL(db, "DropIndex", diff.Table, func(tx *gorm.DB) error {
+					return tx.Migrator().DropIndex(diff.Table, index)
+				})
+				if err != nil {
+					return diffs, fmt.Errorf("failed to drop index %s on table %s: %w", index, diff.Table, err)
+				}
+			}
+		}
+		if opts.DropExtraColumns {
+			for _, column := range diff.ExtraColumns {
+				err := runDDL(db, "DropColumn", diff.Table, func(tx *gorm.DB) error {
+					return tx.Migrator().DropColumn(diff.Table, column)
+				})
+				if err != nil {
+					return diffs, fmt.Errorf("failed to drop column %s on table %s: %w", column, diff.Table, err)
+				}
+			}
+		}
+	}
+	return diffs, nil
+}
@@ -0,0 +1,205 @@
+package sharding
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// ShardClusterOptions 多数据源配置
+type ShardClusterOptions struct {
+	Default string                        // 未分配数据源的分表使用的数据源名称（为空时返回 ErrShardDatabaseNotFound）
+	Locator func(tableName string) string // 根据分表名称计算数据源名称（可选，优先级低于 AssignTables 的显式分配）
+}
+
+// ShardCluster 多数据源注册表，保存数据源名称到连接的映射以及分表所在的数据源
+// 在 ShardQueryOptions.Cluster 中指定后，跨表查询、计数、写入等操作在每个分表所在的数据库上执行，
+// 可以把分表分布到多个物理数据库上
+type ShardCluster struct {
+	mu        sync.RWMutex
+	databases map[string]*gorm.DB // 数据源名称 -> 连接
+	tables    map[string]string   // 分表名称 -> 数据源名称
+	excluded  map[string]bool     // 健康检查失败、暂时从跨表操作中排除的数据源
+	options   ShardClusterOptions
+}
+
+// NewShardCluster 创建多数据源注册表
+// databases: 数据源名称 -> 连接（如 {"ds0": db0, "ds1": db1}）
+func NewShardCluster(databases map[string]*gorm.DB, options ...ShardClusterOptions) *ShardCluster {
+	opts := ShardClusterOptions{}
+	if len(options) > 0 {
+		opts = options[0]
+	}
+	cluster := &ShardCluster{
+		databases: make(map[string]*gorm.DB, len(databases)),
+		tables:    make(map[string]string),
+		excluded:  make(map[string]bool),
+		options:   opts,
+	}
+	for name, db := range databases {
+		cluster.databases[name] = db
+	}
+	return cluster
+}
+
+// OpenShardCluster 根据多个 DSN 打开数据源（数据库不存在时自动创建），任意一个失败时关闭已打开的连接并返回错误
+// dsns: 数据源名称 -> MySQL DSN
+func OpenShardCluster(dsns map[string]string, config *gorm.Config, options ...ShardClusterOptions) (*ShardCluster, error) {
+	cluster := NewShardCluster(nil, options...)
+	for name, dsn := range dsns {
+		db, err := OpenMySQLWithAutoCreateDB(dsn, config)
+		if err != nil {
+			cluster.Close()
+			return nil, fmt.Errorf("failed to open shard database %s: %w", name, err)
+		}
+		cluster.AddDatabase(name, db)
+	}
+	return cluster, nil
+}
+
+// AddDatabase 添加（或替换）数据源
+func (c *ShardCluster) AddDatabase(name string, db *gorm.DB) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.databases[name] = db
+}
+
+// AssignTables 将分表分配到数据源
+func (c *ShardCluster) AssignTables(name string, tableNames ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, tableName := range tableNames {
+		c.tables[tableName] = name
+	}
+}
+
+// DistributeStrategy 将策略的所有分表按顺序轮流分配到数据源（第 i 个分表分配到 names[i % len(names)]）
+// 如 4 个分表、2 个数据源时 users_0、users_2 在 names[0]，users_1、users_3 在 names[1]
+func (c *ShardCluster) DistributeStrategy(strategy ShardingStrategy, names ...string) error {
+	if len(names) == 0 {
+		return fmt.Errorf("no shard databases to distribute %s to", strategy.GetBaseTableName())
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, name := range names {
+		if _, ok := c.databases[name]; !ok {
+			return fmt.Errorf("%w: %s", ErrShardDatabaseNotFound, name)
+		}
+	}
+	for i, tableName := range strategy.GetAllTableNames(strategy.GetBaseTableName()) {
+		c.tables[tableName] = names[i%len(names)]
+	}
+	return nil
+}
+
+// DatabaseName 获取分表所在的数据源名称（显式分配优先，其次是 Locator，最后是 Default）
+func (c *ShardCluster) DatabaseName(tableName string) (string, bool) {
+	c.mu.RLock()
+	name, ok := c.tables[tableName]
+	c.mu.RUnlock()
+	if ok {
+		return name, true
+	}
+	if c.options.Locator != nil {
+		if name := c.options.Locator(tableName); name != "" {
+			return name, true
+		}
+	}
+	if c.options.Default != "" {
+		return c.options.Default, true
+	}
+	return "", false
+}
+
+// Database 获取数据源的连接
+func (c *ShardCluster) Database(name string) (*gorm.DB, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	db, ok := c.databases[name]
+	return db, ok
+}
+
+// DBForTable 获取分表所在数据库的连接，数据源被健康检查排除时返回 ErrShardUnavailable
+func (c *ShardCluster) DBForTable(tableName string) (*gorm.DB, error) {
+	name, ok := c.DatabaseName(tableName)
+	if !ok {
+		return nil, fmt.Errorf("%w: no shard database assigned to table %s", ErrShardDatabaseNotFound, tableName)
+	}
+	c.mu.RLock()
+	db, ok := c.databases[name]
+	excluded := c.excluded[name]
+	c.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s (table %s)", ErrShardDatabaseNotFound, name, tableName)
+	}
+	if excluded {
+		return nil, fmt.Errorf("%w: %s (table %s)", ErrShardUnavailable, name, tableName)
+	}
+	return db, nil
+}
+
+// DBFor 获取分表键值对应的分表所在数据库的连接，已通过 Table() 指定分表名称，可以直接执行读写
+//
+//	db, err := cluster.DBFor(strategy, 1001)
+//	if err == nil {
+//		err = db.Where("user_id = ?", 1001).Find(&users).Error
+//	}
+func (c *ShardCluster) DBFor(strategy ShardingStrategy, shardingValue interface{}) (*gorm.DB, error) {
+	tableName, err := GetTableNameE(strategy, strategy.GetBaseTableName(), shardingValue)
+	if err != nil {
+		return nil, err
+	}
+	db, err := c.DBForTable(tableName)
+	if err != nil {
+		return nil, err
+	}
+	return db.Table(tableName), nil
+}
+
+// Databases 获取所有数据源名称（按名称排序）
+func (c *ShardCluster) Databases() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	names := make([]string, 0, len(c.databases))
+	for name := range c.databases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// assignedTables 获取通过 AssignTables 或 DistributeStrategy 分配了数据源的分表（按名称排序）
+func (c *ShardCluster) assignedTables() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	tableNames := make([]string, 0, len(c.tables))
+	for tableName := range c.tables {
+		tableNames = append(tableNames, tableName)
+	}
+	sort.Strings(tableNames)
+	return tableNames
+}
+
+// Close 关闭所有数据源的连接
+func (c *ShardCluster) Close() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var errs []error
+	for name, db := range c.databases {
+		if err := closeConnectionPool(db); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close shard database %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// shardDB 获取在分表上执行操作使用的连接（未指定 Cluster 时使用 db）
+func shardDB(db *gorm.DB, cluster *ShardCluster, tableName string) (*gorm.DB, error) {
+	if cluster == nil {
+		return db, nil
+	}
+	return cluster.DBForTable(tableName)
+}
@@ -0,0 +1,122 @@
+package sharding
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// shardTablePlaceholder DDL 模板中的分表名占位符
+const shardTablePlaceholder = "{table}"
+
+// ShardDDLOptions 在所有分表上执行 DDL 的选项
+type ShardDDLOptions struct {
+	TimeRange   *AutoMigrateTimeRange // 时间分表的时间范围（为空时为数据库中已存在的所有分表）
+	Concurrency int                   // 并发执行的分表数量（默认 1）
+}
+
+// ShardDDLResult 单个分表的 DDL 执行结果
+type ShardDDLResult struct {
+	Table   string `json:"table"`
+	Skipped bool   `json:"skipped,omitempty"` // 索引或列已存在，跳过
+	Err     error  `json:"-"`                 // 执行失败的错误
+}
+
+// ExecOnAllShards 在策略的所有分表上执行 DDL（如 "ALTER TABLE {table} ADD COLUMN remark VARCHAR(255)"）
+// 模板中的 {table} 替换为引用后的分表名；索引或列已存在的错误视为成功（Skipped），
+// 某个分表失败后继续执行其余分表，返回每个分表的结果，失败的分表汇总为 ShardErrors
+func ExecOnAllShards(db *gorm.DB, strategy ShardingStrategy, sqlTemplate string, options ...ShardDDLOptions) ([]ShardDDLResult, error) {
+	if !strings.Contains(sqlTemplate, shardTablePlaceholder) {
+		return nil, fmt.Errorf("sql template must contain %s", shardTablePlaceholder)
+	}
+	return execOnAllShards(db, strategy, "ExecOnAllShards", func(tx *gorm.DB, tableName string) string {
+		return strings.ReplaceAll(sqlTemplate, shardTablePlaceholder, tx.Statement.Quote(tableName))
+	}, options...)
+}
+
+// AddIndexToAllShards 在策略的所有分表上创建索引（已存在的索引跳过）
+// PostgreSQL 的索引名在 schema 内唯一，indexName 中可以使用 {table}（如 "idx_{table}_user_id"）
+func AddIndexToAllShards(db *gorm.DB, strategy ShardingStrategy, indexName string, columns ...string) ([]ShardDDLResult, error) {
+	if indexName == "" || len(columns) == 0 {
+		return nil, fmt.Errorf("index name and columns are required")
+	}
+	return execOnAllShards(db, strategy, "AddIndexToAllShards", func(tx *gorm.DB, tableName string) string {
+		quoted := make([]string, len(columns))
+		for i, column := range columns {
+			quoted[i] = tx.Statement.Quote(column)
+		}
+		name := strings.ReplaceAll(indexName, shardTablePlaceholder, tableName)
+		return fmt.Sprintf("CREATE INDEX %s ON %s (%s)", tx.Statement.Quote(name), tx.Statement.Quote(tableName), strings.Join(quoted, ", "))
+	})
+}
+
+// AlterColumnOnAllShards 在策略的所有分表上修改列的定义（如 "VARCHAR(512) NOT NULL"）
+// MySQL 使用 MODIFY COLUMN（definition 为完整的列定义），PostgreSQL 使用 ALTER COLUMN ... TYPE（definition 为类型）
+func AlterColumnOnAllShards(db *gorm.DB, strategy ShardingStrategy, column, definition string) ([]ShardDDLResult, error) {
+	if column == "" || definition == "" {
+		return nil, fmt.Errorf("column and definition are required")
+	}
+	return execOnAllShards(db, strategy, "AlterColumnOnAllShards", func(tx *gorm.DB, tableName string) string {
+		if tx.Dialector.Name() == "postgres" {
+			return fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE %s", tx.Statement.Quote(tableName), tx.Statement.Quote(column), definition)
+		}
+		return fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s %s", tx.Statement.Quote(tableName), tx.Statement.Quote(column), definition)
+	})
+}
+
+// execOnAllShards 对策略的每个分表生成并执行 DDL（通过 runDDL 记录审计），返回按分表顺序排列的结果
+func execOnAllShards(db *gorm.DB, strategy ShardingStrategy, operation string, buildSQL func(tx *gorm.DB, tableName string) string, options ...ShardDDLOptions) ([]ShardDDLResult, error) {
+	opts := ShardDDLOptions{}
+	if len(options) > 0 {
+		opts = options[0]
+	}
+	workerCount := opts.Concurrency
+	if workerCount < 1 {
+		workerCount = 1
+	}
+
+	tableNames, err := shardTablesInRange(db, strategy, opts.TimeRange)
+	if err != nil {
+		return nil, err
+	}
+
+	// 分发前创建新的会话，worker 之间不共用调用方的 Statement
+	db = db.Session(&gorm.Session{})
+	results := make([]ShardDDLResult, len(tableNames))
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, workerCount)
+	for i, tableName := range tableNames {
+		semaphore <- struct{}{}
+		wg.Add(1)
+		go func(i int, tableName string) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+
+			err := runDDL(db, operation, tableName, func(tx *gorm.DB) error {
+				return tx.Exec(buildSQL(tx, tableName)).Error
+			})
+			results[i] = ShardDDLResult{Table: tableName}
+			if isDuplicateObjectError(err) {
+				results[i].Skipped = true
+			} else {
+				results[i].Err = err
+			}
+		}(i, tableName)
+	}
+	wg.Wait()
+
+	var shardErrs ShardErrors
+	for _, result := range results {
+		if result.Err != nil {
+			shardErrs = append(shardErrs, &ShardError{Table: result.Table, Err: result.Err})
+		}
+	}
+	if len(shardErrs) > 0 {
+		return results, shardErrs
+	}
+	return results, nil
+}
@@ -0,0 +1,234 @@
+package sharding
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RetryPolicy 分表查询失败时的重试策略
+type RetryPolicy struct {
+	MaxAttempts int                  // 每个分表最多尝试的次数（默认 1，即不重试）
+	Backoff     time.Duration        // 第一次重试前的等待时间，之后每次翻倍（默认不等待）
+	MaxBackoff  time.Duration        // 重试等待时间的上限（0 表示不限制）
+	Retryable   func(err error) bool // 判断错误是否可以重试（默认除列不存在外的错误都重试）
+}
+
+// ShardQueryOptions 跨表操作在各分表上的执行选项
+// 多表连接查询（CrossTableMultiJoin 系列）以表组合为单位执行，未设置 Parallelism 时使用 MultiJoinConfig.Concurrency
+type ShardQueryOptions struct {
+	Parallelism     int             // 同时执行的分表数量（默认 1，按顺序逐个执行）
+	PerShardTimeout time.Duration   // 每个分表单次执行的超时时间（0 表示不限制）
+	RetryPolicy     RetryPolicy     // 重试策略
+	ContinueOnError bool            // 某个分表失败时继续执行其他分表，返回成功分表的结果和 ShardErrors
+	Cluster         *ShardCluster   // 多数据源（可选），指定后每个分表在其所在的数据库上执行
+	CircuitBreaker  *CircuitBreaker // 熔断器（可选），连续失败的分表在冷却时间内被跳过
+
+	requireTables bool // 分表不存在时返回错误而不是跳过（写入操作使用）
+	hinted        bool // 分表已经按路由提示筛选过，不再应用 ForceTable / ForceShards（多表连接的表组合使用）
+}
+
+// getShardQueryOptions 获取分表执行选项（未指定时使用默认值）
+func getShardQueryOptions(options []ShardQueryOptions) ShardQueryOptions {
+	if len(options) > 0 {
+		return options[0]
+	}
+	return ShardQueryOptions{}
+}
+
+// errStopShards 由 shardTask 返回，表示已经得到结果，不需要再执行剩余的分表
+var errStopShards = errors.New("stop shard iteration")
+
+// shardTask 在单个分表上执行的操作，tx 已设置该次执行的 context
+// 返回分表不存在的错误时该分表被跳过（requireTables 除外）；同一个 index 可能因为重试被执行多次
+type shardTask func(tx *gorm.DB, index int, tableName string) error
+
+// runOnShards 按选项在每个分表上执行 task（并发、超时、重试），不存在的分表被跳过
+// 未设置 ContinueOnError 时，第一个失败的分表会取消其他分表的执行并返回该错误
+func runOnShards(db *gorm.DB, tableNames []string, options ShardQueryOptions, task shardTask) error {
+	parentCtx := db.Statement.Context
+	if parentCtx == nil {
+		parentCtx = context.Background()
+	}
+	ctx, cancel := context.WithCancel(parentCtx)
+	defer cancel()
+	// 分发前创建新的会话：调用方传入的可能是链式调用中的实例（Statement 会被原地修改），
+	// 每个分表的每次执行再基于它创建各自的会话（WithContext），worker 之间不共用 Statement
+	db = db.Session(&gorm.Session{})
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+		errs     = make([]*ShardError, len(tableNames))
+		failed   bool
+	)
+	// record 记录分表的执行结果，返回是否应停止执行剩余分表
+	record := func(index int, err error) bool {
+		if err == nil {
+			return false
+		}
+		mu.Lock()
+		defer mu.Unlock()
+
+		if errors.Is(err, errStopShards) {
+			cancel()
+			return true
+		}
+		if ctx.Err() != nil && parentCtx.Err() == nil {
+			// 其他分表已经失败或提前结束，由取消引起的错误不再记录
+			return true
+		}
+		// 健康检查排除的数据源上的分表和熔断中的分表不执行，按部分结果返回，不影响其他分表
+		if (options.ContinueOnError && !errors.Is(err, ErrResultTooLarge)) || errors.Is(err, ErrShardUnavailable) || errors.Is(err, ErrCircuitOpen) {
+			errs[index] = &ShardError{Table: tableNames[index], Err: err}
+			failed = true
+			return false
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+		cancel()
+		return true
+	}
+
+	// 路由提示（ForceTable / ForceShards）只执行指定的分表，序号保持不变
+	indexes := selectShards(parentCtx, tableNames)
+	if options.hinted {
+		indexes = indexes[:0]
+		for i := range tableNames {
+			indexes = append(indexes, i)
+		}
+	}
+	shardMetrics().ObserveFanOut(len(indexes))
+
+	parallelism := options.Parallelism
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	if parallelism > len(indexes) {
+		parallelism = len(indexes)
+	}
+
+	if parallelism <= 1 {
+		for _, i := range indexes {
+			if ctx.Err() != nil {
+				break
+			}
+			if record(i, runShard(ctx, db, i, tableNames[i], options, task)) {
+				break
+			}
+		}
+	} else {
+		indexCh := make(chan int)
+		var wg sync.WaitGroup
+		for w := 0; w < parallelism; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range indexCh {
+					record(i, runShard(ctx, db, i, tableNames[i], options, task))
+				}
+			}()
+		}
+	dispatch:
+		for _, i := range indexes {
+			select {
+			case indexCh <- i:
+			case <-ctx.Done():
+				break dispatch
+			}
+		}
+		close(indexCh)
+		wg.Wait()
+	}
+
+	if firstErr != nil {
+		return firstErr
+	}
+	if failed {
+		// 按分表顺序返回失败的分表
+		shardErrs := make(ShardErrors, 0, len(errs))
+		for _, shardErr := range errs {
+			if shardErr != nil {
+				shardErrs = append(shardErrs, shardErr)
+			}
+		}
+		return shardErrs
+	}
+	if err := parentCtx.Err(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// runShard 在单个分表上执行 task，指定了熔断器时在熔断器的保护下执行
+func runShard(ctx context.Context, db *gorm.DB, index int, tableName string, options ShardQueryOptions, task shardTask) error {
+	return options.CircuitBreaker.Do(tableName, func() error {
+		return runShardWithRetry(ctx, db, index, tableName, options, task)
+	})
+}
+
+// runShardWithRetry 在单个分表上执行 task，按 PerShardTimeout 设置超时并按 RetryPolicy 重试
+func runShardWithRetry(ctx context.Context, db *gorm.DB, index int, tableName string, options ShardQueryOptions, task shardTask) error {
+	policy := options.RetryPolicy
+	maxAttempts := policy.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	retryable := policy.Retryable
+	if retryable == nil {
+		retryable = isRetryableShardError
+	}
+
+	target, err := shardDB(db, options.Cluster, tableName)
+	if err != nil {
+		return err
+	}
+
+	backoff := policy.Backoff
+	for attempt := 1; ; attempt++ {
+		attemptCtx, cancel := ctx, context.CancelFunc(func() {})
+		if options.PerShardTimeout > 0 {
+			attemptCtx, cancel = context.WithTimeout(ctx, options.PerShardTimeout)
+		}
+		start := time.Now()
+		err := task(target.WithContext(attemptCtx), index, tableName)
+		cancel()
+
+		if err == nil || errors.Is(err, errStopShards) {
+			shardMetrics().ObserveShardQuery(tableName, time.Since(start), nil)
+			return err
+		}
+		if !options.requireTables && isTableNotFoundError(err) {
+			// 如果表不存在，跳过（某些分表可能尚未创建）
+			shardMetrics().IncSkippedMissingTable(tableName)
+			return nil
+		}
+		shardMetrics().ObserveShardQuery(tableName, time.Since(start), err)
+		if attempt >= maxAttempts || ctx.Err() != nil || errors.Is(err, ErrResultTooLarge) || !retryable(err) {
+			return err
+		}
+
+		if backoff > 0 {
+			timer := time.NewTimer(backoff)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return err
+			}
+			backoff *= 2
+			if policy.MaxBackoff > 0 && backoff > policy.MaxBackoff {
+				backoff = policy.MaxBackoff
+			}
+		}
+	}
+}
+
+// isRetryableShardError 默认的可重试判断：列不存在等语句错误重试也不会成功
+func isRetryableShardError(err error) bool {
+	return !isColumnNotFoundError(err) && !errors.Is(err, context.Canceled)
+}
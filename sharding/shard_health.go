@@ -0,0 +1,218 @@
+package sharding
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// defaultHealthCheckInterval 默认的健康检查间隔
+	defaultHealthCheckInterval = 10 * time.Second
+
+	// defaultHealthCheckTimeout 默认的单次 Ping 超时
+	defaultHealthCheckTimeout = 2 * time.Second
+)
+
+// ShardStatus 数据源的健康状态
+type ShardStatus struct {
+	Name      string        // 数据源名称
+	Healthy   bool          // Ping 是否成功
+	Latency   time.Duration // Ping 耗时
+	Err       error         // Ping 失败的错误
+	CheckedAt time.Time     // 检查时间
+}
+
+// PingAllShards 并发 Ping 所有数据源，返回各数据源的状态（按名称排序），不改变数据源是否被排除
+func (c *ShardCluster) PingAllShards(ctx context.Context) []ShardStatus {
+	names := c.Databases()
+	statuses := make([]ShardStatus, len(names))
+
+	var wg sync.WaitGroup
+	for i, name := range names {
+		db, _ := c.Database(name)
+		wg.Add(1)
+		go func(i int, name string, db *gorm.DB) {
+			defer wg.Done()
+			statuses[i] = pingShard(ctx, name, db)
+		}(i, name, db)
+	}
+	wg.Wait()
+	return statuses
+}
+
+// IsAvailable 判断数据源是否参与跨表操作（未被健康检查排除）
+func (c *ShardCluster) IsAvailable(name string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return !c.excluded[name]
+}
+
+// setAvailable 设置数据源是否参与跨表操作，返回状态是否发生变化
+func (c *ShardCluster) setAvailable(name string, available bool) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.excluded[name] == !available {
+		return false
+	}
+	if available {
+		delete(c.excluded, name)
+	} else {
+		c.excluded[name] = true
+	}
+	return true
+}
+
+// pingShard Ping 单个数据源
+func pingShard(ctx context.Context, name string, db *gorm.DB) ShardStatus {
+	status := ShardStatus{Name: name, CheckedAt: time.Now()}
+	sqlDB, err := db.DB()
+	if err == nil {
+		err = sqlDB.PingContext(ctx)
+	}
+	status.Latency = time.Since(status.CheckedAt)
+	status.Healthy = err == nil
+	status.Err = err
+	return status
+}
+
+// HealthCheckOptions 数据源健康检查配置
+type HealthCheckOptions struct {
+	Interval         time.Duration     // 检查间隔（默认 10 秒）
+	Timeout          time.Duration     // 单次 Ping 超时（默认 2 秒）
+	FailureThreshold int               // 连续失败多少次后排除数据源（默认 1），一次成功即恢复
+	OnChange         func(ShardStatus) // 数据源被排除或恢复时调用（可选）
+}
+
+// ShardHealthChecker 数据源健康检查器
+// 在后台定期 Ping ShardCluster 的所有数据源，连续失败的数据源暂时从跨表操作中排除：
+// 跨表查询跳过这些数据源上的分表，返回其他分表的结果和 ShardErrors（部分结果，错误包装 ErrShardUnavailable），
+// 而不是每个查询都等待超时后失败；数据源恢复后自动重新加入
+type ShardHealthChecker struct {
+	cluster *ShardCluster
+	options HealthCheckOptions
+
+	mu       sync.RWMutex
+	failures map[string]int         // 连续失败次数
+	statuses map[string]ShardStatus // 最近一次检查的状态
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	wg       sync.WaitGroup // 后台检查 goroutine
+}
+
+// NewShardHealthChecker 创建数据源健康检查器
+func NewShardHealthChecker(cluster *ShardCluster, options ...HealthCheckOptions) *ShardHealthChecker {
+	opts := HealthCheckOptions{}
+	if len(options) > 0 {
+		opts = options[0]
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = defaultHealthCheckInterval
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultHealthCheckTimeout
+	}
+	if opts.FailureThreshold < 1 {
+		opts.FailureThreshold = 1
+	}
+	return &ShardHealthChecker{
+		cluster:  cluster,
+		options:  opts,
+		failures: make(map[string]int),
+		statuses: make(map[string]ShardStatus),
+		stop:     make(chan struct{}),
+	}
+}
+
+// Start 立即检查一次，然后在后台按 Interval 定期检查
+func (h *ShardHealthChecker) Start() {
+	h.Refresh(context.Background())
+
+	// 停止时取消正在执行的检查
+	ctx, cancel := context.WithCancel(context.Background())
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		defer cancel()
+
+		ticker := time.NewTicker(h.options.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				h.Refresh(ctx)
+			case <-h.stop:
+				return
+			}
+		}
+	}()
+	go func() {
+		<-h.stop
+		cancel()
+	}()
+}
+
+// Stop 停止后台检查（已排除的数据源保持排除状态，可以调用 Refresh 更新）
+func (h *ShardHealthChecker) Stop() {
+	h.stopOnce.Do(func() {
+		close(h.stop)
+	})
+}
+
+// Shutdown 停止后台检查并等待正在执行的检查结束（ctx 结束时不再等待）
+func (h *ShardHealthChecker) Shutdown(ctx context.Context) error {
+	h.Stop()
+
+	done := make(chan struct{})
+	go func() {
+		h.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Refresh 立即检查所有数据源，更新数据源的排除状态
+func (h *ShardHealthChecker) Refresh(ctx context.Context) []ShardStatus {
+	ctx, cancel := context.WithTimeout(ctx, h.options.Timeout)
+	defer cancel()
+	statuses := h.cluster.PingAllShards(ctx)
+
+	for _, status := range statuses {
+		h.mu.Lock()
+		h.statuses[status.Name] = status
+		if status.Healthy {
+			h.failures[status.Name] = 0
+		} else {
+			h.failures[status.Name]++
+		}
+		failures := h.failures[status.Name]
+		h.mu.Unlock()
+
+		available := status.Healthy || failures < h.options.FailureThreshold
+		if h.cluster.setAvailable(status.Name, available) && h.options.OnChange != nil {
+			h.options.OnChange(status)
+		}
+	}
+	return statuses
+}
+
+// Statuses 获取最近一次检查的各数据源状态（按名称排序）
+func (h *ShardHealthChecker) Statuses() []ShardStatus {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	statuses := make([]ShardStatus, 0, len(h.statuses))
+	for _, name := range h.cluster.Databases() {
+		if status, ok := h.statuses[name]; ok {
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses
+}
@@ -0,0 +1,110 @@
+package sharding
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// ShardTableOptions 单个分表的建表选项
+type ShardTableOptions struct {
+	Charset      string // 字符集（可选）
+	Collation    string // 排序规则（可选）
+	TableOptions string // 其他表选项（可选，如 "ROW_FORMAT=COMPRESSED TABLESPACE archive_ts"），原样追加到建表语句
+}
+
+// ShardOptionsProvider 提供分表级别建表选项的策略（可选接口）
+// 自动创建分表的 DDL 辅助函数会在创建对应分表时使用这些选项
+type ShardOptionsProvider interface {
+	// GetShardOptions 获取分表的建表选项，没有配置时返回 false
+	GetShardOptions(tableName string) (ShardTableOptions, bool)
+}
+
+// ShardOptionsMap 分表建表选项映射，内置策略通过嵌入该类型支持按分表配置建表选项
+type ShardOptionsMap struct {
+	mu       sync.RWMutex
+	patterns []string                     // 按添加顺序保存的表名或通配符
+	options  map[string]ShardTableOptions // 表名或通配符 -> 建表选项
+}
+
+// SetShardOptions 设置分表的建表选项，pattern 支持通配符（path.Match 语法，如 "orders_archive_*"）
+// 多个通配符都匹配时，精确表名优先，其次是最后添加的通配符
+func (m *ShardOptionsMap) SetShardOptions(pattern string, options ShardTableOptions) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.options == nil {
+		m.options = make(map[string]ShardTableOptions)
+	}
+	if _, ok := m.options[pattern]; !ok {
+		m.patterns = append(m.patterns, pattern)
+	}
+	m.options[pattern] = options
+}
+
+// GetShardOptions 获取分表的建表选项（ShardOptionsProvider 接口）
+func (m *ShardOptionsMap) GetShardOptions(tableName string) (ShardTableOptions, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if options, ok := m.options[tableName]; ok {
+		return options, true
+	}
+	for i := len(m.patterns) - 1; i >= 0; i-- {
+		if matched, err := path.Match(m.patterns[i], tableName); err == nil && matched {
+			return m.options[m.patterns[i]], true
+		}
+	}
+	return ShardTableOptions{}, false
+}
+
+// getShardTableOptions 获取策略为分表配置的建表选项
+func getShardTableOptions(strategy ShardingStrategy, tableName string) (ShardTableOptions, bool) {
+	if strategy == nil {
+		return ShardTableOptions{}, false
+	}
+	provider, ok := strategy.(ShardOptionsProvider)
+	if !ok {
+		return ShardTableOptions{}, false
+	}
+	return provider.GetShardOptions(tableName)
+}
+
+// buildShardTableOptions 构建追加到建表语句末尾的表选项（如 "DEFAULT CHARSET=utf8mb4 ROW_FORMAT=COMPRESSED"）
+func buildShardTableOptions(options ShardTableOptions) (string, error) {
+	charsetOptions, err := buildCharsetTableOptions(CharsetOptions{Charset: options.Charset, Collation: options.Collation})
+	if err != nil {
+		return "", err
+	}
+	if strings.Contains(options.TableOptions, ";") {
+		return "", fmt.Errorf("invalid table options: %q", options.TableOptions)
+	}
+
+	parts := make([]string, 0, 2)
+	if charsetOptions != "" {
+		parts = append(parts, charsetOptions)
+	}
+	if tableOptions := strings.TrimSpace(options.TableOptions); tableOptions != "" {
+		parts = append(parts, tableOptions)
+	}
+	return strings.Join(parts, " "), nil
+}
+
+// withShardTableOptions 为 AutoMigrate 设置分表的建表选项（GORM 的 gorm:table_options）
+func withShardTableOptions(db *gorm.DB, strategy ShardingStrategy, tableName string) (*gorm.DB, error) {
+	options, ok := getShardTableOptions(strategy, tableName)
+	if !ok {
+		return db, nil
+	}
+	tableOptions, err := buildShardTableOptions(options)
+	if err != nil {
+		return nil, fmt.Errorf("invalid shard options for table %s: %w", tableName, err)
+	}
+	if tableOptions == "" {
+		return db, nil
+	}
+	return db.Set("gorm:table_options", tableOptions), nil
+}
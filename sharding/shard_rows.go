@@ -0,0 +1,188 @@
+package sharding
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// ShardRows 跨分表的流式结果集，用法与 *sql.Rows 类似
+// 按分表顺序逐个打开查询（先读完第一个分表，再读第二个……），同一时刻只持有一个分表的结果集，
+// 适合处理无法一次性加载到内存的大量数据
+type ShardRows struct {
+	db            *gorm.DB
+	baseTableName string
+	queryBuilder  QueryBuilder
+	tableNames    []string
+	options       ShardQueryOptions
+
+	index     int                // 下一个要打开的分表下标
+	table     string             // 当前分表
+	rows      *sql.Rows          // 当前分表的结果集
+	cancel    context.CancelFunc // 取消当前分表的读取（PerShardTimeout）
+	shardErrs ShardErrors        // ContinueOnError 时被跳过的分表
+	err       error
+	closed    bool
+}
+
+// CrossTableRows 跨表流式查询，返回逐行读取所有分表结果的 *ShardRows
+// 使用完毕后必须调用 Close 释放连接
+// options: 分表执行选项（可选），每个分表与 runOnShards 相同地按 Cluster 路由、在熔断器保护下打开并按 RetryPolicy 重试；
+// 分表总是逐个读取（忽略 Parallelism），PerShardTimeout 限制读取单个分表的总时间；
+// 设置 ContinueOnError 时打开失败的分表被跳过，读取结束后 Err 返回 ShardErrors
+func CrossTableRows(db *gorm.DB, strategy ShardingStrategy, queryBuilder QueryBuilder, options ...ShardQueryOptions) (*ShardRows, error) {
+	tableNames, err := allShardTables(db, strategy)
+	if err != nil {
+		return nil, err
+	}
+	tableNames = hintedTables(db.Statement.Context, tableNames)
+	if len(tableNames) == 0 {
+		return nil, fmt.Errorf("no tables found")
+	}
+	shardMetrics().ObserveFanOut(len(tableNames))
+
+	return &ShardRows{
+		db:            db,
+		baseTableName: strategy.GetBaseTableName(),
+		queryBuilder:  queryBuilder,
+		tableNames:    tableNames,
+		options:       getShardQueryOptions(options),
+	}, nil
+}
+
+// Next 移动到下一行，当前分表读完后自动打开下一个分表，没有更多数据或出错时返回 false
+func (r *ShardRows) Next() bool {
+	if r.closed || r.err != nil {
+		return false
+	}
+
+	for {
+		if r.rows != nil {
+			if r.rows.Next() {
+				return true
+			}
+			err := r.rows.Err()
+			r.closeShard()
+			if err != nil {
+				r.err = fmt.Errorf("failed to read table %s: %w", r.table, err)
+				return false
+			}
+		}
+
+		if r.index >= len(r.tableNames) {
+			if len(r.shardErrs) > 0 {
+				r.err = r.shardErrs
+			}
+			return false
+		}
+		if err := r.openNext(); err != nil {
+			r.err = err
+			return false
+		}
+	}
+}
+
+// openNext 通过 runShard 打开下一个分表的结果集（不存在的分表直接跳过）
+func (r *ShardRows) openNext() error {
+	index, tableName := r.index, r.tableNames[r.index]
+	r.index++
+
+	// 超时作用于整个分表的读取，不能在打开结果集后就取消
+	ctx := r.db.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	cancel := context.CancelFunc(func() {})
+	if r.options.PerShardTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, r.options.PerShardTimeout)
+	}
+	options := r.options
+	options.PerShardTimeout = 0
+
+	var rows *sql.Rows
+	err := runShard(ctx, r.db, index, tableName, options, func(tx *gorm.DB, _ int, tableName string) error {
+		query := tx.Table(tableName)
+		if r.queryBuilder != nil {
+			query = r.queryBuilder(query)
+		}
+
+		var err error
+		rows, err = query.Rows()
+		return err
+	})
+	if err != nil || rows == nil {
+		cancel()
+		if err == nil {
+			return nil // 分表不存在
+		}
+		// 与 runOnShards 相同，不可用和熔断中的分表总是按部分结果跳过
+		if r.options.ContinueOnError || errors.Is(err, ErrShardUnavailable) || errors.Is(err, ErrCircuitOpen) {
+			r.shardErrs = append(r.shardErrs, &ShardError{Table: tableName, Err: err})
+			return nil
+		}
+		return fmt.Errorf("failed to query table %s: %w", tableName, err)
+	}
+
+	r.table = tableName
+	r.rows = rows
+	r.cancel = cancel
+	return nil
+}
+
+// closeShard 关闭当前分表的结果集
+func (r *ShardRows) closeShard() error {
+	err := r.rows.Close()
+	r.rows = nil
+	r.cancel()
+	r.cancel = nil
+	return err
+}
+
+// Scan 将当前行扫描到 dest（结构体指针或 map，与 gorm.DB.ScanRows 相同）
+func (r *ShardRows) Scan(dest interface{}) error {
+	if r.rows == nil {
+		return fmt.Errorf("scan called before Next")
+	}
+	if err := r.db.ScanRows(r.rows, dest); err != nil {
+		return err
+	}
+	// ScanRows 不经过查询回调，启用了字段加密时在这里解密
+	if encryption := getFieldEncryption(r.db); encryption != nil {
+		return encryption.DecryptFields(r.db, r.baseTableName, dest)
+	}
+	return nil
+}
+
+// Columns 获取当前分表结果集的列名
+func (r *ShardRows) Columns() ([]string, error) {
+	if r.rows == nil {
+		return nil, fmt.Errorf("columns called before Next")
+	}
+	return r.rows.Columns()
+}
+
+// Table 获取当前行所在的分表
+func (r *ShardRows) Table() string {
+	return r.table
+}
+
+// Err 获取迭代过程中遇到的错误
+func (r *ShardRows) Err() error {
+	return r.err
+}
+
+// Close 关闭结果集，可以重复调用
+func (r *ShardRows) Close() error {
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+
+	if r.rows != nil {
+		return r.closeShard()
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package sharding
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SampleShards 按比例确定性地抽取分表（相同的分表列表、比例和种子总是得到相同的结果）
+// 每个分表按 (seed, 表名) 的哈希值排序后取前 ceil(fraction*n) 个，因此同一个种子下比例越大抽到的分表越多且包含比例较小时的分表；
+// fraction 取值 (0, 1]，至少抽取一个分表，返回的分表保持策略中的顺序
+func SampleShards(strategy ShardingStrategy, fraction float64, seed int64) []string {
+	tableNames := getTableNamesWithTimeRange(strategy, strategy.GetBaseTableName(), nil)
+	if len(tableNames) == 0 {
+		return nil
+	}
+	if fraction >= 1 {
+		return tableNames
+	}
+
+	count := int(math.Ceil(fraction * float64(len(tableNames))))
+	if count < 1 {
+		count = 1
+	}
+
+	ranks := make(map[string]uint64, len(tableNames))
+	ordered := append([]string(nil), tableNames...)
+	for _, tableName := range ordered {
+		sum := sha256.Sum256([]byte(fmt.Sprintf("%d|%s", seed, tableName)))
+		ranks[tableName] = binary.BigEndian.Uint64(sum[:8])
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ranks[ordered[i]] < ranks[ordered[j]]
+	})
+
+	selected := make(map[string]bool, count)
+	for _, tableName := range ordered[:count] {
+		selected[tableName] = true
+	}
+	sample := make([]string, 0, count)
+	for _, tableName := range tableNames {
+		if selected[tableName] {
+			sample = append(sample, tableName)
+		}
+	}
+	return sample
+}
+
+// CanaryOptions 金丝雀查询选项
+type CanaryOptions struct {
+	ShardQueryOptions // 抽样分表上的执行选项
+
+	Fraction float64 // 抽样比例（默认 0.1）
+	Seed     int64   // 抽样种子，相同的种子抽取相同的分表
+}
+
+// CanaryReport 金丝雀查询结果，根据抽样分表的结果估算完整跨表查询的规模
+type CanaryReport struct {
+	SampledTables     []string      // 抽样的分表
+	ExecutedTables    int           // 实际执行的分表数（不存在的分表被跳过）
+	TotalTables       int           // 完整查询需要访问的分表总数
+	Rows              int64         // 抽样分表返回的行数
+	EstimatedRows     int64         // 估算的完整查询行数（按执行的分表数等比例放大）
+	Duration          time.Duration // 金丝雀查询耗时
+	AvgShardDuration  time.Duration // 抽样分表的平均耗时
+	EstimatedDuration time.Duration // 估算的完整查询耗时（按平均耗时和并发度计算）
+}
+
+// CrossTableCanary 金丝雀查询，只在确定性抽样的分表上执行查询，结果追加到 dest 中，
+// 用于在执行开销较大的完整跨表查询（如临时分析）前低成本地估算结果行数和耗时
+func CrossTableCanary(db *gorm.DB, strategy ShardingStrategy, dest interface{}, queryBuilder QueryBuilder, options ...CanaryOptions) (*CanaryReport, error) {
+	opts := CanaryOptions{}
+	if len(options) > 0 {
+		opts = options[0]
+	}
+	if opts.Fraction <= 0 {
+		opts.Fraction = 0.1
+	}
+
+	destValue := reflect.ValueOf(dest)
+	if destValue.Kind() != reflect.Ptr || destValue.Elem().Kind() != reflect.Slice {
+		return nil, fmt.Errorf("dest must be a pointer to slice")
+	}
+	destElem := destValue.Elem()
+	elemType := destElem.Type().Elem()
+
+	report := &CanaryReport{
+		SampledTables: SampleShards(strategy, opts.Fraction, opts.Seed),
+		TotalTables:   len(getTableNamesWithTimeRange(strategy, strategy.GetBaseTableName(), nil)),
+	}
+	if len(report.SampledTables) == 0 {
+		return nil, fmt.Errorf("no tables found")
+	}
+
+	var (
+		mu            sync.Mutex
+		shardDuration time.Duration
+		tableResults  = make([]reflect.Value, len(report.SampledTables))
+	)
+	started := time.Now()
+	err := runOnShards(db, report.SampledTables, opts.ShardQueryOptions, func(tx *gorm.DB, index int, tableName string) error {
+		query := tx.Table(tableName)
+		if queryBuilder != nil {
+			query = queryBuilder(query)
+		}
+
+		shardStarted := time.Now()
+		results := reflect.New(reflect.SliceOf(elemType))
+		if err := query.Find(results.Interface()).Error; err != nil {
+			return err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if !tableResults[index].IsValid() {
+			report.ExecutedTables++
+		}
+		shardDuration += time.Since(shardStarted)
+		tableResults[index] = results.Elem()
+		return nil
+	})
+	report.Duration = time.Since(started)
+
+	for _, results := range tableResults {
+		if results.IsValid() {
+			destElem.Set(reflect.AppendSlice(destElem, results))
+			report.Rows += int64(results.Len())
+		}
+	}
+
+	if report.ExecutedTables > 0 {
+		report.AvgShardDuration = shardDuration / time.Duration(report.ExecutedTables)
+		report.EstimatedRows = int64(math.Round(float64(report.Rows) * float64(report.TotalTables) / float64(report.ExecutedTables)))
+
+		parallelism := opts.Parallelism
+		if parallelism <= 0 {
+			parallelism = 1
+		}
+		rounds := (report.TotalTables + parallelism - 1) / parallelism
+		report.EstimatedDuration = report.AvgShardDuration * time.Duration(rounds)
+	}
+	return report, err
+}
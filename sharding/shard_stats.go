@@ -0,0 +1,147 @@
+package sharding
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// ShardStatsOptions 分表统计选项
+type ShardStatsOptions struct {
+	ExactCount bool // 使用 COUNT(*) 统计行数（默认使用 information_schema.TABLES 中的估算值，InnoDB 的误差可能较大）
+}
+
+// ShardTableStats 单个分表的统计
+type ShardTableStats struct {
+	Table      string `json:"table"`
+	Missing    bool   `json:"missing,omitempty"` // 分表不存在
+	Rows       int64  `json:"rows"`              // 行数（默认为估算值）
+	DataBytes  int64  `json:"data_bytes"`        // 数据大小（DATA_LENGTH）
+	IndexBytes int64  `json:"index_bytes"`       // 索引大小（INDEX_LENGTH）
+}
+
+// ShardStatsReport 分表统计与倾斜报告
+type ShardStatsReport struct {
+	Tables          []ShardTableStats `json:"tables"` // 按策略中的顺序排列
+	TotalRows       int64             `json:"total_rows"`
+	TotalDataBytes  int64             `json:"total_data_bytes"`
+	TotalIndexBytes int64             `json:"total_index_bytes"`
+	MaxRowsTable    string            `json:"max_rows_table"` // 行数最多的分表
+	RowSkew         float64           `json:"row_skew"`       // 行数倾斜度：最大行数 / 平均行数（1 表示完全均匀，没有数据时为 0）
+	SizeSkew        float64           `json:"size_skew"`      // 大小倾斜度：最大（数据 + 索引）大小 / 平均大小
+}
+
+// HotShards 获取行数超过平均值 factor 倍的分表（如 factor 为 2 时返回行数超过平均值两倍的分表）
+func (r *ShardStatsReport) HotShards(factor float64) []string {
+	existing := 0
+	for _, table := range r.Tables {
+		if !table.Missing {
+			existing++
+		}
+	}
+	if existing == 0 || r.TotalRows == 0 {
+		return nil
+	}
+	avg := float64(r.TotalRows) / float64(existing)
+
+	var hot []string
+	for _, table := range r.Tables {
+		if !table.Missing && float64(table.Rows) > avg*factor {
+			hot = append(hot, table.Table)
+		}
+	}
+	return hot
+}
+
+// ShardStats 统计策略的每个分表的行数、数据和索引大小（读取 information_schema.TABLES），并计算倾斜度，
+// 用于容量规划和发现热点分表；时间分表统计数据库中已存在的分表，不存在的分表标记为 Missing 且不参与倾斜度计算
+// 非 MySQL 数据库没有大小信息，行数使用 COUNT(*)
+func ShardStats(db *gorm.DB, strategy ShardingStrategy, options ...ShardStatsOptions) (*ShardStatsReport, error) {
+	opts := ShardStatsOptions{}
+	if len(options) > 0 {
+		opts = options[0]
+	}
+
+	tableNames, err := knownShardTables(db, strategy)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make(map[string]*ShardTableStats, len(tableNames))
+	if db.Dialector.Name() == "mysql" && len(tableNames) > 0 {
+		var rows []struct {
+			TableName   string
+			TableRows   *int64
+			DataLength  *int64
+			IndexLength *int64
+		}
+		err := db.Raw("SELECT table_name AS table_name, table_rows AS table_rows, data_length AS data_length, index_length AS index_length "+
+			"FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name IN ?", tableNames).Scan(&rows).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to read information_schema.tables: %w", err)
+		}
+		for _, row := range rows {
+			table := &ShardTableStats{Table: row.TableName}
+			if row.TableRows != nil {
+				table.Rows = *row.TableRows
+			}
+			if row.DataLength != nil {
+				table.DataBytes = *row.DataLength
+			}
+			if row.IndexLength != nil {
+				table.IndexBytes = *row.IndexLength
+			}
+			stats[row.TableName] = table
+		}
+	}
+
+	report := &ShardStatsReport{Tables: make([]ShardTableStats, 0, len(tableNames))}
+	var maxRows, maxSize int64
+	existing := 0
+	for _, tableName := range tableNames {
+		table, ok := stats[tableName]
+		if db.Dialector.Name() != "mysql" || opts.ExactCount {
+			var count int64
+			err := db.Table(tableName).Count(&count).Error
+			switch {
+			case isTableNotFoundError(err):
+				ok = false
+			case err != nil:
+				return nil, fmt.Errorf("failed to count table %s: %w", tableName, err)
+			default:
+				if table == nil {
+					table = &ShardTableStats{Table: tableName}
+				}
+				table.Rows = count
+				ok = true
+			}
+		}
+		if !ok {
+			report.Tables = append(report.Tables, ShardTableStats{Table: tableName, Missing: true})
+			continue
+		}
+
+		existing++
+		report.Tables = append(report.Tables, *table)
+		report.TotalRows += table.Rows
+		report.TotalDataBytes += table.DataBytes
+		report.TotalIndexBytes += table.IndexBytes
+		if table.Rows > maxRows || report.MaxRowsTable == "" {
+			maxRows = table.Rows
+			report.MaxRowsTable = tableName
+		}
+		if size := table.DataBytes + table.IndexBytes; size > maxSize {
+			maxSize = size
+		}
+	}
+
+	if existing > 0 {
+		if report.TotalRows > 0 {
+			report.RowSkew = float64(maxRows) / (float64(report.TotalRows) / float64(existing))
+		}
+		if totalSize := report.TotalDataBytes + report.TotalIndexBytes; totalSize > 0 {
+			report.SizeSkew = float64(maxSize) / (float64(totalSize) / float64(existing))
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,204 @@
+package sharding
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ErrPartialCommit 跨数据源事务的部分数据源已经提交、其余数据源提交失败
+var ErrPartialCommit = errors.New("sharding: partial commit")
+
+// PartialCommitError 部分提交的详细信息，可通过 errors.Is(err, ErrPartialCommit) 判断
+// 已提交的分表无法回滚，需要调用方补偿（如重试写入未提交的分表，或撤销已提交的分表）
+type PartialCommitError struct {
+	Committed    []string // 已提交的分表
+	NotCommitted []string // 提交失败或已回滚的分表
+	Err          error    // 提交失败的错误
+}
+
+func (e *PartialCommitError) Error() string {
+	return fmt.Sprintf("%v: committed %s, not committed %s: %v",
+		ErrPartialCommit, strings.Join(e.Committed, ", "), strings.Join(e.NotCommitted, ", "), e.Err)
+}
+
+func (e *PartialCommitError) Unwrap() []error {
+	return []error{ErrPartialCommit, e.Err}
+}
+
+// ShardTransactionOptions 跨分表事务配置
+type ShardTransactionOptions struct {
+	Tables    []string       // 参与事务的分表（txMap 的键，可选，未指定时见 ShardTransaction）
+	Cluster   *ShardCluster  // 分表所在的数据源（可选，未指定时所有分表使用 db 上的同一个事务）
+	TxOptions *sql.TxOptions // 事务选项（可选，如隔离级别，XA 事务不支持）
+	XA        *XACoordinator // 使用 XA 两阶段提交（可选，仅 MySQL）；fn 中不能调用 tx.Transaction 或 tx.Begin（返回 gorm.ErrInvalidTransaction）
+}
+
+// ShardTransaction 在多个分表上执行事务：为参与的分表所在的每个数据库开启一个事务，fn 成功后提交所有事务，
+// fn 返回错误（或 panic）时回滚所有事务
+// txMap 的键为分表名称，值为该分表所在数据库上的事务（已通过 Table() 指定分表，可以重复使用）：
+//
+//	err := ShardTransaction(db, func(txMap map[string]*gorm.DB) error {
+//		if err := txMap["users_1"].Create(&user).Error; err != nil {
+//			return err
+//		}
+//		return txMap["orders_1"].Create(&order).Error
+//	}, ShardTransactionOptions{Tables: []string{"users_1", "orders_1"}})
+//
+// 未指定 Tables 时，指定了 Cluster 则为 Cluster 中分配了数据源的所有分表（AssignTables、DistributeStrategy），
+// 否则为通过 RegisterSharding 注册到 db 的所有策略的分表（时间分表为数据库中已存在的分表）；
+// 只涉及少数分表时建议指定 Tables，避免在无关的数据源上开启事务。
+//
+// 一致性保证：未指定 Cluster（或所有分表在同一个数据源上）时只有一个数据库事务，提交是原子的；
+// 分表分布在多个数据源上时只能尽力而为——按数据源名称顺序逐个提交，某个数据源提交失败时回滚其余未提交的事务，
+// 但已提交的数据源无法回滚，此时返回 *PartialCommitError 列出已提交和未提交的分表。需要原子提交时指定 XA 使用两阶段提交
+func ShardTransaction(db *gorm.DB, fn func(txMap map[string]*gorm.DB) error, options ...ShardTransactionOptions) error {
+	opts := ShardTransactionOptions{}
+	if len(options) > 0 {
+		opts = options[0]
+	}
+	if len(opts.Tables) == 0 {
+		tableNames, err := defaultTransactionTables(db, opts.Cluster)
+		if err != nil {
+			return err
+		}
+		opts.Tables = tableNames
+	}
+	if len(opts.Tables) == 0 {
+		return fmt.Errorf("no shard tables in transaction: specify ShardTransactionOptions.Tables, assign tables on the cluster or register sharding strategies on db")
+	}
+
+	names, conns, tableGroups, err := groupTransactionTables(db, opts)
+	if err != nil {
+		return err
+	}
+	if opts.XA != nil {
+		return opts.XA.run(db, names, conns, tableGroups, fn)
+	}
+
+	txs := make(map[string]*gorm.DB, len(names))
+	rollback := func(names []string) error {
+		var errs []error
+		for _, name := range names {
+			tx, ok := txs[name]
+			if !ok {
+				continue
+			}
+			if err := tx.Rollback().Error; err != nil && !errors.Is(err, sql.ErrTxDone) {
+				errs = append(errs, fmt.Errorf("failed to rollback transaction on %s: %w", databaseLabel(name), err))
+			}
+		}
+		return errors.Join(errs...)
+	}
+
+	for _, name := range names {
+		tx := conns[name].Begin(opts.TxOptions)
+		if tx.Error != nil {
+			return errors.Join(fmt.Errorf("failed to begin transaction on %s: %w", databaseLabel(name), tx.Error), rollback(names))
+		}
+		txs[name] = tx
+	}
+
+	// fn panic 时回滚所有事务后继续 panic
+	defer func() {
+		if r := recover(); r != nil {
+			rollback(names)
+			panic(r)
+		}
+	}()
+
+	txMap := make(map[string]*gorm.DB, len(opts.Tables))
+	for tableName, name := range tableGroups {
+		txMap[tableName] = txs[name].Table(tableName).Session(&gorm.Session{})
+	}
+	if err := fn(txMap); err != nil {
+		return errors.Join(err, rollback(names))
+	}
+
+	var committedTables []string
+	for i, name := range names {
+		if err := txs[name].Commit().Error; err != nil {
+			rollbackErr := rollback(names[i+1:])
+			if i == 0 {
+				// 没有任何数据源提交，相当于整体回滚
+				return errors.Join(fmt.Errorf("failed to commit transaction on %s: %w", databaseLabel(name), err), rollbackErr)
+			}
+			return &PartialCommitError{
+				Committed:    committedTables,
+				NotCommitted: tablesOf(tableGroups, names[i:]),
+				Err:          errors.Join(err, rollbackErr),
+			}
+		}
+		committedTables = append(committedTables, tablesOf(tableGroups, names[i:i+1])...)
+	}
+	return nil
+}
+
+// defaultTransactionTables 未指定 Tables 时参与事务的分表：Cluster 中分配了数据源的分表，或注册到 db 的策略的所有分表
+func defaultTransactionTables(db *gorm.DB, cluster *ShardCluster) ([]string, error) {
+	if cluster != nil {
+		return cluster.assignedTables(), nil
+	}
+	var tableNames []string
+	for _, strategy := range defaultStrategyRegistry.strategies(db) {
+		strategyTables, err := allShardTables(db, strategy)
+		if err != nil {
+			return nil, err
+		}
+		tableNames = append(tableNames, strategyTables...)
+	}
+	return tableNames, nil
+}
+
+// groupTransactionTables 按数据源对参与事务的分表分组，返回数据源名称（排序）、数据源连接和分表所在的数据源
+// 未指定 Cluster 时所有分表都在名称为空的数据源（db）上
+func groupTransactionTables(db *gorm.DB, opts ShardTransactionOptions) ([]string, map[string]*gorm.DB, map[string]string, error) {
+	conns := make(map[string]*gorm.DB)     // 数据源名称 -> 连接
+	tableGroups := make(map[string]string) // 分表名称 -> 数据源名称
+	for _, tableName := range opts.Tables {
+		name := ""
+		conn := db
+		if opts.Cluster != nil {
+			var err error
+			if conn, err = opts.Cluster.DBForTable(tableName); err != nil {
+				return nil, nil, nil, err
+			}
+			name, _ = opts.Cluster.DatabaseName(tableName)
+		}
+		conns[name] = conn
+		tableGroups[tableName] = name
+	}
+	names := make([]string, 0, len(conns))
+	for name := range conns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, conns, tableGroups, nil
+}
+
+// databaseLabel 错误信息中的数据源名称（未指定 Cluster 时为空）
+func databaseLabel(name string) string {
+	if name == "" {
+		return "database"
+	}
+	return "shard database " + name
+}
+
+// tablesOf 获取数据源上参与事务的分表（按名称排序）
+func tablesOf(tableGroups map[string]string, names []string) []string {
+	var tables []string
+	for tableName, name := range tableGroups {
+		for _, n := range names {
+			if name == n {
+				tables = append(tables, tableName)
+				break
+			}
+		}
+	}
+	sort.Strings(tables)
+	return tables
+}
@@ -0,0 +1,127 @@
+package sharding
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"sort"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// testTxPool 记录事务开启、提交和回滚的连接池
+type testTxPool struct {
+	gorm.ConnPool
+	name string
+	log  *[]string
+}
+
+func (p *testTxPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	*p.log = append(*p.log, "begin "+p.name)
+	return &testTx{p}, nil
+}
+
+type testTx struct{ *testTxPool }
+
+func (t *testTx) Commit() error {
+	*t.log = append(*t.log, "commit "+t.name)
+	return nil
+}
+
+func (t *testTx) Rollback() error {
+	*t.log = append(*t.log, "rollback "+t.name)
+	return nil
+}
+
+// newTxTestDB 创建使用 testTxPool 的 DryRun 数据库
+func newTxTestDB(t *testing.T, name string, log *[]string) *gorm.DB {
+	db := newDryRunDB(t)
+	db.Statement.ConnPool = &testTxPool{ConnPool: db.Statement.ConnPool, name: name, log: log}
+	return db
+}
+
+// txTables 获取 txMap 中的分表（排序）
+func txTables(txMap map[string]*gorm.DB) []string {
+	tables := make([]string, 0, len(txMap))
+	for tableName := range txMap {
+		tables = append(tables, tableName)
+	}
+	sort.Strings(tables)
+	return tables
+}
+
+func TestShardTransactionDefaultsToRegisteredTables(t *testing.T) {
+	var log []string
+	db := newTxTestDB(t, "db", &log)
+	for _, strategy := range []ShardingStrategy{
+		NewHashShardingStrategy("users", "UserID", 2),
+		NewHashShardingStrategy("orders", "UserID", 2),
+	} {
+		if err := RegisterSharding(db, strategy); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var tables []string
+	err := ShardTransaction(db.WithContext(context.Background()), func(txMap map[string]*gorm.DB) error {
+		tables = txTables(txMap)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"orders_0", "orders_1", "users_0", "users_1"}; !reflect.DeepEqual(tables, want) {
+		t.Errorf("txMap tables = %v, want %v", tables, want)
+	}
+	if want := []string{"begin db", "commit db"}; !reflect.DeepEqual(log, want) {
+		t.Errorf("log = %v, want %v", log, want)
+	}
+
+	if err := UnregisterSharding(db, "orders"); err != nil {
+		t.Fatal(err)
+	}
+	_ = ShardTransaction(db, func(txMap map[string]*gorm.DB) error {
+		tables = txTables(txMap)
+		return nil
+	})
+	if want := []string{"users_0", "users_1"}; !reflect.DeepEqual(tables, want) {
+		t.Errorf("after unregister: txMap tables = %v, want %v", tables, want)
+	}
+}
+
+func TestShardTransactionDefaultsToClusterTables(t *testing.T) {
+	var log []string
+	cluster := NewShardCluster(map[string]*gorm.DB{
+		"ds0": newTxTestDB(t, "ds0", &log),
+		"ds1": newTxTestDB(t, "ds1", &log),
+	})
+	if err := cluster.DistributeStrategy(NewHashShardingStrategy("users", "UserID", 4), "ds0", "ds1"); err != nil {
+		t.Fatal(err)
+	}
+
+	var tables []string
+	err := ShardTransaction(newDryRunDB(t), func(txMap map[string]*gorm.DB) error {
+		tables = txTables(txMap)
+		return nil
+	}, ShardTransactionOptions{Cluster: cluster})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"users_0", "users_1", "users_2", "users_3"}; !reflect.DeepEqual(tables, want) {
+		t.Errorf("txMap tables = %v, want %v", tables, want)
+	}
+	if want := []string{"begin ds0", "begin ds1", "commit ds0", "commit ds1"}; !reflect.DeepEqual(log, want) {
+		t.Errorf("log = %v, want %v", log, want)
+	}
+}
+
+func TestShardTransactionWithoutTables(t *testing.T) {
+	err := ShardTransaction(newDryRunDB(t), func(txMap map[string]*gorm.DB) error {
+		t.Error("fn called without shard tables")
+		return nil
+	})
+	if err == nil {
+		t.Error("got nil error, want an error without shard tables")
+	}
+}
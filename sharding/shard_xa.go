@@ -0,0 +1,373 @@
+package sharding
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// defaultXALogTable 默认的 XA 事务日志表名
+	defaultXALogTable = "sharding_xa_log"
+
+	// defaultXAPrefix 默认的 XA 事务 ID 前缀
+	defaultXAPrefix = "sxa"
+
+	// defaultXATimeout 默认的 XA 事务超时
+	defaultXATimeout = 30 * time.Second
+)
+
+// ErrXAPending XA 事务已决定提交，但部分数据源的分支提交失败、仍处于 PREPARED 状态，需要调用 Recover 完成提交
+var ErrXAPending = errors.New("sharding: xa transaction pending recovery")
+
+// XALogRecord XA 事务日志中的提交记录
+// 所有分支 PREPARE 成功后、提交之前写入，恢复时据此决定提交还是回滚悬挂的分支
+type XALogRecord struct {
+	XID          string    `gorm:"column:xid;primaryKey;size:128"` // 全局事务 ID
+	Participants string    `gorm:"size:1024"`                      // 参与的数据源（逗号分隔）
+	CreatedAt    time.Time `gorm:"index"`
+}
+
+// XAOptions XA 两阶段提交配置
+type XAOptions struct {
+	LogTable string        // 事务日志表名（默认 sharding_xa_log）
+	Prefix   string        // 事务 ID 前缀（默认 sxa），Recover 只处理带有该前缀的事务
+	Timeout  time.Duration // 执行 fn 和 PREPARE 的超时（默认 30 秒），提交阶段另有相同长度的超时
+}
+
+// XAResolution Recover 对一个悬挂分支的处理结果
+type XAResolution struct {
+	Database string // 数据源名称（未指定 Cluster 时为空）
+	XID      string // 全局事务 ID
+	Commit   bool   // true 为提交，false 为回滚
+	Err      error  // 处理失败的错误
+}
+
+// XACoordinator XA 两阶段提交协调器（仅 MySQL）
+// 通过 ShardTransactionOptions.XA 启用后，ShardTransaction 在每个数据源的独立连接上执行 XA START，
+// fn 成功后逐个 XA END / XA PREPARE，所有分支 PREPARE 成功后先在事务日志中写入提交记录，再逐个 XA COMMIT；
+// 提交记录写入之前的任何失败都会回滚所有分支。提交阶段失败的分支保持 PREPARED 状态并返回 ErrXAPending，
+// 由 Recover 根据事务日志完成提交，进程崩溃后留下的悬挂分支同样由 Recover 处理（可以在启动时或定期调用）
+type XACoordinator struct {
+	db      *gorm.DB // 事务日志所在的数据库
+	options XAOptions
+}
+
+// NewXACoordinator 创建 XA 两阶段提交协调器
+// db: 事务日志所在的数据库（应独立于分表所在的数据源，或是其中最可靠的一个）
+func NewXACoordinator(db *gorm.DB, options ...XAOptions) *XACoordinator {
+	opts := XAOptions{}
+	if len(options) > 0 {
+		opts = options[0]
+	}
+	if opts.LogTable == "" {
+		opts.LogTable = defaultXALogTable
+	}
+	if opts.Prefix == "" {
+		opts.Prefix = defaultXAPrefix
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultXATimeout
+	}
+	return &XACoordinator{db: db, options: opts}
+}
+
+// Migrate 创建事务日志表
+func (c *XACoordinator) Migrate() error {
+	return c.db.Table(c.options.LogTable).AutoMigrate(&XALogRecord{})
+}
+
+// xaState XA 分支状态
+type xaState int
+
+const (
+	xaNone     xaState = iota // 未开始或已结束
+	xaActive                  // XA START 之后
+	xaIdle                    // XA END 之后
+	xaPrepared                // XA PREPARE 之后
+)
+
+// xaID XA 分支 ID：gtrid 为全局事务 ID，bqual 为数据源名称
+type xaID struct {
+	gtrid string
+	bqual string
+}
+
+// sql XA 语句中的 xid（使用十六进制字面量，不需要转义）
+func (x xaID) sql() string {
+	if x.bqual == "" {
+		return fmt.Sprintf("X'%x'", x.gtrid)
+	}
+	return fmt.Sprintf("X'%x',X'%x'", x.gtrid, x.bqual)
+}
+
+// xaBranch 一个数据源上的 XA 分支
+type xaBranch struct {
+	name  string
+	id    xaID
+	conn  *sql.Conn // XA 分支绑定的连接
+	state xaState
+}
+
+// xaBranchConnPool XA 分支的连接，只提供执行语句的方法（不能在分支内开启本地事务）
+type xaBranchConnPool struct {
+	gorm.ConnPool
+}
+
+// exec 在分支的连接上执行 XA 语句（如 "XA END"）
+func (b *xaBranch) exec(ctx context.Context, statement string) error {
+	_, err := b.conn.ExecContext(ctx, statement+" "+b.id.sql())
+	return err
+}
+
+// rollback 回滚分支
+func (b *xaBranch) rollback(ctx context.Context) error {
+	if b.state == xaActive {
+		if err := b.exec(ctx, "XA END"); err != nil {
+			return err
+		}
+		b.state = xaIdle
+	}
+	if b.state == xaNone {
+		return nil
+	}
+	if err := b.exec(ctx, "XA ROLLBACK"); err != nil {
+		return err
+	}
+	b.state = xaNone
+	return nil
+}
+
+// run 使用 XA 两阶段提交执行 ShardTransaction，db 提供 context
+func (c *XACoordinator) run(db *gorm.DB, names []string, conns map[string]*gorm.DB, tableGroups map[string]string, fn func(txMap map[string]*gorm.DB) error) error {
+	ctx, cancel := context.WithTimeout(db.Statement.Context, c.options.Timeout)
+	defer cancel()
+
+	gtrid, err := c.newGlobalID()
+	if err != nil {
+		return err
+	}
+
+	branches := make([]*xaBranch, 0, len(names))
+	defer func() {
+		for _, b := range branches {
+			b.conn.Close()
+		}
+	}()
+
+	// abort 回滚所有分支（使用独立的 context，超时后仍能回滚）
+	abort := func(cause error) error {
+		rollbackCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.options.Timeout)
+		defer cancel()
+		errs := []error{cause}
+		for _, b := range branches {
+			if err := b.rollback(rollbackCtx); err != nil {
+				errs = append(errs, fmt.Errorf("failed to rollback xa branch on %s: %w", databaseLabel(b.name), err))
+			}
+		}
+		return errors.Join(errs...)
+	}
+
+	txs := make(map[string]*gorm.DB, len(names))
+	for _, name := range names {
+		conn := conns[name]
+		if dialect := conn.Dialector.Name(); dialect != "mysql" {
+			return abort(fmt.Errorf("xa transactions are not supported on %s (%s)", databaseLabel(name), dialect))
+		}
+		sqlDB, err := conn.DB()
+		if err != nil {
+			return abort(err)
+		}
+		sqlConn, err := sqlDB.Conn(ctx)
+		if err != nil {
+			return abort(fmt.Errorf("failed to get connection on %s: %w", databaseLabel(name), err))
+		}
+		b := &xaBranch{name: name, id: xaID{gtrid: gtrid, bqual: name}, conn: sqlConn}
+		branches = append(branches, b)
+		if err := b.exec(ctx, "XA START"); err != nil {
+			return abort(fmt.Errorf("failed to start xa branch on %s: %w", databaseLabel(name), err))
+		}
+		b.state = xaActive
+
+		// 分支内的写入不能再开启本地事务（MySQL 返回 XAER_RMFAIL），跳过默认事务，
+		// 连接也不实现 TxBeginner，fn 中调用 tx.Transaction / tx.Begin 返回 gorm.ErrInvalidTransaction
+		tx := conn.Session(&gorm.Session{NewDB: true, Context: ctx, SkipDefaultTransaction: true})
+		tx.Statement.ConnPool = xaBranchConnPool{ConnPool: sqlConn}
+		txs[name] = tx
+	}
+
+	// fn panic 时回滚所有分支后继续 panic
+	defer func() {
+		if r := recover(); r != nil {
+			abort(fmt.Errorf("panic: %v", r))
+			panic(r)
+		}
+	}()
+
+	txMap := make(map[string]*gorm.DB, len(tableGroups))
+	for tableName, name := range tableGroups {
+		txMap[tableName] = txs[name].Table(tableName).Session(&gorm.Session{})
+	}
+	if err := fn(txMap); err != nil {
+		return abort(err)
+	}
+
+	// 第一阶段：所有分支 PREPARE
+	for _, b := range branches {
+		if err := b.exec(ctx, "XA END"); err != nil {
+			return abort(fmt.Errorf("failed to end xa branch on %s: %w", databaseLabel(b.name), err))
+		}
+		b.state = xaIdle
+		if err := b.exec(ctx, "XA PREPARE"); err != nil {
+			return abort(fmt.Errorf("failed to prepare xa branch on %s: %w", databaseLabel(b.name), err))
+		}
+		b.state = xaPrepared
+	}
+
+	// 记录提交决定，之后的失败由 Recover 完成提交
+	record := XALogRecord{XID: gtrid, Participants: strings.Join(names, ","), CreatedAt: time.Now()}
+	if err := c.db.WithContext(ctx).Table(c.options.LogTable).Create(&record).Error; err != nil {
+		// 写入结果未知时删除记录，避免 Recover 提交回滚失败的分支
+		c.deleteLog(context.WithoutCancel(ctx), gtrid)
+		return abort(fmt.Errorf("failed to write xa log: %w", err))
+	}
+
+	// 第二阶段：逐个提交（调用方取消也不中断提交）
+	commitCtx, cancelCommit := context.WithTimeout(context.WithoutCancel(ctx), c.options.Timeout)
+	defer cancelCommit()
+	var errs []error
+	for _, b := range branches {
+		if err := b.exec(commitCtx, "XA COMMIT"); err != nil {
+			errs = append(errs, fmt.Errorf("failed to commit xa branch on %s: %w", databaseLabel(b.name), err))
+			continue
+		}
+		b.state = xaNone
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %s: %w", ErrXAPending, gtrid, errors.Join(errs...))
+	}
+
+	// 所有分支已提交，删除提交记录（失败时由 Recover 清理）
+	c.deleteLog(commitCtx, gtrid)
+	return nil
+}
+
+// Recover 处理悬挂的 XA 分支：扫描数据源中带有本协调器前缀、开始时间早于两倍 Timeout 的 PREPARED 分支，
+// 事务日志中有提交记录的执行 XA COMMIT，否则执行 XA ROLLBACK，然后清理过期的提交记录
+// cluster 为 nil 时只扫描事务日志所在的数据库（未指定 Cluster 的 ShardTransaction）
+func (c *XACoordinator) Recover(ctx context.Context, cluster *ShardCluster) ([]XAResolution, error) {
+	databases := map[string]*gorm.DB{"": c.db}
+	if cluster != nil {
+		databases = make(map[string]*gorm.DB)
+		for _, name := range cluster.Databases() {
+			databases[name], _ = cluster.Database(name)
+		}
+	}
+
+	var resolutions []XAResolution
+	var errs []error
+	var pending []string // 处理失败、仍需保留提交记录的事务
+	for name, db := range databases {
+		ids, err := c.danglingBranches(ctx, name, db)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to scan xa transactions on %s: %w", databaseLabel(name), err))
+			continue
+		}
+		for _, id := range ids {
+			resolution := XAResolution{Database: name, XID: id.gtrid}
+			var count int64
+			resolution.Err = c.db.WithContext(ctx).Table(c.options.LogTable).Where("xid = ?", id.gtrid).Count(&count).Error
+			if resolution.Err == nil {
+				resolution.Commit = count > 0
+				statement := "XA ROLLBACK "
+				if resolution.Commit {
+					statement = "XA COMMIT "
+				}
+				resolution.Err = db.WithContext(ctx).Exec(statement + id.sql()).Error
+			}
+			if resolution.Err != nil {
+				pending = append(pending, id.gtrid)
+			}
+			resolutions = append(resolutions, resolution)
+		}
+	}
+
+	// 所有数据源都扫描成功时，清理已经没有悬挂分支的过期提交记录
+	if len(errs) == 0 {
+		query := c.db.WithContext(ctx).Table(c.options.LogTable).Where("created_at < ?", time.Now().Add(-2*c.options.Timeout))
+		if len(pending) > 0 {
+			query = query.Where("xid NOT IN ?", pending)
+		}
+		if err := query.Delete(&XALogRecord{}).Error; err != nil {
+			errs = append(errs, fmt.Errorf("failed to clean xa log: %w", err))
+		}
+	}
+	return resolutions, errors.Join(errs...)
+}
+
+// danglingBranches 获取数据源上属于本协调器、开始时间早于两倍 Timeout 的 PREPARED 分支
+func (c *XACoordinator) danglingBranches(ctx context.Context, name string, db *gorm.DB) ([]xaID, error) {
+	rows, err := db.WithContext(ctx).Raw("XA RECOVER").Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []xaID
+	for rows.Next() {
+		var formatID, gtridLength, bqualLength int
+		var data []byte
+		if err := rows.Scan(&formatID, &gtridLength, &bqualLength, &data); err != nil {
+			return nil, err
+		}
+		if gtridLength+bqualLength > len(data) {
+			continue
+		}
+		id := xaID{gtrid: string(data[:gtridLength]), bqual: string(data[gtridLength : gtridLength+bqualLength])}
+		startedAt, ok := c.startedAt(id.gtrid)
+		if !ok || id.bqual != name || time.Since(startedAt) < 2*c.options.Timeout {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// newGlobalID 生成全局事务 ID：前缀-开始时间（纳秒）-随机数
+func (c *XACoordinator) newGlobalID() (string, error) {
+	random := make([]byte, 4)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("failed to generate xa transaction id: %w", err)
+	}
+	return fmt.Sprintf("%s-%d-%s", c.options.Prefix, time.Now().UnixNano(), hex.EncodeToString(random)), nil
+}
+
+// startedAt 从全局事务 ID 中解析开始时间，不是本协调器生成的 ID 时返回 false
+func (c *XACoordinator) startedAt(gtrid string) (time.Time, bool) {
+	rest, ok := strings.CutPrefix(gtrid, c.options.Prefix+"-")
+	if !ok {
+		return time.Time{}, false
+	}
+	i := strings.IndexByte(rest, '-')
+	if i < 0 {
+		return time.Time{}, false
+	}
+	nanos, err := strconv.ParseInt(rest[:i], 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(0, nanos), true
+}
+
+// deleteLog 删除提交记录
+func (c *XACoordinator) deleteLog(ctx context.Context, gtrid string) {
+	c.db.WithContext(ctx).Table(c.options.LogTable).Where("xid = ?", gtrid).Delete(&XALogRecord{})
+}
@@ -1,9 +1,12 @@
 package sharding
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"gorm.io/gorm"
@@ -24,6 +27,13 @@ type ShardingStrategy interface {
 	GetBaseTableName() string
 }
 
+// TableNameResolver 可以报告路由错误的策略（可选接口）
+// 例如严格模式的时间分表在时间值无法转换时返回 ErrInvalidTimeValue，而不是路由到当前时间的分表
+type TableNameResolver interface {
+	// GetTableNameE 根据分表键值获取实际表名，分表键值无效时返回错误
+	GetTableNameE(baseTableName string, shardingValue interface{}) (string, error)
+}
+
 // ShardingConfig 分表配置
 type ShardingConfig struct {
 	Strategy        ShardingStrategy
@@ -45,12 +55,84 @@ func RegisterShardingWithAutoCreate(db *gorm.DB, strategy ShardingStrategy, mode
 
 // RegisterShardingWithConfig 注册分表策略（带配置）
 func RegisterShardingWithConfig(db *gorm.DB, strategy ShardingStrategy, autoCreate bool, model interface{}) error {
+	return registerSharding(db, strategy, autoCreate, model, nil)
+}
+
+// strategyRegistry 通过 RegisterSharding 注册到数据库的策略
+// 按数据库的回调注册表（同一个数据库的会话共用）和基础表名保存
+type strategyRegistry struct {
+	mu   sync.Mutex
+	byDB map[interface{}]map[string]ShardingStrategy
+}
+
+// defaultStrategyRegistry 全局的策略注册表
+var defaultStrategyRegistry = &strategyRegistry{
+	byDB: make(map[interface{}]map[string]ShardingStrategy),
+}
+
+// register 记录注册到数据库的策略（同一个基础表后注册的策略覆盖之前的策略）
+func (r *strategyRegistry) register(db *gorm.DB, strategy ShardingStrategy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.byDB[db.Callback()] == nil {
+		r.byDB[db.Callback()] = make(map[string]ShardingStrategy)
+	}
+	r.byDB[db.Callback()][strategy.GetBaseTableName()] = strategy
+}
+
+// unregister 移除基础表的策略
+func (r *strategyRegistry) unregister(db *gorm.DB, baseTableName string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.byDB[db.Callback()], baseTableName)
+}
+
+// strategies 获取注册到数据库的策略（按基础表名排序）
+func (r *strategyRegistry) strategies(db *gorm.DB) []ShardingStrategy {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	strategies := make([]ShardingStrategy, 0, len(r.byDB[db.Callback()]))
+	for _, strategy := range r.byDB[db.Callback()] {
+		strategies = append(strategies, strategy)
+	}
+	sort.Slice(strategies, func(i, j int) bool {
+		return strategies[i].GetBaseTableName() < strategies[j].GetBaseTableName()
+	})
+	return strategies
+}
+
+// registerSharding 注册分表策略，budget 为自动建表使用的维护预算（nil 时使用默认预算）
+func registerSharding(db *gorm.DB, strategy ShardingStrategy, autoCreate bool, model interface{}, budget *MaintenanceBudget) error {
+	defaultStrategyRegistry.register(db, strategy)
+
 	// 使用 GORM 的插件机制
-	db.Callback().Create().Before("gorm:create").Register("sharding:create", func(db *gorm.DB) {
+	// 回调名称包含基础表名，避免注册多个策略时同名回调相互覆盖
+	db.Callback().Create().Before("gorm:create").Register("sharding:create:"+strategy.GetBaseTableName(), func(db *gorm.DB) {
 		if db.Statement.Schema != nil && db.Statement.Schema.Table == strategy.GetBaseTableName() {
+			// 重新分表写入新布局的分表，不按该策略路由
+			if isReshardWrite(db.Statement.Context) {
+				return
+			}
+			if err := checkWritable(strategy.GetBaseTableName()); err != nil {
+				db.AddError(err)
+				return
+			}
+			if tableName, ok := forcedTable(db.Statement.Context, strategy); ok {
+				db.Statement.Table = tableName
+				return
+			}
 			if value := db.Statement.ReflectValue; value.IsValid() {
-				if shardingValue, err := strategy.GetShardingValue(db.Statement.Dest); err == nil {
-					tableName := strategy.GetTableName(strategy.GetBaseTableName(), shardingValue)
+				shardingValue, err := strategy.GetShardingValue(db.Statement.Dest)
+				if errors.Is(err, ErrInvalidTimeValue) {
+					db.AddError(err)
+					return
+				}
+				if err == nil {
+					tableName, err := GetTableNameE(strategy, strategy.GetBaseTableName(), shardingValue)
+					if err != nil {
+						db.AddError(err)
+						return
+					}
 					db.Statement.Table = tableName
 
 					// 如果启用了自动创建表，检查并创建表
@@ -60,21 +142,72 @@ func RegisterShardingWithConfig(db *gorm.DB, strategy ShardingStrategy, autoCrea
 						if tableModel == nil {
 							tableModel = db.Statement.Dest
 						}
-						// 异步创建表（避免影响插入性能）
-						_ = AutoCreateTable(db, strategy, tableName, tableModel)
+						// 在维护预算内检查并创建表（插入之前同步完成，已确认存在的分表不再检查），使用独立的会话，不影响当前的插入语句
+						ctx, cancel := maintenanceContext(db.Statement.Context, resolveMaintenanceBudget(db.Statement.Context, budget))
+						err := ensureShardTable(db.Session(&gorm.Session{NewDB: true, Context: ctx}), strategy, tableName, tableModel)
+						cancel()
+						if err != nil {
+							db.AddError(fmt.Errorf("failed to auto create table %s: %w", tableName, err))
+							return
+						}
 					}
 				}
 			}
 		}
 	})
 
-	db.Callback().Query().Before("gorm:query").Register("sharding:query", func(db *gorm.DB) {
-		// 查询时的表名替换由用户通过 Table() 方法指定
+	// 查询时根据 WHERE 条件中的分表键自动路由（已通过 Table() 指定表名的查询不受影响）
+	db.Callback().Query().Before("gorm:query").Register("sharding:query:"+strategy.GetBaseTableName(), func(db *gorm.DB) {
+		routeQueryByWhere(db, strategy)
+	})
+
+	// 更新和删除时根据模型或 WHERE 条件中的分表键自动路由（如 db.Save(&user)、db.Delete(&user)）
+	// 只读模式下拒绝写入（包括通过 Table() 指定分表的写入）
+	db.Callback().Update().Before("gorm:update").Register("sharding:update:"+strategy.GetBaseTableName(), func(db *gorm.DB) {
+		if rejectReadOnlyWrite(db, strategy) {
+			return
+		}
+		routeWriteStatement(db, strategy)
+	})
+
+	db.Callback().Delete().Before("gorm:delete").Register("sharding:delete:"+strategy.GetBaseTableName(), func(db *gorm.DB) {
+		if rejectReadOnlyWrite(db, strategy) {
+			return
+		}
+		routeWriteStatement(db, strategy)
 	})
 
 	return nil
 }
 
+// UnregisterSharding 移除 RegisterSharding 为基础表注册的分表回调
+func UnregisterSharding(db *gorm.DB, baseTableName string) error {
+	name := func(operation string) string {
+		return "sharding:" + operation + ":" + baseTableName
+	}
+	defaultStrategyRegistry.unregister(db, baseTableName)
+
+	return errors.Join(
+		db.Callback().Create().Remove(name("create")),
+		db.Callback().Query().Remove(name("query")),
+		db.Callback().Update().Remove(name("update")),
+		db.Callback().Delete().Remove(name("delete")),
+	)
+}
+
+// rejectReadOnlyWrite 语句写入的是只读的基础表时记录 ErrReadOnly 并返回 true
+func rejectReadOnlyWrite(db *gorm.DB, strategy ShardingStrategy) bool {
+	baseTableName := strategy.GetBaseTableName()
+	if statementBaseTable(db.Statement) != baseTableName {
+		return false
+	}
+	if err := checkWritable(baseTableName); err != nil {
+		db.AddError(err)
+		return true
+	}
+	return false
+}
+
 // GetTableNameWithValue 根据分表值获取表名（辅助函数）
 func GetTableNameWithValue(strategy ShardingStrategy, value interface{}) string {
 	shardingValue, err := strategy.GetShardingValue(value)
@@ -84,6 +217,30 @@ func GetTableNameWithValue(strategy ShardingStrategy, value interface{}) string
 	return strategy.GetTableName(strategy.GetBaseTableName(), shardingValue)
 }
 
+// GetTableNameE 根据分表键值获取实际表名，策略实现了 TableNameResolver 时返回其路由错误
+func GetTableNameE(strategy ShardingStrategy, baseTableName string, shardingValue interface{}) (string, error) {
+	if resolver, ok := strategy.(TableNameResolver); ok {
+		return resolver.GetTableNameE(baseTableName, shardingValue)
+	}
+	return strategy.GetTableName(baseTableName, shardingValue), nil
+}
+
+// Shard 返回将语句指定到分表键值对应分表的 Scope（只指定分表，不添加分表键条件）：
+//
+//	db.Scopes(sharding.Shard(userStrategy, 123)).Where("user_id = ?", 123).Find(&users)
+//
+// 分表键值无效时（如严格模式下的时间分表）语句返回路由错误
+func Shard(strategy ShardingStrategy, shardingValue interface{}) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		tableName, err := GetTableNameE(strategy, strategy.GetBaseTableName(), shardingValue)
+		if err != nil {
+			db.AddError(err)
+			return db
+		}
+		return db.Table(tableName)
+	}
+}
+
 // SetTableName 设置表名到 GORM Statement
 func SetTableName(db *gorm.DB, strategy ShardingStrategy, value interface{}) {
 	tableName := GetTableNameWithValue(strategy, value)
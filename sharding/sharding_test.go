@@ -0,0 +1,36 @@
+package sharding
+
+import (
+	"testing"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testUser 单元测试使用的模型（按 user_id 分表）
+type testUser struct {
+	ID     uint
+	UserID int64
+	Name   string
+}
+
+func (testUser) TableName() string { return "users" }
+
+// newDryRunDB 创建不连接数据库的 MySQL 实例（DryRun 模式只生成 SQL）
+func newDryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "user:pass@tcp(127.0.0.1:3306)/test",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("open dry run db: %v", err)
+	}
+	return db
+}
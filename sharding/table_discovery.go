@@ -0,0 +1,131 @@
+package sharding
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// defaultDiscoveryRefresh 默认的分表发现刷新间隔
+const defaultDiscoveryRefresh = time.Minute
+
+// DiscoverExistingTables 从数据库（information_schema）中列出符合策略命名规则的已存在的分表
+// 时间分表按名称解析出的时间排序（支持名称模板）；第二级为时间分表的组合分表按第一级的分表、时间排序，
+// 其他策略按 GetAllTableNames 的顺序排列
+func DiscoverExistingTables(db *gorm.DB, strategy ShardingStrategy) ([]string, error) {
+	existingTables, err := db.Migrator().GetTables()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+
+	if composite, ok := strategy.(*CompositeShardingStrategy); ok {
+		if timeStrategy, ok := composite.secondary.(*TimeShardingStrategy); ok {
+			var tableNames []string
+			for _, primaryTable := range composite.primary.GetAllTableNames(strategy.GetBaseTableName()) {
+				tableNames = append(tableNames, discoverPeriodTables(timeStrategy, primaryTable, existingTables)...)
+			}
+			return tableNames, nil
+		}
+	}
+
+	timeStrategy, ok := strategy.(*TimeShardingStrategy)
+	if !ok {
+		existing := make(map[string]bool, len(existingTables))
+		for _, tableName := range existingTables {
+			existing[tableName] = true
+		}
+		var tableNames []string
+		for _, tableName := range strategy.GetAllTableNames(strategy.GetBaseTableName()) {
+			if existing[tableName] {
+				tableNames = append(tableNames, tableName)
+			}
+		}
+		return tableNames, nil
+	}
+
+	return discoverPeriodTables(timeStrategy, timeStrategy.baseTableName, existingTables), nil
+}
+
+// discoverPeriodTables 从已存在的表中找出时间分表在 baseTableName 下的分表，按时间排序
+func discoverPeriodTables(timeStrategy *TimeShardingStrategy, baseTableName string, existingTables []string) []string {
+	periods := make(map[string]time.Time)
+	var tableNames []string
+	for _, tableName := range existingTables {
+		if period, ok := timeStrategy.tablePeriodOf(baseTableName, tableName); ok {
+			periods[tableName] = period
+			tableNames = append(tableNames, tableName)
+		}
+	}
+	sort.Slice(tableNames, func(i, j int) bool {
+		return periods[tableNames[i]].Before(periods[tableNames[j]])
+	})
+	return tableNames
+}
+
+// allShardTables 获取没有指定时间范围的跨表操作需要访问的分表
+// 非时间分表为策略的所有分表；时间分表（包括第二级为时间分表的组合分表）使用数据库中已存在的所有分表
+// （开启 EnableTableDiscovery 时使用缓存的发现结果），而不是最近一年生成的表名，更早的分表不会被静默跳过；
+// 无法列出数据库中的表，或无法发现分表的组合分表（第一级为时间分表）返回 ErrTimeRangeRequired，需要指定时间范围
+func allShardTables(db *gorm.DB, strategy ShardingStrategy) ([]string, error) {
+	if _, ok := strategy.(timeRangeSharding); !ok {
+		return strategy.GetAllTableNames(strategy.GetBaseTableName()), nil
+	}
+	if timeStrategy, ok := strategy.(*TimeShardingStrategy); ok {
+		if tableNames, ok := timeStrategy.discoveredTables(); ok {
+			return tableNames, nil
+		}
+	}
+	if composite, ok := strategy.(*CompositeShardingStrategy); ok {
+		if _, ok := composite.secondary.(*TimeShardingStrategy); !ok {
+			return nil, fmt.Errorf("%w: cannot discover tables of %s", ErrTimeRangeRequired, strategy.GetBaseTableName())
+		}
+	}
+
+	tableNames, err := DiscoverExistingTables(db, strategy)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTimeRangeRequired, err)
+	}
+	return tableNames, nil
+}
+
+// tableDiscovery 缓存从数据库中发现的时间分表
+type tableDiscovery struct {
+	db      *gorm.DB
+	refresh time.Duration
+
+	mu       sync.Mutex
+	tables   []string
+	loadedAt time.Time
+}
+
+// EnableTableDiscovery 开启分表发现：跨表查询没有指定时间范围时，使用数据库中已存在的分表（DiscoverExistingTables），
+// 而不是最近一年生成的表名；发现的分表缓存 refresh（默认 1 分钟），刷新失败时继续使用上次的结果
+func (s *TimeShardingStrategy) EnableTableDiscovery(db *gorm.DB, refresh time.Duration) {
+	if refresh <= 0 {
+		refresh = defaultDiscoveryRefresh
+	}
+	s.discovery = &tableDiscovery{db: db, refresh: refresh}
+}
+
+// discoveredTables 获取发现的分表（未开启或从未发现成功时返回 false）
+func (s *TimeShardingStrategy) discoveredTables() ([]string, bool) {
+	discovery := s.discovery
+	if discovery == nil {
+		return nil, false
+	}
+
+	discovery.mu.Lock()
+	defer discovery.mu.Unlock()
+	if discovery.loadedAt.IsZero() || time.Since(discovery.loadedAt) >= discovery.refresh {
+		if tables, err := DiscoverExistingTables(discovery.db, s); err == nil {
+			discovery.tables = tables
+			discovery.loadedAt = time.Now()
+		} else if discovery.loadedAt.IsZero() {
+			return nil, false
+		}
+	}
+	return append([]string(nil), discovery.tables...), true
+}
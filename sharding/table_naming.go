@@ -0,0 +1,220 @@
+package sharding
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// templatePlaceholderPattern 匹配表名模板中的占位符，如 {base}、{index:02d}、{yyyy}
+var templatePlaceholderPattern = regexp.MustCompile(`\{(\w+)(?::([^}]*))?\}`)
+
+// indexFormatPattern 索引占位符的格式（如 02d、3d、d）
+var indexFormatPattern = regexp.MustCompile(`^0?\d*d$`)
+
+// timePlaceholderLayouts 时间占位符对应的时间格式
+var timePlaceholderLayouts = map[string]string{
+	"yyyy": "2006",
+	"yy":   "06",
+	"mm":   "01",
+	"dd":   "02",
+	"hh":   "15",
+	"mi":   "04",
+}
+
+// templatePart 表名模板的一段（文本或占位符）
+type templatePart struct {
+	literal string
+	name    string // 占位符名称（为空表示文本）
+	format  string // 索引的格式（如 "%02d"）
+}
+
+// tableNameTemplate 分表名称模板
+// 支持的占位符：{base} 基础表名；{index} 分表索引（可指定宽度，如 {index:02d}）；
+// {yyyy} {yy} {mm} {dd} {hh} {mi} 时间分表的年、两位年、月、日、小时、分钟
+type tableNameTemplate struct {
+	raw   string
+	parts []templatePart
+}
+
+// parseTableNameTemplate 解析分表名称模板
+func parseTableNameTemplate(raw string) (*tableNameTemplate, error) {
+	template := &tableNameTemplate{raw: raw}
+	last := 0
+	for _, match := range templatePlaceholderPattern.FindAllStringSubmatchIndex(raw, -1) {
+		if match[0] > last {
+			template.parts = append(template.parts, templatePart{literal: raw[last:match[0]]})
+		}
+		last = match[1]
+
+		name := raw[match[2]:match[3]]
+		part := templatePart{name: name}
+		format := ""
+		if match[4] >= 0 {
+			format = raw[match[4]:match[5]]
+		}
+		switch {
+		case name == "index":
+			if format == "" {
+				format = "d"
+			}
+			if !indexFormatPattern.MatchString(format) {
+				return nil, fmt.Errorf("invalid table name template %q: unsupported index format %q", raw, format)
+			}
+			part.format = "%" + format
+		case name == "base" || timePlaceholderLayouts[name] != "":
+			if format != "" {
+				return nil, fmt.Errorf("invalid table name template %q: placeholder {%s} does not take a format", raw, name)
+			}
+		default:
+			return nil, fmt.Errorf("invalid table name template %q: unknown placeholder {%s}", raw, name)
+		}
+		template.parts = append(template.parts, part)
+	}
+	if last < len(raw) {
+		template.parts = append(template.parts, templatePart{literal: raw[last:]})
+	}
+	return template, nil
+}
+
+// has 判断模板是否包含任意一个占位符
+func (t *tableNameTemplate) has(names ...string) bool {
+	for _, part := range t.parts {
+		for _, name := range names {
+			if part.name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// render 生成分表名称
+func (t *tableNameTemplate) render(baseTableName string, index int, tm time.Time) string {
+	var result strings.Builder
+	for _, part := range t.parts {
+		switch {
+		case part.name == "":
+			result.WriteString(part.literal)
+		case part.name == "base":
+			result.WriteString(baseTableName)
+		case part.name == "index":
+			fmt.Fprintf(&result, part.format, index)
+		default:
+			result.WriteString(tm.Format(timePlaceholderLayouts[part.name]))
+		}
+	}
+	return result.String()
+}
+
+// pattern 匹配该模板生成的所有分表名称的正则表达式
+func (t *tableNameTemplate) pattern(baseTableName string) *regexp.Regexp {
+	var result strings.Builder
+	result.WriteString("^")
+	for _, part := range t.parts {
+		switch part.name {
+		case "":
+			result.WriteString(regexp.QuoteMeta(part.literal))
+		case "base":
+			result.WriteString(regexp.QuoteMeta(baseTableName))
+		default:
+			result.WriteString(`\d+`)
+		}
+	}
+	result.WriteString("$")
+	return regexp.MustCompile(result.String())
+}
+
+// parseTime 解析该模板生成的时间分表名称中的时间（分表时间单位的起始时间）
+func (t *tableNameTemplate) parseTime(baseTableName, tableName string) (time.Time, bool) {
+	var expr, layout strings.Builder
+	expr.WriteString("^")
+	for _, part := range t.parts {
+		switch part.name {
+		case "":
+			expr.WriteString(regexp.QuoteMeta(part.literal))
+		case "base":
+			expr.WriteString(regexp.QuoteMeta(baseTableName))
+		default:
+			placeholderLayout := timePlaceholderLayouts[part.name]
+			fmt.Fprintf(&expr, `(\d{%d})`, len(placeholderLayout))
+			layout.WriteString(placeholderLayout + "|")
+		}
+	}
+	expr.WriteString("$")
+
+	match := regexp.MustCompile(expr.String()).FindStringSubmatch(tableName)
+	if match == nil {
+		return time.Time{}, false
+	}
+	parsed, err := time.Parse(layout.String(), strings.Join(match[1:], "|")+"|")
+	return parsed, err == nil
+}
+
+// parseIndexTemplate 解析按索引分表（Hash、取模、范围）使用的模板，必须包含 {index}
+func parseIndexTemplate(raw string) (*tableNameTemplate, error) {
+	template, err := parseTableNameTemplate(raw)
+	if err != nil {
+		return nil, err
+	}
+	if !template.has("index") {
+		return nil, fmt.Errorf("invalid table name template %q: missing {index}", raw)
+	}
+	return template, nil
+}
+
+// parseTimeTemplate 解析时间分表使用的模板，必须包含分表单位对应的所有时间占位符（如按月分表需要 {yyyy} 或 {yy} 和 {mm}）
+func parseTimeTemplate(raw string, unit TimeShardingUnit) (*tableNameTemplate, error) {
+	template, err := parseTableNameTemplate(raw)
+	if err != nil {
+		return nil, err
+	}
+	if template.has("index") {
+		return nil, fmt.Errorf("invalid table name template %q: {index} is not supported by time sharding", raw)
+	}
+
+	required := [][]string{{"yyyy", "yy"}}
+	if unit >= TimeShardingByMonth {
+		required = append(required, []string{"mm"})
+	}
+	if unit >= TimeShardingByDay {
+		required = append(required, []string{"dd"})
+	}
+	if unit >= TimeShardingByHour {
+		required = append(required, []string{"hh"})
+	}
+	if unit >= TimeShardingByMinute {
+		required = append(required, []string{"mi"})
+	}
+	for _, names := range required {
+		if !template.has(names...) {
+			return nil, fmt.Errorf("invalid table name template %q: missing {%s}", raw, names[0])
+		}
+	}
+	return template, nil
+}
+
+// formatIndexTableName 生成按索引分表的表名（未设置模板时为 基础表名_索引）
+func formatIndexTableName(template *tableNameTemplate, baseTableName string, index int) string {
+	if template == nil {
+		return fmt.Sprintf("%s_%d", baseTableName, index)
+	}
+	return template.render(baseTableName, index, time.Time{})
+}
+
+// nameTemplateProvider 设置了分表名称模板的策略
+type nameTemplateProvider interface {
+	tableNameTemplate() *tableNameTemplate
+}
+
+// shardTablePattern 匹配策略的分表名称的正则表达式（未设置模板时为 基础表名_数字）
+func shardTablePattern(strategy ShardingStrategy) *regexp.Regexp {
+	baseTableName := strategy.GetBaseTableName()
+	if provider, ok := strategy.(nameTemplateProvider); ok {
+		if template := provider.tableNameTemplate(); template != nil {
+			return template.pattern(baseTableName)
+		}
+	}
+	return regexp.MustCompile("^" + regexp.QuoteMeta(baseTableName) + `_\d+$`)
+}
@@ -0,0 +1,76 @@
+package sharding
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestIndexNameTemplate(t *testing.T) {
+	strategy := NewHashShardingStrategy("orders", "UserID", 4)
+	if err := strategy.SetNameTemplate("{base}_shard{index:02d}"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"orders_shard00", "orders_shard01", "orders_shard02", "orders_shard03"}
+	if got := strategy.GetAllTableNames("orders"); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetAllTableNames = %v, want %v", got, want)
+	}
+	pattern := shardTablePattern(strategy)
+	for _, tableName := range []string{"orders_shard02", "orders_shard123"} {
+		if !pattern.MatchString(tableName) {
+			t.Errorf("pattern %s does not match %s", pattern, tableName)
+		}
+	}
+	for _, tableName := range []string{"orders_2", "orders_shard", "xorders_shard01"} {
+		if pattern.MatchString(tableName) {
+			t.Errorf("pattern %s matches %s", pattern, tableName)
+		}
+	}
+
+	modulo := NewModuloShardingStrategy("o", "x", 3)
+	if err := modulo.SetNameTemplate("p{index:03d}_{base}"); err != nil {
+		t.Fatal(err)
+	}
+	if got := modulo.GetTableName("o", 5); got != "p002_o" {
+		t.Errorf("modulo table name = %s, want p002_o", got)
+	}
+}
+
+func TestInvalidNameTemplate(t *testing.T) {
+	strategy := NewModuloShardingStrategy("o", "x", 3)
+	for _, template := range []string{"{base}", "{base}_{idx}", "{index:x}", "{base}_{index"} {
+		if err := strategy.SetNameTemplate(template); err == nil {
+			t.Errorf("SetNameTemplate(%q) succeeded, want error", template)
+		}
+	}
+
+	timeStrategy := NewTimeShardingStrategy("logs", "CreatedAt", TimeShardingByMonth)
+	for _, template := range []string{"{base}_{yyyy}", "{base}_{mm}", "{base}_{index}"} {
+		if err := timeStrategy.SetNameTemplate(template); err == nil {
+			t.Errorf("time SetNameTemplate(%q) succeeded, want error", template)
+		}
+	}
+}
+
+func TestTimeNameTemplate(t *testing.T) {
+	strategy := NewTimeShardingStrategy("logs", "CreatedAt", TimeShardingByMonth)
+	if err := strategy.SetNameTemplate("{base}_{yyyy}_{mm}"); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	want := []string{"logs_2024_11", "logs_2024_12", "logs_2025_01", "logs_2025_02"}
+	if got := strategy.GetAllTableNamesInRange("logs", start, end); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetAllTableNamesInRange = %v, want %v", got, want)
+	}
+
+	period, ok := strategy.tablePeriod("logs_2024_12")
+	if !ok || period.Year() != 2024 || period.Month() != time.December {
+		t.Errorf("tablePeriod(logs_2024_12) = %v, %v", period, ok)
+	}
+	if !isKnownShardTable(strategy, "logs_2024_11") || isKnownShardTable(strategy, "logs_202411") {
+		t.Error("isKnownShardTable does not follow the template")
+	}
+}
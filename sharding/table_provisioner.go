@@ -0,0 +1,184 @@
+package sharding
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// defaultProvisionInterval 默认的预创建检查间隔
+	defaultProvisionInterval = time.Hour
+
+	// defaultProvisionPeriods 默认提前创建的时间单位数量
+	defaultProvisionPeriods = 3
+)
+
+// ProvisionerOptions 时间分表预创建配置
+type ProvisionerOptions struct {
+	Interval       time.Duration                     // 检查间隔（默认 1 小时，应小于分表的时间单位）
+	Periods        int                               // 在当前时间单位之后提前创建的时间单位数量（默认 3，如按月分表时创建之后 3 个月的分表）
+	OnTableCreated func(tableName string)            // 创建分表后调用（可选，已存在的分表不会调用）
+	OnError        func(tableName string, err error) // 创建分表失败时调用（可选），下次检查时重试
+}
+
+// provisionTarget 需要预创建的时间分表及其模型
+type provisionTarget struct {
+	strategy *TimeShardingStrategy
+	model    interface{}
+}
+
+// TableProvisioner 时间分表预创建器
+// 在后台定期检查已注册的时间分表，提前创建当前及之后 Periods 个时间单位的分表（如 logs_202401 ~ logs_202404），
+// 避免跨月（跨天）时第一批写入等待建表或并发建表
+type TableProvisioner struct {
+	db      *gorm.DB
+	options ProvisionerOptions
+
+	mu      sync.Mutex
+	targets []provisionTarget
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	wg       sync.WaitGroup // 后台检查 goroutine
+}
+
+// NewTableProvisioner 创建时间分表预创建器
+func NewTableProvisioner(db *gorm.DB, options ...ProvisionerOptions) *TableProvisioner {
+	opts := ProvisionerOptions{}
+	if len(options) > 0 {
+		opts = options[0]
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = defaultProvisionInterval
+	}
+	if opts.Periods <= 0 {
+		opts.Periods = defaultProvisionPeriods
+	}
+	return &TableProvisioner{
+		db:      db,
+		options: opts,
+		stop:    make(chan struct{}),
+	}
+}
+
+// Register 注册需要预创建的时间分表，model 为建表使用的模型
+func (p *TableProvisioner) Register(strategy *TimeShardingStrategy, model interface{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.targets = append(p.targets, provisionTarget{strategy: strategy, model: model})
+}
+
+// Start 立即检查一次，然后在后台按 Interval 定期检查
+func (p *TableProvisioner) Start() {
+	p.Provision(context.Background())
+
+	// 停止时取消正在执行的检查
+	ctx, cancel := context.WithCancel(context.Background())
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		defer cancel()
+
+		ticker := time.NewTicker(p.options.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.Provision(ctx)
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+	go func() {
+		<-p.stop
+		cancel()
+	}()
+}
+
+// Stop 停止后台检查
+func (p *TableProvisioner) Stop() {
+	p.stopOnce.Do(func() {
+		close(p.stop)
+	})
+}
+
+// Shutdown 停止后台检查并等待正在执行的检查结束（ctx 结束时不再等待）
+func (p *TableProvisioner) Shutdown(ctx context.Context) error {
+	p.Stop()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Provision 立即创建所有已注册的时间分表中缺少的分表（当前及之后 Periods 个时间单位），返回新创建的分表
+// 某个分表创建失败后继续创建其余分表，失败的分表汇总为 ShardErrors
+func (p *TableProvisioner) Provision(ctx context.Context) ([]string, error) {
+	p.mu.Lock()
+	targets := append([]provisionTarget(nil), p.targets...)
+	p.mu.Unlock()
+
+	db := p.db.WithContext(ctx)
+	now := time.Now()
+
+	var (
+		created   []string
+		shardErrs ShardErrors
+	)
+	for _, target := range targets {
+		for _, tableName := range provisionTableNames(target.strategy, now, p.options.Periods) {
+			if ctx.Err() != nil {
+				return created, ctx.Err()
+			}
+			if tableExists(db, tableName) {
+				continue
+			}
+
+			err := migrateTable(db, target.strategy, tableName, target.model, false)
+			if isDuplicateObjectError(err) {
+				// 其他实例或写入时的自动建表已经创建了该分表
+				continue
+			}
+			if err != nil {
+				shardErrs = append(shardErrs, &ShardError{Table: tableName, Err: err})
+				if p.options.OnError != nil {
+					p.options.OnError(tableName, fmt.Errorf("failed to provision table %s: %w", tableName, err))
+				}
+				continue
+			}
+
+			created = append(created, tableName)
+			if p.options.OnTableCreated != nil {
+				p.options.OnTableCreated(tableName)
+			}
+		}
+	}
+
+	if len(shardErrs) > 0 {
+		return created, shardErrs
+	}
+	return created, nil
+}
+
+// provisionTableNames 获取当前及之后 periods 个时间单位的分表名
+func provisionTableNames(strategy *TimeShardingStrategy, now time.Time, periods int) []string {
+	start := strategy.periodStart(now)
+	tableNames := make([]string, 0, periods+1)
+	for i := 0; i <= periods; i++ {
+		tableNames = append(tableNames, strategy.formatTableName(strategy.GetBaseTableName(), strategy.addPeriods(start, i)))
+	}
+	return tableNames
+}
@@ -0,0 +1,177 @@
+package sharding
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// TenantShardingStrategy 租户分表策略
+// 大租户使用独立的分表（如 orders_tenant_acme），其他租户按租户 ID Hash 到共享的分表池（如 orders_pool_3），
+// 租户增长后可以通过 PromoteTenant 升级为独立分表（需要自行将旧数据从共享分表迁移到独立分表）
+type TenantShardingStrategy struct {
+	baseTableName string
+	tenantField   string // 租户 ID 字段名（如 "tenant_id"）
+	poolSize      int    // 共享分表池的分表数量
+
+	mu        sync.RWMutex
+	dedicated map[string]string // 租户 ID 的字符串形式 -> 独立分表后缀
+
+	ShardOptionsMap // 分表级别的建表选项（通过 SetShardOptions 配置）
+}
+
+// NewTenantShardingStrategy 创建租户分表策略
+// baseTableName: 基础表名（如 "orders"）
+// tenantField: 租户 ID 字段名（如 "tenant_id"）
+// poolSize: 共享分表池的分表数量（小于 1 时为 1）
+// dedicatedTenants: 使用独立分表的租户 ID
+func NewTenantShardingStrategy(baseTableName, tenantField string, poolSize int, dedicatedTenants ...interface{}) *TenantShardingStrategy {
+	if poolSize < 1 {
+		poolSize = 1
+	}
+	strategy := &TenantShardingStrategy{
+		baseTableName: baseTableName,
+		tenantField:   tenantField,
+		poolSize:      poolSize,
+		dedicated:     make(map[string]string),
+	}
+	for _, tenant := range dedicatedTenants {
+		strategy.PromoteTenant(tenant)
+	}
+	return strategy
+}
+
+// PromoteTenant 将租户升级为独立分表，返回独立分表名称（之后的读写路由到独立分表）
+func (s *TenantShardingStrategy) PromoteTenant(tenant interface{}) string {
+	key := listValueKey(tenant)
+	suffix := tenantTableSuffix(key)
+
+	s.mu.Lock()
+	s.dedicated[key] = suffix
+	s.mu.Unlock()
+	return s.dedicatedTableName(s.baseTableName, suffix)
+}
+
+// DemoteTenant 将租户降级回共享分表池
+func (s *TenantShardingStrategy) DemoteTenant(tenant interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.dedicated, listValueKey(tenant))
+}
+
+// PromoteTenantsBySize 将数据量（如行数）达到阈值的租户升级为独立分表，返回新升级的租户的独立分表名称（按名称排序）
+func (s *TenantShardingStrategy) PromoteTenantsBySize(sizes map[interface{}]int64, threshold int64) []string {
+	promoted := make([]string, 0)
+	for tenant, size := range sizes {
+		if size < threshold || s.IsDedicated(tenant) {
+			continue
+		}
+		promoted = append(promoted, s.PromoteTenant(tenant))
+	}
+	sort.Strings(promoted)
+	return promoted
+}
+
+// IsDedicated 判断租户是否使用独立分表
+func (s *TenantShardingStrategy) IsDedicated(tenant interface{}) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.dedicated[listValueKey(tenant)]
+	return ok
+}
+
+// DedicatedTenants 获取使用独立分表的租户 ID（字符串形式，按名称排序）
+func (s *TenantShardingStrategy) DedicatedTenants() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tenants := make([]string, 0, len(s.dedicated))
+	for tenant := range s.dedicated {
+		tenants = append(tenants, tenant)
+	}
+	sort.Strings(tenants)
+	return tenants
+}
+
+// TenantTables 获取单个租户的数据所在的分表
+// 独立分表的租户同时返回其在共享分表池中的分表（升级前写入的数据可能尚未迁移），独立分表在前
+func (s *TenantShardingStrategy) TenantTables(tenant interface{}) []string {
+	poolTable := s.poolTableName(s.baseTableName, tenant)
+
+	s.mu.RLock()
+	suffix, ok := s.dedicated[listValueKey(tenant)]
+	s.mu.RUnlock()
+	if !ok {
+		return []string{poolTable}
+	}
+	return []string{s.dedicatedTableName(s.baseTableName, suffix), poolTable}
+}
+
+// GetTableName 根据租户 ID 获取实际表名
+func (s *TenantShardingStrategy) GetTableName(baseTableName string, shardingValue interface{}) string {
+	s.mu.RLock()
+	suffix, ok := s.dedicated[listValueKey(shardingValue)]
+	s.mu.RUnlock()
+	if ok {
+		return s.dedicatedTableName(baseTableName, suffix)
+	}
+	return s.poolTableName(baseTableName, shardingValue)
+}
+
+// GetAllTableNames 获取所有分表名称（共享分表池和所有独立分表）
+func (s *TenantShardingStrategy) GetAllTableNames(baseTableName string) []string {
+	tableNames := make([]string, 0, s.poolSize)
+	for i := 0; i < s.poolSize; i++ {
+		tableNames = append(tableNames, fmt.Sprintf("%s_pool_%d", baseTableName, i))
+	}
+
+	s.mu.RLock()
+	dedicated := make([]string, 0, len(s.dedicated))
+	for _, suffix := range s.dedicated {
+		dedicated = append(dedicated, s.dedicatedTableName(baseTableName, suffix))
+	}
+	s.mu.RUnlock()
+
+	sort.Strings(dedicated)
+	return append(tableNames, dedicated...)
+}
+
+// GetShardingValue 从模型对象中提取租户 ID
+func (s *TenantShardingStrategy) GetShardingValue(value interface{}) (interface{}, error) {
+	return ExtractValue(value, s.tenantField)
+}
+
+// GetBaseTableName 获取基础表名
+func (s *TenantShardingStrategy) GetBaseTableName() string {
+	return s.baseTableName
+}
+
+// GetShardingKey 获取分表键字段名
+func (s *TenantShardingStrategy) GetShardingKey() string {
+	return s.tenantField
+}
+
+// poolTableName 获取租户在共享分表池中的分表名称
+func (s *TenantShardingStrategy) poolTableName(baseTableName string, tenant interface{}) string {
+	index := hashShardingValue(listValueKey(tenant)) % uint64(s.poolSize)
+	return fmt.Sprintf("%s_pool_%d", baseTableName, index)
+}
+
+// dedicatedTableName 获取独立分表名称
+func (s *TenantShardingStrategy) dedicatedTableName(baseTableName, suffix string) string {
+	return fmt.Sprintf("%s_tenant_%s", baseTableName, suffix)
+}
+
+// tenantTableSuffix 将租户 ID 转换为可以用于表名的后缀（小写，字母、数字以外的字符替换为 '_'）
+func tenantTableSuffix(tenant string) string {
+	var result strings.Builder
+	for _, r := range strings.ToLower(tenant) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			result.WriteRune(r)
+		} else {
+			result.WriteByte('_')
+		}
+	}
+	return result.String()
+}
@@ -0,0 +1,141 @@
+package sharding
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// 时间戳格式，可以与普通的时间格式一起传给 NewTimeConverter 和 NewTimeShardingStrategyWithType，
+// 指定整数和数字字符串的时间戳单位（未指定时大于 1e10 的值按毫秒处理，否则按秒处理）
+const (
+	LayoutUnix      = "unix"      // Unix 时间戳（秒）
+	LayoutUnixMilli = "unixmilli" // Unix 时间戳（毫秒）
+	LayoutUnixMicro = "unixmicro" // Unix 时间戳（微秒）
+	LayoutUnixNano  = "unixnano"  // Unix 时间戳（纳秒）
+)
+
+// DefaultTimeLayouts 默认支持的字符串时间格式（按顺序尝试），未指定格式的转换器使用该列表，可以追加自定义格式
+var DefaultTimeLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05Z07:00",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05.000",
+	"2006-01-02",
+	time.RFC3339,
+	time.RFC3339Nano,
+}
+
+// defaultTimeConverter 使用默认格式的转换器
+var defaultTimeConverter = NewTimeConverter()
+
+// TimeConverter 时间值转换器，将 time.Time、时间戳、字符串等分表键值转换为 time.Time
+type TimeConverter struct {
+	layouts []string // 字符串时间格式（为空时使用 DefaultTimeLayouts，只指定了时间戳格式时也是如此）
+	unit    string   // 时间戳单位（LayoutUnix 等，为空时自动识别秒/毫秒）
+}
+
+// NewTimeConverter 创建时间值转换器
+// layouts: 字符串时间格式（如 "02/01/2006"），可以包含 LayoutUnixMicro 等时间戳格式；为空时使用 DefaultTimeLayouts
+func NewTimeConverter(layouts ...string) *TimeConverter {
+	converter := &TimeConverter{}
+	for _, layout := range layouts {
+		switch layout {
+		case LayoutUnix, LayoutUnixMilli, LayoutUnixMicro, LayoutUnixNano:
+			if converter.unit == "" {
+				converter.unit = layout
+			}
+		default:
+			converter.layouts = append(converter.layouts, layout)
+		}
+	}
+	return converter
+}
+
+// Layouts 获取字符串时间格式
+func (c *TimeConverter) Layouts() []string {
+	if c.layouts == nil {
+		return DefaultTimeLayouts
+	}
+	return c.layouts
+}
+
+// Convert 将时间值转换为 time.Time，无法转换时返回 ErrInvalidTimeValue
+func (c *TimeConverter) Convert(value interface{}) (time.Time, error) {
+	switch v := value.(type) {
+	case nil:
+		return time.Time{}, fmt.Errorf("%w: nil", ErrInvalidTimeValue)
+	case time.Time:
+		return v, nil
+	case *time.Time:
+		if v != nil {
+			return *v, nil
+		}
+		return time.Time{}, fmt.Errorf("%w: nil *time.Time", ErrInvalidTimeValue)
+	case string:
+		return c.Parse(v)
+	}
+
+	// 尝试通过反射获取值
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return time.Time{}, fmt.Errorf("%w: nil %T", ErrInvalidTimeValue, value)
+		}
+		rv = rv.Elem()
+	}
+
+	switch {
+	case rv.CanInt():
+		return timestampToTime(rv.Int(), c.unit), nil
+	case rv.CanUint():
+		return timestampToTime(int64(rv.Uint()), c.unit), nil
+	case rv.CanInterface():
+		switch v := rv.Interface().(type) {
+		case string:
+			return c.Parse(v)
+		case time.Time:
+			return v, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: unsupported type %T", ErrInvalidTimeValue, value)
+}
+
+// Parse 按格式解析字符串时间，都不匹配时尝试作为时间戳解析
+func (c *TimeConverter) Parse(str string) (time.Time, error) {
+	if str == "" {
+		return time.Time{}, fmt.Errorf("%w: empty string", ErrInvalidTimeValue)
+	}
+
+	for _, layout := range c.Layouts() {
+		if t, err := time.Parse(layout, str); err == nil {
+			return t, nil
+		}
+	}
+
+	// 尝试作为 Unix 时间戳字符串解析
+	if timestamp, err := strconv.ParseInt(str, 10, 64); err == nil {
+		return timestampToTime(timestamp, c.unit), nil
+	}
+
+	return time.Time{}, fmt.Errorf("%w: %q", ErrInvalidTimeValue, str)
+}
+
+// timestampToTime 将时间戳转换为 time.Time，unit 为 LayoutUnix 等时间戳格式（为空时大于 1e10 的值按毫秒处理，否则按秒处理）
+func timestampToTime(timestamp int64, unit string) time.Time {
+	switch unit {
+	case LayoutUnix:
+		return time.Unix(timestamp, 0)
+	case LayoutUnixMilli:
+		return time.UnixMilli(timestamp)
+	case LayoutUnixMicro:
+		return time.UnixMicro(timestamp)
+	case LayoutUnixNano:
+		return time.Unix(0, timestamp)
+	}
+	if timestamp > 1e10 {
+		return time.UnixMilli(timestamp)
+	}
+	return time.Unix(timestamp, 0)
+}
@@ -1,8 +1,8 @@
 package sharding
 
 import (
-	"reflect"
-	"strconv"
+	"fmt"
+	"strings"
 	"time"
 )
 
@@ -32,10 +32,17 @@ const (
 // TimeShardingStrategy 基于时间的分表策略
 type TimeShardingStrategy struct {
 	baseTableName string
-	timeField     string           // 时间字段名（如 "created_at"）
-	unit          TimeShardingUnit // 分表单位
-	timeFormat    string           // 时间格式字符串
-	fieldType     TimeFieldType    // 时间字段类型
+	timeField     string             // 时间字段名（如 "created_at"）
+	unit          TimeShardingUnit   // 分表单位
+	timeFormat    string             // 时间格式字符串
+	fieldType     TimeFieldType      // 时间字段类型
+	nameTemplate  *tableNameTemplate // 分表名称模板（为空时为 基础表名_时间格式）
+	strict        bool               // 严格模式：无法转换的时间值返回错误，而不是使用当前时间
+	converter     *TimeConverter     // 时间值转换器（字符串时间格式、时间戳单位）
+	retention     *RetentionPolicy   // 过期分表的保留策略（通过 SetRetentionPolicy 配置）
+	discovery     *tableDiscovery    // 从数据库中发现已存在的分表（通过 EnableTableDiscovery 开启）
+
+	ShardOptionsMap // 分表级别的建表选项（通过 SetShardOptions 配置）
 }
 
 // NewTimeShardingStrategy 创建时间分表策略
@@ -51,12 +58,14 @@ func NewTimeShardingStrategy(baseTableName, timeField string, unit TimeShardingU
 // timeField: 时间字段名（如 "created_at"）
 // unit: 分表单位（年/月/日/小时/分钟）
 // fieldType: 时间字段类型（自动识别/Time/时间戳/日期等）
-func NewTimeShardingStrategyWithType(baseTableName, timeField string, unit TimeShardingUnit, fieldType TimeFieldType) *TimeShardingStrategy {
+// layouts: 字符串时间格式（可选，如 "02/01/2006"、LayoutUnixMicro），默认使用 DefaultTimeLayouts
+func NewTimeShardingStrategyWithType(baseTableName, timeField string, unit TimeShardingUnit, fieldType TimeFieldType, layouts ...string) *TimeShardingStrategy {
 	strategy := &TimeShardingStrategy{
 		baseTableName: baseTableName,
 		timeField:     timeField,
 		unit:          unit,
 		fieldType:     fieldType,
+		converter:     NewTimeConverter(layouts...),
 	}
 	strategy.timeFormat = strategy.getTimeFormat(unit)
 	return strategy
@@ -65,7 +74,31 @@ func NewTimeShardingStrategyWithType(baseTableName, timeField string, unit TimeS
 // GetTableName 根据时间值获取实际表名
 func (s *TimeShardingStrategy) GetTableName(baseTableName string, shardingValue interface{}) string {
 	t := s.convertToTime(shardingValue)
-	return FormatTimeTableName(baseTableName, t, s.timeFormat)
+	return s.formatTableName(baseTableName, t)
+}
+
+// GetTableNameE 根据时间值获取实际表名
+// 严格模式下无法转换的时间值（nil、空字符串、无法解析的字符串等）返回 ErrInvalidTimeValue；非严格模式与 GetTableName 相同，使用当前时间
+func (s *TimeShardingStrategy) GetTableNameE(baseTableName string, shardingValue interface{}) (string, error) {
+	t, err := s.convertToTimeE(shardingValue)
+	if err != nil {
+		if s.strict {
+			return "", err
+		}
+		t = time.Now()
+	}
+	return s.formatTableName(baseTableName, t), nil
+}
+
+// SetStrict 设置严格模式：GetShardingValue、GetTableNameE、ParseTimeRange 对无法转换的时间值返回 ErrInvalidTimeValue，
+// 插件在写入和查询路由时返回该错误，避免数据被写入当前时间对应的分表
+func (s *TimeShardingStrategy) SetStrict(strict bool) {
+	s.strict = strict
+}
+
+// IsStrict 是否为严格模式
+func (s *TimeShardingStrategy) IsStrict() bool {
+	return s.strict
 }
 
 // GetAllTableNames 获取所有分表名称（需要指定时间范围）
@@ -82,7 +115,7 @@ func (s *TimeShardingStrategy) GetAllTableNamesInRange(baseTableName string, sta
 	currentTime := startTime
 
 	for currentTime.Before(endTime) || currentTime.Equal(endTime) {
-		tableName := FormatTimeTableName(baseTableName, currentTime, s.timeFormat)
+		tableName := s.formatTableName(baseTableName, currentTime)
 		tableNames = append(tableNames, tableName)
 
 		// 移动到下一个时间单位
@@ -113,12 +146,90 @@ func (s *TimeShardingStrategy) GetAllTableNamesInRange(baseTableName string, sta
 	return result
 }
 
+// SetNameTemplate 设置分表名称模板（如 "{base}_{yyyy}_{mm}"），需要包含分表单位对应的时间占位符，需要在注册策略前调用
+func (s *TimeShardingStrategy) SetNameTemplate(template string) error {
+	parsed, err := parseTimeTemplate(template, s.unit)
+	if err != nil {
+		return err
+	}
+	s.nameTemplate = parsed
+	return nil
+}
+
+// tableNameTemplate 获取分表名称模板
+func (s *TimeShardingStrategy) tableNameTemplate() *tableNameTemplate {
+	return s.nameTemplate
+}
+
+// formatTableName 生成时间对应的分表名称
+func (s *TimeShardingStrategy) formatTableName(baseTableName string, t time.Time) string {
+	if s.nameTemplate == nil {
+		return FormatTimeTableName(baseTableName, t, s.timeFormat)
+	}
+	return s.nameTemplate.render(baseTableName, 0, t)
+}
+
+// tablePeriod 解析分表名称对应的时间（分表时间单位的起始时间，如 logs_202401 为 2024-01-01）
+func (s *TimeShardingStrategy) tablePeriod(tableName string) (time.Time, bool) {
+	return s.tablePeriodOf(s.baseTableName, tableName)
+}
+
+// tablePeriodOf 从 baseTableName 下的分表名称中解析分表的时间（组合分表中基础表名为上一级策略的分表）
+func (s *TimeShardingStrategy) tablePeriodOf(baseTableName, tableName string) (time.Time, bool) {
+	if s.nameTemplate != nil {
+		return s.nameTemplate.parseTime(baseTableName, tableName)
+	}
+	suffix, ok := strings.CutPrefix(tableName, baseTableName+"_")
+	if !ok || len(suffix) != len(s.timeFormat) {
+		return time.Time{}, false
+	}
+	period, err := time.Parse(s.timeFormat, suffix)
+	return period, err == nil
+}
+
+// periodStart 获取时间所在分表时间单位的起始时间（如按月分表时 2024-01-31 为 2024-01-01）
+func (s *TimeShardingStrategy) periodStart(t time.Time) time.Time {
+	switch s.unit {
+	case TimeShardingByYear:
+		return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, t.Location())
+	case TimeShardingByMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	case TimeShardingByDay:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	case TimeShardingByHour:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, t.Location())
+	}
+}
+
+// addPeriods 将时间单位的起始时间向后移动 n 个分表时间单位
+func (s *TimeShardingStrategy) addPeriods(start time.Time, n int) time.Time {
+	switch s.unit {
+	case TimeShardingByYear:
+		return start.AddDate(n, 0, 0)
+	case TimeShardingByMonth:
+		return start.AddDate(0, n, 0)
+	case TimeShardingByDay:
+		return start.AddDate(0, 0, n)
+	case TimeShardingByHour:
+		return start.Add(time.Duration(n) * time.Hour)
+	default:
+		return start.Add(time.Duration(n) * time.Minute)
+	}
+}
+
 // GetShardingValue 从模型对象中提取时间字段值
 func (s *TimeShardingStrategy) GetShardingValue(value interface{}) (interface{}, error) {
 	timeValue, err := ExtractValue(value, s.timeField)
 	if err != nil {
 		return nil, err
 	}
+	if s.strict {
+		if _, err := s.convertToTimeE(timeValue); err != nil {
+			return nil, fmt.Errorf("invalid value of %s: %w", s.timeField, err)
+		}
+	}
 
 	// 如果已经指定了字段类型，使用指定的类型转换
 	if s.fieldType != TimeFieldTypeAuto {
@@ -129,95 +240,36 @@ func (s *TimeShardingStrategy) GetShardingValue(value interface{}) (interface{},
 	return s.convertToTime(timeValue), nil
 }
 
-// convertToTime 将各种类型的时间值转换为 time.Time
+// convertToTime 将各种类型的时间值转换为 time.Time（无法转换时使用当前时间）
 func (s *TimeShardingStrategy) convertToTime(value interface{}) time.Time {
-	if value == nil {
-		return time.Now()
-	}
-
-	// 如果已经是指定类型，直接使用
-	if s.fieldType != TimeFieldTypeAuto {
-		result := s.convertByType(value, s.fieldType)
-		if t, ok := result.(time.Time); ok {
-			return t
-		}
+	t, err := s.convertToTimeE(value)
+	if err != nil {
 		return time.Now()
 	}
+	return t
+}
 
-	// 自动识别类型
-	switch v := value.(type) {
-	case time.Time:
-		return v
-
-	case int:
-		// Unix 时间戳（秒）
-		return time.Unix(int64(v), 0)
-	case int32:
-		return time.Unix(int64(v), 0)
-	case int64:
-		// 判断是秒还是毫秒（通常 > 1e10 的是毫秒）
-		if v > 1e10 {
-			return time.Unix(v/1000, (v%1000)*1e6)
-		}
-		return time.Unix(v, 0)
-
-	case uint:
-		return time.Unix(int64(v), 0)
-	case uint32:
-		return time.Unix(int64(v), 0)
-	case uint64:
-		// 判断是秒还是毫秒
-		if v > 1e10 {
-			return time.Unix(int64(v/1000), int64((v%1000)*1e6))
-		}
-		return time.Unix(int64(v), 0)
-
-	case string:
-		return s.parseStringTime(v)
-
-	case *time.Time:
-		if v != nil {
-			return *v
-		}
-		return time.Now()
-
-	default:
-		// 尝试通过反射获取值
-		rv := reflect.ValueOf(value)
-		if rv.Kind() == reflect.Ptr {
-			if rv.IsNil() {
-				return time.Now()
-			}
-			rv = rv.Elem()
-		}
-
-		// 尝试作为整数时间戳
-		if rv.CanInt() {
-			timestamp := rv.Int()
-			if timestamp > 1e10 {
-				return time.Unix(timestamp/1000, (timestamp%1000)*1e6)
-			}
-			return time.Unix(timestamp, 0)
-		}
-
-		// 尝试作为无符号整数时间戳
-		if rv.CanUint() {
-			timestamp := rv.Uint()
-			if timestamp > 1e10 {
-				return time.Unix(int64(timestamp/1000), int64((timestamp%1000)*1e6))
+// convertToTimeE 将各种类型的时间值转换为 time.Time，无法转换时返回 ErrInvalidTimeValue
+func (s *TimeShardingStrategy) convertToTimeE(value interface{}) (time.Time, error) {
+	// 指定为时间戳类型时，整数按指定的单位（秒/毫秒）转换
+	if s.fieldType == TimeFieldTypeTimestamp || s.fieldType == TimeFieldTypeTimestampMs {
+		if n, ok := integerBits(value); ok {
+			if s.fieldType == TimeFieldTypeTimestampMs {
+				return time.UnixMilli(int64(n)), nil
 			}
-			return time.Unix(int64(timestamp), 0)
+			return time.Unix(int64(n), 0), nil
 		}
+	}
 
-		// 尝试转换为字符串再解析
-		if rv.CanInterface() {
-			if str, ok := rv.Interface().(string); ok {
-				return s.parseStringTime(str)
-			}
-		}
+	return s.timeConverter().Convert(value)
+}
 
-		return time.Now()
+// timeConverter 获取策略的时间值转换器（直接构造的策略使用默认转换器）
+func (s *TimeShardingStrategy) timeConverter() *TimeConverter {
+	if s.converter == nil {
+		return defaultTimeConverter
 	}
+	return s.converter
 }
 
 // convertByType 根据指定的类型转换时间值
@@ -294,7 +346,7 @@ func (s *TimeShardingStrategy) convertToTimestamp(value interface{}, isMilliseco
 		}
 	case string:
 		// 尝试解析字符串
-		t := s.parseStringTime(v)
+		t := s.convertToTime(v)
 		if isMillisecond {
 			return t.UnixMilli()
 		}
@@ -303,46 +355,16 @@ func (s *TimeShardingStrategy) convertToTimestamp(value interface{}, isMilliseco
 	return 0
 }
 
-// parseStringTime 解析字符串时间
-func (s *TimeShardingStrategy) parseStringTime(str string) time.Time {
-	if str == "" {
-		return time.Now()
-	}
-
-	// 尝试多种时间格式
-	formats := []string{
-		"2006-01-02 15:04:05",
-		"2006-01-02T15:04:05Z07:00",
-		"2006-01-02T15:04:05",
-		"2006-01-02 15:04:05.000",
-		"2006-01-02",
-		time.RFC3339,
-		time.RFC3339Nano,
-	}
-
-	for _, format := range formats {
-		if t, err := time.Parse(format, str); err == nil {
-			return t
-		}
-	}
-
-	// 尝试作为 Unix 时间戳字符串解析
-	if timestamp, err := strconv.ParseInt(str, 10, 64); err == nil {
-		// 判断是秒还是毫秒
-		if timestamp > 1e10 {
-			return time.Unix(timestamp/1000, (timestamp%1000)*1e6)
-		}
-		return time.Unix(timestamp, 0)
-	}
-
-	return time.Now()
-}
-
 // GetBaseTableName 获取基础表名
 func (s *TimeShardingStrategy) GetBaseTableName() string {
 	return s.baseTableName
 }
 
+// GetShardingKey 获取分表键字段名
+func (s *TimeShardingStrategy) GetShardingKey() string {
+	return s.timeField
+}
+
 // getTimeFormat 根据分表单位获取时间格式
 func (s *TimeShardingStrategy) getTimeFormat(unit TimeShardingUnit) string {
 	switch unit {
@@ -368,7 +390,16 @@ func (s *TimeShardingStrategy) GetTimeFieldType() TimeFieldType {
 
 // ParseTimeRange 从查询条件中解析时间范围（辅助函数，用于跨表查询优化）
 // 支持从 int64 时间戳、time.Time 或字符串时间中提取时间范围
+// 严格模式下无法转换的时间值返回 ErrInvalidTimeValue
 func (s *TimeShardingStrategy) ParseTimeRange(startValue, endValue interface{}) (time.Time, time.Time, error) {
+	if s.strict {
+		if _, err := s.convertToTimeE(startValue); err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		if _, err := s.convertToTimeE(endValue); err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	}
 	startTime := s.convertToTime(startValue)
 	endTime := s.convertToTime(endValue)
 
@@ -0,0 +1,107 @@
+package sharding
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// TokenProvider 提供短期数据库凭证（如 AWS RDS IAM 认证令牌、GCP Cloud SQL 访问令牌）
+// 每次建立新的物理连接时调用，返回的令牌作为密码使用
+// info: DSN 信息（包含用户名、地址等，Password 为空）
+type TokenProvider func(ctx context.Context, info *DSNInfo) (string, error)
+
+// OpenWithTokenProvider 使用 TokenProvider 打开数据库连接，DSN 中无需包含密码
+// 连接池中的每个新连接都会通过 provider 获取最新的令牌，因此令牌过期后新连接仍然可以建立
+// 注意：AWS RDS IAM 认证需要在 DSN 中开启 TLS 和 allowCleartextPasswords=true
+func OpenWithTokenProvider(dsn string, config *gorm.Config, provider TokenProvider) (*gorm.DB, error) {
+	sqlDB, err := openTokenSQLDB(dsn, provider)
+	if err != nil {
+		return nil, err
+	}
+	return gorm.Open(mysql.New(mysql.Config{Conn: sqlDB}), config)
+}
+
+// OpenWithAutoCreateDBAndTokenProvider 使用 TokenProvider 打开数据库连接，如果数据库不存在则自动创建
+func OpenWithAutoCreateDBAndTokenProvider(dsn string, config *gorm.Config, charset, collation string, provider TokenProvider) (*gorm.DB, error) {
+	return openWithAutoCreateDB(dsn, charset, collation, func(dsn string) (*gorm.DB, error) {
+		return OpenWithTokenProvider(dsn, config, provider)
+	})
+}
+
+// openTokenSQLDB 创建在每次建立连接前刷新密码的 *sql.DB
+func openTokenSQLDB(dsn string, provider TokenProvider) (*sql.DB, error) {
+	if provider == nil {
+		return nil, fmt.Errorf("token provider is required")
+	}
+
+	dsnInfo, err := ParseDSN(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse DSN: %w", err)
+	}
+
+	driverConfig, err := mysqldriver.ParseDSN(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse DSN: %w", err)
+	}
+
+	err = driverConfig.Apply(mysqldriver.BeforeConnect(func(ctx context.Context, cfg *mysqldriver.Config) error {
+		info := *dsnInfo
+		info.Password = ""
+		token, err := provider(ctx, &info)
+		if err != nil {
+			return fmt.Errorf("failed to get database token: %w", err)
+		}
+		cfg.Passwd = token
+		return nil
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	connector, err := mysqldriver.NewConnector(driverConfig)
+	if err != nil {
+		return nil, err
+	}
+	return sql.OpenDB(connector), nil
+}
+
+// cachedToken 缓存的令牌
+type cachedToken struct {
+	token     string
+	expiresAt time.Time
+}
+
+// CachedTokenProvider 包装 TokenProvider，在 ttl 内复用同一用户和地址的令牌
+// 避免连接池扩容或多个分表数据库同时建立连接时重复生成令牌（如 RDS IAM 令牌有效期为 15 分钟）
+func CachedTokenProvider(provider TokenProvider, ttl time.Duration) TokenProvider {
+	var mu sync.Mutex
+	tokens := make(map[string]cachedToken)
+
+	return func(ctx context.Context, info *DSNInfo) (string, error) {
+		key := info.Username + "@" + info.Protocol + "(" + info.Address + ")"
+
+		mu.Lock()
+		cached, ok := tokens[key]
+		mu.Unlock()
+		if ok && time.Now().Before(cached.expiresAt) {
+			return cached.token, nil
+		}
+
+		token, err := provider(ctx, info)
+		if err != nil {
+			return "", err
+		}
+
+		mu.Lock()
+		tokens[key] = cachedToken{token: token, expiresAt: time.Now().Add(ttl)}
+		mu.Unlock()
+		return token, nil
+	}
+}
@@ -0,0 +1,69 @@
+package sharding
+
+import (
+	"gorm.io/gorm"
+)
+
+// Page 类型化的分页结果（与 Paginator 相同，Data 为 []T）
+type Page[T any] struct {
+	Page       int   `json:"page"`         // 当前页码（从1开始）
+	PageSize   int   `json:"page_size"`    // 每页数量
+	Total      int64 `json:"total"`        // 总记录数
+	TotalPages int   `json:"total_pages"`  // 总页数
+	Data       []T   `json:"data"`         // 数据列表
+	OutOfRange bool  `json:"out_of_range"` // 请求的页码是否超出总页数
+	Partial    bool  `json:"partial"`      // 部分分表失败或被排除，结果只包含成功的分表的数据（同时返回 ShardErrors）
+}
+
+// Query 跨表查询，返回类型化的结果（见 CrossTableQuery）
+//
+//	users, err := sharding.Query[User](db, strategy, func(db *gorm.DB) *gorm.DB {
+//		return db.Where("status = ?", 1)
+//	})
+//
+// 部分分表失败时（ContinueOnError 或数据源被排除）同时返回成功分表的结果和 ShardErrors
+func Query[T any](db *gorm.DB, strategy ShardingStrategy, queryBuilder QueryBuilder, options ...ShardQueryOptions) ([]T, error) {
+	var results []T
+	err := CrossTableQuery(db, strategy, &results, queryBuilder, options...)
+	if err != nil && !isPartialShardError(err) {
+		return nil, err
+	}
+	return results, err
+}
+
+// Paginate 跨表分页查询，返回类型化的分页结果（见 CrossTablePaginate）
+func Paginate[T any](db *gorm.DB, strategy ShardingStrategy, page, pageSize int, queryBuilder QueryBuilder, options ...PaginateOptions) (*Page[T], error) {
+	var results []T
+	paginator, err := CrossTablePaginate(db, strategy, &results, page, pageSize, queryBuilder, options...)
+	if paginator == nil {
+		return nil, err
+	}
+	return &Page[T]{
+		Page:       paginator.Page,
+		PageSize:   paginator.PageSize,
+		Total:      paginator.Total,
+		TotalPages: paginator.TotalPages,
+		Data:       results,
+		OutOfRange: paginator.OutOfRange,
+		Partial:    paginator.Partial,
+	}, err
+}
+
+// Create 将记录写入分表键值对应的分表，多条记录时按分表分组批量写入（见 CrossTableBatchCreate）
+func Create[T any](db *gorm.DB, strategy ShardingStrategy, values ...*T) error {
+	if len(values) != 1 {
+		return CrossTableBatchCreate(db, strategy, values)
+	}
+	if err := checkWritable(strategy.GetBaseTableName()); err != nil {
+		return err
+	}
+	shardingValue, err := strategy.GetShardingValue(values[0])
+	if err != nil {
+		return err
+	}
+	tableName, err := GetTableNameE(strategy, strategy.GetBaseTableName(), shardingValue)
+	if err != nil {
+		return err
+	}
+	return db.Table(tableName).Create(values[0]).Error
+}